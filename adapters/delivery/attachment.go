@@ -0,0 +1,135 @@
+package exportdelivery
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/goliatone/go-export/export"
+)
+
+var errAttachmentTooLarge = export.NewError(export.KindValidation, "attachment size exceeds limit", nil)
+
+// prepareAttachment loads the artifact as an attachment, applying the
+// request's AttachmentFallback when it exceeds Limits.MaxAttachmentSize. The
+// returned fallback is the action taken: empty, AttachmentFallbackZip, or
+// AttachmentFallbackLink (no attachment; the caller switches to a link).
+func (s *Service) prepareAttachment(ctx context.Context, req Request, ref export.ArtifactRef) (*Attachment, AttachmentFallback, error) {
+	attachment, err := s.loadAttachment(ctx, ref)
+	if err == nil {
+		return attachment, "", nil
+	}
+	if !errors.Is(err, errAttachmentTooLarge) {
+		return nil, "", err
+	}
+
+	policy := req.AttachmentFallback
+	switch policy {
+	case AttachmentFallbackZip, AttachmentFallbackZipThenLink:
+		filename := resolveNotifyFilename(ref.Meta, "", req.Export.Definition, req.Export.Format)
+		zipped, fits, err := s.zipArtifacts(ctx, strings.TrimSuffix(filename, path.Ext(filename))+".zip", []zipSource{{
+			key:  ref.Key,
+			name: filename,
+		}})
+		if err != nil {
+			return nil, "", err
+		}
+		if fits {
+			return zipped, AttachmentFallbackZip, nil
+		}
+		if policy == AttachmentFallbackZip {
+			return nil, "", errAttachmentTooLarge
+		}
+		return nil, AttachmentFallbackLink, nil
+	case AttachmentFallbackLink:
+		return nil, AttachmentFallbackLink, nil
+	default:
+		return nil, "", err
+	}
+}
+
+type zipSource struct {
+	key  string
+	name string
+}
+
+// zipArtifacts archives files from the artifact store into a single
+// attachment. fits is false when the archive would exceed the attachment
+// limit; archiving stops as soon as the limit is crossed.
+func (s *Service) zipArtifacts(ctx context.Context, filename string, files []zipSource) (*Attachment, bool, error) {
+	buf := &cappedBuffer{limit: s.limits.MaxAttachmentSize}
+	zw := zip.NewWriter(buf)
+	names := make(map[string]int)
+	for _, file := range files {
+		reader, _, err := s.store.Open(ctx, file.key)
+		if err != nil {
+			return nil, false, err
+		}
+		w, err := zw.Create(uniqueZipName(names, file.name))
+		if err == nil {
+			_, err = io.Copy(w, reader)
+		}
+		_ = reader.Close()
+		if errors.Is(err, errAttachmentTooLarge) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		if errors.Is(err, errAttachmentTooLarge) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return &Attachment{
+		Filename:    filename,
+		ContentType: "application/zip",
+		Data:        buf.Bytes(),
+		Size:        int64(buf.Len()),
+	}, true, nil
+}
+
+// cappedBuffer rejects writes that would grow it past limit, so oversized
+// archives are abandoned without buffering the whole artifact.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit int64
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && int64(b.Len()+len(p)) > b.limit {
+		return 0, errAttachmentTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+func uniqueZipName(names map[string]int, filename string) string {
+	name := path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	if name == "" || name == "." || name == "/" {
+		name = "export"
+	}
+	count := names[name]
+	names[name] = count + 1
+	if count == 0 {
+		return name
+	}
+	return renamedPath(name, count)
+}
+
+// attachmentFallbackNote explains a fallback in the message body.
+func attachmentFallbackNote(fallback AttachmentFallback) string {
+	switch fallback {
+	case AttachmentFallbackZip:
+		return "The export was compressed to fit the attachment size limit."
+	case AttachmentFallbackLink:
+		return "The export exceeds the attachment size limit; download it from the link instead."
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,119 @@
+package exportdelivery
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/goliatone/go-export/export"
+)
+
+func TestService_Deliver_AttachmentFallback(t *testing.T) {
+	ctx := context.Background()
+	store := export.NewMemoryStore()
+	compressible := []byte(strings.Repeat("id,name\n1,alice\n", 200))
+	incompressible := make([]byte, 4096)
+	rand.New(rand.NewSource(1)).Read(incompressible)
+
+	svc := &stubExportService{
+		request: func(ctx context.Context, actor export.Actor, req export.ExportRequest) (export.ExportRecord, error) {
+			return export.ExportRecord{ID: "exp-" + req.Definition}, nil
+		},
+		generate: func(ctx context.Context, actor export.Actor, exportID string, req export.ExportRequest) (export.ExportResult, error) {
+			data := compressible
+			if req.Definition == "noise" {
+				data = incompressible
+			}
+			ref, err := store.Put(ctx, "exports/"+exportID, bytes.NewReader(data), export.ArtifactMeta{Filename: req.Definition + ".csv"})
+			if err != nil {
+				return export.ExportResult{}, err
+			}
+			return export.ExportResult{ID: exportID, Format: req.Format, Artifact: &ref}, nil
+		},
+	}
+
+	email := &captureEmailSender{}
+	delivery := NewService(Config{
+		Service:     svc,
+		Store:       store,
+		EmailSender: email,
+		Limits:      Limits{MaxAttachmentSize: 1024},
+		LinkBuilder: func(exportID string, ref export.ArtifactRef) string {
+			return "https://example.com/exports/" + exportID
+		},
+	})
+	deliver := func(definition string, policy AttachmentFallback) (Result, error) {
+		return delivery.Deliver(ctx, Request{
+			Actor:              export.Actor{ID: "actor-1"},
+			Export:             export.ExportRequest{Definition: definition, Format: export.FormatCSV},
+			Mode:               DeliveryAttachment,
+			AttachmentFallback: policy,
+			Targets:            []Target{{Kind: TargetEmail, Email: EmailTarget{To: []string{"demo@example.com"}}}},
+		})
+	}
+
+	if _, err := deliver("orders", ""); err == nil {
+		t.Fatalf("expected default policy to fail oversized attachment")
+	}
+
+	result, err := deliver("orders", AttachmentFallbackZip)
+	if err != nil {
+		t.Fatalf("deliver zip: %v", err)
+	}
+	if result.Fallback != AttachmentFallbackZip || result.Mode != DeliveryAttachment {
+		t.Fatalf("expected zip fallback, got %+v", result)
+	}
+	msg := email.messages[len(email.messages)-1]
+	if msg.Attachment == nil || msg.Attachment.Filename != "orders.zip" || msg.Attachment.ContentType != "application/zip" {
+		t.Fatalf("expected zipped attachment, got %+v", msg.Attachment)
+	}
+	if !strings.Contains(msg.Body, "compressed") {
+		t.Fatalf("expected fallback note in body %q", msg.Body)
+	}
+
+	if _, err := deliver("noise", AttachmentFallbackZip); err == nil {
+		t.Fatalf("expected zip policy to fail when archive is still too large")
+	}
+
+	result, err = deliver("noise", AttachmentFallbackZipThenLink)
+	if err != nil {
+		t.Fatalf("deliver zip_then_link: %v", err)
+	}
+	if result.Fallback != AttachmentFallbackLink || result.Mode != DeliveryLink || result.Attachment != nil {
+		t.Fatalf("expected link fallback, got %+v", result)
+	}
+	msg = email.messages[len(email.messages)-1]
+	if msg.Attachment != nil || !strings.Contains(msg.Body, "https://example.com/exports/exp-noise") {
+		t.Fatalf("expected link email, got %+v", msg)
+	}
+
+	if _, err := deliver("orders", "inline"); err == nil {
+		t.Fatalf("expected invalid policy error")
+	}
+}
+
+func TestZipArtifacts_StopsAtLimit(t *testing.T) {
+	ctx := context.Background()
+	store := export.NewMemoryStore()
+	large := make([]byte, 1<<20)
+	rand.New(rand.NewSource(2)).Read(large)
+	if _, err := store.Put(ctx, "exports/large", bytes.NewReader(large), export.ArtifactMeta{}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	svc := &Service{store: store, limits: Limits{MaxAttachmentSize: 1024}}
+
+	zipped, fits, err := svc.zipArtifacts(ctx, "large.zip", []zipSource{{key: "exports/large", name: "large.bin"}})
+	if err != nil || fits || zipped != nil {
+		t.Fatalf("expected oversized archive to be abandoned, got %v %v %v", zipped, fits, err)
+	}
+
+	buf := &cappedBuffer{limit: 4}
+	if _, err := buf.Write([]byte("abc")); err != nil {
+		t.Fatalf("write within limit: %v", err)
+	}
+	if _, err := buf.Write([]byte("de")); err == nil || buf.Len() != 3 {
+		t.Fatalf("expected write past limit to be rejected, got len %d err %v", buf.Len(), err)
+	}
+}
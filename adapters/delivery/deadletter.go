@@ -0,0 +1,108 @@
+package exportdelivery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+)
+
+// DeadLetter records a webhook delivery that exhausted its retries. It lives
+// in the export package so stores need no delivery adapter imports.
+type DeadLetter = export.WebhookDeadLetter
+
+// DeadLetterStore persists failed webhook deliveries.
+type DeadLetterStore = export.WebhookDeadLetterStore
+
+// deadLetterMessage rebuilds the webhook message for a replay.
+func deadLetterMessage(d DeadLetter) WebhookMessage {
+	return WebhookMessage{
+		ID:            d.WebhookID,
+		URL:           d.URL,
+		Method:        d.Method,
+		Headers:       d.Headers,
+		HeaderSecrets: d.HeaderSecrets,
+		SecretRef:     d.SecretRef,
+		Payload:       d.Payload,
+		AttachmentKey: d.ArtifactKey,
+	}
+}
+
+// MemoryDeadLetterStore stores dead letters in memory (test/dev only).
+type MemoryDeadLetterStore struct {
+	mu      sync.RWMutex
+	letters map[string]DeadLetter
+	counter uint64
+}
+
+// NewMemoryDeadLetterStore creates an in-memory dead-letter store.
+func NewMemoryDeadLetterStore() *MemoryDeadLetterStore {
+	return &MemoryDeadLetterStore{letters: make(map[string]DeadLetter)}
+}
+
+// Save inserts or replaces a dead letter.
+func (s *MemoryDeadLetterStore) Save(ctx context.Context, letter DeadLetter) (DeadLetter, error) {
+	_ = ctx
+	if letter.ID == "" {
+		letter.ID = fmt.Sprintf("dlq-%d", atomic.AddUint64(&s.counter, 1))
+	}
+	if letter.FailedAt.IsZero() {
+		letter.FailedAt = time.Now()
+	}
+	if letter.CreatedAt.IsZero() {
+		letter.CreatedAt = letter.FailedAt
+	}
+	s.mu.Lock()
+	s.letters[letter.ID] = letter
+	s.mu.Unlock()
+	return letter, nil
+}
+
+// Get returns a dead letter by ID.
+func (s *MemoryDeadLetterStore) Get(ctx context.Context, id string) (DeadLetter, error) {
+	_ = ctx
+	s.mu.RLock()
+	letter, ok := s.letters[id]
+	s.mu.RUnlock()
+	if !ok {
+		return DeadLetter{}, export.NewError(export.KindNotFound, fmt.Sprintf("dead letter %q not found", id), nil)
+	}
+	return letter, nil
+}
+
+// List returns unparked dead letters, oldest first. limit <= 0 returns all.
+func (s *MemoryDeadLetterStore) List(ctx context.Context, limit int) ([]DeadLetter, error) {
+	_ = ctx
+	s.mu.RLock()
+	letters := make([]DeadLetter, 0, len(s.letters))
+	for _, letter := range s.letters {
+		if letter.Parked {
+			continue
+		}
+		letters = append(letters, letter)
+	}
+	s.mu.RUnlock()
+	sort.Slice(letters, func(i, j int) bool {
+		if letters[i].FailedAt.Equal(letters[j].FailedAt) {
+			return letters[i].ID < letters[j].ID
+		}
+		return letters[i].FailedAt.Before(letters[j].FailedAt)
+	})
+	if limit > 0 && len(letters) > limit {
+		letters = letters[:limit]
+	}
+	return letters, nil
+}
+
+// Delete removes a dead letter.
+func (s *MemoryDeadLetterStore) Delete(ctx context.Context, id string) error {
+	_ = ctx
+	s.mu.Lock()
+	delete(s.letters, id)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,264 @@
+package exportdelivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+)
+
+// Destination receives artifacts for store-copy targets. Write returns false
+// without writing when path exists and overwrite is false.
+type Destination interface {
+	Write(ctx context.Context, path string, r io.Reader, meta export.ArtifactMeta, overwrite bool) (bool, error)
+}
+
+// OverwritePolicy controls what happens when the destination path exists.
+type OverwritePolicy string
+
+const (
+	// OverwriteFail fails the target (default).
+	OverwriteFail OverwritePolicy = "fail"
+	// OverwriteReplace replaces the existing file.
+	OverwriteReplace OverwritePolicy = "replace"
+	// OverwriteSkip leaves the existing file and records the target as skipped.
+	OverwriteSkip OverwritePolicy = "skip"
+	// OverwriteRename writes to "name-1.ext", "name-2.ext", ... instead.
+	OverwriteRename OverwritePolicy = "rename"
+)
+
+// DefaultStorePathTemplate lays out copies by definition and month.
+const DefaultStorePathTemplate = "{definition}/{yyyy}/{mm}/{filename}"
+
+const maxRenameAttempts = 100
+
+// DirectoryDestination copies artifacts into a local or mounted directory
+// (e.g. an NFS drop folder). Files are written to a hidden temp file in the
+// target directory and renamed into place, so readers never see partial
+// files; no-overwrite writes use a hard link to claim the name atomically.
+type DirectoryDestination struct {
+	Root     string
+	DirMode  os.FileMode
+	FileMode os.FileMode
+}
+
+// NewDirectoryDestination creates a directory destination rooted at root.
+func NewDirectoryDestination(root string) *DirectoryDestination {
+	return &DirectoryDestination{Root: root, DirMode: 0o755, FileMode: 0o644}
+}
+
+// Write copies r to path below Root.
+func (d *DirectoryDestination) Write(ctx context.Context, key string, r io.Reader, meta export.ArtifactMeta, overwrite bool) (bool, error) {
+	_ = ctx
+	_ = meta
+	if d == nil {
+		return false, export.NewError(export.KindInternal, "destination is nil", nil)
+	}
+	if d.Root == "" {
+		return false, export.NewError(export.KindValidation, "destination root is required", nil)
+	}
+	target, err := d.resolvePath(key)
+	if err != nil {
+		return false, err
+	}
+	if !overwrite {
+		if _, err := os.Lstat(target); err == nil {
+			return false, nil
+		}
+	}
+
+	dir := filepath.Dir(target)
+	if err := os.MkdirAll(dir, d.dirMode()); err != nil {
+		return false, err
+	}
+	tmp, err := os.CreateTemp(dir, ".delivery-*.partial")
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		return false, err
+	}
+	if err := tmp.Chmod(d.fileMode()); err != nil {
+		return false, err
+	}
+	if err := tmp.Sync(); err != nil {
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+
+	if overwrite {
+		if err := os.Rename(tmp.Name(), target); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if err := os.Link(tmp.Name(), target); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (d *DirectoryDestination) resolvePath(key string) (string, error) {
+	rel, err := cleanDestinationPath(key)
+	if err != nil {
+		return "", err
+	}
+	root, err := filepath.Abs(d.Root)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, filepath.FromSlash(rel)), nil
+}
+
+func (d *DirectoryDestination) dirMode() os.FileMode {
+	if d.DirMode == 0 {
+		return 0o755
+	}
+	return d.DirMode
+}
+
+func (d *DirectoryDestination) fileMode() os.FileMode {
+	if d.FileMode == 0 {
+		return 0o644
+	}
+	return d.FileMode
+}
+
+// StoreDestination copies artifacts into another ArtifactStore, such as a
+// partner bucket. Atomicity is that of the store's Put; the existence check
+// for non-overwriting writes is best effort.
+type StoreDestination struct {
+	Store export.ArtifactStore
+}
+
+// NewStoreDestination wraps an artifact store as a delivery destination.
+func NewStoreDestination(store export.ArtifactStore) *StoreDestination {
+	return &StoreDestination{Store: store}
+}
+
+// Write puts r under key in the wrapped store. Only ContentType, Checksum and
+// TenantID are kept from meta; the store rejects data that does not match
+// Checksum, and TenantID selects the key of a per-tenant encrypted store.
+func (d *StoreDestination) Write(ctx context.Context, key string, r io.Reader, meta export.ArtifactMeta, overwrite bool) (bool, error) {
+	if d == nil || d.Store == nil {
+		return false, export.NewError(export.KindNotImpl, "destination store not configured", nil)
+	}
+	key, err := cleanDestinationPath(key)
+	if err != nil {
+		return false, err
+	}
+	if !overwrite {
+		reader, _, err := d.Store.Open(ctx, key)
+		if err == nil {
+			_ = reader.Close()
+			return false, nil
+		}
+		if export.KindFromError(err) != export.KindNotFound {
+			return false, err
+		}
+	}
+	// Only describe the copy; the source's encryption and expiry do not
+	// apply to the plaintext written here.
+	copied := export.ArtifactMeta{
+		ContentType: meta.ContentType,
+		Filename:    path.Base(key),
+		Checksum:    meta.Checksum,
+		TenantID:    meta.TenantID,
+	}
+	if _, err := d.Store.Put(ctx, key, r, copied); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// cleanDestinationPath normalizes a rendered path and rejects paths that
+// escape the destination root.
+func cleanDestinationPath(key string) (string, error) {
+	key = strings.TrimSpace(filepath.ToSlash(key))
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", export.NewError(export.KindValidation, "destination path must be relative", nil)
+	}
+	clean := path.Clean(key)
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", export.NewError(export.KindValidation, "destination path escapes root", nil)
+	}
+	return clean, nil
+}
+
+// StorePathVars are the values available to store path templates.
+type StorePathVars struct {
+	ExportID   string
+	Definition string
+	Format     export.Format
+	Filename   string
+	TenantID   string
+	Time       time.Time
+}
+
+// RenderStorePath expands {definition}, {format}, {export_id}, {filename},
+// {tenant}, {yyyy}, {mm}, {dd}, and {hh} placeholders. Unknown placeholders
+// are rejected.
+func RenderStorePath(template string, vars StorePathVars) (string, error) {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultStorePathTemplate
+	}
+	t := vars.Time.UTC()
+	values := map[string]string{
+		"definition": vars.Definition,
+		"format":     string(vars.Format),
+		"export_id":  vars.ExportID,
+		"filename":   vars.Filename,
+		"tenant":     vars.TenantID,
+		"yyyy":       fmt.Sprintf("%04d", t.Year()),
+		"mm":         fmt.Sprintf("%02d", int(t.Month())),
+		"dd":         fmt.Sprintf("%02d", t.Day()),
+		"hh":         fmt.Sprintf("%02d", t.Hour()),
+	}
+
+	var out strings.Builder
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			out.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", export.NewError(export.KindValidation, "store path template is malformed", nil)
+		}
+		name := rest[start+1 : start+end]
+		value, ok := values[name]
+		if !ok {
+			return "", export.NewError(export.KindValidation, fmt.Sprintf("store path placeholder {%s} is unknown", name), nil)
+		}
+		out.WriteString(rest[:start])
+		out.WriteString(strings.ReplaceAll(value, "/", "_"))
+		rest = rest[start+end+1:]
+	}
+	return cleanDestinationPath(out.String())
+}
+
+// renamedPath returns "dir/name-n.ext" for rename collisions.
+func renamedPath(key string, n int) string {
+	dir, file := path.Split(key)
+	ext := path.Ext(file)
+	return fmt.Sprintf("%s%s-%d%s", dir, strings.TrimSuffix(file, ext), n, ext)
+}
@@ -0,0 +1,237 @@
+package exportdelivery
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	storeencrypted "github.com/goliatone/go-export/adapters/store/encrypted"
+	"github.com/goliatone/go-export/export"
+)
+
+func TestRenderStorePath(t *testing.T) {
+	vars := StorePathVars{
+		ExportID:   "exp-1",
+		Definition: "orders",
+		Format:     export.FormatCSV,
+		Filename:   "orders.csv",
+		TenantID:   "acme",
+		Time:       time.Date(2024, 3, 7, 22, 0, 0, 0, time.UTC),
+	}
+
+	got, err := RenderStorePath("", vars)
+	if err != nil {
+		t.Fatalf("render default: %v", err)
+	}
+	if got != "orders/2024/03/orders.csv" {
+		t.Fatalf("unexpected default path %q", got)
+	}
+
+	got, err = RenderStorePath("{tenant}/{yyyy}{mm}{dd}/{export_id}.{format}", vars)
+	if err != nil {
+		t.Fatalf("render custom: %v", err)
+	}
+	if got != "acme/20240307/exp-1.csv" {
+		t.Fatalf("unexpected custom path %q", got)
+	}
+
+	for _, template := range []string{"{unknown}/{filename}", "{definition", "../{filename}", "/abs/{filename}"} {
+		if _, err := RenderStorePath(template, vars); err == nil {
+			t.Fatalf("expected error for %q", template)
+		}
+	}
+}
+
+func TestDirectoryDestination_Write(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	dest := NewDirectoryDestination(root)
+
+	written, err := dest.Write(ctx, "orders/2024/report.csv", strings.NewReader("v1"), export.ArtifactMeta{}, false)
+	if err != nil || !written {
+		t.Fatalf("first write: written=%v err=%v", written, err)
+	}
+	written, err = dest.Write(ctx, "orders/2024/report.csv", strings.NewReader("v2"), export.ArtifactMeta{}, false)
+	if err != nil || written {
+		t.Fatalf("expected no-overwrite write to be refused: written=%v err=%v", written, err)
+	}
+	assertFile(t, filepath.Join(root, "orders/2024/report.csv"), "v1")
+
+	written, err = dest.Write(ctx, "orders/2024/report.csv", strings.NewReader("v3"), export.ArtifactMeta{}, true)
+	if err != nil || !written {
+		t.Fatalf("overwrite: written=%v err=%v", written, err)
+	}
+	assertFile(t, filepath.Join(root, "orders/2024/report.csv"), "v3")
+
+	entries, err := os.ReadDir(filepath.Join(root, "orders/2024"))
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected temp files to be cleaned up, got %d entries", len(entries))
+	}
+
+	if _, err := dest.Write(ctx, "../escape.csv", strings.NewReader("x"), export.ArtifactMeta{}, true); err == nil {
+		t.Fatalf("expected escape error")
+	}
+}
+
+func TestStoreDestination_WriteDropsSourceMeta(t *testing.T) {
+	ctx := context.Background()
+	store := export.NewMemoryStore()
+	dest := NewStoreDestination(store)
+
+	source := export.ArtifactMeta{
+		ContentType: "text/csv",
+		Filename:    "source.csv",
+		Checksum:    "a116c9ed46d6207734a43317d30fd88f52ac8634c37d904bbf4e41d865f90475", // sha256("plain")
+		TenantID:    "acme",
+		Encryption:  &export.ArtifactEncryption{Algorithm: "AES-256-GCM", KeyID: "k1"},
+		ExpiresAt:   time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC),
+	}
+	if _, err := dest.Write(ctx, "copies/report.csv", strings.NewReader("plain"), source, true); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	reader, meta, err := store.Open(ctx, "copies/report.csv")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	_ = reader.Close()
+	if meta.ContentType != "text/csv" || meta.Filename != "report.csv" {
+		t.Fatalf("expected content type and filename, got %+v", meta)
+	}
+	if meta.Encryption != nil || !meta.ExpiresAt.IsZero() {
+		t.Fatalf("expected source meta not to carry over, got %+v", meta)
+	}
+	if meta.TenantID != "acme" {
+		t.Fatalf("expected tenant forwarded, got %q", meta.TenantID)
+	}
+	if meta.Checksum != source.Checksum {
+		t.Fatalf("expected checksum %q, got %q", source.Checksum, meta.Checksum)
+	}
+
+	source.Checksum = "abc"
+	if _, err := dest.Write(ctx, "copies/tampered.csv", strings.NewReader("plain"), source, true); err == nil {
+		t.Fatalf("expected checksum mismatch error")
+	}
+	if _, _, err := store.Open(ctx, "copies/tampered.csv"); err == nil {
+		t.Fatalf("expected mismatched copy not to be stored")
+	}
+}
+
+func TestStoreDestination_WriteUsesTenantKey(t *testing.T) {
+	ctx := context.Background()
+	keys := storeencrypted.NewLocalKeyProvider()
+	if err := keys.AddKey("acme", "k1", bytes.Repeat([]byte{1}, 32)); err != nil {
+		t.Fatalf("add key: %v", err)
+	}
+	store := storeencrypted.NewStore(export.NewMemoryStore(), keys)
+	dest := NewStoreDestination(store)
+
+	if _, err := dest.Write(ctx, "copies/report.csv", strings.NewReader("plain"), export.ArtifactMeta{TenantID: "acme"}, true); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	reader, _, err := store.Open(ctx, "copies/report.csv")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil || string(data) != "plain" {
+		t.Fatalf("expected decrypted copy, got %q %v", data, err)
+	}
+}
+
+func TestService_Deliver_StoreTarget(t *testing.T) {
+	ctx := context.Background()
+	source := export.NewMemoryStore()
+	ref, err := source.Put(ctx, "exports/exp-1.csv", bytes.NewReader([]byte("a,b\n")), export.ArtifactMeta{Filename: "orders.csv"})
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	svc := &stubExportService{
+		request: func(ctx context.Context, actor export.Actor, req export.ExportRequest) (export.ExportRecord, error) {
+			return export.ExportRecord{ID: "exp-1"}, nil
+		},
+		generate: func(ctx context.Context, actor export.Actor, exportID string, req export.ExportRequest) (export.ExportResult, error) {
+			return export.ExportResult{ID: exportID, Format: req.Format, Artifact: &ref}, nil
+		},
+	}
+
+	partner := export.NewMemoryStore()
+	tracker := export.NewMemoryDeliveryTracker()
+	delivery := NewService(Config{
+		Service:         svc,
+		Store:           source,
+		DeliveryTracker: tracker,
+		Destinations:    map[string]Destination{"partner": NewStoreDestination(partner)},
+	})
+	delivery.now = func() time.Time { return time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC) }
+
+	deliver := func(policy OverwritePolicy) (Result, error) {
+		return delivery.Deliver(ctx, Request{
+			Actor:  export.Actor{ID: "actor-1"},
+			Export: export.ExportRequest{Definition: "orders", Format: export.FormatCSV},
+			Targets: []Target{{
+				Kind:  TargetStore,
+				Store: StoreTarget{Destination: "partner", Overwrite: policy},
+			}},
+		})
+	}
+
+	result, err := deliver("")
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if len(result.Deliveries) != 1 || result.Deliveries[0].Target != "partner:orders/2024/03/orders.csv" {
+		t.Fatalf("unexpected deliveries: %+v", result.Deliveries)
+	}
+	reader, _, err := partner.Open(ctx, "orders/2024/03/orders.csv")
+	if err != nil {
+		t.Fatalf("open copy: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	_ = reader.Close()
+	if string(data) != "a,b\n" {
+		t.Fatalf("unexpected copy %q", data)
+	}
+
+	if _, err := deliver(OverwriteFail); err == nil {
+		t.Fatalf("expected existing path to fail")
+	}
+	result, err = deliver(OverwriteSkip)
+	if err != nil || result.Deliveries[0].Status != export.DeliveryStatusSkipped {
+		t.Fatalf("expected skipped delivery, got %+v err=%v", result.Deliveries, err)
+	}
+	result, err = deliver(OverwriteRename)
+	if err != nil {
+		t.Fatalf("deliver rename: %v", err)
+	}
+	if result.Deliveries[0].Target != "partner:orders/2024/03/orders-1.csv" {
+		t.Fatalf("expected renamed copy, got %q", result.Deliveries[0].Target)
+	}
+
+	if _, err := delivery.Deliver(ctx, Request{
+		Actor:   export.Actor{ID: "actor-1"},
+		Export:  export.ExportRequest{Definition: "orders", Format: export.FormatCSV},
+		Targets: []Target{{Kind: TargetStore, Store: StoreTarget{Destination: "missing"}}},
+	}); export.KindFromError(err) != export.KindNotImpl {
+		t.Fatalf("expected unconfigured destination error, got %v", err)
+	}
+}
+
+func assertFile(t *testing.T, name, want string) {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	if string(data) != want {
+		t.Fatalf("expected %q, got %q", want, data)
+	}
+}
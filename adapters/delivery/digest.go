@@ -0,0 +1,588 @@
+package exportdelivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	errorslib "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-export/export"
+	"github.com/goliatone/go-export/export/notify"
+)
+
+// Digest channels.
+const (
+	DigestChannelEmail  = "email"
+	DigestChannelNotify = "notify"
+)
+
+// DigestEntry is a generated export waiting to be bundled into a recipient's
+// digest. Links are minted when the digest is flushed so they do not expire
+// while the entry waits.
+type DigestEntry struct {
+	ID          string        `json:"id"`
+	Digest      string        `json:"digest"`
+	Channel     string        `json:"channel"`
+	Recipient   string        `json:"recipient"`
+	Channels    []string      `json:"channels,omitempty"`
+	ExportID    string        `json:"export_id"`
+	Definition  string        `json:"definition"`
+	Format      export.Format `json:"format"`
+	Filename    string        `json:"filename"`
+	ContentType string        `json:"content_type,omitempty"`
+	ArtifactKey string        `json:"artifact_key"`
+	Size        int64         `json:"size,omitempty"`
+	Checksum    string        `json:"checksum,omitempty"`
+	Rows        int64         `json:"rows"`
+	Mode        DeliveryMode  `json:"mode"`
+	LinkTTL     time.Duration `json:"link_ttl,omitempty"`
+	Locale      string        `json:"locale,omitempty"`
+	Template    string        `json:"template,omitempty"`
+	Actor       export.Actor  `json:"actor"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// DigestStore holds digest entries until they are flushed.
+type DigestStore interface {
+	Add(ctx context.Context, entry DigestEntry) error
+	// Pending returns the entries of a digest, oldest first.
+	Pending(ctx context.Context, digest string) ([]DigestEntry, error)
+	Remove(ctx context.Context, ids []string) error
+}
+
+// DigestResult summarizes a digest flush. Dropped counts entries removed
+// without being sent because they expired or failed permanently.
+type DigestResult struct {
+	Sent    int
+	Entries int
+	Held    int
+	Failed  int
+	Dropped int
+}
+
+// MemoryDigestStore stores digest entries in memory (test/dev only).
+type MemoryDigestStore struct {
+	mu      sync.RWMutex
+	entries map[string]DigestEntry
+	counter uint64
+}
+
+// NewMemoryDigestStore creates an in-memory digest store.
+func NewMemoryDigestStore() *MemoryDigestStore {
+	return &MemoryDigestStore{entries: make(map[string]DigestEntry)}
+}
+
+// Add stores a digest entry.
+func (s *MemoryDigestStore) Add(ctx context.Context, entry DigestEntry) error {
+	_ = ctx
+	if entry.ID == "" {
+		entry.ID = fmt.Sprintf("dge-%d", atomic.AddUint64(&s.counter, 1))
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	s.mu.Lock()
+	s.entries[entry.ID] = entry
+	s.mu.Unlock()
+	return nil
+}
+
+// Pending returns the entries of a digest, oldest first.
+func (s *MemoryDigestStore) Pending(ctx context.Context, digest string) ([]DigestEntry, error) {
+	_ = ctx
+	s.mu.RLock()
+	entries := make([]DigestEntry, 0)
+	for _, entry := range s.entries {
+		if entry.Digest == digest {
+			entries = append(entries, entry)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].ID < entries[j].ID
+		}
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+// Remove deletes flushed entries.
+func (s *MemoryDigestStore) Remove(ctx context.Context, ids []string) error {
+	_ = ctx
+	s.mu.Lock()
+	for _, id := range ids {
+		delete(s.entries, id)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// queueDigest adds an entry per email recipient and notification recipient.
+func (s *Service) queueDigest(ctx context.Context, req Request, base export.DeliveryRecord, ref export.ArtifactRef) (int, error) {
+	entry := DigestEntry{
+		Digest:      req.Digest,
+		ExportID:    base.ExportID,
+		Definition:  base.Definition,
+		Format:      base.Format,
+		Filename:    resolveNotifyFilename(ref.Meta, "", base.Definition, base.Format),
+		ContentType: ref.Meta.ContentType,
+		ArtifactKey: ref.Key,
+		Size:        ref.Meta.Size,
+		Checksum:    base.Checksum,
+		Rows:        base.Rows,
+		Mode:        req.Mode,
+		LinkTTL:     req.LinkTTL,
+		Locale:      req.Export.Locale,
+		Template:    req.Message.Template,
+		Actor:       req.Actor,
+		CreatedAt:   s.now(),
+	}
+
+	queued := 0
+	add := func(channel, recipient string, channels []string) error {
+		item := entry
+		item.Channel = channel
+		item.Recipient = recipient
+		item.Channels = channels
+		if err := s.digests.Add(ctx, item); err != nil {
+			return err
+		}
+		queued++
+		return nil
+	}
+	for _, target := range req.Targets {
+		if target.Kind != TargetEmail {
+			continue
+		}
+		for _, recipient := range emailRecipients(target.Email) {
+			if err := add(DigestChannelEmail, recipient, nil); err != nil {
+				return queued, err
+			}
+		}
+	}
+	if hasNotifyRequest(req.Notify) {
+		for _, recipient := range req.Notify.Recipients {
+			if err := add(DigestChannelNotify, recipient, normalizeNotifyChannels(req.Notify.Channels)); err != nil {
+				return queued, err
+			}
+		}
+	}
+	return queued, nil
+}
+
+// FlushDigest sends one message per recipient listing every pending export
+// of the digest. Recipients whose oldest entry is younger than window are
+// held for a later flush; window <= 0 flushes everything. Entries older than
+// the digest max age, and groups that fail with a not-found or validation
+// error, are recorded as failed deliveries and dropped instead of retried.
+func (s *Service) FlushDigest(ctx context.Context, digest string, window time.Duration) (DigestResult, error) {
+	if s == nil {
+		return DigestResult{}, export.NewError(export.KindInternal, "delivery service is nil", nil)
+	}
+	if s.digests == nil {
+		return DigestResult{}, export.NewError(export.KindNotImpl, "digest store not configured", nil)
+	}
+	if strings.TrimSpace(digest) == "" {
+		return DigestResult{}, export.NewError(export.KindValidation, "digest is required", nil)
+	}
+	entries, err := s.digests.Pending(ctx, digest)
+	if err != nil {
+		return DigestResult{}, err
+	}
+
+	var result DigestResult
+	var errs []error
+	now := s.now()
+	for _, group := range groupDigestEntries(entries) {
+		group, expired := s.splitExpiredDigest(group, now)
+		if len(expired) > 0 && s.logger != nil {
+			s.logger.Warn("digest entries expired before sending",
+				"digest", digest,
+				"recipient", expired[0].Recipient,
+				"entries", len(expired),
+			)
+		}
+		if len(expired) > 0 {
+			s.recordDigest(ctx, expired, export.NewError(export.KindTimeout, "digest entry expired before it was sent", nil))
+			if err := s.digests.Remove(ctx, digestEntryIDs(expired)); err != nil {
+				errs = append(errs, err)
+			}
+			result.Dropped += len(expired)
+		}
+		if len(group) == 0 {
+			continue
+		}
+		if window > 0 && now.Sub(group[0].CreatedAt) < window {
+			result.Held += len(group)
+			continue
+		}
+		sendErr := s.sendDigest(ctx, digest, group)
+		s.recordDigest(ctx, group, sendErr)
+		if sendErr != nil {
+			result.Failed++
+			errs = append(errs, sendErr)
+			// A missing artifact or invalid entry fails the same way on
+			// every flush; the delivery history keeps the failure.
+			if !permanentDigestError(sendErr) {
+				continue
+			}
+			result.Dropped += len(group)
+		} else {
+			result.Sent++
+			result.Entries += len(group)
+		}
+		if err := s.digests.Remove(ctx, digestEntryIDs(group)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// splitExpiredDigest separates entries older than the digest max age.
+func (s *Service) splitExpiredDigest(group []DigestEntry, now time.Time) ([]DigestEntry, []DigestEntry) {
+	if s.digestMaxAge <= 0 {
+		return group, nil
+	}
+	var live, expired []DigestEntry
+	for _, entry := range group {
+		if now.Sub(entry.CreatedAt) > s.digestMaxAge {
+			expired = append(expired, entry)
+			continue
+		}
+		live = append(live, entry)
+	}
+	return live, expired
+}
+
+func permanentDigestError(err error) bool {
+	switch export.AsGoError(err).Category {
+	case errorslib.CategoryNotFound, errorslib.CategoryValidation:
+		return true
+	}
+	return false
+}
+
+func digestEntryIDs(group []DigestEntry) []string {
+	ids := make([]string, 0, len(group))
+	for _, entry := range group {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+// groupDigestEntries groups entries by channel and recipient, keeping the
+// order in which recipients first appear.
+func groupDigestEntries(entries []DigestEntry) [][]DigestEntry {
+	index := make(map[string]int)
+	var groups [][]DigestEntry
+	for _, entry := range entries {
+		key := entry.Channel + "\x00" + strings.ToLower(entry.Recipient)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], entry)
+	}
+	return groups
+}
+
+type digestItem struct {
+	entry DigestEntry
+	link  string
+}
+
+func (s *Service) sendDigest(ctx context.Context, digest string, group []DigestEntry) error {
+	items, attachment, err := s.digestBundle(ctx, digest, group)
+	if err != nil {
+		return err
+	}
+	content, err := s.digestContent(digest, group, items, attachment)
+	if err != nil {
+		return err
+	}
+	first := group[0]
+
+	switch first.Channel {
+	case DigestChannelEmail:
+		if s.emailSender == nil {
+			return export.NewError(export.KindNotImpl, "email sender not configured", nil)
+		}
+		return s.emailSender.Send(ctx, EmailMessage{
+			To:         []string{first.Recipient},
+			Subject:    content.Subject,
+			Body:       content.Text,
+			HTML:       content.HTML,
+			Attachment: attachment,
+		})
+	case DigestChannelNotify:
+		if s.notifier == nil {
+			return export.NewError(export.KindNotImpl, "notification notifier not configured", nil)
+		}
+		evt := notify.ExportReadyEvent{
+			Recipients:       []string{first.Recipient},
+			Channels:         normalizeNotifyChannels(first.Channels),
+			Locale:           first.Locale,
+			TenantID:         first.Actor.Scope.TenantID,
+			ActorID:          first.Actor.ID,
+			FileName:         content.Subject,
+			Format:           "digest",
+			Parts:            len(items),
+			Message:          content.Text,
+			ChannelOverrides: ensureNotifyEmailOverrides(nil, content.HTML, content.Text),
+		}
+		if len(items) > 0 {
+			evt.URL = items[0].link
+		}
+		for _, item := range items {
+			evt.Rows += notifyRowCount(item.entry.Rows)
+		}
+		if attachment != nil {
+			evt.FileName = attachment.Filename
+			evt.Format = "zip"
+			evt.Attachments = []notify.NotificationAttachment{{
+				Filename:    attachment.Filename,
+				ContentType: attachment.ContentType,
+				Data:        attachment.Data,
+				Size:        attachment.Size,
+			}}
+		}
+		return s.notifier.Send(ctx, evt)
+	default:
+		return export.NewError(export.KindValidation, fmt.Sprintf("digest channel %q is invalid", first.Channel), nil)
+	}
+}
+
+// digestContent renders the digest through EmailTemplates in the entries'
+// locale when configured; otherwise it uses the built-in text.
+func (s *Service) digestContent(digest string, group []DigestEntry, items []digestItem, attachment *Attachment) (EmailContent, error) {
+	if s.emailTemplates == nil {
+		return EmailContent{
+			Subject: digestSubject(group),
+			Text:    buildDigestText(items, attachment),
+			HTML:    buildDigestHTML(items, attachment),
+		}, nil
+	}
+
+	first := group[0]
+	data := EmailTemplateData{
+		Locale:  first.Locale,
+		Digest:  digest,
+		Exports: make([]EmailTemplateExport, 0, len(items)),
+	}
+	for _, item := range items {
+		data.Exports = append(data.Exports, EmailTemplateExport{
+			ExportID:   item.entry.ExportID,
+			Definition: item.entry.Definition,
+			Format:     item.entry.Format,
+			Filename:   item.entry.Filename,
+			Link:       item.link,
+			Rows:       item.entry.Rows,
+		})
+		data.Rows += item.entry.Rows
+	}
+	if len(items) == 1 {
+		only := data.Exports[0]
+		data.ExportID = only.ExportID
+		data.Definition = only.Definition
+		data.Format = only.Format
+		data.Filename = only.Filename
+		data.Link = only.Link
+	}
+	if attachment != nil {
+		data.Attachment = attachment.Filename
+	}
+	rendered, err := s.emailTemplates.Render(first.Template, data)
+	if err != nil {
+		return EmailContent{}, err
+	}
+	if rendered.Subject == "" {
+		rendered.Subject = digestSubject(group)
+	}
+	return rendered, nil
+}
+
+// digestBundle zips the artifacts when every entry asked for attachments and
+// the archive fits Limits.MaxAttachmentSize; otherwise each item gets a link.
+func (s *Service) digestBundle(ctx context.Context, digest string, group []DigestEntry) ([]digestItem, *Attachment, error) {
+	items := make([]digestItem, 0, len(group))
+	for _, entry := range group {
+		items = append(items, digestItem{entry: entry})
+	}
+
+	if allAttachments(group) {
+		attachment, fits, err := s.zipDigest(ctx, digest, group)
+		if err != nil {
+			return nil, nil, err
+		}
+		if fits {
+			return items, attachment, nil
+		}
+		if s.logger != nil {
+			s.logger.Info("digest attachment exceeds limit, sending links",
+				"digest", digest,
+				"recipient", group[0].Recipient,
+				"limit", s.limits.MaxAttachmentSize,
+			)
+		}
+	}
+
+	for i := range items {
+		link, err := s.digestLink(ctx, items[i].entry)
+		if err != nil {
+			return nil, nil, err
+		}
+		items[i].link = link
+	}
+	return items, nil, nil
+}
+
+func (s *Service) digestLink(ctx context.Context, entry DigestEntry) (string, error) {
+	ref := export.ArtifactRef{
+		Key: entry.ArtifactKey,
+		Meta: export.ArtifactMeta{
+			Filename:    entry.Filename,
+			ContentType: entry.ContentType,
+			Size:        entry.Size,
+			Checksum:    entry.Checksum,
+		},
+	}
+	if s.linkBuilder != nil {
+		if link := strings.TrimSpace(s.linkBuilder(entry.ExportID, ref)); link != "" {
+			return link, nil
+		}
+	}
+	return s.signedURL(ctx, ref, entry.LinkTTL)
+}
+
+// zipDigest archives the group's artifacts. fits is false when the archive
+// would exceed the attachment limit.
+func (s *Service) zipDigest(ctx context.Context, digest string, group []DigestEntry) (*Attachment, bool, error) {
+	files := make([]zipSource, 0, len(group))
+	for _, entry := range group {
+		files = append(files, zipSource{key: entry.ArtifactKey, name: entry.Filename})
+	}
+	filename := fmt.Sprintf("%s-%s.zip", sanitizeZipPart(digest), s.now().UTC().Format("20060102"))
+	return s.zipArtifacts(ctx, filename, files)
+}
+
+func (s *Service) recordDigest(ctx context.Context, group []DigestEntry, sendErr error) {
+	now := s.now()
+	for _, entry := range group {
+		delivery := export.DeliveryRecord{
+			ExportID:    entry.ExportID,
+			Definition:  entry.Definition,
+			Format:      entry.Format,
+			Mode:        string(entry.Mode),
+			Actor:       entry.Actor,
+			TargetKind:  string(TargetEmail),
+			Target:      entry.Recipient,
+			Recipients:  []string{entry.Recipient},
+			Rows:        entry.Rows,
+			Checksum:    entry.Checksum,
+			Status:      export.DeliveryStatusSent,
+			Attempts:    1,
+			Reason:      "digest:" + entry.Digest,
+			StartedAt:   entry.CreatedAt,
+			CompletedAt: now,
+		}
+		if entry.Channel == DigestChannelNotify {
+			delivery.TargetKind = "notification"
+		}
+		if sendErr != nil {
+			delivery.Status = export.DeliveryStatusFailed
+			delivery.Error = sendErr.Error()
+		}
+		s.recordDelivery(ctx, delivery)
+	}
+}
+
+func allAttachments(group []DigestEntry) bool {
+	for _, entry := range group {
+		if entry.Mode != DeliveryAttachment {
+			return false
+		}
+	}
+	return len(group) > 0
+}
+
+func sanitizeZipPart(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "exports"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, value)
+}
+
+func digestSubject(group []DigestEntry) string {
+	if len(group) == 1 {
+		return fmt.Sprintf("Export ready: %s", group[0].Definition)
+	}
+	return fmt.Sprintf("%d exports ready", len(group))
+}
+
+func buildDigestText(items []digestItem, attachment *Attachment) string {
+	lines := []string{"Your exports are ready:", ""}
+	for _, item := range items {
+		lines = append(lines, "- "+digestItemLabel(item.entry))
+		if item.link != "" {
+			lines = append(lines, "  Download: "+item.link)
+		}
+	}
+	if attachment != nil {
+		lines = append(lines, "", "Attachment: "+attachment.Filename)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func buildDigestHTML(items []digestItem, attachment *Attachment) string {
+	var sb strings.Builder
+	sb.WriteString("<p>Your exports are ready:</p><ul>")
+	for _, item := range items {
+		sb.WriteString("<li>")
+		sb.WriteString(html.EscapeString(digestItemLabel(item.entry)))
+		if item.link != "" {
+			sb.WriteString(fmt.Sprintf(" &ndash; <a href=\"%s\">Download</a>", html.EscapeString(item.link)))
+		}
+		sb.WriteString("</li>")
+	}
+	sb.WriteString("</ul>")
+	if attachment != nil {
+		sb.WriteString(fmt.Sprintf("<p>Attachment: %s</p>", html.EscapeString(attachment.Filename)))
+	}
+	return sb.String()
+}
+
+func digestItemLabel(entry DigestEntry) string {
+	label := fmt.Sprintf("%s (%s", entry.Filename, entry.Definition)
+	if entry.Rows > 0 {
+		label += fmt.Sprintf(", %d rows", entry.Rows)
+	}
+	return label + ")"
+}
+
+// immediateTargets drops the email targets a digest request queued.
+func immediateTargets(targets []Target) []Target {
+	out := make([]Target, 0, len(targets))
+	for _, target := range targets {
+		if target.Kind != TargetEmail {
+			out = append(out, target)
+		}
+	}
+	return out
+}
@@ -0,0 +1,177 @@
+package exportdelivery
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	gcmd "github.com/goliatone/go-command"
+	errorslib "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-export/export"
+)
+
+// DigestFlusher sends pending digest entries. Service implements it.
+type DigestFlusher interface {
+	FlushDigest(ctx context.Context, digest string, window time.Duration) (DigestResult, error)
+}
+
+// DigestCommand wires CLI/Cron flushing of digest deliveries.
+type DigestCommand struct {
+	flusher     DigestFlusher
+	digests     []string
+	window      time.Duration
+	cliConfig   gcmd.CLIConfig
+	cronConfig  gcmd.HandlerConfig
+	locker      export.Locker
+	leaseWindow time.Duration
+	now         func() time.Time
+}
+
+// DigestOption customizes digest commands.
+type DigestOption func(*DigestCommand)
+
+// WithDigestCLIConfig overrides CLI configuration.
+func WithDigestCLIConfig(cfg gcmd.CLIConfig) DigestOption {
+	return func(cmd *DigestCommand) {
+		cmd.cliConfig = cfg
+	}
+}
+
+// WithDigestCronConfig overrides cron configuration; the expression sets the
+// digest schedule.
+func WithDigestCronConfig(cfg gcmd.HandlerConfig) DigestOption {
+	return func(cmd *DigestCommand) {
+		cmd.cronConfig = cfg
+	}
+}
+
+// WithDigestWindow holds recipients until their oldest entry is this old.
+func WithDigestWindow(window time.Duration) DigestOption {
+	return func(cmd *DigestCommand) {
+		cmd.window = window
+	}
+}
+
+// WithDigestLease makes cron runs acquire a lease per run window, so
+// digests are flushed once across replicas. Set window to the cron interval.
+func WithDigestLease(locker export.Locker, window time.Duration) DigestOption {
+	return func(cmd *DigestCommand) {
+		cmd.locker = locker
+		cmd.leaseWindow = window
+	}
+}
+
+// NewDigestCommand creates a digest flush CLI/Cron command for the named
+// digests. The default cron schedule flushes daily at 08:00.
+func NewDigestCommand(flusher DigestFlusher, digests []string, opts ...DigestOption) *DigestCommand {
+	cmd := &DigestCommand{
+		flusher: flusher,
+		digests: digests,
+		cliConfig: gcmd.CLIConfig{
+			Path:        []string{"exports-digest"},
+			Description: "Send pending export digests",
+			Group:       "exports",
+		},
+		cronConfig: gcmd.HandlerConfig{Expression: "0 8 * * *"},
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cmd)
+		}
+	}
+	return cmd
+}
+
+// Flush sends the given digests, or the configured ones when digests is
+// empty. Every digest is attempted; failures are joined into the error.
+func (c *DigestCommand) Flush(ctx context.Context, digests []string) (DigestResult, error) {
+	if c == nil {
+		return DigestResult{}, errorslib.New("digest command is nil", errorslib.CategoryInternal).
+			WithTextCode("DIGEST_CMD_NIL")
+	}
+	if c.flusher == nil {
+		return DigestResult{}, errorslib.New("digest flusher is required", errorslib.CategoryValidation).
+			WithTextCode("DIGEST_FLUSHER_REQUIRED")
+	}
+	if len(digests) == 0 {
+		digests = c.digests
+	}
+	if len(digests) == 0 {
+		return DigestResult{}, errorslib.New("digest name is required", errorslib.CategoryValidation).
+			WithTextCode("DIGEST_REQUIRED")
+	}
+
+	var total DigestResult
+	var errs []error
+	for _, digest := range digests {
+		result, err := c.flusher.FlushDigest(ctx, digest, c.window)
+		total.Sent += result.Sent
+		total.Entries += result.Entries
+		total.Held += result.Held
+		total.Failed += result.Failed
+		total.Dropped += result.Dropped
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+// CronHandler flushes the configured digests.
+func (c *DigestCommand) CronHandler() func() error {
+	return func() error {
+		ctx := context.Background()
+		if c != nil && c.locker != nil {
+			_, acquired, err := export.AcquireRunLease(ctx, c.locker, c.leaseName(), c.leaseWindow, c.now())
+			if err != nil || !acquired {
+				return err
+			}
+		}
+		_, err := c.Flush(ctx, nil)
+		return err
+	}
+}
+
+func (c *DigestCommand) leaseName() string {
+	if len(c.cliConfig.Path) > 0 {
+		return strings.Join(c.cliConfig.Path, ":")
+	}
+	return "exports-digest"
+}
+
+// CronOptions returns cron configuration.
+func (c *DigestCommand) CronOptions() gcmd.HandlerConfig {
+	if c == nil {
+		return gcmd.HandlerConfig{}
+	}
+	return c.cronConfig
+}
+
+// CLIHandler exposes the CLI handler.
+func (c *DigestCommand) CLIHandler() any {
+	return &digestCLI{cmd: c}
+}
+
+// CLIOptions returns CLI configuration.
+func (c *DigestCommand) CLIOptions() gcmd.CLIConfig {
+	if c == nil {
+		return gcmd.CLIConfig{}
+	}
+	return c.cliConfig
+}
+
+type digestCLI struct {
+	cmd     *DigestCommand
+	Digests []string `kong:"name='digest',help='Digests to flush (default: configured digests)'"`
+}
+
+func (c *digestCLI) Run() error {
+	if c == nil || c.cmd == nil {
+		return errorslib.New("digest command is required", errorslib.CategoryInternal).
+			WithTextCode("DIGEST_CMD_NIL")
+	}
+	_, err := c.cmd.Flush(context.Background(), c.Digests)
+	return err
+}
@@ -0,0 +1,278 @@
+package exportdelivery
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+)
+
+func TestService_Deliver_Digest(t *testing.T) {
+	ctx := context.Background()
+	store := export.NewMemoryStore()
+	count := 0
+	svc := &stubExportService{
+		request: func(ctx context.Context, actor export.Actor, req export.ExportRequest) (export.ExportRecord, error) {
+			count++
+			return export.ExportRecord{ID: fmt.Sprintf("exp-%d", count)}, nil
+		},
+		generate: func(ctx context.Context, actor export.Actor, exportID string, req export.ExportRequest) (export.ExportResult, error) {
+			ref, err := store.Put(ctx, "exports/"+exportID, bytes.NewReader([]byte("a,b\n")), export.ArtifactMeta{Filename: "orders.csv"})
+			if err != nil {
+				return export.ExportResult{}, err
+			}
+			return export.ExportResult{ID: exportID, Format: req.Format, Rows: 1, Artifact: &ref}, nil
+		},
+	}
+
+	newService := func(limit int64) (*Service, *captureEmailSender, *export.MemoryDeliveryTracker) {
+		sender := &captureEmailSender{}
+		tracker := export.NewMemoryDeliveryTracker()
+		delivery := NewService(Config{
+			Service:         svc,
+			Store:           store,
+			EmailSender:     sender,
+			DeliveryTracker: tracker,
+			Digests:         NewMemoryDigestStore(),
+			Limits:          Limits{MaxAttachmentSize: limit},
+			LinkBuilder: func(exportID string, ref export.ArtifactRef) string {
+				return "https://example.com/exports/" + exportID
+			},
+		})
+		return delivery, sender, tracker
+	}
+	deliver := func(delivery *Service, mode DeliveryMode) {
+		t.Helper()
+		result, err := delivery.Deliver(ctx, Request{
+			Actor:   export.Actor{ID: "actor-1"},
+			Export:  export.ExportRequest{Definition: "orders", Format: export.FormatCSV},
+			Targets: []Target{{Kind: TargetEmail, Email: EmailTarget{To: []string{"alice@example.com"}}}},
+			Mode:    mode,
+			Digest:  "daily",
+		})
+		if err != nil {
+			t.Fatalf("deliver: %v", err)
+		}
+		if result.Queued != 1 || len(result.Deliveries) != 0 {
+			t.Fatalf("expected queued entry, got %+v", result)
+		}
+	}
+
+	t.Run("links", func(t *testing.T) {
+		delivery, sender, tracker := newService(0)
+		deliver(delivery, DeliveryLink)
+		deliver(delivery, DeliveryLink)
+		if len(sender.messages) != 0 {
+			t.Fatalf("expected no email before flush, got %d", len(sender.messages))
+		}
+
+		start := time.Now()
+		delivery.now = func() time.Time { return start.Add(30 * time.Minute) }
+		result, err := delivery.FlushDigest(ctx, "daily", time.Hour)
+		if err != nil {
+			t.Fatalf("flush: %v", err)
+		}
+		if result.Sent != 0 || result.Held != 2 {
+			t.Fatalf("expected entries held by window, got %+v", result)
+		}
+
+		result, err = delivery.FlushDigest(ctx, "daily", 0)
+		if err != nil {
+			t.Fatalf("flush: %v", err)
+		}
+		if result.Sent != 1 || result.Entries != 2 {
+			t.Fatalf("unexpected flush result %+v", result)
+		}
+		if len(sender.messages) != 1 {
+			t.Fatalf("expected one digest email, got %d", len(sender.messages))
+		}
+		msg := sender.messages[0]
+		if msg.Subject != "2 exports ready" || msg.Attachment != nil {
+			t.Fatalf("unexpected digest email %+v", msg)
+		}
+		for _, link := range []string{"https://example.com/exports/exp-1", "https://example.com/exports/exp-2"} {
+			if !strings.Contains(msg.Body, link) {
+				t.Fatalf("expected %s in body %q", link, msg.Body)
+			}
+		}
+
+		history, _ := tracker.ListDeliveries(ctx, export.DeliveryFilter{Target: "alice@example.com"})
+		if len(history) != 2 || history[0].Status != export.DeliveryStatusSent || history[0].Reason != "digest:daily" {
+			t.Fatalf("unexpected history %+v", history)
+		}
+		result, _ = delivery.FlushDigest(ctx, "daily", 0)
+		if result.Sent != 0 || len(sender.messages) != 1 {
+			t.Fatalf("expected flushed entries removed, got %+v", result)
+		}
+	})
+
+	t.Run("zipped attachments", func(t *testing.T) {
+		delivery, sender, _ := newService(0)
+		deliver(delivery, DeliveryAttachment)
+		deliver(delivery, DeliveryAttachment)
+		if _, err := delivery.FlushDigest(ctx, "daily", 0); err != nil {
+			t.Fatalf("flush: %v", err)
+		}
+		if len(sender.messages) != 1 || sender.messages[0].Attachment == nil {
+			t.Fatalf("expected zipped attachment, got %+v", sender.messages)
+		}
+		attachment := sender.messages[0].Attachment
+		archive, err := zip.NewReader(bytes.NewReader(attachment.Data), attachment.Size)
+		if err != nil {
+			t.Fatalf("zip: %v", err)
+		}
+		names := make([]string, 0, len(archive.File))
+		for _, file := range archive.File {
+			names = append(names, file.Name)
+		}
+		if strings.Join(names, ",") != "orders.csv,orders-1.csv" {
+			t.Fatalf("unexpected archive entries %v", names)
+		}
+	})
+
+	t.Run("attachments over limit fall back to links", func(t *testing.T) {
+		delivery, sender, _ := newService(64)
+		deliver(delivery, DeliveryAttachment)
+		deliver(delivery, DeliveryAttachment)
+		if _, err := delivery.FlushDigest(ctx, "daily", 0); err != nil {
+			t.Fatalf("flush: %v", err)
+		}
+		msg := sender.messages[0]
+		if msg.Attachment != nil || !strings.Contains(msg.Body, "https://example.com/exports/") {
+			t.Fatalf("expected link fallback, got %+v", msg)
+		}
+	})
+
+	t.Run("missing artifacts are dropped", func(t *testing.T) {
+		delivery, sender, tracker := newService(0)
+		deliver(delivery, DeliveryAttachment)
+		pending, _ := delivery.digests.Pending(ctx, "daily")
+		if err := store.Delete(ctx, pending[0].ArtifactKey); err != nil {
+			t.Fatalf("delete artifact: %v", err)
+		}
+
+		result, err := delivery.FlushDigest(ctx, "daily", 0)
+		if err == nil || result.Failed != 1 || result.Dropped != 1 {
+			t.Fatalf("expected dropped failure, got %+v (%v)", result, err)
+		}
+		if len(sender.messages) != 0 {
+			t.Fatalf("expected no digest email, got %d", len(sender.messages))
+		}
+		if pending, _ := delivery.digests.Pending(ctx, "daily"); len(pending) != 0 {
+			t.Fatalf("expected failed entry removed, got %+v", pending)
+		}
+		history, _ := tracker.ListDeliveries(ctx, export.DeliveryFilter{Target: "alice@example.com"})
+		if len(history) != 1 || history[0].Status != export.DeliveryStatusFailed {
+			t.Fatalf("expected failed delivery recorded, got %+v", history)
+		}
+	})
+
+	t.Run("expired entries are dropped", func(t *testing.T) {
+		delivery, sender, tracker := newService(0)
+		deliver(delivery, DeliveryLink)
+		start := time.Now()
+		delivery.now = func() time.Time { return start.Add(DefaultDigestMaxAge + time.Hour) }
+
+		result, err := delivery.FlushDigest(ctx, "daily", 0)
+		if err != nil {
+			t.Fatalf("flush: %v", err)
+		}
+		if result.Sent != 0 || result.Dropped != 1 || len(sender.messages) != 0 {
+			t.Fatalf("expected expired entry dropped, got %+v", result)
+		}
+		history, _ := tracker.ListDeliveries(ctx, export.DeliveryFilter{Target: "alice@example.com"})
+		if len(history) != 1 || history[0].Status != export.DeliveryStatusFailed {
+			t.Fatalf("expected expired delivery recorded, got %+v", history)
+		}
+	})
+}
+
+func TestService_FlushDigest_EmailTemplates(t *testing.T) {
+	ctx := context.Background()
+	tmpl := template.Must(template.New("digest").Parse(`
+{{define "digest.subject"}}{{.T "digest.subject" (len .Exports)}}{{end}}
+{{define "digest.html"}}<ul>{{range .Exports}}<li><a href="{{.Link}}">{{.Filename}}</a></li>{{end}}</ul>{{end}}
+`))
+	delivery := NewService(Config{
+		EmailSender: &captureEmailSender{},
+		Digests:     NewMemoryDigestStore(),
+		EmailTemplates: &EmailTemplates{
+			Templates:  tmpl,
+			Translator: mapTranslator{"es": {"digest.subject": "%d exportaciones listas"}},
+			Default:    EmailTemplateSet{Subject: "ready.subject"},
+			Digest:     EmailTemplateSet{Subject: "digest.subject", HTML: "digest.html"},
+		},
+		LinkBuilder: func(exportID string, ref export.ArtifactRef) string {
+			return "https://example.com/exports/" + exportID
+		},
+	})
+	for _, id := range []string{"exp-1", "exp-2"} {
+		if err := delivery.digests.Add(ctx, DigestEntry{
+			Digest:     "daily",
+			Channel:    DigestChannelEmail,
+			Recipient:  "alice@example.com",
+			ExportID:   id,
+			Definition: "orders",
+			Filename:   id + ".csv",
+			Mode:       DeliveryLink,
+			Locale:     "es",
+		}); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	if _, err := delivery.FlushDigest(ctx, "daily", 0); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	msg := delivery.emailSender.(*captureEmailSender).messages[0]
+	if msg.Subject != "2 exportaciones listas" {
+		t.Fatalf("expected localized subject, got %q", msg.Subject)
+	}
+	if !strings.Contains(msg.HTML, `href="https://example.com/exports/exp-2"`) || !strings.Contains(msg.Body, "exp-1.csv: https://example.com/exports/exp-1") {
+		t.Fatalf("expected templated digest body, got %q / %q", msg.HTML, msg.Body)
+	}
+}
+
+type countingDigestFlusher struct {
+	count int
+}
+
+func (f *countingDigestFlusher) FlushDigest(ctx context.Context, digest string, window time.Duration) (DigestResult, error) {
+	f.count++
+	return DigestResult{}, nil
+}
+
+func TestDigestCommand_CronHandlerRunsOncePerLeaseWindow(t *testing.T) {
+	flusher := &countingDigestFlusher{}
+	now := time.Date(2024, 5, 1, 8, 0, 1, 0, time.UTC)
+	locker := export.NewMemoryLocker()
+	locker.Now = func() time.Time { return now }
+
+	replicas := make([]*DigestCommand, 3)
+	for i := range replicas {
+		replicas[i] = NewDigestCommand(flusher, []string{"daily"}, WithDigestLease(locker, time.Hour))
+		replicas[i].now = func() time.Time { return now }
+	}
+	for _, replica := range replicas {
+		if err := replica.CronHandler()(); err != nil {
+			t.Fatalf("cron: %v", err)
+		}
+	}
+	if flusher.count != 1 {
+		t.Fatalf("expected one flush across replicas, got %d", flusher.count)
+	}
+
+	now = now.Add(time.Hour)
+	if err := replicas[2].CronHandler()(); err != nil {
+		t.Fatalf("cron: %v", err)
+	}
+	if flusher.count != 2 {
+		t.Fatalf("expected the next window to flush, got %d", flusher.count)
+	}
+}
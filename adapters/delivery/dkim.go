@@ -0,0 +1,209 @@
+package exportdelivery
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+)
+
+// DefaultDKIMHeaders are signed when DKIMSigner.Headers is empty. Headers
+// missing from a message are skipped.
+var DefaultDKIMHeaders = []string{
+	"From", "To", "Cc", "Reply-To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type",
+}
+
+// DKIMSigner adds a DKIM-Signature (RFC 6376, relaxed/relaxed) to outgoing
+// messages. Key is an *rsa.PrivateKey (rsa-sha256) or ed25519.PrivateKey
+// (ed25519-sha256, RFC 8463).
+type DKIMSigner struct {
+	Domain   string
+	Selector string
+	Key      crypto.Signer
+	Headers  []string
+	Now      func() time.Time
+}
+
+// ParseDKIMKey parses a PEM-encoded PKCS#1, PKCS#8, or Ed25519 private key.
+func ParseDKIMKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, export.NewError(export.KindValidation, "dkim key is not PEM encoded", nil)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, export.NewError(export.KindValidation, "dkim key is invalid", err)
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, export.NewError(export.KindValidation, "dkim key type is unsupported", nil)
+	}
+}
+
+// Sign returns message with a DKIM-Signature header prepended.
+func (s *DKIMSigner) Sign(message []byte) ([]byte, error) {
+	if s == nil || s.Key == nil {
+		return nil, export.NewError(export.KindValidation, "dkim key is required", nil)
+	}
+	if strings.TrimSpace(s.Domain) == "" || strings.TrimSpace(s.Selector) == "" {
+		return nil, export.NewError(export.KindValidation, "dkim domain and selector are required", nil)
+	}
+	algorithm, err := dkimAlgorithm(s.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	message = []byte(toCRLF(string(message)))
+	headerBlock, body, _ := bytes.Cut(message, []byte("\r\n\r\n"))
+	headers := splitHeaders(string(headerBlock) + "\r\n")
+	bodyHash := sha256.Sum256([]byte(relaxedBody(string(body))))
+
+	names := s.Headers
+	if len(names) == 0 {
+		names = DefaultDKIMHeaders
+	}
+	signed, names := selectHeaders(headers, names)
+
+	value := fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s; t=%d; h=%s; bh=%s; b=",
+		algorithm,
+		s.Domain,
+		s.Selector,
+		nowOr(s.Now).Unix(),
+		strings.Join(names, ":"),
+		base64.StdEncoding.EncodeToString(bodyHash[:]),
+	)
+
+	var data strings.Builder
+	for _, header := range signed {
+		data.WriteString(relaxedHeader(header))
+		data.WriteString("\r\n")
+	}
+	data.WriteString(relaxedHeader("DKIM-Signature: " + value))
+	digest := sha256.Sum256([]byte(data.String()))
+
+	var signature []byte
+	switch algorithm {
+	case "rsa-sha256":
+		signature, err = s.Key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	default:
+		signature, err = s.Key.Sign(rand.Reader, digest[:], crypto.Hash(0))
+	}
+	if err != nil {
+		return nil, export.NewError(export.KindInternal, "dkim signing failed", err)
+	}
+
+	header := "DKIM-Signature: " + strings.ReplaceAll(value, "; ", ";\r\n\t") +
+		base64.StdEncoding.EncodeToString(signature) + "\r\n"
+	return append([]byte(header), message...), nil
+}
+
+func dkimAlgorithm(key crypto.Signer) (string, error) {
+	switch key.Public().(type) {
+	case *rsa.PublicKey:
+		return "rsa-sha256", nil
+	case ed25519.PublicKey:
+		return "ed25519-sha256", nil
+	default:
+		return "", export.NewError(export.KindValidation, "dkim key type is unsupported", nil)
+	}
+}
+
+// splitHeaders returns raw header fields, keeping folded continuation lines
+// with their field.
+func splitHeaders(block string) []string {
+	var headers []string
+	for _, line := range strings.SplitAfter(block, "\r\n") {
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(headers) > 0 {
+			headers[len(headers)-1] += line
+			continue
+		}
+		headers = append(headers, line)
+	}
+	for i := range headers {
+		headers[i] = strings.TrimSuffix(headers[i], "\r\n")
+	}
+	return headers
+}
+
+// selectHeaders picks the fields to sign, using the last unused instance of
+// each name as RFC 6376 5.4.2 requires, and drops names not present.
+func selectHeaders(headers []string, names []string) ([]string, []string) {
+	used := make(map[int]bool)
+	var signed []string
+	var present []string
+	for _, name := range names {
+		for i := len(headers) - 1; i >= 0; i-- {
+			if used[i] {
+				continue
+			}
+			field, _, ok := strings.Cut(headers[i], ":")
+			if ok && strings.EqualFold(strings.TrimSpace(field), name) {
+				used[i] = true
+				signed = append(signed, headers[i])
+				present = append(present, strings.ToLower(name))
+				break
+			}
+		}
+	}
+	return signed, present
+}
+
+// relaxedHeader applies relaxed header canonicalization.
+func relaxedHeader(header string) string {
+	name, value, _ := strings.Cut(header, ":")
+	value = strings.NewReplacer("\r\n", "").Replace(value)
+	return strings.ToLower(strings.TrimSpace(name)) + ":" + strings.Join(strings.Fields(value), " ")
+}
+
+// relaxedBody applies relaxed body canonicalization.
+func relaxedBody(body string) string {
+	lines := strings.Split(body, "\r\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		lines[i] = collapseWhitespace(line)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func collapseWhitespace(line string) string {
+	var out strings.Builder
+	space := false
+	for _, r := range line {
+		if r == ' ' || r == '\t' {
+			space = true
+			continue
+		}
+		if space {
+			out.WriteByte(' ')
+			space = false
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
@@ -0,0 +1,173 @@
+package exportdelivery
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-msgauth/dkim"
+)
+
+// smtpStandIn is a minimal local SMTP server that captures one message.
+type smtpStandIn struct {
+	addr     string
+	messages chan []byte
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	server := &smtpStandIn{addr: listener.Addr().String(), messages: make(chan []byte, 1)}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		_ = tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			switch verb := strings.ToUpper(strings.Fields(line + " ")[0]); verb {
+			case "EHLO", "HELO":
+				_ = tp.PrintfLine("250 localhost")
+			case "DATA":
+				_ = tp.PrintfLine("354 go ahead")
+				data, err := io.ReadAll(tp.DotReader())
+				if err != nil {
+					return
+				}
+				server.messages <- data
+				_ = tp.PrintfLine("250 queued")
+			case "QUIT":
+				_ = tp.PrintfLine("221 bye")
+				return
+			default:
+				_ = tp.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return server
+}
+
+func TestSMTPMailer_DKIMSigned(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519 key: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa key: %v", err)
+	}
+
+	for name, key := range map[string]crypto.Signer{"rsa": rsaKey, "ed25519": edKey} {
+		t.Run(name, func(t *testing.T) {
+			server := newSMTPStandIn(t)
+			mailer := &SMTPMailer{
+				Addr:            server.addr,
+				From:            "Reports <reports@example.com>",
+				MessageIDDomain: "mail.example.com",
+				DKIM:            &DKIMSigner{Domain: "example.com", Selector: "exports", Key: key},
+				Now:             func() time.Time { return time.Date(2024, 3, 7, 9, 0, 0, 0, time.UTC) },
+			}
+			err := mailer.Send(context.Background(), EmailMessage{
+				To:      []string{"partner@example.net"},
+				Subject: "Relatório  pronto",
+				Body:    "See the attached report.\n",
+				HTML:    `<p>See the report <img src="cid:logo"></p>`,
+				Inline: []InlineImage{{
+					ContentID:   "logo",
+					Filename:    "logo.png",
+					ContentType: "image/png",
+					Data:        []byte("png"),
+				}},
+				Attachment: &Attachment{Filename: "relatório.csv", ContentType: "text/csv", Data: []byte("a,b\n")},
+			})
+			if err != nil {
+				t.Fatalf("send: %v", err)
+			}
+
+			var raw []byte
+			select {
+			case raw = <-server.messages:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for message")
+			}
+			payload := string(raw)
+			for _, want := range []string{
+				"multipart/mixed",
+				"multipart/related",
+				"multipart/alternative",
+				"Content-Id: <logo>",
+				`filename="=?utf-8?q?relat=C3=B3rio.csv?="`,
+				"@mail.example.com>",
+			} {
+				if !strings.Contains(payload, want) {
+					t.Fatalf("expected %q in message:\n%s", want, payload)
+				}
+			}
+			if err := verifyDKIM(raw, key.Public()); err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+
+			tampered := strings.Replace(payload, "Relat", "Ralat", 1)
+			if err := verifyDKIM([]byte(tampered), key.Public()); err == nil {
+				t.Fatalf("expected tampered message to fail verification")
+			}
+		})
+	}
+}
+
+// verifyDKIM checks the DKIM-Signature of message against pub with an
+// independent verifier, serving the key record the selector would publish.
+func verifyDKIM(message []byte, pub crypto.PublicKey) error {
+	var record string
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			return err
+		}
+		record = "v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(der)
+	case ed25519.PublicKey:
+		record = "v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(key)
+	default:
+		return errors.New("unsupported key")
+	}
+
+	verifications, err := dkim.VerifyWithOptions(bytes.NewReader(message), &dkim.VerifyOptions{
+		LookupTXT: func(domain string) ([]string, error) {
+			if domain != "exports._domainkey.example.com" {
+				return nil, fmt.Errorf("unexpected DKIM lookup %q", domain)
+			}
+			return []string{record}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if len(verifications) != 1 {
+		return fmt.Errorf("expected one DKIM signature, got %d", len(verifications))
+	}
+	return verifications[0].Err
+}
@@ -3,10 +3,15 @@ package exportdelivery
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
 	"net/smtp"
 	"net/textproto"
 	"strings"
@@ -17,16 +22,28 @@ import (
 
 // EmailMessage describes an outbound email delivery.
 type EmailMessage struct {
-	From       string
-	To         []string
-	Cc         []string
-	Bcc        []string
-	ReplyTo    string
-	Subject    string
-	Body       string
+	From    string
+	To      []string
+	Cc      []string
+	Bcc     []string
+	ReplyTo string
+	Subject string
+	Body    string
+	// HTML is sent as a multipart/alternative part next to the text Body.
+	HTML string
+	// Inline images are referenced from HTML as "cid:<ContentID>".
+	Inline     []InlineImage
 	Attachment *Attachment
 }
 
+// InlineImage is an image embedded in the HTML body.
+type InlineImage struct {
+	ContentID   string
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
 // EmailSender delivers email messages.
 type EmailSender interface {
 	Send(ctx context.Context, msg EmailMessage) error
@@ -37,13 +54,16 @@ type SMTPClient interface {
 	SendMail(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
 }
 
-// SMTPMailer sends email via SMTP.
+// SMTPMailer sends email via SMTP. MessageIDDomain sets the Message-ID
+// domain (default: the From domain); DKIM signs outgoing messages when set.
 type SMTPMailer struct {
-	Addr   string
-	Auth   smtp.Auth
-	From   string
-	Client SMTPClient
-	Now    func() time.Time
+	Addr            string
+	Auth            smtp.Auth
+	From            string
+	Client          SMTPClient
+	Now             func() time.Time
+	MessageIDDomain string
+	DKIM            *DKIMSigner
 }
 
 // Send delivers the message via SMTP.
@@ -67,11 +87,23 @@ func (m *SMTPMailer) Send(ctx context.Context, msg EmailMessage) error {
 	if len(msg.To) == 0 && len(msg.Cc) == 0 && len(msg.Bcc) == 0 {
 		return export.NewError(export.KindValidation, "email recipients are required", nil)
 	}
+	if len(msg.Inline) > 0 && msg.HTML == "" {
+		return export.NewError(export.KindValidation, "inline images require an HTML body", nil)
+	}
 
-	payload, err := buildEmailMessage(msg, from, nowOr(m.Now))
+	messageID, err := newMessageID(m.MessageIDDomain, from)
+	if err != nil {
+		return err
+	}
+	payload, err := buildEmailMessage(msg, from, messageID, nowOr(m.Now))
 	if err != nil {
 		return err
 	}
+	if m.DKIM != nil {
+		if payload, err = m.DKIM.Sign(payload); err != nil {
+			return err
+		}
+	}
 
 	client := m.Client
 	if client == nil {
@@ -80,13 +112,16 @@ func (m *SMTPMailer) Send(ctx context.Context, msg EmailMessage) error {
 
 	recipients := append(append([]string{}, msg.To...), msg.Cc...)
 	recipients = append(recipients, msg.Bcc...)
-	if err := client.SendMail(m.Addr, m.Auth, from, recipients, payload); err != nil {
+	if err := client.SendMail(m.Addr, m.Auth, addressOnly(from), recipients, payload); err != nil {
 		return export.NewError(export.KindExternal, "smtp send failed", err)
 	}
 	return nil
 }
 
-func buildEmailMessage(msg EmailMessage, from string, now time.Time) ([]byte, error) {
+// buildEmailMessage renders msg as multipart/mixed (attachment) wrapping
+// multipart/related (inline images) wrapping multipart/alternative (text and
+// HTML); levels that are not needed are left out.
+func buildEmailMessage(msg EmailMessage, from, messageID string, now time.Time) ([]byte, error) {
 	var buf bytes.Buffer
 	writeHeader(&buf, "From", from)
 	if len(msg.To) > 0 {
@@ -98,54 +133,177 @@ func buildEmailMessage(msg EmailMessage, from string, now time.Time) ([]byte, er
 	if reply := strings.TrimSpace(msg.ReplyTo); reply != "" {
 		writeHeader(&buf, "Reply-To", reply)
 	}
-	writeHeader(&buf, "Subject", msg.Subject)
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
 	writeHeader(&buf, "Date", now.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID)
 	writeHeader(&buf, "MIME-Version", "1.0")
 
-	if msg.Attachment == nil {
-		writeHeader(&buf, "Content-Type", "text/plain; charset=utf-8")
-		writeHeader(&buf, "Content-Transfer-Encoding", "7bit")
-		buf.WriteString("\r\n")
-		buf.WriteString(msg.Body)
-		buf.WriteString("\r\n")
-		return buf.Bytes(), nil
+	body := textEntity("text/plain", msg.Body)
+	if msg.HTML != "" {
+		alternative, err := multipartEntity("alternative", []mimeEntity{
+			textEntity("text/plain", msg.Body),
+			textEntity("text/html", msg.HTML),
+		})
+		if err != nil {
+			return nil, err
+		}
+		body = alternative
+	}
+	if len(msg.Inline) > 0 {
+		parts := []mimeEntity{body}
+		for _, image := range msg.Inline {
+			parts = append(parts, inlineEntity(image))
+		}
+		related, err := multipartEntity("related", parts)
+		if err != nil {
+			return nil, err
+		}
+		body = related
+	}
+	if msg.Attachment != nil {
+		mixed, err := multipartEntity("mixed", []mimeEntity{body, attachmentEntity(msg.Attachment)})
+		if err != nil {
+			return nil, err
+		}
+		body = mixed
 	}
 
-	writer := multipart.NewWriter(&buf)
-	writeHeader(&buf, "Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", writer.Boundary()))
+	for _, key := range []string{"Content-Type", "Content-Transfer-Encoding", "Content-ID", "Content-Disposition"} {
+		writeHeader(&buf, key, body.header.Get(key))
+	}
 	buf.WriteString("\r\n")
+	buf.Write(body.body)
+	return buf.Bytes(), nil
+}
 
-	textHeaders := make(textproto.MIMEHeader)
-	textHeaders.Set("Content-Type", "text/plain; charset=utf-8")
-	textHeaders.Set("Content-Transfer-Encoding", "7bit")
-	textPart, err := writer.CreatePart(textHeaders)
-	if err != nil {
-		return nil, err
+// mimeEntity is a MIME part: its headers and encoded body.
+type mimeEntity struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// textEntity encodes text as 7bit when it is plain ASCII and
+// quoted-printable otherwise. Line endings are normalized to CRLF.
+func textEntity(contentType, text string) mimeEntity {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", contentType+"; charset=utf-8")
+	if isASCII(text) && contentType == "text/plain" {
+		header.Set("Content-Transfer-Encoding", "7bit")
+		return mimeEntity{header: header, body: []byte(toCRLF(text) + "\r\n")}
 	}
-	if _, err := textPart.Write([]byte(msg.Body)); err != nil {
-		return nil, err
+
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	var buf bytes.Buffer
+	qp := quotedprintable.NewWriter(&buf)
+	_, _ = qp.Write([]byte(text))
+	_ = qp.Close()
+	buf.WriteString("\r\n")
+	return mimeEntity{header: header, body: buf.Bytes()}
+}
+
+func inlineEntity(image InlineImage) mimeEntity {
+	header := make(textproto.MIMEHeader)
+	contentType := image.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
 	}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "base64")
+	header.Set("Content-ID", "<"+strings.Trim(image.ContentID, "<>")+">")
+	header.Set("Content-Disposition", "inline"+filenameParam(image.Filename))
+	return mimeEntity{header: header, body: base64Body(image.Data)}
+}
 
-	attachHeaders := make(textproto.MIMEHeader)
-	contentType := msg.Attachment.ContentType
+func attachmentEntity(attachment *Attachment) mimeEntity {
+	header := make(textproto.MIMEHeader)
+	contentType := attachment.ContentType
 	if contentType == "" {
 		contentType = "application/octet-stream"
 	}
-	attachHeaders.Set("Content-Type", contentType)
-	attachHeaders.Set("Content-Transfer-Encoding", "base64")
-	attachHeaders.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", msg.Attachment.Filename))
-	attachPart, err := writer.CreatePart(attachHeaders)
-	if err != nil {
-		return nil, err
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "base64")
+	header.Set("Content-Disposition", "attachment"+filenameParam(attachment.Filename))
+	return mimeEntity{header: header, body: base64Body(attachment.Data)}
+}
+
+func multipartEntity(subtype string, parts []mimeEntity) (mimeEntity, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, part := range parts {
+		w, err := writer.CreatePart(part.header)
+		if err != nil {
+			return mimeEntity{}, err
+		}
+		if _, err := w.Write(part.body); err != nil {
+			return mimeEntity{}, err
+		}
 	}
-	if err := writeBase64(attachPart, msg.Attachment.Data); err != nil {
-		return nil, err
+	if err := writer.Close(); err != nil {
+		return mimeEntity{}, err
 	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", fmt.Sprintf("multipart/%s; boundary=%q", subtype, writer.Boundary()))
+	return mimeEntity{header: header, body: buf.Bytes()}, nil
+}
 
-	if err := writer.Close(); err != nil {
-		return nil, err
+// filenameParam renders a filename parameter, RFC 2047 encoding names that
+// are not plain ASCII.
+func filenameParam(filename string) string {
+	filename = strings.TrimSpace(filename)
+	if filename == "" {
+		return ""
 	}
-	return buf.Bytes(), nil
+	encoded := mime.QEncoding.Encode("utf-8", filename)
+	encoded = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(encoded)
+	return fmt.Sprintf("; filename=\"%s\"", encoded)
+}
+
+func base64Body(data []byte) []byte {
+	var buf bytes.Buffer
+	_ = writeBase64(&buf, data)
+	return buf.Bytes()
+}
+
+// newMessageID builds a Message-ID in domain, falling back to the sender's
+// domain.
+func newMessageID(domain, from string) (string, error) {
+	domain = strings.TrimSpace(domain)
+	if domain == "" {
+		address := addressOnly(from)
+		if at := strings.LastIndex(address, "@"); at >= 0 {
+			domain = address[at+1:]
+		}
+	}
+	if domain == "" {
+		domain = "localhost"
+	}
+	var token [16]byte
+	if _, err := rand.Read(token[:]); err != nil {
+		return "", export.NewError(export.KindInternal, "message id generation failed", err)
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(token[:]), domain), nil
+}
+
+// addressOnly strips the display name from an address.
+func addressOnly(value string) string {
+	if addr, err := mail.ParseAddress(value); err == nil {
+		return addr.Address
+	}
+	return strings.TrimSpace(value)
+}
+
+func isASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+func toCRLF(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.ReplaceAll(value, "\n", "\r\n")
 }
 
 func writeHeader(buf *bytes.Buffer, key, value string) {
@@ -0,0 +1,187 @@
+package exportdelivery
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	exporttemplate "github.com/goliatone/go-export/adapters/template"
+	"github.com/goliatone/go-export/export"
+	i18n "github.com/goliatone/go-i18n"
+)
+
+// EmailTemplateSet names the templates used for a delivery email. Text is
+// optional; when empty the plain-text part is derived from the HTML output.
+type EmailTemplateSet struct {
+	Subject string `json:"subject,omitempty"`
+	HTML    string `json:"html,omitempty"`
+	Text    string `json:"text,omitempty"`
+}
+
+// EmailTemplates renders delivery emails through a template executor (for
+// example html/template or the go-template adapter). Subject and Text are
+// plain text, so HTML escaping in their output is undone. The set is selected by
+// Message.Template, then Digest for digest messages, then by export
+// definition, then Default.
+type EmailTemplates struct {
+	Templates     exporttemplate.TemplateExecutor
+	Translator    i18n.Translator
+	DefaultLocale string
+	Default       EmailTemplateSet
+	Digest        EmailTemplateSet
+	Sets          map[string]EmailTemplateSet
+	Definitions   map[string]EmailTemplateSet
+}
+
+// EmailContent is a rendered delivery email.
+type EmailContent struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// EmailTemplateData is the context passed to email templates. Templates
+// translate with {{ .T "key" args... }} using the request locale.
+type EmailTemplateData struct {
+	Locale     string
+	ExportID   string
+	Definition string
+	Format     export.Format
+	Filename   string
+	Link       string
+	ExpiresAt  string
+	Attachment string
+	Rows       int64
+	Note       string
+	Message    string
+	Metadata   map[string]any
+	// Digest names the digest being flushed; Exports lists its entries.
+	Digest  string
+	Exports []EmailTemplateExport
+
+	translator i18n.Translator
+}
+
+// EmailTemplateExport describes one export listed in a digest email.
+type EmailTemplateExport struct {
+	ExportID   string
+	Definition string
+	Format     export.Format
+	Filename   string
+	Link       string
+	Rows       int64
+}
+
+// T translates key for the data's locale. Missing translations render the
+// key so gaps stay visible without failing the delivery.
+func (d EmailTemplateData) T(key string, args ...any) string {
+	if d.translator == nil {
+		return key
+	}
+	value, err := d.translator.Translate(d.Locale, key, args...)
+	if err != nil || value == "" {
+		return key
+	}
+	return value
+}
+
+// Render executes the selected template set. An empty name selects the
+// definition set or Default.
+func (t *EmailTemplates) Render(name string, data EmailTemplateData) (EmailContent, error) {
+	if t == nil || t.Templates == nil {
+		return EmailContent{}, export.NewError(export.KindNotImpl, "email templates not configured", nil)
+	}
+	set, err := t.resolveSet(name, data)
+	if err != nil {
+		return EmailContent{}, err
+	}
+	if data.Locale == "" {
+		data.Locale = t.DefaultLocale
+	}
+	data.translator = t.Translator
+
+	var content EmailContent
+	if set.Subject != "" {
+		subject, err := t.execute(set.Subject, data)
+		if err != nil {
+			return EmailContent{}, err
+		}
+		content.Subject = strings.Join(strings.Fields(html.UnescapeString(subject)), " ")
+	}
+	if set.HTML != "" {
+		if content.HTML, err = t.execute(set.HTML, data); err != nil {
+			return EmailContent{}, err
+		}
+	}
+	if set.Text != "" {
+		text, err := t.execute(set.Text, data)
+		if err != nil {
+			return EmailContent{}, err
+		}
+		content.Text = html.UnescapeString(text)
+	} else if content.HTML != "" {
+		content.Text = htmlToText(content.HTML)
+	}
+	content.Text = strings.TrimSpace(content.Text)
+	return content, nil
+}
+
+func (t *EmailTemplates) resolveSet(name string, data EmailTemplateData) (EmailTemplateSet, error) {
+	if name = strings.TrimSpace(name); name != "" {
+		set, ok := t.Sets[name]
+		if !ok {
+			return EmailTemplateSet{}, export.NewError(export.KindValidation, fmt.Sprintf("email template %q is not registered", name), nil)
+		}
+		return set, nil
+	}
+	if data.Digest != "" && t.Digest != (EmailTemplateSet{}) {
+		return t.Digest, nil
+	}
+	if set, ok := t.Definitions[data.Definition]; ok {
+		return set, nil
+	}
+	return t.Default, nil
+}
+
+func (t *EmailTemplates) execute(name string, data EmailTemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", export.NewError(export.KindInternal, fmt.Sprintf("email template %q failed", name), err)
+	}
+	return buf.String(), nil
+}
+
+var (
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</tr>`)
+	htmlBlockPattern = regexp.MustCompile(`(?i)</(p|div|h[1-6]|table|ul|ol)>`)
+	htmlItemPattern  = regexp.MustCompile(`(?i)<li[^>]*>`)
+	htmlLinkPattern  = regexp.MustCompile(`(?is)<a[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	htmlTagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines       = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText derives a plain-text alternative from rendered HTML. Links keep
+// their URL so text clients can still follow them.
+func htmlToText(body string) string {
+	body = htmlLinkPattern.ReplaceAllStringFunc(body, func(match string) string {
+		parts := htmlLinkPattern.FindStringSubmatch(match)
+		label := strings.TrimSpace(htmlTagPattern.ReplaceAllString(parts[2], ""))
+		if label == "" || label == parts[1] {
+			return parts[1]
+		}
+		return label + ": " + parts[1]
+	})
+	body = htmlBreakPattern.ReplaceAllString(body, "\n")
+	body = htmlBlockPattern.ReplaceAllString(body, "\n\n")
+	body = htmlItemPattern.ReplaceAllString(body, "\n- ")
+	body = htmlTagPattern.ReplaceAllString(body, "")
+	body = html.UnescapeString(body)
+
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
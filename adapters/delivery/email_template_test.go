@@ -0,0 +1,132 @@
+package exportdelivery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/goliatone/go-export/export"
+)
+
+type mapTranslator map[string]map[string]string
+
+func (m mapTranslator) Translate(locale, key string, args ...any) (string, error) {
+	value, ok := m[locale][key]
+	if !ok {
+		return "", fmt.Errorf("missing %s/%s", locale, key)
+	}
+	return fmt.Sprintf(value, args...), nil
+}
+
+func TestService_Deliver_EmailTemplates(t *testing.T) {
+	tmpl := template.Must(template.New("delivery").Parse(`
+{{define "ready.subject"}}{{.T "export.ready.subject" .Definition}}{{end}}
+{{define "ready.html"}}<p>{{.T "export.ready.body" .Rows}}</p><p><a href="{{.Link}}">{{.T "export.download"}}</a></p>{{end}}
+{{define "orders.html"}}<h1>Orders & Co</h1><ul><li>{{.Filename}}</li></ul>{{end}}
+`))
+	templates := &EmailTemplates{
+		Templates:     tmpl,
+		DefaultLocale: "en",
+		Translator: mapTranslator{
+			"en": {"export.ready.subject": "Export ready: %s", "export.ready.body": "%d rows exported.", "export.download": "Download"},
+			"es": {"export.ready.subject": "Exportación lista: %s", "export.ready.body": "%d filas exportadas.", "export.download": "Descargar"},
+		},
+		Default:     EmailTemplateSet{Subject: "ready.subject", HTML: "ready.html"},
+		Definitions: map[string]EmailTemplateSet{"orders": {HTML: "orders.html"}},
+	}
+
+	store := export.NewMemoryStore()
+	svc := &stubExportService{
+		request: func(ctx context.Context, actor export.Actor, req export.ExportRequest) (export.ExportRecord, error) {
+			return export.ExportRecord{ID: "exp-1"}, nil
+		},
+		generate: func(ctx context.Context, actor export.Actor, exportID string, req export.ExportRequest) (export.ExportResult, error) {
+			ref, err := store.Put(ctx, "exports/"+exportID, bytes.NewReader([]byte("a,b\n")), export.ArtifactMeta{Filename: "report.csv"})
+			if err != nil {
+				return export.ExportResult{}, err
+			}
+			return export.ExportResult{ID: exportID, Format: req.Format, Rows: 3, Artifact: &ref}, nil
+		},
+	}
+	email := &captureEmailSender{}
+	delivery := NewService(Config{
+		Service:        svc,
+		Store:          store,
+		EmailSender:    email,
+		EmailTemplates: templates,
+		LinkBuilder: func(exportID string, ref export.ArtifactRef) string {
+			return "https://example.com/exports/" + exportID
+		},
+	})
+	deliver := func(definition, locale string, message Message) (EmailMessage, error) {
+		_, err := delivery.Deliver(context.Background(), Request{
+			Actor:   export.Actor{ID: "actor-1"},
+			Export:  export.ExportRequest{Definition: definition, Format: export.FormatCSV, Locale: locale},
+			Targets: []Target{{Kind: TargetEmail, Email: EmailTarget{To: []string{"demo@example.com"}}}},
+			Message: message,
+		})
+		if err != nil {
+			return EmailMessage{}, err
+		}
+		return email.messages[len(email.messages)-1], nil
+	}
+
+	msg, err := deliver("users", "es", Message{})
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if msg.Subject != "Exportación lista: users" {
+		t.Fatalf("unexpected subject %q", msg.Subject)
+	}
+	if !strings.Contains(msg.HTML, "3 filas exportadas.") || !strings.Contains(msg.HTML, `href="https://example.com/exports/exp-1"`) {
+		t.Fatalf("unexpected html %q", msg.HTML)
+	}
+	if msg.Body != "3 filas exportadas.\n\nDescargar: https://example.com/exports/exp-1" {
+		t.Fatalf("unexpected text alternative %q", msg.Body)
+	}
+
+	msg, err = deliver("orders", "", Message{Subject: "Custom"})
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if msg.Subject != "Custom" || msg.Body != "Orders & Co\n\n- report.csv" {
+		t.Fatalf("unexpected definition template output %+v", msg)
+	}
+
+	if _, err := deliver("users", "en", Message{Template: "missing"}); err == nil {
+		t.Fatalf("expected unknown template error")
+	}
+}
+
+func TestEmailTemplates_RenderUnescapesPlainTextParts(t *testing.T) {
+	tmpl := template.Must(template.New("delivery").Parse(`
+{{define "subject"}}{{.Definition}} ready{{end}}
+{{define "text"}}{{.Note}}: {{.Link}}{{end}}
+{{define "html"}}<p>{{.Note}}</p>{{end}}
+`))
+	templates := &EmailTemplates{
+		Templates: tmpl,
+		Default:   EmailTemplateSet{Subject: "subject", Text: "text", HTML: "html"},
+	}
+
+	content, err := templates.Render("", EmailTemplateData{
+		Definition: "Tom & Jerry's",
+		Note:       "Sales & Ops' report",
+		Link:       "https://example.com/exports/exp-1?a=1&b=2",
+	})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if content.Subject != "Tom & Jerry's ready" {
+		t.Fatalf("unexpected subject %q", content.Subject)
+	}
+	if content.Text != "Sales & Ops' report: https://example.com/exports/exp-1?a=1&b=2" {
+		t.Fatalf("unexpected text %q", content.Text)
+	}
+	if content.HTML != "<p>Sales &amp; Ops&#39; report</p>" {
+		t.Fatalf("expected html part to stay escaped, got %q", content.HTML)
+	}
+}
@@ -78,3 +78,44 @@ func TestSMTPMailer_SendPlainText(t *testing.T) {
 		t.Fatalf("did not expect multipart email")
 	}
 }
+
+func TestSMTPMailer_SendHTMLAlternative(t *testing.T) {
+	client := &captureSMTP{}
+	mailer := &SMTPMailer{
+		Addr:   "smtp.test:25",
+		From:   "sender@example.com",
+		Client: client,
+	}
+
+	err := mailer.Send(context.Background(), EmailMessage{
+		To:      []string{"recipient@example.com"},
+		Subject: "Exportación lista",
+		Body:    "Here is your report",
+		HTML:    "<p>Here is your report</p>",
+		Attachment: &Attachment{
+			Filename: "report.csv",
+			Data:     []byte("a,b"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	payload := string(client.msg)
+	for _, want := range []string{
+		"Subject: =?utf-8?q?",
+		"multipart/mixed",
+		"multipart/alternative",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Type: text/html; charset=utf-8",
+		"<p>Here is your report</p>",
+		"Content-Disposition: attachment",
+	} {
+		if !strings.Contains(payload, want) {
+			t.Fatalf("expected %q in payload:\n%s", want, payload)
+		}
+	}
+	if strings.Index(payload, "text/plain") > strings.Index(payload, "text/html") {
+		t.Fatalf("expected text part before html part")
+	}
+}
@@ -13,6 +13,9 @@ type Payload struct {
 }
 
 func encodePayload(payload Payload) (json.RawMessage, error) {
+	if hasInlineWebhookSecret(payload.Request.Targets) {
+		return nil, export.NewError(export.KindValidation, "queued webhook targets must use a secret reference", nil)
+	}
 	raw, err := json.Marshal(payload)
 	if err != nil {
 		return nil, export.NewError(export.KindValidation, "payload is not serializable", err)
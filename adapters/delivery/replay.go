@@ -0,0 +1,187 @@
+package exportdelivery
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	gcmd "github.com/goliatone/go-command"
+	errorslib "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-export/export"
+)
+
+// DeadLetterReplayer re-sends a dead-lettered webhook by ID.
+type DeadLetterReplayer interface {
+	Replay(ctx context.Context, id string) error
+}
+
+// ReplayResult summarizes a dead-letter replay run.
+type ReplayResult struct {
+	Replayed int
+	Failed   int
+}
+
+// ReplayCommand wires CLI/Cron replay of dead-lettered webhook deliveries.
+type ReplayCommand struct {
+	store       DeadLetterStore
+	replayer    DeadLetterReplayer
+	cliConfig   gcmd.CLIConfig
+	cronConfig  gcmd.HandlerConfig
+	limit       int
+	locker      export.Locker
+	leaseWindow time.Duration
+	now         func() time.Time
+}
+
+// ReplayOption customizes dead-letter replay commands.
+type ReplayOption func(*ReplayCommand)
+
+// WithReplayCLIConfig overrides CLI configuration.
+func WithReplayCLIConfig(cfg gcmd.CLIConfig) ReplayOption {
+	return func(cmd *ReplayCommand) {
+		cmd.cliConfig = cfg
+	}
+}
+
+// WithReplayCronConfig overrides cron configuration.
+func WithReplayCronConfig(cfg gcmd.HandlerConfig) ReplayOption {
+	return func(cmd *ReplayCommand) {
+		cmd.cronConfig = cfg
+	}
+}
+
+// WithReplayLimit bounds how many dead letters a run replays.
+func WithReplayLimit(limit int) ReplayOption {
+	return func(cmd *ReplayCommand) {
+		cmd.limit = limit
+	}
+}
+
+// WithReplayLease makes cron runs acquire a lease per run window, so dead
+// letters are replayed once across replicas. Set window to the cron interval.
+func WithReplayLease(locker export.Locker, window time.Duration) ReplayOption {
+	return func(cmd *ReplayCommand) {
+		cmd.locker = locker
+		cmd.leaseWindow = window
+	}
+}
+
+// NewReplayDeadLettersCommand creates a dead-letter replay CLI/Cron command.
+// HTTPWebhookSender implements DeadLetterReplayer.
+func NewReplayDeadLettersCommand(store DeadLetterStore, replayer DeadLetterReplayer, opts ...ReplayOption) *ReplayCommand {
+	cmd := &ReplayCommand{
+		store:    store,
+		replayer: replayer,
+		cliConfig: gcmd.CLIConfig{
+			Path:        []string{"exports-webhooks-replay"},
+			Description: "Replay dead-lettered export webhooks",
+			Group:       "exports",
+		},
+		cronConfig: gcmd.HandlerConfig{Expression: "*/15 * * * *"},
+		limit:      100,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cmd)
+		}
+	}
+	return cmd
+}
+
+// Replay re-sends the given dead letters, or the oldest unparked ones when ids
+// is empty. Every letter is attempted; failures are joined into the returned
+// error. Letters the replayer parks drop out of later cron runs.
+func (c *ReplayCommand) Replay(ctx context.Context, ids []string) (ReplayResult, error) {
+	if c == nil {
+		return ReplayResult{}, errorslib.New("replay command is nil", errorslib.CategoryInternal).
+			WithTextCode("REPLAY_CMD_NIL")
+	}
+	if c.replayer == nil {
+		return ReplayResult{}, errorslib.New("dead-letter replayer is required", errorslib.CategoryValidation).
+			WithTextCode("REPLAYER_REQUIRED")
+	}
+	if len(ids) == 0 {
+		if c.store == nil {
+			return ReplayResult{}, errorslib.New("dead-letter store is required", errorslib.CategoryValidation).
+				WithTextCode("DEAD_LETTER_STORE_REQUIRED")
+		}
+		letters, err := c.store.List(ctx, c.limit)
+		if err != nil {
+			return ReplayResult{}, err
+		}
+		for _, letter := range letters {
+			ids = append(ids, letter.ID)
+		}
+	}
+
+	var result ReplayResult
+	var errs []error
+	for _, id := range ids {
+		if err := c.replayer.Replay(ctx, id); err != nil {
+			result.Failed++
+			errs = append(errs, err)
+			continue
+		}
+		result.Replayed++
+	}
+	return result, errors.Join(errs...)
+}
+
+// CronHandler replays pending dead letters.
+func (c *ReplayCommand) CronHandler() func() error {
+	return func() error {
+		ctx := context.Background()
+		if c != nil && c.locker != nil {
+			_, acquired, err := export.AcquireRunLease(ctx, c.locker, c.leaseName(), c.leaseWindow, c.now())
+			if err != nil || !acquired {
+				return err
+			}
+		}
+		_, err := c.Replay(ctx, nil)
+		return err
+	}
+}
+
+func (c *ReplayCommand) leaseName() string {
+	if len(c.cliConfig.Path) > 0 {
+		return strings.Join(c.cliConfig.Path, ":")
+	}
+	return "exports-webhooks-replay"
+}
+
+// CronOptions returns cron configuration.
+func (c *ReplayCommand) CronOptions() gcmd.HandlerConfig {
+	if c == nil {
+		return gcmd.HandlerConfig{}
+	}
+	return c.cronConfig
+}
+
+// CLIHandler exposes the CLI handler.
+func (c *ReplayCommand) CLIHandler() any {
+	return &replayCLI{cmd: c}
+}
+
+// CLIOptions returns CLI configuration.
+func (c *ReplayCommand) CLIOptions() gcmd.CLIConfig {
+	if c == nil {
+		return gcmd.CLIConfig{}
+	}
+	return c.cliConfig
+}
+
+type replayCLI struct {
+	cmd *ReplayCommand
+	IDs []string `kong:"name='id',help='Dead letter IDs to replay (default: oldest pending)'"`
+}
+
+func (c *replayCLI) Run() error {
+	if c == nil || c.cmd == nil {
+		return errorslib.New("replay command is required", errorslib.CategoryInternal).
+			WithTextCode("REPLAY_CMD_NIL")
+	}
+	_, err := c.cmd.Replay(context.Background(), c.IDs)
+	return err
+}
@@ -3,12 +3,14 @@ package exportdelivery
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"strings"
 	"time"
 
 	gcmd "github.com/goliatone/go-command"
-	"github.com/goliatone/go-errors"
+	errorslib "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-export/export"
 )
 
 const scheduleModeEnv = "EXPORT_DELIVERY_SCHEDULE_MODE"
@@ -39,7 +41,7 @@ type ScheduleExecutorFunc func(ctx context.Context, req Request) error
 
 func (f ScheduleExecutorFunc) ExecuteDelivery(ctx context.Context, req Request) error {
 	if f == nil {
-		return errors.New("schedule executor is required", errors.CategoryInternal).
+		return errorslib.New("schedule executor is required", errorslib.CategoryInternal).
 			WithTextCode("SCHEDULE_EXECUTOR_NIL")
 	}
 	return f(ctx, req)
@@ -49,11 +51,11 @@ func (f ScheduleExecutorFunc) ExecuteDelivery(ctx context.Context, req Request)
 func NewTaskExecutor(task *Task, builder *MessageBuilder) ScheduleExecutor {
 	return ScheduleExecutorFunc(func(ctx context.Context, req Request) error {
 		if task == nil {
-			return errors.New("schedule task is required", errors.CategoryValidation).
+			return errorslib.New("schedule task is required", errorslib.CategoryValidation).
 				WithTextCode("SCHEDULE_TASK_REQUIRED")
 		}
 		if builder == nil {
-			return errors.New("schedule message builder is required", errors.CategoryValidation).
+			return errorslib.New("schedule message builder is required", errorslib.CategoryValidation).
 				WithTextCode("SCHEDULE_BUILDER_REQUIRED")
 		}
 		msg, err := builder.Build(ctx, req)
@@ -61,14 +63,15 @@ func NewTaskExecutor(task *Task, builder *MessageBuilder) ScheduleExecutor {
 			return err
 		}
 		if msg == nil {
-			return errors.New("schedule execution message is required", errors.CategoryValidation).
+			return errorslib.New("schedule execution message is required", errorslib.CategoryValidation).
 				WithTextCode("EXECUTION_MESSAGE_REQUIRED")
 		}
 		return task.Execute(ctx, msg)
 	})
 }
 
-// ScheduleLimits bounds scheduled delivery execution.
+// ScheduleLimits bounds scheduled delivery execution. MaxRequests does not
+// apply to claimed schedule runs, which cannot be retried once claimed.
 type ScheduleLimits struct {
 	MaxRequests int
 	MinInterval time.Duration
@@ -76,14 +79,18 @@ type ScheduleLimits struct {
 
 // ScheduleCommand wires CLI/Cron execution for scheduled deliveries.
 type ScheduleCommand struct {
-	requester  ScheduleRequester
-	executor   ScheduleExecutor
-	loader     ScheduleLoader
-	cliConfig  gcmd.CLIConfig
-	cronConfig gcmd.HandlerConfig
-	limits     ScheduleLimits
-	mode       ScheduleMode
-	sleep      func(time.Duration)
+	requester   ScheduleRequester
+	executor    ScheduleExecutor
+	loader      ScheduleLoader
+	cliConfig   gcmd.CLIConfig
+	cronConfig  gcmd.HandlerConfig
+	limits      ScheduleLimits
+	mode        ScheduleMode
+	sleep       func(time.Duration)
+	locker      export.Locker
+	leaseWindow time.Duration
+	now         func() time.Time
+	logger      export.Logger
 }
 
 // ScheduleOption customizes scheduled delivery commands.
@@ -124,6 +131,24 @@ func WithScheduleExecutor(executor ScheduleExecutor) ScheduleOption {
 	}
 }
 
+// WithScheduleLease makes cron runs acquire a lease per run window, so
+// deliveries run once across replicas. Set window to the cron interval.
+func WithScheduleLease(locker export.Locker, window time.Duration) ScheduleOption {
+	return func(cmd *ScheduleCommand) {
+		cmd.locker = locker
+		cmd.leaseWindow = window
+	}
+}
+
+// WithScheduleLogger logs deliveries that fail during a run.
+func WithScheduleLogger(logger export.Logger) ScheduleOption {
+	return func(cmd *ScheduleCommand) {
+		if logger != nil {
+			cmd.logger = logger
+		}
+	}
+}
+
 // NewScheduledDeliveriesCommand creates a scheduled delivery CLI/Cron command.
 func NewScheduledDeliveriesCommand(requester ScheduleRequester, loader ScheduleLoader, opts ...ScheduleOption) *ScheduleCommand {
 	cmd := &ScheduleCommand{
@@ -136,6 +161,8 @@ func NewScheduledDeliveriesCommand(requester ScheduleRequester, loader ScheduleL
 		},
 		cronConfig: gcmd.HandlerConfig{Expression: "0 * * * *"},
 		sleep:      time.Sleep,
+		now:        time.Now,
+		logger:     export.NopLogger(),
 	}
 	for _, opt := range opts {
 		if opt != nil {
@@ -148,11 +175,25 @@ func NewScheduledDeliveriesCommand(requester ScheduleRequester, loader ScheduleL
 // CronHandler executes scheduled deliveries.
 func (c *ScheduleCommand) CronHandler() func() error {
 	return func() error {
-		_, err := c.run(context.Background(), "", "")
+		ctx := context.Background()
+		if c != nil && c.locker != nil {
+			_, acquired, err := export.AcquireRunLease(ctx, c.locker, c.leaseName(), c.leaseWindow, c.now())
+			if err != nil || !acquired {
+				return err
+			}
+		}
+		_, err := c.run(ctx, "", "")
 		return err
 	}
 }
 
+func (c *ScheduleCommand) leaseName() string {
+	if len(c.cliConfig.Path) > 0 {
+		return strings.Join(c.cliConfig.Path, ":")
+	}
+	return "exports-deliver"
+}
+
 // CronOptions returns cron configuration.
 func (c *ScheduleCommand) CronOptions() gcmd.HandlerConfig {
 	if c == nil {
@@ -176,7 +217,7 @@ func (c *ScheduleCommand) CLIOptions() gcmd.CLIConfig {
 
 func (c *ScheduleCommand) run(ctx context.Context, from string, modeFlag string) (int, error) {
 	if c == nil {
-		return 0, errors.New("schedule command is nil", errors.CategoryInternal).
+		return 0, errorslib.New("schedule command is nil", errorslib.CategoryInternal).
 			WithTextCode("SCHEDULE_CMD_NIL")
 	}
 	mode, err := c.resolveMode(modeFlag)
@@ -188,40 +229,56 @@ func (c *ScheduleCommand) run(ctx context.Context, from string, modeFlag string)
 	switch mode {
 	case ScheduleModeEnqueue:
 		if c.requester == nil {
-			return 0, errors.New("schedule requester is required", errors.CategoryValidation).
+			return 0, errorslib.New("schedule requester is required", errorslib.CategoryValidation).
 				WithTextCode("REQUESTER_REQUIRED")
 		}
 		execute = c.requester.RequestDelivery
 	case ScheduleModeExecuteSync:
 		if c.executor == nil {
-			return 0, errors.New("schedule executor is required", errors.CategoryValidation).
+			return 0, errorslib.New("schedule executor is required", errorslib.CategoryValidation).
 				WithTextCode("EXECUTOR_REQUIRED")
 		}
 		execute = c.executor.ExecuteDelivery
 	default:
-		return 0, errors.New("schedule mode is invalid", errors.CategoryValidation).
+		return 0, errorslib.New("schedule mode is invalid", errorslib.CategoryValidation).
 			WithTextCode("SCHEDULE_MODE_INVALID")
 	}
 
-	requests, err := c.loadRequests(ctx, from)
-	if err != nil {
-		return 0, err
+	// Loaders may return the runs they claimed together with an error for
+	// the ones they could not load; the claimed runs still execute.
+	requests, loadErr := c.loadRequests(ctx, from)
+	if loadErr != nil && len(requests) == 0 {
+		return 0, loadErr
 	}
 
-	count := 0
+	logger := c.logger
+	if logger == nil {
+		logger = export.NopLogger()
+	}
+	errs := []error{loadErr}
+	count, limited := 0, 0
 	for _, req := range requests {
-		if c.limits.MaxRequests > 0 && count >= c.limits.MaxRequests {
-			break
+		if req.ScheduleID == "" {
+			if c.limits.MaxRequests > 0 && limited >= c.limits.MaxRequests {
+				continue
+			}
+			limited++
 		}
-		if err := execute(ctx, req); err != nil {
-			return count, err
+		if req.Export, err = export.ResolveParams(req.Export, export.ParamContext{}); err == nil {
+			err = execute(ctx, req)
+		}
+		if err != nil {
+			logger.Error("scheduled delivery failed", "error", err,
+				"definition", req.Export.Definition, "actor_id", req.Actor.ID, "schedule_id", req.ScheduleID)
+			errs = append(errs, err)
+			continue
 		}
 		count++
 		if c.limits.MinInterval > 0 && c.sleep != nil {
 			c.sleep(c.limits.MinInterval)
 		}
 	}
-	return count, nil
+	return count, errors.Join(errs...)
 }
 
 func (c *ScheduleCommand) loadRequests(ctx context.Context, from string) ([]Request, error) {
@@ -229,7 +286,7 @@ func (c *ScheduleCommand) loadRequests(ctx context.Context, from string) ([]Requ
 		return loadScheduleRequestsFromFile(from)
 	}
 	if c.loader == nil {
-		return nil, errors.New("schedule loader not configured", errors.CategoryValidation).
+		return nil, errorslib.New("schedule loader not configured", errorslib.CategoryValidation).
 			WithTextCode("LOADER_REQUIRED")
 	}
 	return c.loader(ctx)
@@ -265,7 +322,7 @@ func (c *ScheduleCommand) resolveMode(flagMode string) (ScheduleMode, error) {
 }
 
 func invalidScheduleMode(value string) error {
-	return errors.New("schedule mode is invalid", errors.CategoryValidation).
+	return errorslib.New("schedule mode is invalid", errorslib.CategoryValidation).
 		WithTextCode("SCHEDULE_MODE_INVALID")
 }
 
@@ -304,7 +361,7 @@ type scheduleCLI struct {
 
 func (c *scheduleCLI) Run() error {
 	if c == nil || c.cmd == nil {
-		return errors.New("schedule command is required", errors.CategoryInternal).
+		return errorslib.New("schedule command is required", errorslib.CategoryInternal).
 			WithTextCode("SCHEDULE_CMD_NIL")
 	}
 	_, err := c.cmd.run(context.Background(), c.From, c.Mode)
@@ -314,14 +371,137 @@ func (c *scheduleCLI) Run() error {
 func loadScheduleRequestsFromFile(path string) ([]Request, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
-		return nil, errors.Wrap(err, errors.CategoryExternal, "read schedule file failed").
+		return nil, errorslib.Wrap(err, errorslib.CategoryExternal, "read schedule file failed").
 			WithTextCode("SCHEDULE_FILE_READ")
 	}
 
-	var requests []Request
-	if err := json.Unmarshal(content, &requests); err != nil {
-		return nil, errors.Wrap(err, errors.CategoryValidation, "schedule file invalid JSON").
+	var raw []json.RawMessage
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return nil, errorslib.Wrap(err, errorslib.CategoryValidation, "schedule file invalid JSON").
 			WithTextCode("SCHEDULE_FILE_INVALID")
 	}
+	requests := make([]Request, 0, len(raw))
+	for _, item := range raw {
+		req, err := decodeScheduleRequest(item)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
 	return requests, nil
 }
+
+// NewScheduleLoader returns a ScheduleLoader that claims due delivery
+// schedule runs and decodes their stored requests, so
+// NewScheduledDeliveriesCommand runs them as their owners. ScheduleID is set
+// to the schedule ID for change-aware conditions and delivery history, and
+// Export.ScheduledAt to the run's slot. Runs whose request does not decode or
+// whose parameters do not resolve are skipped and reported in the error
+// returned alongside the other runs.
+func NewScheduleLoader(claimer export.ScheduleClaimer) ScheduleLoader {
+	return func(ctx context.Context) ([]Request, error) {
+		if claimer == nil {
+			return nil, errorslib.New("schedule claimer is required", errorslib.CategoryInternal).
+				WithTextCode("SCHEDULE_CLAIMER_REQUIRED")
+		}
+		runs, err := claimer.ClaimDue(ctx, export.ScheduleKindDelivery, time.Time{})
+		errs := []error{err}
+		requests := make([]Request, 0, len(runs))
+		for _, run := range runs {
+			req, err := scheduledRequest(run)
+			if err != nil {
+				errs = append(errs, export.AsGoError(err).WithMetadata(map[string]any{"schedule_id": run.Schedule.ID}))
+				continue
+			}
+			requests = append(requests, req)
+		}
+		return requests, errors.Join(errs...)
+	}
+}
+
+func scheduledRequest(run export.ScheduleRun) (Request, error) {
+	req, err := decodeScheduleRequest(run.Schedule.Delivery)
+	if err != nil {
+		return Request{}, err
+	}
+	req.Actor = run.Schedule.Owner
+	req.ScheduleID = run.Schedule.ID
+	req.Export.ScheduledAt = run.RunAt
+	req.Export, err = export.ResolveParams(req.Export, export.ParamContext{
+		RunTime:  run.RunAt,
+		Timezone: run.Schedule.Timezone,
+	})
+	if err != nil {
+		return Request{}, err
+	}
+	return req, nil
+}
+
+// decodeScheduleRequest decodes a stored delivery request. Inline webhook
+// secrets are rejected rather than silently dropped, since Secret is not
+// serialized.
+func decodeScheduleRequest(raw json.RawMessage) (Request, error) {
+	var req Request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return Request{}, errorslib.Wrap(err, errorslib.CategoryValidation, "schedule delivery request invalid").
+			WithTextCode("SCHEDULE_REQUEST_INVALID")
+	}
+	var secrets struct {
+		Targets []struct {
+			Webhook struct {
+				Secret string `json:"secret"`
+			} `json:"webhook"`
+		} `json:"targets"`
+	}
+	_ = json.Unmarshal(raw, &secrets)
+	for _, target := range secrets.Targets {
+		if target.Webhook.Secret != "" {
+			return Request{}, errorslib.New("stored webhook targets must use secret_ref", errorslib.CategoryValidation).
+				WithTextCode("SCHEDULE_WEBHOOK_SECRET_INLINE")
+		}
+	}
+	return req, nil
+}
+
+// ValidateSchedule decodes a delivery schedule's request and validates it as
+// the schedule owner will run it. Set it as
+// export.ScheduleServiceConfig.DeliveryValidator so malformed requests are
+// rejected when the schedule is stored rather than on every run. Webhook and
+// SFTP targets send to hosts named in the request and resolve secret
+// references, so only actors the Guard grants schedule admin may store them.
+func (s *Service) ValidateSchedule(ctx context.Context, actor export.Actor, schedule export.Schedule) error {
+	req, err := decodeScheduleRequest(schedule.Delivery)
+	if err != nil {
+		return err
+	}
+	req.Actor = schedule.Owner
+	req.ScheduleID = schedule.ID
+	if err := s.validateRequest(req); err != nil {
+		return err
+	}
+	if hasExternalTargets(req.Targets) {
+		return s.authorizeScheduleAdmin(ctx, actor)
+	}
+	return nil
+}
+
+func (s *Service) authorizeScheduleAdmin(ctx context.Context, actor export.Actor) error {
+	const msg = "webhook and sftp schedules require admin access"
+	admin, ok := s.guard.(export.AdminGuard)
+	if !ok {
+		return export.NewError(export.KindAuthz, msg, nil)
+	}
+	if err := admin.AuthorizeAdmin(ctx, actor, export.AdminSchedules); err != nil {
+		return export.NewError(export.KindAuthz, msg, err)
+	}
+	return nil
+}
+
+func hasExternalTargets(targets []Target) bool {
+	for _, target := range targets {
+		if target.Kind == TargetWebhook || target.Kind == TargetSFTP {
+			return true
+		}
+	}
+	return false
+}
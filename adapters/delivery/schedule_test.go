@@ -2,9 +2,13 @@ package exportdelivery
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	errorslib "github.com/goliatone/go-errors"
 	"github.com/goliatone/go-export/export"
 )
 
@@ -96,3 +100,190 @@ func TestScheduleCommand_ModeConfigOverridesFlag(t *testing.T) {
 		t.Fatalf("expected execute_sync, got %s", mode)
 	}
 }
+
+type captureDeliveryRequests struct {
+	requests []Request
+}
+
+func (c *captureDeliveryRequests) RequestDelivery(ctx context.Context, req Request) error {
+	c.requests = append(c.requests, req)
+	return nil
+}
+
+func TestScheduleLoader_RunsDueStoredSchedules(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	delivery := NewService(Config{EmailSender: &captureEmailSender{}})
+	schedules := export.NewScheduleService(export.ScheduleServiceConfig{
+		Store:             export.NewMemoryScheduleStore(),
+		Now:               func() time.Time { return now },
+		DeliveryValidator: delivery.ValidateSchedule,
+	})
+	owner := export.Actor{ID: "owner-1", Scope: export.Scope{TenantID: "t1"}}
+	schedule, err := schedules.CreateSchedule(ctx, owner, export.Schedule{
+		Kind:     export.ScheduleKindDelivery,
+		Cron:     "*/15 * * * *",
+		Enabled:  true,
+		Delivery: []byte(`{"export":{"Definition":"users","Format":"csv"},"targets":[{"kind":"email","email":{"to":["ops@example.com"]}}],"mode":"link"}`),
+	})
+	if err != nil {
+		t.Fatalf("create schedule: %v", err)
+	}
+
+	requester := &captureDeliveryRequests{}
+	cmd := NewScheduledDeliveriesCommand(requester, NewScheduleLoader(schedules), WithScheduleMode(ScheduleModeEnqueue))
+
+	if count, err := cmd.run(ctx, "", ""); err != nil || count != 0 {
+		t.Fatalf("expected nothing due, got count=%d err=%v", count, err)
+	}
+
+	now = now.Add(20 * time.Minute)
+	count, err := cmd.run(ctx, "", "")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if count != 1 || len(requester.requests) != 1 {
+		t.Fatalf("expected one scheduled delivery, got %d", count)
+	}
+	req := requester.requests[0]
+	if req.ScheduleID != schedule.ID || req.Actor.ID != "owner-1" || req.Export.Definition != "users" || req.Mode != DeliveryLink {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	if want := time.Date(2024, 6, 3, 8, 15, 0, 0, time.UTC); !req.Export.ScheduledAt.Equal(want) {
+		t.Fatalf("expected logical run time %s, got %s", want, req.Export.ScheduledAt)
+	}
+	if len(req.Targets) != 1 || req.Targets[0].Email.To[0] != "ops@example.com" {
+		t.Fatalf("unexpected targets: %+v", req.Targets)
+	}
+
+	if count, _ := cmd.run(ctx, "", ""); count != 0 {
+		t.Fatalf("expected schedule to run once per slot, got %d", count)
+	}
+}
+
+func TestScheduleCommand_RunKeepsClaimedRunsAfterFailure(t *testing.T) {
+	var calls []string
+	executor := ScheduleExecutorFunc(func(ctx context.Context, req Request) error {
+		calls = append(calls, req.ScheduleID)
+		if req.ScheduleID == "sch-1" {
+			return export.NewError(export.KindExternal, "smtp unavailable", nil)
+		}
+		return nil
+	})
+	loader := func(ctx context.Context) ([]Request, error) {
+		return []Request{
+			{ScheduleID: "sch-1", Export: export.ExportRequest{Definition: "users"}},
+			{ScheduleID: "sch-2", Export: export.ExportRequest{Definition: "teams"}},
+		}, nil
+	}
+
+	cmd := NewScheduledDeliveriesCommand(nil, loader, WithScheduleExecutor(executor), WithScheduleLimits(ScheduleLimits{MaxRequests: 1}))
+	count, err := cmd.run(context.Background(), "", "")
+	if err == nil {
+		t.Fatalf("expected the failed delivery to be reported")
+	}
+	if count != 1 || len(calls) != 2 {
+		t.Fatalf("expected both claimed runs attempted, got count=%d calls=%v", count, calls)
+	}
+}
+
+type stubClaimer struct {
+	runs []export.ScheduleRun
+}
+
+func (s stubClaimer) ClaimDue(ctx context.Context, kind export.ScheduleKind, now time.Time) ([]export.ScheduleRun, error) {
+	return s.runs, nil
+}
+
+func TestScheduleLoader_SkipsUndecodableSchedules(t *testing.T) {
+	ctx := context.Background()
+	delivery := NewService(Config{EmailSender: &captureEmailSender{}})
+	schedules := export.NewScheduleService(export.ScheduleServiceConfig{
+		Store:             export.NewMemoryScheduleStore(),
+		DeliveryValidator: delivery.ValidateSchedule,
+	})
+	_, err := schedules.CreateSchedule(ctx, export.Actor{ID: "owner-1"}, export.Schedule{
+		Kind:     export.ScheduleKindDelivery,
+		Cron:     "@hourly",
+		Enabled:  true,
+		Delivery: []byte(`[1]`),
+	})
+	if err == nil {
+		t.Fatalf("expected undecodable delivery request to be rejected")
+	}
+
+	good := `{"export":{"Definition":"users","Format":"csv"},"targets":[{"kind":"email","email":{"to":["ops@example.com"]}}]}`
+	claimer := stubClaimer{runs: []export.ScheduleRun{
+		{Schedule: export.Schedule{ID: "sch-bad", Delivery: []byte(`[1]`)}},
+		{Schedule: export.Schedule{ID: "sch-good", Delivery: []byte(good)}},
+	}}
+	requester := &captureDeliveryRequests{}
+	cmd := NewScheduledDeliveriesCommand(requester, NewScheduleLoader(claimer), WithScheduleMode(ScheduleModeEnqueue))
+	count, err := cmd.run(ctx, "", "")
+	if err == nil {
+		t.Fatalf("expected the bad schedule to be reported")
+	}
+	if count != 1 || len(requester.requests) != 1 || requester.requests[0].ScheduleID != "sch-good" {
+		t.Fatalf("expected the good schedule to run, got count=%d requests=%+v", count, requester.requests)
+	}
+}
+
+type adminRoleGuard struct{}
+
+func (adminRoleGuard) AuthorizeExport(ctx context.Context, actor export.Actor, req export.ExportRequest, def export.ResolvedDefinition) error {
+	return nil
+}
+
+func (adminRoleGuard) AuthorizeDownload(ctx context.Context, actor export.Actor, exportID string) error {
+	return nil
+}
+
+func (adminRoleGuard) AuthorizeAdmin(ctx context.Context, actor export.Actor, resource string) error {
+	if slices.Contains(actor.Roles, "admin") {
+		return nil
+	}
+	return export.NewError(export.KindAuthz, "not an admin", nil)
+}
+
+func TestService_ValidateSchedule_ExternalTargetsRequireAdmin(t *testing.T) {
+	ctx := context.Background()
+	delivery := NewService(Config{WebhookSender: &captureWebhookSender{}, Guard: adminRoleGuard{}})
+	schedules := export.NewScheduleService(export.ScheduleServiceConfig{
+		Store:             export.NewMemoryScheduleStore(),
+		DeliveryValidator: delivery.ValidateSchedule,
+	})
+	schedule := export.Schedule{
+		Kind:     export.ScheduleKindDelivery,
+		Cron:     "@daily",
+		Enabled:  true,
+		Delivery: []byte(`{"export":{"Definition":"users","Format":"csv"},"targets":[{"kind":"webhook","webhook":{"url":"https://attacker.example/hook","secret_ref":"partner-acme"}}]}`),
+	}
+
+	_, err := schedules.CreateSchedule(ctx, export.Actor{ID: "user-1"}, schedule)
+	if mapped := export.AsGoError(err); mapped == nil || mapped.Category != errorslib.CategoryAuthz {
+		t.Fatalf("expected authz error for non-admin webhook schedule, got %v", err)
+	}
+	if _, err := schedules.CreateSchedule(ctx, export.Actor{ID: "admin-1", Roles: []string{"admin"}}, schedule); err != nil {
+		t.Fatalf("expected admin to create webhook schedule: %v", err)
+	}
+}
+
+func TestScheduleRequests_RejectInlineWebhookSecrets(t *testing.T) {
+	raw, err := json.Marshal(Request{Targets: []Target{{Kind: TargetWebhook, Webhook: WebhookTarget{URL: "https://hooks.test", Secret: "whsec_inline"}}}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(raw), "whsec_inline") {
+		t.Fatalf("expected inline secret not serialized, got %s", raw)
+	}
+
+	_, err = decodeScheduleRequest([]byte(`{"targets":[{"kind":"webhook","webhook":{"url":"https://hooks.test","secret":"whsec_inline"}}]}`))
+	if mapped := export.AsGoError(err); mapped == nil || mapped.Category != errorslib.CategoryValidation {
+		t.Fatalf("expected stored inline secret rejected, got %v", err)
+	}
+
+	_, err = encodePayload(Payload{Request: Request{Targets: []Target{{Kind: TargetWebhook, Webhook: WebhookTarget{Secret: "whsec_inline"}}}}})
+	if err == nil {
+		t.Fatalf("expected queued inline secret rejected")
+	}
+}
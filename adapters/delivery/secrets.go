@@ -0,0 +1,35 @@
+package exportdelivery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/goliatone/go-export/export"
+)
+
+// SecretResolver resolves secret references (e.g. "vault:partners/acme/sftp")
+// to their values so credentials never travel in delivery requests.
+type SecretResolver interface {
+	ResolveSecret(ctx context.Context, ref string) ([]byte, error)
+}
+
+// SecretResolverFunc adapts a function to SecretResolver.
+type SecretResolverFunc func(ctx context.Context, ref string) ([]byte, error)
+
+// ResolveSecret calls f.
+func (f SecretResolverFunc) ResolveSecret(ctx context.Context, ref string) ([]byte, error) {
+	return f(ctx, ref)
+}
+
+// StaticSecrets resolves references from a fixed map (test/dev only).
+type StaticSecrets map[string][]byte
+
+// ResolveSecret returns the value stored under ref.
+func (s StaticSecrets) ResolveSecret(ctx context.Context, ref string) ([]byte, error) {
+	_ = ctx
+	value, ok := s[ref]
+	if !ok {
+		return nil, export.NewError(export.KindNotFound, fmt.Sprintf("secret %q not found", ref), nil)
+	}
+	return value, nil
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"path"
 	"strings"
 	"time"
 
@@ -18,6 +19,7 @@ const (
 	DefaultMaxAttachmentSize = 10 * 1024 * 1024
 	DefaultMaxTargets        = 20
 	DefaultMaxRecipients     = 50
+	DefaultDigestMaxAge      = 7 * 24 * time.Hour
 )
 
 // Limits define operational bounds for delivery.
@@ -29,11 +31,22 @@ type Limits struct {
 
 // Config configures delivery service behavior.
 type Config struct {
-	Service        export.Service
-	Store          export.ArtifactStore
-	EmailSender    EmailSender
-	WebhookSender  WebhookSender
-	Logger         export.Logger
+	Service         export.Service
+	Store           export.ArtifactStore
+	EmailSender     EmailSender
+	WebhookSender   WebhookSender
+	DeliveryTracker export.DeliveryTracker
+	Destinations    map[string]Destination
+	SFTPSender      SFTPSender
+	EmailTemplates  *EmailTemplates
+	Digests         DigestStore
+	// DigestMaxAge drops digest entries still queued after this long
+	// (default DefaultDigestMaxAge; negative keeps them until sent).
+	DigestMaxAge time.Duration
+	Logger       export.Logger
+	// Guard authorizes delivery schedules with webhook or SFTP targets; it
+	// must implement export.AdminGuard for such schedules to be accepted.
+	Guard          export.Guard
 	LinkTTL        time.Duration
 	Limits         Limits
 	Notifier       notify.ExportReadyNotifier
@@ -43,17 +56,24 @@ type Config struct {
 
 // Service orchestrates scheduled export generation + delivery.
 type Service struct {
-	service        export.Service
-	store          export.ArtifactStore
-	emailSender    EmailSender
-	webhookSender  WebhookSender
-	logger         export.Logger
-	linkTTL        time.Duration
-	limits         Limits
-	notifier       notify.ExportReadyNotifier
-	notifyFailHard bool
-	linkBuilder    func(exportID string, ref export.ArtifactRef) string
-	now            func() time.Time
+	service         export.Service
+	store           export.ArtifactStore
+	emailSender     EmailSender
+	webhookSender   WebhookSender
+	deliveryTracker export.DeliveryTracker
+	destinations    map[string]Destination
+	sftpSender      SFTPSender
+	emailTemplates  *EmailTemplates
+	digests         DigestStore
+	digestMaxAge    time.Duration
+	logger          export.Logger
+	guard           export.Guard
+	linkTTL         time.Duration
+	limits          Limits
+	notifier        notify.ExportReadyNotifier
+	notifyFailHard  bool
+	linkBuilder     func(exportID string, ref export.ArtifactRef) string
+	now             func() time.Time
 }
 
 // NewService creates a delivery service.
@@ -77,23 +97,36 @@ func NewService(cfg Config) *Service {
 	if limits.MaxAttachmentSize == 0 {
 		limits.MaxAttachmentSize = DefaultMaxAttachmentSize
 	}
+	digestMaxAge := cfg.DigestMaxAge
+	if digestMaxAge == 0 {
+		digestMaxAge = DefaultDigestMaxAge
+	}
 
 	return &Service{
-		service:        cfg.Service,
-		store:          cfg.Store,
-		emailSender:    cfg.EmailSender,
-		webhookSender:  cfg.WebhookSender,
-		logger:         logger,
-		linkTTL:        linkTTL,
-		limits:         limits,
-		notifier:       cfg.Notifier,
-		notifyFailHard: cfg.NotifyFailHard,
-		linkBuilder:    cfg.LinkBuilder,
-		now:            time.Now,
+		service:         cfg.Service,
+		store:           cfg.Store,
+		emailSender:     cfg.EmailSender,
+		webhookSender:   cfg.WebhookSender,
+		deliveryTracker: cfg.DeliveryTracker,
+		destinations:    cfg.Destinations,
+		sftpSender:      cfg.SFTPSender,
+		emailTemplates:  cfg.EmailTemplates,
+		digests:         cfg.Digests,
+		digestMaxAge:    digestMaxAge,
+		logger:          logger,
+		guard:           cfg.Guard,
+		linkTTL:         linkTTL,
+		limits:          limits,
+		notifier:        cfg.Notifier,
+		notifyFailHard:  cfg.NotifyFailHard,
+		linkBuilder:     cfg.LinkBuilder,
+		now:             time.Now,
 	}
 }
 
-// Deliver generates the export and notifies delivery targets.
+// Deliver generates the export and notifies delivery targets. When some
+// targets fail, the returned Result still lists every delivery alongside
+// the joined error.
 func (s *Service) Deliver(ctx context.Context, req Request) (Result, error) {
 	if s == nil {
 		return Result{}, export.NewError(export.KindInternal, "delivery service is nil", nil)
@@ -114,6 +147,7 @@ func (s *Service) Deliver(ctx context.Context, req Request) (Result, error) {
 	}
 	req.Mode = mode
 	notifyRequested := s.shouldNotify(req)
+	messaging := hasMessageTargets(req.Targets)
 
 	exportReq := req.Export
 	exportReq.Delivery = export.DeliveryAsync
@@ -128,21 +162,79 @@ func (s *Service) Deliver(ctx context.Context, req Request) (Result, error) {
 	if err != nil {
 		return Result{}, err
 	}
+	// Definitions can force watermarks, which only shows after generation.
+	if req.Conditions.SkipUnchanged && result.Watermark != nil {
+		return Result{}, errUnchangedWatermark
+	}
 
 	ref, err := s.resolveArtifact(ctx, req, record.ID, result.Artifact)
 	if err != nil {
 		return Result{}, err
 	}
 
+	base := export.DeliveryRecord{
+		ExportID:   record.ID,
+		ScheduleID: req.ScheduleID,
+		Definition: exportReq.Definition,
+		Format:     exportReq.Format,
+		Mode:       string(mode),
+		Actor:      req.Actor,
+		Rows:       result.Rows,
+		Checksum:   result.Checksum,
+	}
+	if base.Checksum == "" {
+		base.Checksum = artifactChecksum(record, ref)
+	}
+	reason, err := s.skipReason(ctx, req, base)
+	if err != nil {
+		return Result{}, err
+	}
+	if reason != "" {
+		return s.skip(ctx, req, base, ref, reason), nil
+	}
+
+	targets := len(req.Targets)
+	queued := 0
+	if req.Digest != "" {
+		queued, err = s.queueDigest(ctx, req, base, ref)
+		if err != nil {
+			return Result{}, err
+		}
+		req.Targets = immediateTargets(req.Targets)
+		notifyRequested = false
+		messaging = hasMessageTargets(req.Targets)
+	}
+
+	var attachment *Attachment
+	var fallback AttachmentFallback
+	if mode == DeliveryAttachment && messaging {
+		attachment, fallback, err = s.prepareAttachment(ctx, req, ref)
+		if err != nil {
+			return Result{}, err
+		}
+		if fallback == AttachmentFallbackLink {
+			mode = DeliveryLink
+			req.Mode = mode
+			base.Mode = string(mode)
+		}
+		if fallback != "" && s.logger != nil {
+			s.logger.Info("attachment exceeds limit, fallback applied",
+				"fallback", fallback,
+				"export_id", record.ID,
+				"limit", s.limits.MaxAttachmentSize,
+			)
+		}
+	}
+
 	link := ""
-	if mode == DeliveryLink || notifyRequested {
+	if (mode == DeliveryLink && messaging) || notifyRequested {
 		if s.linkBuilder != nil {
 			link = strings.TrimSpace(s.linkBuilder(record.ID, ref))
 		}
 		if link == "" {
 			link, err = s.signedURL(ctx, ref, req.LinkTTL)
 			if err != nil {
-				if mode == DeliveryLink || s.notifyFailHard {
+				if (mode == DeliveryLink && messaging) || s.notifyFailHard {
 					return Result{}, err
 				}
 				notifyRequested = false
@@ -159,19 +251,31 @@ func (s *Service) Deliver(ctx context.Context, req Request) (Result, error) {
 		}
 	}
 
-	var attachment *Attachment
-	if mode == DeliveryAttachment {
-		attachment, err = s.loadAttachment(ctx, ref)
-		if err != nil {
-			return Result{}, err
-		}
-	}
-
-	body := buildBody(req, link, attachment)
-	subject := buildSubject(req)
-	if err := s.dispatchTargets(ctx, req, subject, body, link, attachment, record, ref); err != nil {
+	content, err := s.emailContent(req, base, ref, link, attachment, fallback)
+	if err != nil {
 		return Result{}, err
 	}
+	deliveries, err := s.dispatchTargets(ctx, req, base, content, link, attachment, record, ref)
+	delivered := Result{
+		ExportID:   record.ID,
+		Definition: exportReq.Definition,
+		Format:     exportReq.Format,
+		Filename:   ref.Meta.Filename,
+		Mode:       mode,
+		Fallback:   fallback,
+		Link:       link,
+		Attachment: attachment,
+		Targets:    targets,
+		Rows:       base.Rows,
+		Checksum:   base.Checksum,
+		Queued:     queued,
+		Deliveries: deliveries,
+		SentAt:     s.now(),
+	}
+	if err != nil {
+		// Partial failures still report which targets were delivered.
+		return delivered, err
+	}
 	if notifyRequested {
 		if err := s.notify(ctx, req, record, result, ref, link, attachment); err != nil {
 			if s.notifyFailHard {
@@ -189,16 +293,7 @@ func (s *Service) Deliver(ctx context.Context, req Request) (Result, error) {
 		}
 	}
 
-	return Result{
-		ExportID:   record.ID,
-		Definition: exportReq.Definition,
-		Format:     exportReq.Format,
-		Filename:   ref.Meta.Filename,
-		Link:       link,
-		Attachment: attachment,
-		Targets:    len(req.Targets),
-		SentAt:     time.Now(),
-	}, nil
+	return delivered, nil
 }
 
 func (s *Service) validateRequest(req Request) error {
@@ -238,11 +333,48 @@ func (s *Service) validateRequest(req Request) error {
 			if s.webhookSender == nil {
 				return export.NewError(export.KindNotImpl, "webhook sender not configured", nil)
 			}
+		case TargetStore:
+			if err := s.validateStoreTarget(target.Store); err != nil {
+				return err
+			}
+		case TargetSFTP:
+			if err := s.validateSFTPTarget(target.SFTP); err != nil {
+				return err
+			}
 		default:
 			return export.NewError(export.KindValidation, "delivery target kind is invalid", nil)
 		}
 	}
 
+	if strings.TrimSpace(req.Message.Template) != "" && s.emailTemplates == nil {
+		return export.NewError(export.KindNotImpl, "email templates not configured", nil)
+	}
+
+	switch req.AttachmentFallback {
+	case "", AttachmentFallbackFail, AttachmentFallbackZip, AttachmentFallbackLink, AttachmentFallbackZipThenLink:
+	default:
+		return export.NewError(export.KindValidation, "attachment fallback policy is invalid", nil)
+	}
+
+	if req.Conditions.MinRows < 0 {
+		return export.NewError(export.KindValidation, "minimum rows condition is invalid", nil)
+	}
+	if req.Conditions.SkipUnchanged {
+		if strings.TrimSpace(req.ScheduleID) == "" {
+			return export.NewError(export.KindValidation, "schedule ID is required for change-aware delivery", nil)
+		}
+		if s.deliveryTracker == nil {
+			return export.NewError(export.KindNotImpl, "delivery tracker not configured", nil)
+		}
+		if req.Export.RenderOptions.Watermark.Enabled {
+			return errUnchangedWatermark
+		}
+	}
+
+	if strings.TrimSpace(req.Digest) != "" && s.digests == nil {
+		return export.NewError(export.KindNotImpl, "digest store not configured", nil)
+	}
+
 	if hasNotifyRequest(req.Notify) {
 		if len(req.Notify.Recipients) == 0 {
 			return export.NewError(export.KindValidation, "notification recipients are required", nil)
@@ -274,7 +406,7 @@ func (s *Service) signedURL(ctx context.Context, ref export.ArtifactRef, ttl tim
 func (s *Service) loadAttachment(ctx context.Context, ref export.ArtifactRef) (*Attachment, error) {
 	limit := s.limits.MaxAttachmentSize
 	if limit > 0 && ref.Meta.Size > limit {
-		return nil, export.NewError(export.KindValidation, "attachment size exceeds limit", nil)
+		return nil, errAttachmentTooLarge
 	}
 
 	reader, meta, err := s.store.Open(ctx, ref.Key)
@@ -288,14 +420,30 @@ func (s *Service) loadAttachment(ctx context.Context, ref export.ArtifactRef) (*
 		return nil, err
 	}
 
+	checksum := meta.Checksum
+	if checksum == "" {
+		checksum = ref.Meta.Checksum
+	}
 	return &Attachment{
 		Filename:    meta.Filename,
 		ContentType: meta.ContentType,
 		Data:        data,
 		Size:        int64(len(data)),
+		Checksum:    checksum,
+		Key:         ref.Key,
 	}, nil
 }
 
+func artifactChecksum(record export.ExportRecord, ref export.ArtifactRef) string {
+	if ref.Meta.Checksum != "" {
+		return ref.Meta.Checksum
+	}
+	if record.Artifact.Meta.Checksum != "" {
+		return record.Artifact.Meta.Checksum
+	}
+	return record.Checksum
+}
+
 func readWithLimit(r io.Reader, limit int64) ([]byte, error) {
 	if limit <= 0 {
 		return io.ReadAll(r)
@@ -305,7 +453,7 @@ func readWithLimit(r io.Reader, limit int64) ([]byte, error) {
 		return nil, err
 	}
 	if int64(len(buf)) > limit {
-		return nil, export.NewError(export.KindValidation, "attachment size exceeds limit", nil)
+		return nil, errAttachmentTooLarge
 	}
 	return buf, nil
 }
@@ -321,11 +469,14 @@ func buildSubject(req Request) string {
 	return fmt.Sprintf("Export ready: %s", req.Export.Definition)
 }
 
-func buildBody(req Request, link string, attachment *Attachment) string {
+func buildBody(req Request, link string, attachment *Attachment, fallback AttachmentFallback) string {
 	body := strings.TrimSpace(req.Message.Body)
 	if body == "" {
 		body = fmt.Sprintf("Your %s export is ready.", req.Export.Definition)
 	}
+	if note := attachmentFallbackNote(fallback); note != "" {
+		body = strings.TrimSpace(body + "\n\n" + note)
+	}
 	if link != "" {
 		body = strings.TrimSpace(body + "\n\nDownload: " + link)
 	}
@@ -335,30 +486,234 @@ func buildBody(req Request, link string, attachment *Attachment) string {
 	return body
 }
 
-func (s *Service) dispatchTargets(ctx context.Context, req Request, subject, body, link string, attachment *Attachment, record export.ExportRecord, ref export.ArtifactRef) error {
+// emailContent builds the email for req, rendering EmailTemplates when
+// configured and email targets exist. An explicit Message.Subject wins over
+// the subject template.
+func (s *Service) emailContent(req Request, base export.DeliveryRecord, ref export.ArtifactRef, link string, attachment *Attachment, fallback AttachmentFallback) (EmailContent, error) {
+	content := EmailContent{
+		Subject: buildSubject(req),
+		Text:    buildBody(req, link, attachment, fallback),
+	}
+	if s.emailTemplates == nil || !hasEmailTargets(req.Targets) {
+		return content, nil
+	}
+
+	data := EmailTemplateData{
+		Locale:     req.Export.Locale,
+		ExportID:   base.ExportID,
+		Definition: base.Definition,
+		Format:     base.Format,
+		Filename:   ref.Meta.Filename,
+		Link:       link,
+		Rows:       base.Rows,
+		Note:       attachmentFallbackNote(fallback),
+		Message:    strings.TrimSpace(req.Message.Body),
+		Metadata:   req.Metadata,
+	}
+	if link != "" {
+		data.ExpiresAt = deriveExpiresAt(ref.Meta, s.resolveLinkTTL(req.LinkTTL), s.now())
+	}
+	if attachment != nil {
+		data.Attachment = attachment.Filename
+	}
+	rendered, err := s.emailTemplates.Render(req.Message.Template, data)
+	if err != nil {
+		return EmailContent{}, err
+	}
+	if strings.TrimSpace(req.Message.Subject) == "" && rendered.Subject != "" {
+		content.Subject = rendered.Subject
+	}
+	if rendered.Text != "" {
+		content.Text = rendered.Text
+	}
+	content.HTML = rendered.HTML
+	return content, nil
+}
+
+// dispatchTargets sends to every target and records one delivery entry per
+// target. Tracker failures are logged and never fail the delivery.
+func (s *Service) dispatchTargets(ctx context.Context, req Request, base export.DeliveryRecord, content EmailContent, link string, attachment *Attachment, record export.ExportRecord, ref export.ArtifactRef) ([]export.DeliveryRecord, error) {
 	var errs []error
+	deliveries := make([]export.DeliveryRecord, 0, len(req.Targets))
 	for _, target := range req.Targets {
+		delivery := targetDelivery(base, target)
+		delivery.StartedAt = s.now()
+
+		var err error
+		skipped := false
 		switch target.Kind {
 		case TargetEmail:
-			if err := s.sendEmail(ctx, req, target, subject, body, attachment); err != nil {
-				errs = append(errs, err)
-			}
+			delivery.Attempts = 1
+			err = s.sendEmail(ctx, req, target, content, attachment)
 		case TargetWebhook:
-			if err := s.sendWebhook(ctx, req, target, link, attachment, record, ref); err != nil {
-				errs = append(errs, err)
-			}
+			var webhook WebhookResult
+			webhook, err = s.sendWebhook(ctx, req, target, link, attachment, record, ref)
+			delivery.StatusCode = webhook.StatusCode
+			delivery.Attempts = webhook.Attempts
+			delivery.DeadLetterID = webhook.DeadLetterID
+		case TargetStore:
+			var copied storeCopy
+			copied, err = s.copyToStore(ctx, req, target.Store, record, ref)
+			delivery.Target = target.Store.Destination + ":" + copied.path
+			delivery.Attempts = copied.attempts
+			skipped = copied.skipped
+		case TargetSFTP:
+			var uploaded SFTPResult
+			uploaded, err = s.sendSFTP(ctx, req, target.SFTP, record, ref)
+			delivery.Target = sftpURL(target.SFTP, uploaded.Path)
+			delivery.Attempts = uploaded.Attempts
+			skipped = uploaded.Skipped
+		}
+
+		delivery.Status = export.DeliveryStatusSent
+		if skipped {
+			delivery.Status = export.DeliveryStatusSkipped
+			delivery.Reason = SkipReasonExists
 		}
+		if err != nil {
+			errs = append(errs, err)
+			delivery.Status = export.DeliveryStatusFailed
+			delivery.Error = err.Error()
+		}
+		delivery.CompletedAt = s.now()
+		s.recordDelivery(ctx, delivery)
+		deliveries = append(deliveries, delivery)
 	}
 	if len(errs) == 1 {
-		return errs[0]
+		return deliveries, errs[0]
 	}
 	if len(errs) > 1 {
-		return errors.Join(errs...)
+		return deliveries, errors.Join(errs...)
 	}
-	return nil
+	return deliveries, nil
 }
 
-func (s *Service) sendEmail(ctx context.Context, req Request, target Target, subject, body string, attachment *Attachment) error {
+func (s *Service) recordDelivery(ctx context.Context, delivery export.DeliveryRecord) {
+	if s.deliveryTracker == nil {
+		return
+	}
+	if err := s.deliveryTracker.RecordDelivery(ctx, delivery); err != nil && s.logger != nil {
+		s.logger.Error("delivery history record failed",
+			"error", err,
+			"export_id", delivery.ExportID,
+			"target_kind", delivery.TargetKind,
+			"target", delivery.Target,
+		)
+	}
+}
+
+// errUnchangedWatermark rejects SkipUnchanged for watermarked exports: the
+// watermark token differs on every run, so checksums never match.
+var errUnchangedWatermark = export.NewError(export.KindValidation, "change-aware delivery cannot compare watermarked exports", nil)
+
+// skipReason evaluates the request's delivery conditions against the
+// generated export. An empty reason means the run should be sent.
+func (s *Service) skipReason(ctx context.Context, req Request, base export.DeliveryRecord) (string, error) {
+	conditions := req.Conditions
+	if conditions.SkipEmpty && base.Rows == 0 {
+		return SkipReasonEmpty, nil
+	}
+	if conditions.MinRows > 0 && base.Rows < conditions.MinRows {
+		return SkipReasonBelowThreshold, nil
+	}
+	if conditions.SkipUnchanged && base.Checksum != "" {
+		last, err := s.deliveryTracker.ListDeliveries(ctx, export.DeliveryFilter{
+			ScheduleID: req.ScheduleID,
+			Status:     export.DeliveryStatusSent,
+			Scope:      req.Actor.Scope,
+			Limit:      1,
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(last) > 0 && last[0].Checksum == base.Checksum {
+			return SkipReasonUnchanged, nil
+		}
+	}
+	return "", nil
+}
+
+// skip records a held-back run for every target and returns its result.
+func (s *Service) skip(ctx context.Context, req Request, base export.DeliveryRecord, ref export.ArtifactRef, reason string) Result {
+	deliveries := make([]export.DeliveryRecord, 0, len(req.Targets))
+	now := s.now()
+	for _, target := range req.Targets {
+		delivery := targetDelivery(base, target)
+		delivery.Status = export.DeliveryStatusSkipped
+		delivery.Reason = reason
+		delivery.StartedAt = now
+		delivery.CompletedAt = now
+		s.recordDelivery(ctx, delivery)
+		deliveries = append(deliveries, delivery)
+	}
+	if s.logger != nil {
+		s.logger.Info("export delivery skipped",
+			"reason", reason,
+			"export_id", base.ExportID,
+			"schedule_id", req.ScheduleID,
+			"definition", base.Definition,
+			"rows", base.Rows,
+		)
+	}
+	return Result{
+		ExportID:   base.ExportID,
+		Definition: base.Definition,
+		Format:     base.Format,
+		Filename:   ref.Meta.Filename,
+		Targets:    len(req.Targets),
+		Rows:       base.Rows,
+		Checksum:   base.Checksum,
+		Skipped:    true,
+		SkipReason: reason,
+		Deliveries: deliveries,
+	}
+}
+
+// targetDelivery describes target on a copy of base. Store and SFTP targets
+// are refined with the rendered path once the copy runs.
+func targetDelivery(base export.DeliveryRecord, target Target) export.DeliveryRecord {
+	delivery := base
+	delivery.TargetKind = string(target.Kind)
+	switch target.Kind {
+	case TargetEmail:
+		delivery.Recipients = emailRecipients(target.Email)
+		delivery.Target = strings.Join(delivery.Recipients, ",")
+	case TargetWebhook:
+		delivery.Target = target.Webhook.URL
+	case TargetStore:
+		delivery.Target = target.Store.Destination + ":"
+	case TargetSFTP:
+		delivery.Target = sftpURL(target.SFTP, target.SFTP.Dir)
+	}
+	return delivery
+}
+
+func hasEmailTargets(targets []Target) bool {
+	for _, target := range targets {
+		if target.Kind == TargetEmail {
+			return true
+		}
+	}
+	return false
+}
+
+func hasMessageTargets(targets []Target) bool {
+	for _, target := range targets {
+		if target.Kind == TargetEmail || target.Kind == TargetWebhook {
+			return true
+		}
+	}
+	return false
+}
+
+func emailRecipients(target EmailTarget) []string {
+	recipients := make([]string, 0, countRecipients(target))
+	recipients = append(recipients, target.To...)
+	recipients = append(recipients, target.Cc...)
+	return append(recipients, target.Bcc...)
+}
+
+func (s *Service) sendEmail(ctx context.Context, req Request, target Target, content EmailContent, attachment *Attachment) error {
 	if s.emailSender == nil {
 		return export.NewError(export.KindNotImpl, "email sender not configured", nil)
 	}
@@ -367,16 +722,19 @@ func (s *Service) sendEmail(ctx context.Context, req Request, target Target, sub
 		Cc:         target.Email.Cc,
 		Bcc:        target.Email.Bcc,
 		ReplyTo:    target.Email.ReplyTo,
-		Subject:    subject,
-		Body:       body,
+		Subject:    content.Subject,
+		Body:       content.Text,
+		HTML:       content.HTML,
 		Attachment: attachment,
 	}
 	return s.emailSender.Send(ctx, msg)
 }
 
-func (s *Service) sendWebhook(ctx context.Context, req Request, target Target, link string, attachment *Attachment, record export.ExportRecord, ref export.ArtifactRef) error {
+// sendWebhook delivers the webhook payload. Attempt details are only known
+// when the sender implements WebhookReporter.
+func (s *Service) sendWebhook(ctx context.Context, req Request, target Target, link string, attachment *Attachment, record export.ExportRecord, ref export.ArtifactRef) (WebhookResult, error) {
 	if s.webhookSender == nil {
-		return export.NewError(export.KindNotImpl, "webhook sender not configured", nil)
+		return WebhookResult{}, export.NewError(export.KindNotImpl, "webhook sender not configured", nil)
 	}
 
 	payload := WebhookPayload{
@@ -384,25 +742,221 @@ func (s *Service) sendWebhook(ctx context.Context, req Request, target Target, l
 		Definition: req.Export.Definition,
 		Format:     req.Export.Format,
 		Filename:   ref.Meta.Filename,
+		Checksum:   artifactChecksum(record, ref),
 		Mode:       req.Mode,
 		Link:       link,
 		Metadata:   req.Metadata,
 		Actor:      req.Actor,
-		SentAt:     time.Now(),
+		SentAt:     s.now(),
 	}
 	if attachment != nil {
 		payload.Attachment = &WebhookAttachment{
 			Filename:    attachment.Filename,
 			ContentType: attachment.ContentType,
 			Size:        attachment.Size,
+			Checksum:    attachment.Checksum,
 			Data:        base64.StdEncoding.EncodeToString(attachment.Data),
 		}
 	}
 
-	return s.webhookSender.Send(ctx, WebhookMessage{
-		URL:     target.Webhook.URL,
-		Method:  target.Webhook.Method,
-		Headers: target.Webhook.Headers,
-		Payload: payload,
+	msg := WebhookMessage{
+		URL:           target.Webhook.URL,
+		Method:        target.Webhook.Method,
+		Headers:       target.Webhook.Headers,
+		HeaderSecrets: target.Webhook.HeaderSecrets,
+		Secret:        target.Webhook.Secret,
+		SecretRef:     target.Webhook.SecretRef,
+		Payload:       payload,
+	}
+	if attachment != nil {
+		msg.AttachmentKey = attachment.Key
+	}
+	if reporter, ok := s.webhookSender.(WebhookReporter); ok {
+		return reporter.SendWithResult(ctx, msg)
+	}
+	return WebhookResult{}, s.webhookSender.Send(ctx, msg)
+}
+
+func validOverwritePolicy(policy OverwritePolicy) bool {
+	switch policy {
+	case "", OverwriteFail, OverwriteReplace, OverwriteSkip, OverwriteRename:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Service) validatePathTemplate(template string) error {
+	_, err := RenderStorePath(template, StorePathVars{
+		ExportID:   "export",
+		Definition: "definition",
+		Filename:   "export.csv",
+		Time:       s.now(),
+	})
+	return err
+}
+
+func (s *Service) validateStoreTarget(target StoreTarget) error {
+	if strings.TrimSpace(target.Destination) == "" {
+		return export.NewError(export.KindValidation, "store destination is required", nil)
+	}
+	if s.destinations[target.Destination] == nil {
+		return export.NewError(export.KindNotImpl, fmt.Sprintf("store destination %q not configured", target.Destination), nil)
+	}
+	if !validOverwritePolicy(target.Overwrite) {
+		return export.NewError(export.KindValidation, "store overwrite policy is invalid", nil)
+	}
+	return s.validatePathTemplate(target.Path)
+}
+
+func (s *Service) validateSFTPTarget(target SFTPTarget) error {
+	if strings.TrimSpace(target.Host) == "" {
+		return export.NewError(export.KindValidation, "sftp host is required", nil)
+	}
+	if target.Port < 0 || target.Port > 65535 {
+		return export.NewError(export.KindValidation, "sftp port is invalid", nil)
+	}
+	if strings.TrimSpace(target.Username) == "" {
+		return export.NewError(export.KindValidation, "sftp username is required", nil)
+	}
+	if target.PasswordSecret == "" && target.PrivateKeySecret == "" {
+		return export.NewError(export.KindValidation, "sftp credentials are required", nil)
+	}
+	if strings.TrimSpace(target.HostKey) == "" {
+		return export.NewError(export.KindValidation, "sftp host key is required", nil)
+	}
+	if !validOverwritePolicy(target.Overwrite) {
+		return export.NewError(export.KindValidation, "sftp overwrite policy is invalid", nil)
+	}
+	if err := s.validatePathTemplate(target.Path); err != nil {
+		return err
+	}
+	if s.sftpSender == nil {
+		return export.NewError(export.KindNotImpl, "sftp sender not configured", nil)
+	}
+	return nil
+}
+
+type storeCopy struct {
+	path     string
+	attempts int
+	skipped  bool
+}
+
+// copyToStore streams the artifact into the target destination, applying the
+// target's overwrite policy when the rendered path already exists.
+func (s *Service) copyToStore(ctx context.Context, req Request, target StoreTarget, record export.ExportRecord, ref export.ArtifactRef) (storeCopy, error) {
+	dest := s.destinations[target.Destination]
+	if dest == nil {
+		return storeCopy{}, export.NewError(export.KindNotImpl, fmt.Sprintf("store destination %q not configured", target.Destination), nil)
+	}
+	key, err := s.renderPath(target.Path, req, record, ref)
+	if err != nil {
+		return storeCopy{}, err
+	}
+
+	policy := target.Overwrite
+	if policy == "" {
+		policy = OverwriteFail
+	}
+	candidate := key
+	for attempt := 1; ; attempt++ {
+		written, err := s.writeDestination(ctx, dest, candidate, ref, policy == OverwriteReplace)
+		if err != nil {
+			return storeCopy{path: candidate, attempts: attempt}, err
+		}
+		if written {
+			return storeCopy{path: candidate, attempts: attempt}, nil
+		}
+		switch policy {
+		case OverwriteSkip:
+			return storeCopy{path: candidate, attempts: attempt, skipped: true}, nil
+		case OverwriteRename:
+			if attempt >= maxRenameAttempts {
+				return storeCopy{path: key, attempts: attempt}, export.NewError(export.KindValidation, "store destination path exists", nil)
+			}
+			candidate = renamedPath(key, attempt)
+		default:
+			return storeCopy{path: candidate, attempts: attempt}, export.NewError(export.KindValidation, "store destination path exists", nil)
+		}
+	}
+}
+
+func (s *Service) writeDestination(ctx context.Context, dest Destination, key string, ref export.ArtifactRef, overwrite bool) (bool, error) {
+	reader, meta, err := s.store.Open(ctx, ref.Key)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+	if meta.Filename == "" {
+		meta.Filename = ref.Meta.Filename
+	}
+	if meta.ContentType == "" {
+		meta.ContentType = ref.Meta.ContentType
+	}
+	if meta.TenantID == "" {
+		meta.TenantID = ref.Meta.TenantID
+	}
+	return dest.Write(ctx, key, reader, meta, overwrite)
+}
+
+// sendSFTP uploads the artifact; retries resume via ranged reads of the source.
+func (s *Service) sendSFTP(ctx context.Context, req Request, target SFTPTarget, record export.ExportRecord, ref export.ArtifactRef) (SFTPResult, error) {
+	if s.sftpSender == nil {
+		return SFTPResult{}, export.NewError(export.KindNotImpl, "sftp sender not configured", nil)
+	}
+	rel, err := s.renderPath(target.Path, req, record, ref)
+	if err != nil {
+		return SFTPResult{}, err
+	}
+	remote := rel
+	if dir := strings.TrimSpace(target.Dir); dir != "" {
+		remote = path.Join(dir, rel)
+	}
+	return s.sftpSender.Upload(ctx, SFTPMessage{
+		ID:     record.ID,
+		Target: target,
+		Path:   remote,
+		Size:   ref.Meta.Size,
+		Open: func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+			return s.openArtifactAt(ctx, ref.Key, offset)
+		},
+	})
+}
+
+func (s *Service) renderPath(template string, req Request, record export.ExportRecord, ref export.ArtifactRef) (string, error) {
+	filename := ref.Meta.Filename
+	if filename == "" {
+		filename = path.Base(ref.Key)
+	}
+	return RenderStorePath(template, StorePathVars{
+		ExportID:   record.ID,
+		Definition: req.Export.Definition,
+		Format:     req.Export.Format,
+		Filename:   filename,
+		TenantID:   req.Actor.Scope.TenantID,
+		Time:       s.now(),
 	})
 }
+
+// openArtifactAt opens the artifact at offset, using ranged reads when the
+// store supports them.
+func (s *Service) openArtifactAt(ctx context.Context, key string, offset int64) (io.ReadCloser, error) {
+	if offset > 0 {
+		if ranged, ok := s.store.(export.RangeOpener); ok {
+			reader, _, err := ranged.OpenRange(ctx, key, offset, -1)
+			return reader, err
+		}
+	}
+	reader, _, err := s.store.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		if _, err := io.CopyN(io.Discard, reader, offset); err != nil {
+			_ = reader.Close()
+			return nil, err
+		}
+	}
+	return reader, nil
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -59,6 +61,42 @@ func (s *stubExportService) Cleanup(ctx context.Context, now time.Time) (int, er
 	return 0, nil
 }
 
+func (s *stubExportService) ApproveExport(ctx context.Context, actor export.Actor, exportID, comment string) (export.ExportRecord, error) {
+	return export.ExportRecord{}, nil
+}
+
+func (s *stubExportService) RejectExport(ctx context.Context, actor export.Actor, exportID, comment string) (export.ExportRecord, error) {
+	return export.ExportRecord{}, nil
+}
+
+func (s *stubExportService) RecordDownload(ctx context.Context, actor export.Actor, download export.DownloadRecord) error {
+	return nil
+}
+
+func (s *stubExportService) Downloads(ctx context.Context, actor export.Actor, exportID string) ([]export.DownloadRecord, error) {
+	return nil, nil
+}
+
+func (s *stubExportService) Deliveries(ctx context.Context, actor export.Actor, exportID string) ([]export.DeliveryRecord, error) {
+	return nil, nil
+}
+
+func (s *stubExportService) DeliveryHistory(ctx context.Context, actor export.Actor, filter export.DeliveryFilter) ([]export.DeliveryRecord, error) {
+	return nil, nil
+}
+
+func (s *stubExportService) VerifyExport(ctx context.Context, actor export.Actor, exportID string) (export.ChecksumVerification, error) {
+	return export.ChecksumVerification{}, nil
+}
+
+func (s *stubExportService) VerifyManifest(ctx context.Context, actor export.Actor, exportID string) (export.ManifestVerification, error) {
+	return export.ManifestVerification{}, nil
+}
+
+func (s *stubExportService) LookupWatermark(ctx context.Context, actor export.Actor, token string) (export.ExportRecord, error) {
+	return export.ExportRecord{}, nil
+}
+
 type stubStore struct {
 	objects   map[string][]byte
 	meta      export.ArtifactMeta
@@ -130,6 +168,7 @@ func TestService_Deliver_Link(t *testing.T) {
 			Filename:    "report.pdf",
 			ContentType: "application/pdf",
 			Size:        3,
+			Checksum:    "abc123",
 		},
 		signedURL: "https://download.test/exp-1.pdf",
 	}
@@ -147,6 +186,8 @@ func TestService_Deliver_Link(t *testing.T) {
 	email := &captureEmailSender{}
 	webhook := &captureWebhookSender{}
 	delivery := NewService(Config{Service: svc, Store: store, EmailSender: email, WebhookSender: webhook})
+	sentAt := time.Date(2024, 3, 7, 9, 30, 0, 0, time.UTC)
+	delivery.now = func() time.Time { return sentAt }
 
 	req := Request{
 		Actor: export.Actor{ID: "actor-1"},
@@ -184,9 +225,111 @@ func TestService_Deliver_Link(t *testing.T) {
 	if payload.Link == "" {
 		t.Fatalf("expected webhook link")
 	}
+	if payload.Checksum != "abc123" {
+		t.Fatalf("expected webhook checksum, got %q", payload.Checksum)
+	}
 	if payload.Attachment != nil {
 		t.Fatalf("expected no webhook attachment")
 	}
+	if !result.SentAt.Equal(sentAt) || !payload.SentAt.Equal(sentAt) {
+		t.Fatalf("expected service clock for sent_at, got result=%v payload=%v", result.SentAt, payload.SentAt)
+	}
+}
+
+func TestService_Deliver_RecordsDeliveryHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	store := &stubStore{
+		meta:      export.ArtifactMeta{Filename: "report.csv"},
+		signedURL: "https://download.test/exp-3.csv",
+	}
+	svc := &stubExportService{
+		request: func(ctx context.Context, actor export.Actor, req export.ExportRequest) (export.ExportRecord, error) {
+			return export.ExportRecord{ID: "exp-3"}, nil
+		},
+		generate: func(ctx context.Context, actor export.Actor, exportID string, req export.ExportRequest) (export.ExportResult, error) {
+			ref := export.ArtifactRef{Key: "exports/exp-3.csv", Meta: store.meta}
+			return export.ExportResult{ID: exportID, Format: req.Format, Artifact: &ref}, nil
+		},
+	}
+
+	tracker := export.NewMemoryDeliveryTracker()
+	deadLetters := NewMemoryDeadLetterStore()
+	webhook := &HTTPWebhookSender{
+		Retry:       RetryPolicy{MaxAttempts: 2},
+		DeadLetters: deadLetters,
+		Sleep:       func(ctx context.Context, d time.Duration) error { return nil },
+	}
+	delivery := NewService(Config{
+		Service:         svc,
+		Store:           store,
+		EmailSender:     &captureEmailSender{},
+		WebhookSender:   webhook,
+		DeliveryTracker: tracker,
+	})
+
+	actor := export.Actor{ID: "actor-1", Scope: export.Scope{TenantID: "tenant-1"}}
+	result, err := delivery.Deliver(context.Background(), Request{
+		Actor:  actor,
+		Export: export.ExportRequest{Definition: "users", Format: export.FormatCSV},
+		Targets: []Target{
+			{Kind: TargetEmail, Email: EmailTarget{To: []string{"partner@example.com"}, Cc: []string{"ops@example.com"}}},
+			{Kind: TargetWebhook, Webhook: WebhookTarget{URL: server.URL}},
+		},
+	})
+	if err == nil {
+		t.Fatalf("expected webhook failure")
+	}
+	if result.ExportID != "exp-3" || len(result.Deliveries) != 2 {
+		t.Fatalf("expected partial result alongside the error, got %+v", result)
+	}
+	if result.Deliveries[0].Status != export.DeliveryStatusSent || result.Deliveries[1].Status != export.DeliveryStatusFailed {
+		t.Fatalf("expected email sent and webhook failed, got %+v", result.Deliveries)
+	}
+
+	records, err := tracker.ListDeliveries(context.Background(), export.DeliveryFilter{ExportID: "exp-3"})
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", len(records))
+	}
+
+	hook := records[0]
+	if hook.TargetKind != string(TargetWebhook) || hook.Target != server.URL {
+		t.Fatalf("expected webhook delivery first, got %+v", hook)
+	}
+	if hook.Status != export.DeliveryStatusFailed || hook.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected failed 502 delivery, got %+v", hook)
+	}
+	if hook.Attempts != 2 || hook.DeadLetterID == "" || hook.Error == "" {
+		t.Fatalf("expected retries and dead letter, got %+v", hook)
+	}
+	if _, err := deadLetters.Get(context.Background(), hook.DeadLetterID); err != nil {
+		t.Fatalf("expected dead letter: %v", err)
+	}
+
+	sent, err := tracker.ListDeliveries(context.Background(), export.DeliveryFilter{
+		Target: "partner@example.com",
+		Scope:  export.Scope{TenantID: "tenant-1"},
+	})
+	if err != nil {
+		t.Fatalf("list by recipient: %v", err)
+	}
+	if len(sent) != 1 || sent[0].Status != export.DeliveryStatusSent {
+		t.Fatalf("expected sent email delivery, got %+v", sent)
+	}
+	if sent[0].Target != "partner@example.com,ops@example.com" || sent[0].Definition != "users" {
+		t.Fatalf("unexpected email delivery: %+v", sent[0])
+	}
+
+	other, _ := tracker.ListDeliveries(context.Background(), export.DeliveryFilter{Scope: export.Scope{TenantID: "tenant-2"}})
+	if len(other) != 0 {
+		t.Fatalf("expected tenant-scoped history, got %d", len(other))
+	}
 }
 
 func TestService_Deliver_Attachment(t *testing.T) {
@@ -249,3 +392,107 @@ func TestService_Deliver_Attachment(t *testing.T) {
 		t.Fatalf("expected webhook attachment")
 	}
 }
+
+func TestService_Deliver_Conditions(t *testing.T) {
+	rows := int64(0)
+	checksum := "sum-1"
+	var watermark *export.Watermark
+	store := &stubStore{meta: export.ArtifactMeta{Filename: "report.csv"}, signedURL: "https://download.test/report.csv"}
+	svc := &stubExportService{
+		request: func(ctx context.Context, actor export.Actor, req export.ExportRequest) (export.ExportRecord, error) {
+			return export.ExportRecord{ID: "exp-" + checksum}, nil
+		},
+		generate: func(ctx context.Context, actor export.Actor, exportID string, req export.ExportRequest) (export.ExportResult, error) {
+			ref := export.ArtifactRef{Key: "exports/" + exportID + ".csv", Meta: store.meta}
+			return export.ExportResult{ID: exportID, Format: req.Format, Rows: rows, Checksum: checksum, Artifact: &ref, Watermark: watermark}, nil
+		},
+	}
+	email := &captureEmailSender{}
+	tracker := export.NewMemoryDeliveryTracker()
+	delivery := NewService(Config{Service: svc, Store: store, EmailSender: email, DeliveryTracker: tracker})
+
+	deliver := func(conditions DeliveryConditions) Result {
+		t.Helper()
+		result, err := delivery.Deliver(context.Background(), Request{
+			ScheduleID: "nightly-orders",
+			Conditions: conditions,
+			Actor:      export.Actor{ID: "actor-1"},
+			Export:     export.ExportRequest{Definition: "orders", Format: export.FormatCSV},
+			Targets:    []Target{{Kind: TargetEmail, Email: EmailTarget{To: []string{"partner@example.com"}}}},
+		})
+		if err != nil {
+			t.Fatalf("deliver: %v", err)
+		}
+		return result
+	}
+
+	result := deliver(DeliveryConditions{SkipEmpty: true})
+	if !result.Skipped || result.SkipReason != SkipReasonEmpty || len(email.messages) != 0 {
+		t.Fatalf("expected empty export to be skipped, got %+v", result)
+	}
+
+	rows = 5
+	result = deliver(DeliveryConditions{MinRows: 10})
+	if !result.Skipped || result.SkipReason != SkipReasonBelowThreshold {
+		t.Fatalf("expected below-threshold skip, got %+v", result)
+	}
+
+	result = deliver(DeliveryConditions{SkipEmpty: true, SkipUnchanged: true})
+	if result.Skipped || len(email.messages) != 1 {
+		t.Fatalf("expected first change-aware run to send, got %+v", result)
+	}
+	result = deliver(DeliveryConditions{SkipUnchanged: true})
+	if !result.Skipped || result.SkipReason != SkipReasonUnchanged || len(email.messages) != 1 {
+		t.Fatalf("expected unchanged export to be skipped, got %+v", result)
+	}
+	checksum = "sum-2"
+	result = deliver(DeliveryConditions{SkipUnchanged: true})
+	if result.Skipped || len(email.messages) != 2 {
+		t.Fatalf("expected changed export to send, got %+v", result)
+	}
+
+	skipped, err := tracker.ListDeliveries(context.Background(), export.DeliveryFilter{
+		ScheduleID: "nightly-orders",
+		Status:     export.DeliveryStatusSkipped,
+	})
+	if err != nil {
+		t.Fatalf("list skipped: %v", err)
+	}
+	if len(skipped) != 3 {
+		t.Fatalf("expected 3 skipped runs, got %d", len(skipped))
+	}
+	if skipped[0].Reason != SkipReasonUnchanged || skipped[0].Target != "partner@example.com" || skipped[0].Checksum != "sum-1" {
+		t.Fatalf("unexpected skipped record: %+v", skipped[0])
+	}
+
+	if _, err := delivery.Deliver(context.Background(), Request{
+		Conditions: DeliveryConditions{SkipUnchanged: true},
+		Actor:      export.Actor{ID: "actor-1"},
+		Export:     export.ExportRequest{Definition: "orders", Format: export.FormatCSV},
+		Targets:    []Target{{Kind: TargetEmail, Email: EmailTarget{To: []string{"partner@example.com"}}}},
+	}); export.KindFromError(err) != export.KindValidation {
+		t.Fatalf("expected schedule ID validation error, got %v", err)
+	}
+
+	watermarked := Request{
+		ScheduleID: "nightly-orders",
+		Conditions: DeliveryConditions{SkipUnchanged: true},
+		Actor:      export.Actor{ID: "actor-1"},
+		Export: export.ExportRequest{Definition: "orders", Format: export.FormatCSV, RenderOptions: export.RenderOptions{
+			Watermark: export.WatermarkOptions{Enabled: true},
+		}},
+		Targets: []Target{{Kind: TargetEmail, Email: EmailTarget{To: []string{"partner@example.com"}}}},
+	}
+	if _, err := delivery.Deliver(context.Background(), watermarked); export.KindFromError(err) != export.KindValidation {
+		t.Fatalf("expected watermark validation error, got %v", err)
+	}
+	// A definition policy can watermark without the request asking for it.
+	watermarked.Export.RenderOptions = export.RenderOptions{}
+	watermark = &export.Watermark{Token: "wm_1"}
+	if _, err := delivery.Deliver(context.Background(), watermarked); export.KindFromError(err) != export.KindValidation {
+		t.Fatalf("expected watermarked result to be rejected, got %v", err)
+	}
+	if len(email.messages) != 2 {
+		t.Fatalf("expected no email for watermarked change-aware run, got %d", len(email.messages))
+	}
+}
@@ -0,0 +1,383 @@
+package exportdelivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultSFTPPort is used when SFTPTarget.Port is zero.
+const DefaultSFTPPort = 22
+
+// DefaultSFTPTimeout bounds the TCP dial and SSH handshake.
+const DefaultSFTPTimeout = 30 * time.Second
+
+// SFTPSender uploads artifacts to SFTP targets.
+type SFTPSender interface {
+	Upload(ctx context.Context, msg SFTPMessage) (SFTPResult, error)
+}
+
+// SFTPMessage describes a single upload. Open must return the artifact content
+// starting at offset so interrupted uploads can resume.
+type SFTPMessage struct {
+	ID     string
+	Target SFTPTarget
+	Path   string
+	Size   int64
+	Open   func(ctx context.Context, offset int64) (io.ReadCloser, error)
+}
+
+// SFTPResult describes the outcome of an upload. Path is the final remote
+// path; ResumedAt is the offset the last attempt resumed from.
+type SFTPResult struct {
+	Path      string
+	Attempts  int
+	Bytes     int64
+	ResumedAt int64
+	Skipped   bool
+}
+
+// SFTPUploader uploads over SSH. Host keys are always pinned via
+// SFTPTarget.HostKey; credentials are resolved through Secrets. Files are
+// written to a hidden "<name>.<id>.partial" file and renamed into place;
+// retries resume from the size of the partial file.
+type SFTPUploader struct {
+	Secrets SecretResolver
+	Retry   RetryPolicy
+	Timeout time.Duration
+	Dial    func(ctx context.Context, network, addr string) (net.Conn, error)
+	Logger  export.Logger
+	Sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// Upload copies the artifact to the remote path, applying the target's
+// overwrite policy and retrying transient failures.
+func (u *SFTPUploader) Upload(ctx context.Context, msg SFTPMessage) (SFTPResult, error) {
+	if u == nil {
+		return SFTPResult{}, export.NewError(export.KindInternal, "sftp uploader is nil", nil)
+	}
+	if msg.Open == nil {
+		return SFTPResult{}, export.NewError(export.KindValidation, "sftp content is required", nil)
+	}
+	config, err := u.clientConfig(ctx, msg.Target)
+	if err != nil {
+		return SFTPResult{}, err
+	}
+
+	policy := u.Retry.withDefaults()
+	result := SFTPResult{Path: msg.Path}
+	for {
+		result.Attempts++
+		err := u.attempt(ctx, config, msg, &result)
+		if err == nil {
+			return result, nil
+		}
+		if !sftpRetryable(ctx, err) || result.Attempts >= policy.MaxAttempts {
+			return result, err
+		}
+		if u.Logger != nil {
+			u.Logger.Warn("sftp upload failed, retrying",
+				"error", err,
+				"host", msg.Target.Host,
+				"path", msg.Path,
+				"attempt", result.Attempts,
+			)
+		}
+		if sleepErr := u.sleep(ctx, backoffDelay(policy, result.Attempts)); sleepErr != nil {
+			return result, errors.Join(err, sleepErr)
+		}
+	}
+}
+
+func (u *SFTPUploader) attempt(ctx context.Context, config *ssh.ClientConfig, msg SFTPMessage, result *SFTPResult) error {
+	client, closeFn, err := u.connect(ctx, config, msg.Target)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	final, skip, err := resolveSFTPPath(client, msg.Path, msg.Target.Overwrite)
+	if err != nil || skip {
+		result.Path = final
+		result.Skipped = skip
+		return err
+	}
+	result.Path = final
+
+	dir, name := path.Split(final)
+	if dir != "" {
+		if err := client.MkdirAll(path.Clean(dir)); err != nil {
+			return export.NewError(export.KindExternal, "sftp mkdir failed", err)
+		}
+	}
+	partial := dir + "." + name + "." + sftpPartialID(msg.ID) + ".partial"
+
+	offset := int64(0)
+	if info, err := client.Stat(partial); err == nil {
+		offset = info.Size()
+		if msg.Size > 0 && offset > msg.Size {
+			offset = 0
+		}
+	}
+	written, err := uploadPartial(ctx, client, partial, offset, msg)
+	result.Bytes += written
+	result.ResumedAt = offset
+	if err != nil {
+		return err
+	}
+
+	if msg.Target.Overwrite == OverwriteReplace {
+		if _, ok := client.HasExtension("posix-rename@openssh.com"); ok {
+			err = client.PosixRename(partial, final)
+		} else {
+			_ = client.Remove(final)
+			err = client.Rename(partial, final)
+		}
+	} else {
+		err = client.Rename(partial, final)
+	}
+	if err != nil {
+		return export.NewError(export.KindExternal, "sftp rename failed", err)
+	}
+	return nil
+}
+
+func uploadPartial(ctx context.Context, client *sftp.Client, partial string, offset int64, msg SFTPMessage) (int64, error) {
+	flags := os.O_WRONLY | os.O_CREATE
+	if offset == 0 {
+		flags |= os.O_TRUNC
+	}
+	file, err := client.OpenFile(partial, flags)
+	if err != nil {
+		return 0, export.NewError(export.KindExternal, "sftp open failed", err)
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, export.NewError(export.KindExternal, "sftp seek failed", err)
+	}
+
+	reader, err := msg.Open(ctx, offset)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	// Wrap the file so io.Copy issues sequential writes; a failed attempt then
+	// leaves a contiguous prefix that the next attempt can resume from.
+	written, err := io.Copy(struct{ io.Writer }{file}, reader)
+	if err != nil {
+		return written, err
+	}
+	if err := file.Close(); err != nil {
+		return written, export.NewError(export.KindExternal, "sftp close failed", err)
+	}
+	if msg.Size > 0 && offset+written != msg.Size {
+		return written, export.NewError(export.KindExternal,
+			fmt.Sprintf("sftp upload incomplete: %d of %d bytes", offset+written, msg.Size), nil)
+	}
+	return written, nil
+}
+
+// resolveSFTPPath applies the overwrite policy against the remote tree.
+func resolveSFTPPath(client *sftp.Client, target string, policy OverwritePolicy) (string, bool, error) {
+	if policy == OverwriteReplace {
+		return target, false, nil
+	}
+	candidate := target
+	for attempt := 1; ; attempt++ {
+		_, err := client.Stat(candidate)
+		if errors.Is(err, os.ErrNotExist) {
+			return candidate, false, nil
+		}
+		if err != nil {
+			return candidate, false, export.NewError(export.KindExternal, "sftp stat failed", err)
+		}
+		switch policy {
+		case OverwriteSkip:
+			return candidate, true, nil
+		case OverwriteRename:
+			if attempt >= maxRenameAttempts {
+				return target, false, export.NewError(export.KindValidation, "sftp destination path exists", nil)
+			}
+			candidate = renamedPath(target, attempt)
+		default:
+			return candidate, false, export.NewError(export.KindValidation, "sftp destination path exists", nil)
+		}
+	}
+}
+
+func (u *SFTPUploader) connect(ctx context.Context, config *ssh.ClientConfig, target SFTPTarget) (*sftp.Client, func(), error) {
+	addr := net.JoinHostPort(target.Host, strconv.Itoa(sftpPort(target)))
+	dialCtx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	dial := u.Dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	conn, err := dial(dialCtx, "tcp", addr)
+	if err != nil {
+		return nil, nil, export.NewError(export.KindExternal, "sftp dial failed", err)
+	}
+	if deadline, ok := dialCtx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		if export.KindFromError(err) == export.KindAuthz {
+			return nil, nil, err
+		}
+		if strings.Contains(err.Error(), "unable to authenticate") {
+			return nil, nil, export.NewError(export.KindAuthz, "sftp authentication failed", err)
+		}
+		return nil, nil, export.NewError(export.KindExternal, "sftp handshake failed", err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		_ = sshClient.Close()
+		return nil, nil, export.NewError(export.KindExternal, "sftp session failed", err)
+	}
+	// Abort in-flight I/O when the context is canceled.
+	stop := context.AfterFunc(ctx, func() { _ = sshClient.Close() })
+	return client, func() {
+		stop()
+		_ = client.Close()
+		_ = sshClient.Close()
+	}, nil
+}
+
+func (u *SFTPUploader) clientConfig(ctx context.Context, target SFTPTarget) (*ssh.ClientConfig, error) {
+	hostKey, err := pinnedHostKey(target.HostKey)
+	if err != nil {
+		return nil, err
+	}
+	if u.Secrets == nil {
+		return nil, export.NewError(export.KindNotImpl, "sftp secret resolver not configured", nil)
+	}
+
+	var auth []ssh.AuthMethod
+	if target.PrivateKeySecret != "" {
+		pem, err := u.Secrets.ResolveSecret(ctx, target.PrivateKeySecret)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ssh.ParsePrivateKey(pem)
+		if err != nil {
+			return nil, export.NewError(export.KindValidation, "sftp private key invalid", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if target.PasswordSecret != "" {
+		password, err := u.Secrets.ResolveSecret(ctx, target.PasswordSecret)
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, ssh.Password(string(password)))
+	}
+	if len(auth) == 0 {
+		return nil, export.NewError(export.KindValidation, "sftp credentials are required", nil)
+	}
+
+	timeout := u.Timeout
+	if timeout <= 0 {
+		timeout = DefaultSFTPTimeout
+	}
+	return &ssh.ClientConfig{
+		User:            target.Username,
+		Auth:            auth,
+		HostKeyCallback: hostKey,
+		Timeout:         timeout,
+	}, nil
+}
+
+// pinnedHostKey accepts an authorized_keys line ("ssh-ed25519 AAAA...") or a
+// "SHA256:..." fingerprint.
+func pinnedHostKey(pin string) (ssh.HostKeyCallback, error) {
+	pin = strings.TrimSpace(pin)
+	if pin == "" {
+		return nil, export.NewError(export.KindValidation, "sftp host key is required", nil)
+	}
+	if strings.HasPrefix(pin, "SHA256:") {
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if subtle.ConstantTimeCompare([]byte(ssh.FingerprintSHA256(key)), []byte(pin)) != 1 {
+				return errHostKeyMismatch(hostname)
+			}
+			return nil
+		}, nil
+	}
+	expected, _, _, _, err := ssh.ParseAuthorizedKey([]byte(pin))
+	if err != nil {
+		return nil, export.NewError(export.KindValidation, "sftp host key invalid", err)
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if !bytes.Equal(key.Marshal(), expected.Marshal()) {
+			return errHostKeyMismatch(hostname)
+		}
+		return nil
+	}, nil
+}
+
+func errHostKeyMismatch(hostname string) error {
+	return export.NewError(export.KindAuthz, fmt.Sprintf("sftp host key mismatch for %s", hostname), nil)
+}
+
+// sftpRetryable retries everything except configuration, authorization, and
+// overwrite-policy failures.
+func sftpRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch export.KindFromError(err) {
+	case export.KindValidation, export.KindAuthz, export.KindNotImpl, export.KindNotFound, export.KindCanceled:
+		return false
+	default:
+		return true
+	}
+}
+
+func (u *SFTPUploader) sleep(ctx context.Context, d time.Duration) error {
+	if u.Sleep != nil {
+		return u.Sleep(ctx, d)
+	}
+	return sleepContext(ctx, d)
+}
+
+func sftpPort(target SFTPTarget) int {
+	if target.Port > 0 {
+		return target.Port
+	}
+	return DefaultSFTPPort
+}
+
+func sftpPartialID(id string) string {
+	if id == "" {
+		return "upload"
+	}
+	return strings.NewReplacer("/", "_", "\\", "_").Replace(id)
+}
+
+// sftpURL formats a target for delivery history.
+func sftpURL(target SFTPTarget, remotePath string) string {
+	host := net.JoinHostPort(target.Host, strconv.Itoa(sftpPort(target)))
+	if !strings.HasPrefix(remotePath, "/") {
+		remotePath = "/" + remotePath
+	}
+	return "sftp://" + target.Username + "@" + host + remotePath
+}
@@ -0,0 +1,290 @@
+package exportdelivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+type testSFTPServer struct {
+	root    string
+	port    int
+	hostKey string
+}
+
+// startSFTPServer runs an in-process SSH server exposing the SFTP subsystem
+// over a temp directory. It accepts user "partner" with password "secret" or
+// the given client key.
+func startSFTPServer(t *testing.T, clientKey ssh.PublicKey) *testSFTPServer {
+	t.Helper()
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("host key: %v", err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatalf("host signer: %v", err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if meta.User() == "partner" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, errors.New("denied")
+		},
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if clientKey != nil && meta.User() == "partner" && bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("denied")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	root := t.TempDir()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTPConn(conn, config, root)
+		}
+	}()
+
+	return &testSFTPServer{
+		root:    root,
+		port:    listener.Addr().(*net.TCPAddr).Port,
+		hostKey: strings.TrimSpace(string(ssh.MarshalAuthorizedKey(hostSigner.PublicKey()))),
+	}
+}
+
+func serveSFTPConn(conn net.Conn, config *ssh.ServerConfig, root string) {
+	defer conn.Close()
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if !ok {
+					continue
+				}
+				server, err := sftp.NewServer(channel, sftp.WithServerWorkingDirectory(root))
+				if err != nil {
+					_ = channel.Close()
+					return
+				}
+				_ = server.Serve()
+				_ = channel.Close()
+				return
+			}
+		}()
+	}
+}
+
+func (s *testSFTPServer) target() SFTPTarget {
+	return SFTPTarget{
+		Host:           "127.0.0.1",
+		Port:           s.port,
+		Username:       "partner",
+		PasswordSecret: "partner-password",
+		HostKey:        s.hostKey,
+		Dir:            "outbox",
+	}
+}
+
+func noSleep(ctx context.Context, d time.Duration) error { return nil }
+
+func TestService_Deliver_SFTPTarget(t *testing.T) {
+	ctx := context.Background()
+	server := startSFTPServer(t, nil)
+
+	source := export.NewMemoryStore()
+	ref, err := source.Put(ctx, "exports/exp-1.csv", strings.NewReader("a,b\n1,2\n"), export.ArtifactMeta{Filename: "orders.csv"})
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	svc := &stubExportService{
+		request: func(ctx context.Context, actor export.Actor, req export.ExportRequest) (export.ExportRecord, error) {
+			return export.ExportRecord{ID: "exp-1"}, nil
+		},
+		generate: func(ctx context.Context, actor export.Actor, exportID string, req export.ExportRequest) (export.ExportResult, error) {
+			return export.ExportResult{ID: exportID, Format: req.Format, Artifact: &ref}, nil
+		},
+	}
+	uploader := &SFTPUploader{
+		Secrets: StaticSecrets{"partner-password": []byte("secret")},
+		Retry:   RetryPolicy{MaxAttempts: 2},
+		Sleep:   noSleep,
+	}
+	delivery := NewService(Config{Service: svc, Store: source, SFTPSender: uploader})
+	delivery.now = func() time.Time { return time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC) }
+
+	deliver := func(policy OverwritePolicy) (Result, error) {
+		target := server.target()
+		target.Overwrite = policy
+		return delivery.Deliver(ctx, Request{
+			Actor:   export.Actor{ID: "actor-1"},
+			Export:  export.ExportRequest{Definition: "orders", Format: export.FormatCSV},
+			Targets: []Target{{Kind: TargetSFTP, SFTP: target}},
+		})
+	}
+
+	result, err := deliver("")
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	want := "sftp://partner@" + net.JoinHostPort("127.0.0.1", strconv.Itoa(server.port)) + "/outbox/orders/2024/03/orders.csv"
+	if got := result.Deliveries[0].Target; got != want {
+		t.Fatalf("expected target %q, got %q", want, got)
+	}
+	assertFile(t, filepath.Join(server.root, "outbox/orders/2024/03/orders.csv"), "a,b\n1,2\n")
+	entries, _ := os.ReadDir(filepath.Join(server.root, "outbox/orders/2024/03"))
+	if len(entries) != 1 {
+		t.Fatalf("expected partial file to be renamed away, got %d entries", len(entries))
+	}
+
+	if _, err := deliver(OverwriteFail); export.KindFromError(err) != export.KindValidation {
+		t.Fatalf("expected existing path error, got %v", err)
+	}
+	result, err = deliver(OverwriteRename)
+	if err != nil {
+		t.Fatalf("deliver rename: %v", err)
+	}
+	assertFile(t, filepath.Join(server.root, "outbox/orders/2024/03/orders-1.csv"), "a,b\n1,2\n")
+	if result.Deliveries[0].Attempts != 1 {
+		t.Fatalf("expected single attempt, got %d", result.Deliveries[0].Attempts)
+	}
+}
+
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestSFTPUploader_ResumesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	_, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("client key: %v", err)
+	}
+	clientSigner, err := ssh.NewSignerFromKey(clientPriv)
+	if err != nil {
+		t.Fatalf("client signer: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	server := startSFTPServer(t, clientSigner.PublicKey())
+
+	content := []byte("hello resumable world")
+	var offsets []int64
+	open := func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+		offsets = append(offsets, offset)
+		if len(offsets) == 1 {
+			return io.NopCloser(&failingReader{data: content[:5], err: errors.New("connection reset")}), nil
+		}
+		return io.NopCloser(bytes.NewReader(content[offset:])), nil
+	}
+
+	target := server.target()
+	target.PasswordSecret = ""
+	target.PrivateKeySecret = "partner-key"
+	uploader := &SFTPUploader{
+		Secrets: StaticSecrets{"partner-key": pem.EncodeToMemory(block)},
+		Sleep:   noSleep,
+	}
+	result, err := uploader.Upload(ctx, SFTPMessage{
+		ID:     "exp-9",
+		Target: target,
+		Path:   "outbox/report.txt",
+		Size:   int64(len(content)),
+		Open:   open,
+	})
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if result.Attempts != 2 || result.ResumedAt != 5 {
+		t.Fatalf("expected resume at byte 5 on second attempt, got %+v", result)
+	}
+	if len(offsets) != 2 || offsets[1] != 5 {
+		t.Fatalf("expected source reopened at offset 5, got %v", offsets)
+	}
+	assertFile(t, filepath.Join(server.root, "outbox/report.txt"), string(content))
+}
+
+func TestSFTPUploader_RejectsUnpinnedHostKey(t *testing.T) {
+	server := startSFTPServer(t, nil)
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	sshPub, err := ssh.NewPublicKey(otherPub)
+	if err != nil {
+		t.Fatalf("public key: %v", err)
+	}
+
+	target := server.target()
+	target.HostKey = ssh.FingerprintSHA256(sshPub)
+	uploader := &SFTPUploader{Secrets: StaticSecrets{"partner-password": []byte("secret")}, Sleep: noSleep}
+	result, err := uploader.Upload(context.Background(), SFTPMessage{
+		Target: target,
+		Path:   "outbox/report.txt",
+		Open: func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("data")), nil
+		},
+	})
+	if export.KindFromError(err) != export.KindAuthz {
+		t.Fatalf("expected host key mismatch, got %v", err)
+	}
+	if result.Attempts != 1 {
+		t.Fatalf("expected no retries on host key mismatch, got %d", result.Attempts)
+	}
+	if _, statErr := os.Stat(filepath.Join(server.root, "outbox")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected nothing uploaded")
+	}
+}
@@ -15,18 +15,37 @@ const (
 	DeliveryAttachment DeliveryMode = "attachment"
 )
 
+// AttachmentFallback controls what happens when an attachment exceeds
+// Limits.MaxAttachmentSize.
+type AttachmentFallback string
+
+const (
+	// AttachmentFallbackFail fails the delivery (default).
+	AttachmentFallbackFail AttachmentFallback = "fail"
+	// AttachmentFallbackZip compresses the artifact and fails if it still does not fit.
+	AttachmentFallbackZip AttachmentFallback = "zip"
+	// AttachmentFallbackLink sends a signed link instead.
+	AttachmentFallbackLink AttachmentFallback = "link"
+	// AttachmentFallbackZipThenLink compresses first and sends a link if it still does not fit.
+	AttachmentFallbackZipThenLink AttachmentFallback = "zip_then_link"
+)
+
 // TargetKind identifies delivery destination type.
 type TargetKind string
 
 const (
 	TargetEmail   TargetKind = "email"
 	TargetWebhook TargetKind = "webhook"
+	TargetStore   TargetKind = "store"
+	TargetSFTP    TargetKind = "sftp"
 )
 
 // Message describes optional subject/body overrides for notifications.
 type Message struct {
 	Subject string `json:"subject,omitempty"`
 	Body    string `json:"body,omitempty"`
+	// Template selects a registered EmailTemplates set for this request.
+	Template string `json:"template,omitempty"`
 }
 
 // EmailTarget configures email delivery.
@@ -37,11 +56,58 @@ type EmailTarget struct {
 	ReplyTo string   `json:"reply_to,omitempty"`
 }
 
-// WebhookTarget configures webhook delivery.
+// WebhookTarget configures webhook delivery. When Secret is set, deliveries
+// are signed with Standard Webhooks headers ("whsec_<base64>" or raw secret).
+// Secret is never serialized, so requests that are queued, scheduled or read
+// from files must use SecretRef, which names the secret for the sender's
+// SecretResolver; only deliveries signed through SecretRef can be re-signed
+// on dead-letter replay.
+// HeaderSecrets maps header names to secret references the same way, for
+// tokens that must survive a replay; credential-like inline Headers are
+// dropped from dead letters.
 type WebhookTarget struct {
-	URL     string            `json:"url"`
-	Method  string            `json:"method,omitempty"`
-	Headers map[string]string `json:"headers,omitempty"`
+	URL           string            `json:"url"`
+	Method        string            `json:"method,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	HeaderSecrets map[string]string `json:"header_secrets,omitempty"`
+	Secret        string            `json:"-"`
+	SecretRef     string            `json:"secret_ref,omitempty"`
+}
+
+// StoreTarget copies the artifact into a named Destination. Path is a
+// template (see RenderStorePath); Overwrite defaults to OverwriteFail.
+type StoreTarget struct {
+	Destination string          `json:"destination"`
+	Path        string          `json:"path,omitempty"`
+	Overwrite   OverwritePolicy `json:"overwrite,omitempty"`
+}
+
+// SFTPTarget uploads the artifact to an SFTP server. Dir is the remote base
+// directory (absolute or relative to the login directory) and Path is a
+// template below it (see RenderStorePath). Credentials are secret references
+// resolved by the uploader; HostKey pins the server key as an authorized_keys
+// line or a "SHA256:..." fingerprint.
+type SFTPTarget struct {
+	Host             string          `json:"host"`
+	Port             int             `json:"port,omitempty"`
+	Username         string          `json:"username"`
+	PasswordSecret   string          `json:"password_secret,omitempty"`
+	PrivateKeySecret string          `json:"private_key_secret,omitempty"`
+	HostKey          string          `json:"host_key"`
+	Dir              string          `json:"dir,omitempty"`
+	Path             string          `json:"path,omitempty"`
+	Overwrite        OverwritePolicy `json:"overwrite,omitempty"`
+}
+
+// hasInlineWebhookSecret reports whether any webhook target carries an
+// inline Secret, which would be lost when the request is serialized.
+func hasInlineWebhookSecret(targets []Target) bool {
+	for _, target := range targets {
+		if target.Kind == TargetWebhook && target.Webhook.Secret != "" {
+			return true
+		}
+	}
+	return false
 }
 
 // Target defines a destination for export delivery.
@@ -49,18 +115,51 @@ type Target struct {
 	Kind    TargetKind    `json:"kind"`
 	Email   EmailTarget   `json:"email"`
 	Webhook WebhookTarget `json:"webhook"`
+	Store   StoreTarget   `json:"store"`
+	SFTP    SFTPTarget    `json:"sftp"`
+}
+
+// Skip reasons recorded when delivery conditions hold a run back.
+const (
+	SkipReasonEmpty          = "empty"
+	SkipReasonUnchanged      = "unchanged"
+	SkipReasonBelowThreshold = "below_threshold"
+	// SkipReasonExists marks store/SFTP copies held back by OverwriteSkip.
+	SkipReasonExists = "exists"
+)
+
+// DeliveryConditions gate a delivery after the export is generated. Skipped
+// runs are recorded per target with status "skipped" and a reason.
+type DeliveryConditions struct {
+	// SkipEmpty skips exports with no rows.
+	SkipEmpty bool `json:"skip_empty,omitempty"`
+	// SkipUnchanged skips exports whose checksum equals the last artifact
+	// delivered for the same ScheduleID. Requires a DeliveryTracker.
+	// Watermarked exports embed a per-run token and never compare equal,
+	// so they are rejected.
+	SkipUnchanged bool `json:"skip_unchanged,omitempty"`
+	// MinRows only sends once the row count reaches the threshold.
+	MinRows int64 `json:"min_rows,omitempty"`
 }
 
-// Request describes a scheduled delivery request.
+// Request describes a scheduled delivery request. ScheduleID identifies the
+// schedule across runs for change-aware conditions and delivery history.
 type Request struct {
-	Actor    export.Actor         `json:"actor"`
-	Export   export.ExportRequest `json:"export"`
-	Targets  []Target             `json:"targets"`
-	Mode     DeliveryMode         `json:"mode"`
-	LinkTTL  time.Duration        `json:"link_ttl,omitempty"`
-	Message  Message              `json:"message"`
-	Notify   NotificationRequest  `json:"notify"`
-	Metadata map[string]any       `json:"metadata,omitempty"`
+	Actor   export.Actor         `json:"actor"`
+	Export  export.ExportRequest `json:"export"`
+	Targets []Target             `json:"targets"`
+	Mode    DeliveryMode         `json:"mode"`
+	// AttachmentFallback applies when Mode is DeliveryAttachment.
+	AttachmentFallback AttachmentFallback  `json:"attachment_fallback,omitempty"`
+	LinkTTL            time.Duration       `json:"link_ttl,omitempty"`
+	Message            Message             `json:"message"`
+	Notify             NotificationRequest `json:"notify"`
+	Metadata           map[string]any      `json:"metadata,omitempty"`
+	ScheduleID         string              `json:"schedule_id,omitempty"`
+	Conditions         DeliveryConditions  `json:"conditions,omitempty"`
+	// Digest queues email and notification recipients into the named digest
+	// instead of sending; webhook, store, and SFTP targets still run now.
+	Digest string `json:"digest,omitempty"`
 }
 
 // NotificationRequest configures export-ready notifications.
@@ -80,16 +179,29 @@ type Attachment struct {
 	ContentType string
 	Data        []byte
 	Size        int64
+	Checksum    string
+	// Key is the artifact key when Data is the stored artifact unchanged.
+	Key string
 }
 
-// Result describes the outcome of a delivery request.
+// Result describes the outcome of a delivery request. Skipped runs carry the
+// SkipReason and send nothing; Queued counts recipients added to a digest.
+// Mode is the mode actually used and Fallback the attachment fallback applied.
 type Result struct {
 	ExportID   string
 	Definition string
 	Format     export.Format
 	Filename   string
+	Mode       DeliveryMode
+	Fallback   AttachmentFallback
 	Link       string
 	Attachment *Attachment
 	Targets    int
+	Rows       int64
+	Checksum   string
+	Skipped    bool
+	SkipReason string
+	Queued     int
+	Deliveries []export.DeliveryRecord
 	SentAt     time.Time
 }
@@ -3,20 +3,37 @@ package exportdelivery
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/goliatone/go-export/export"
 )
 
-// WebhookMessage describes an outbound webhook call.
+// WebhookMessage describes an outbound webhook call. ID is the stable
+// webhook-id reused across retries and replays; a random ID is assigned when
+// empty. Secret or SecretRef enables Standard Webhooks signing; SecretRef and
+// HeaderSecrets are resolved by the sender so dead letters can be replayed.
+// AttachmentKey names the artifact behind a WebhookPayload attachment, so dead
+// letters can drop the data and re-read it on replay.
 type WebhookMessage struct {
-	URL     string
-	Method  string
-	Headers map[string]string
-	Payload any
+	ID            string
+	URL           string
+	Method        string
+	Headers       map[string]string
+	HeaderSecrets map[string]string
+	Secret        string
+	SecretRef     string
+	Payload       any
+	AttachmentKey string
 }
 
 // WebhookSender delivers webhook messages.
@@ -24,32 +41,296 @@ type WebhookSender interface {
 	Send(ctx context.Context, msg WebhookMessage) error
 }
 
-// HTTPWebhookSender posts JSON payloads via HTTP.
+// WebhookResult describes the outcome of a webhook delivery.
+type WebhookResult struct {
+	WebhookID    string
+	StatusCode   int
+	Attempts     int
+	DeadLetterID string
+}
+
+// WebhookReporter is implemented by senders that report per-delivery outcomes,
+// used to record delivery history.
+type WebhookReporter interface {
+	SendWithResult(ctx context.Context, msg WebhookMessage) (WebhookResult, error)
+}
+
+// RetryPolicy configures webhook and SFTP retries with exponential backoff.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxRetryAfter caps honored Retry-After delays; longer delays stop retrying.
+	MaxRetryAfter time.Duration
+}
+
+const (
+	DefaultWebhookMaxAttempts    = 5
+	DefaultWebhookInitialBackoff = time.Second
+	DefaultWebhookMaxBackoff     = time.Minute
+	DefaultWebhookMaxRetryAfter  = 5 * time.Minute
+
+	DefaultWebhookReplayMaxAttempts = 20
+	DefaultWebhookReplayMaxAge      = 72 * time.Hour
+)
+
+// HTTPWebhookSender posts JSON payloads via HTTP. Network errors, 408, 429,
+// and 5xx responses are retried; deliveries that still fail are saved to
+// DeadLetters when configured. Secrets resolves WebhookMessage.SecretRef and
+// HeaderSecrets; Store re-reads attachment artifacts when replaying.
+// ReplayMaxAttempts (total attempts, default 20) and ReplayMaxAge (since the
+// first failure, default 72h) bound how long dead letters are replayed.
 type HTTPWebhookSender struct {
-	Client *http.Client
+	Client            *http.Client
+	Retry             RetryPolicy
+	DeadLetters       DeadLetterStore
+	Secrets           SecretResolver
+	Store             export.ArtifactStore
+	Logger            export.Logger
+	Now               func() time.Time
+	Sleep             func(ctx context.Context, d time.Duration) error
+	ReplayMaxAttempts int
+	ReplayMaxAge      time.Duration
 }
 
-// Send posts the webhook payload.
+// Send posts the webhook payload, retrying transient failures.
 func (s *HTTPWebhookSender) Send(ctx context.Context, msg WebhookMessage) error {
+	_, err := s.SendWithResult(ctx, msg)
+	return err
+}
+
+// SendWithResult posts the webhook payload and reports attempts, the last
+// response status, and the dead-letter ID when delivery failed.
+func (s *HTTPWebhookSender) SendWithResult(ctx context.Context, msg WebhookMessage) (WebhookResult, error) {
 	if s == nil {
-		return export.NewError(export.KindInternal, "webhook sender is nil", nil)
+		return WebhookResult{}, export.NewError(export.KindInternal, "webhook sender is nil", nil)
 	}
 	if strings.TrimSpace(msg.URL) == "" {
-		return export.NewError(export.KindValidation, "webhook URL is required", nil)
+		return WebhookResult{}, export.NewError(export.KindValidation, "webhook URL is required", nil)
 	}
-	method := msg.Method
-	if method == "" {
-		method = http.MethodPost
+	if msg.ID == "" {
+		msg.ID = newWebhookID()
 	}
-
 	payload, err := json.Marshal(msg.Payload)
 	if err != nil {
-		return export.NewError(export.KindValidation, "webhook payload invalid", err)
+		return WebhookResult{WebhookID: msg.ID}, export.NewError(export.KindValidation, "webhook payload invalid", err)
+	}
+
+	signed, err := s.resolveSecrets(ctx, msg)
+	if err != nil {
+		return WebhookResult{WebhookID: msg.ID}, err
+	}
+	attempt, err := s.deliver(ctx, signed, payload)
+	result := WebhookResult{WebhookID: msg.ID, StatusCode: attempt.status, Attempts: attempt.count}
+	if err == nil {
+		return result, nil
+	}
+	result.DeadLetterID = s.deadLetter(ctx, msg, payload, attempt, err)
+	return result, err
+}
+
+// Replay re-sends a dead letter once and removes it on success; the replay
+// schedule acts as the backoff. On failure the dead letter is updated with the
+// attempt and error. Letters that cannot be replayed, get a non-retryable
+// response, or pass ReplayMaxAttempts or ReplayMaxAge are parked so they are no
+// longer listed. Signed deliveries can only be replayed when they were sent
+// with a SecretRef, and deliveries whose credential headers were dropped cannot
+// be replayed.
+func (s *HTTPWebhookSender) Replay(ctx context.Context, id string) error {
+	if s == nil {
+		return export.NewError(export.KindInternal, "webhook sender is nil", nil)
+	}
+	if s.DeadLetters == nil {
+		return export.NewError(export.KindNotImpl, "dead-letter store not configured", nil)
+	}
+	letter, err := s.DeadLetters.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if reason := s.unreplayable(letter); reason != "" {
+		return s.park(ctx, letter, reason)
+	}
+
+	msg, err := s.resolveSecrets(ctx, deadLetterMessage(letter))
+	if err != nil {
+		return err
+	}
+	payload, err := s.restoreAttachment(ctx, letter)
+	if err != nil {
+		return err
+	}
+
+	resp, sendErr := s.post(ctx, msg, payload)
+	if sendErr == nil {
+		return s.DeadLetters.Delete(ctx, letter.ID)
+	}
+	letter.Attempts++
+	letter.StatusCode = resp.status
+	letter.Error = sendErr.Error()
+	letter.FailedAt = s.now()
+	letter.Parked = !resp.retryable || letter.Attempts >= s.replayMaxAttempts()
+	if _, err := s.DeadLetters.Save(ctx, letter); err != nil {
+		return errors.Join(sendErr, err)
+	}
+	return sendErr
+}
+
+// unreplayable returns why a dead letter must not be replayed, or "".
+func (s *HTTPWebhookSender) unreplayable(letter DeadLetter) string {
+	switch {
+	case letter.Signed && letter.SecretRef == "":
+		return "dead letter was signed with an inline secret and cannot be re-signed"
+	case len(letter.RedactedHeaders) > 0:
+		return "dead letter dropped credential headers and cannot be replayed"
+	case letter.Attempts >= s.replayMaxAttempts():
+		return "dead letter reached the replay attempt limit"
+	}
+	created := letter.CreatedAt
+	if created.IsZero() {
+		created = letter.FailedAt
+	}
+	if !created.IsZero() && s.now().Sub(created) > s.replayMaxAge() {
+		return "dead letter is older than the replay age limit"
+	}
+	return ""
+}
+
+// park marks a dead letter as no longer replayable and returns reason as an error.
+func (s *HTTPWebhookSender) park(ctx context.Context, letter DeadLetter, reason string) error {
+	parkErr := export.NewError(export.KindValidation, reason, nil)
+	if letter.Parked {
+		return parkErr
+	}
+	letter.Parked = true
+	letter.Error = reason
+	if _, err := s.DeadLetters.Save(ctx, letter); err != nil {
+		return errors.Join(parkErr, err)
+	}
+	return parkErr
+}
+
+func (s *HTTPWebhookSender) replayMaxAttempts() int {
+	if s.ReplayMaxAttempts > 0 {
+		return s.ReplayMaxAttempts
+	}
+	return DefaultWebhookReplayMaxAttempts
+}
+
+func (s *HTTPWebhookSender) replayMaxAge() time.Duration {
+	if s.ReplayMaxAge > 0 {
+		return s.ReplayMaxAge
+	}
+	return DefaultWebhookReplayMaxAge
+}
+
+// resolveSecrets fills Secret from SecretRef and adds HeaderSecrets to a copy
+// of Headers.
+func (s *HTTPWebhookSender) resolveSecrets(ctx context.Context, msg WebhookMessage) (WebhookMessage, error) {
+	if msg.SecretRef == "" && len(msg.HeaderSecrets) == 0 {
+		return msg, nil
+	}
+	if s.Secrets == nil {
+		return msg, export.NewError(export.KindNotImpl, "webhook secret resolver not configured", nil)
+	}
+	if msg.SecretRef != "" {
+		secret, err := s.Secrets.ResolveSecret(ctx, msg.SecretRef)
+		if err != nil {
+			return msg, err
+		}
+		msg.Secret = string(secret)
+	}
+	if len(msg.HeaderSecrets) > 0 {
+		headers := make(map[string]string, len(msg.Headers)+len(msg.HeaderSecrets))
+		maps.Copy(headers, msg.Headers)
+		for name, ref := range msg.HeaderSecrets {
+			value, err := s.Secrets.ResolveSecret(ctx, ref)
+			if err != nil {
+				return msg, err
+			}
+			headers[name] = string(value)
+		}
+		msg.Headers = headers
+	}
+	return msg, nil
+}
+
+// restoreAttachment returns the dead letter payload with the attachment data
+// re-read from the artifact store.
+func (s *HTTPWebhookSender) restoreAttachment(ctx context.Context, letter DeadLetter) ([]byte, error) {
+	var body WebhookPayload
+	if err := json.Unmarshal(letter.Payload, &body); err != nil || body.Attachment == nil || body.Attachment.Data != "" {
+		return letter.Payload, nil
+	}
+	if letter.ArtifactKey == "" {
+		return nil, export.NewError(export.KindValidation, "dead letter attachment cannot be restored", nil)
+	}
+	if s.Store == nil {
+		return nil, export.NewError(export.KindNotImpl, "webhook artifact store not configured", nil)
+	}
+	reader, _, err := s.Store.Open(ctx, letter.ArtifactKey)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, export.NewError(export.KindExternal, "webhook attachment read failed", err)
+	}
+	body.Attachment.Data = base64.StdEncoding.EncodeToString(data)
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, export.NewError(export.KindValidation, "webhook payload invalid", err)
+	}
+	return payload, nil
+}
+
+type webhookAttempt struct {
+	count  int
+	status int
+}
+
+func (s *HTTPWebhookSender) deliver(ctx context.Context, msg WebhookMessage, payload []byte) (webhookAttempt, error) {
+	policy := s.retryPolicy()
+	var attempt webhookAttempt
+	for {
+		attempt.count++
+		resp, err := s.post(ctx, msg, payload)
+		attempt.status = resp.status
+		if err == nil {
+			return attempt, nil
+		}
+		if !resp.retryable || attempt.count >= policy.MaxAttempts {
+			return attempt, err
+		}
+
+		delay := backoffDelay(policy, attempt.count)
+		if resp.retryAfter > 0 {
+			if resp.retryAfter > policy.MaxRetryAfter {
+				return attempt, err
+			}
+			delay = resp.retryAfter
+		}
+		if sleepErr := s.sleep(ctx, delay); sleepErr != nil {
+			return attempt, errors.Join(err, sleepErr)
+		}
 	}
+}
 
+type webhookResponse struct {
+	status     int
+	retryAfter time.Duration
+	retryable  bool
+}
+
+// post performs a single attempt. status is 0 when no response was received.
+func (s *HTTPWebhookSender) post(ctx context.Context, msg WebhookMessage, payload []byte) (webhookResponse, error) {
+	method := msg.Method
+	if method == "" {
+		method = http.MethodPost
+	}
 	req, err := http.NewRequestWithContext(ctx, method, msg.URL, bytes.NewReader(payload))
 	if err != nil {
-		return export.NewError(export.KindInternal, "webhook request failed", err)
+		return webhookResponse{}, export.NewError(export.KindInternal, "webhook request failed", err)
 	}
 	if msg.Headers != nil {
 		for key, value := range msg.Headers {
@@ -62,6 +343,16 @@ func (s *HTTPWebhookSender) Send(ctx context.Context, msg WebhookMessage) error
 	if req.Header.Get("Content-Type") == "" {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if msg.Secret != "" {
+		now := s.now()
+		signature, err := SignWebhook(msg.Secret, msg.ID, now, payload)
+		if err != nil {
+			return webhookResponse{}, err
+		}
+		req.Header.Set(HeaderWebhookID, msg.ID)
+		req.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(now.Unix(), 10))
+		req.Header.Set(HeaderWebhookSignature, signature)
+	}
 
 	client := s.Client
 	if client == nil {
@@ -70,13 +361,191 @@ func (s *HTTPWebhookSender) Send(ctx context.Context, msg WebhookMessage) error
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return export.NewError(export.KindExternal, "webhook request failed", err)
+		return webhookResponse{retryable: ctx.Err() == nil}, export.NewError(export.KindExternal, "webhook request failed", err)
 	}
 	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return export.NewError(export.KindExternal, "webhook response error", nil)
+		return webhookResponse{
+			status:     resp.StatusCode,
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), s.now()),
+			retryable:  retryableStatus(resp.StatusCode),
+		}, export.NewError(export.KindExternal, fmt.Sprintf("webhook response error: status %d", resp.StatusCode), nil)
+	}
+	return webhookResponse{status: resp.StatusCode}, nil
+}
+
+func (s *HTTPWebhookSender) deadLetter(ctx context.Context, msg WebhookMessage, payload []byte, attempt webhookAttempt, sendErr error) string {
+	if s.DeadLetters == nil {
+		return ""
+	}
+	headers, redacted := splitCredentialHeaders(msg.Headers)
+	now := s.now()
+	letter, err := s.DeadLetters.Save(ctx, DeadLetter{
+		WebhookID:       msg.ID,
+		URL:             msg.URL,
+		Method:          msg.Method,
+		Headers:         headers,
+		HeaderSecrets:   msg.HeaderSecrets,
+		RedactedHeaders: redacted,
+		SecretRef:       msg.SecretRef,
+		Signed:          msg.Secret != "" || msg.SecretRef != "",
+		Payload:         deadLetterPayload(msg, payload),
+		ArtifactKey:     msg.AttachmentKey,
+		Attempts:        attempt.count,
+		StatusCode:      attempt.status,
+		Error:           sendErr.Error(),
+		CreatedAt:       now,
+		FailedAt:        now,
+	})
+	if err != nil {
+		if s.Logger != nil {
+			s.Logger.Error("webhook dead-letter failed", "error", err, "webhook_id", msg.ID, "url", msg.URL)
+		}
+		return ""
+	}
+	return letter.ID
+}
+
+// credentialHeaderNames mark headers whose values are not stored in dead
+// letters; matching is case-insensitive on substrings.
+var credentialHeaderNames = []string{"auth", "cookie", "token", "secret", "password", "api-key", "apikey", "session"}
+
+// splitCredentialHeaders returns headers without credential-like entries and
+// the sorted names of the entries it dropped.
+func splitCredentialHeaders(headers map[string]string) (map[string]string, []string) {
+	var kept map[string]string
+	var dropped []string
+	for name, value := range headers {
+		lower := strings.ToLower(name)
+		if slices.ContainsFunc(credentialHeaderNames, func(sensitive string) bool {
+			return strings.Contains(lower, sensitive)
+		}) {
+			dropped = append(dropped, name)
+			continue
+		}
+		if kept == nil {
+			kept = make(map[string]string, len(headers))
+		}
+		kept[name] = value
+	}
+	slices.Sort(dropped)
+	return kept, dropped
+}
+
+// deadLetterPayload strips attachment data from WebhookPayload bodies so dead
+// letters never hold the artifact itself.
+func deadLetterPayload(msg WebhookMessage, payload []byte) []byte {
+	var body WebhookPayload
+	switch p := msg.Payload.(type) {
+	case WebhookPayload:
+		body = p
+	case *WebhookPayload:
+		if p == nil {
+			return payload
+		}
+		body = *p
+	default:
+		return payload
+	}
+	if body.Attachment == nil || body.Attachment.Data == "" {
+		return payload
+	}
+	attachment := *body.Attachment
+	attachment.Data = ""
+	body.Attachment = &attachment
+	stripped, err := json.Marshal(body)
+	if err != nil {
+		return nil
+	}
+	return stripped
+}
+
+func (s *HTTPWebhookSender) retryPolicy() RetryPolicy {
+	return s.Retry.withDefaults()
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultWebhookMaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultWebhookInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultWebhookMaxBackoff
+	}
+	if p.MaxRetryAfter <= 0 {
+		p.MaxRetryAfter = DefaultWebhookMaxRetryAfter
+	}
+	return p
+}
+
+func (s *HTTPWebhookSender) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+func (s *HTTPWebhookSender) sleep(ctx context.Context, d time.Duration) error {
+	if s.Sleep != nil {
+		return s.Sleep(ctx, d)
+	}
+	return sleepContext(ctx, d)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func retryableStatus(status int) bool {
+	switch {
+	case status == http.StatusRequestTimeout, status == http.StatusTooManyRequests:
+		return true
+	case status >= http.StatusInternalServerError:
+		return true
+	default:
+		return false
+	}
+}
+
+func backoffDelay(policy RetryPolicy, attempt int) time.Duration {
+	delay := policy.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= policy.MaxBackoff {
+			return policy.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// parseRetryAfter reads delay-seconds or HTTP-date Retry-After values.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if delay := at.Sub(now); delay > 0 {
+			return delay
+		}
 	}
-	return nil
+	return 0
 }
 
 // WebhookPayload describes the webhook event body.
@@ -85,6 +554,7 @@ type WebhookPayload struct {
 	Definition string             `json:"definition"`
 	Format     export.Format      `json:"format"`
 	Filename   string             `json:"filename"`
+	Checksum   string             `json:"checksum,omitempty"`
 	Mode       DeliveryMode       `json:"mode"`
 	Link       string             `json:"link,omitempty"`
 	Attachment *WebhookAttachment `json:"attachment,omitempty"`
@@ -98,5 +568,6 @@ type WebhookAttachment struct {
 	Filename    string `json:"filename"`
 	ContentType string `json:"content_type"`
 	Size        int64  `json:"size"`
+	Checksum    string `json:"checksum,omitempty"`
 	Data        string `json:"data"`
 }
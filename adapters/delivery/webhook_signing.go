@@ -0,0 +1,103 @@
+package exportdelivery
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+)
+
+// Standard Webhooks header names.
+const (
+	HeaderWebhookID        = "webhook-id"
+	HeaderWebhookTimestamp = "webhook-timestamp"
+	HeaderWebhookSignature = "webhook-signature"
+)
+
+// DefaultWebhookTolerance bounds the accepted clock skew when verifying signatures.
+const DefaultWebhookTolerance = 5 * time.Minute
+
+const webhookSecretPrefix = "whsec_"
+
+// SignWebhook computes a Standard Webhooks "v1,<base64>" signature over
+// "{id}.{timestamp}.{body}". Secrets in "whsec_<base64>" form are decoded;
+// other values are used as raw key bytes.
+func SignWebhook(secret, id string, timestamp time.Time, body []byte) (string, error) {
+	key, err := webhookSecretKey(secret)
+	if err != nil {
+		return "", err
+	}
+	return "v1," + base64.StdEncoding.EncodeToString(webhookMAC(key, id, timestamp.Unix(), body)), nil
+}
+
+// VerifyWebhook checks Standard Webhooks headers for body. Receivers can use it
+// to authenticate deliveries; tolerance <= 0 uses DefaultWebhookTolerance.
+func VerifyWebhook(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	key, err := webhookSecretKey(secret)
+	if err != nil {
+		return err
+	}
+	id := header.Get(HeaderWebhookID)
+	if id == "" {
+		return export.NewError(export.KindValidation, "webhook id header is required", nil)
+	}
+	ts, err := strconv.ParseInt(header.Get(HeaderWebhookTimestamp), 10, 64)
+	if err != nil {
+		return export.NewError(export.KindValidation, "webhook timestamp header is invalid", err)
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultWebhookTolerance
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > tolerance || skew < -tolerance {
+		return export.NewError(export.KindValidation, "webhook timestamp outside tolerance", nil)
+	}
+
+	expected := webhookMAC(key, id, ts, body)
+	for _, candidate := range strings.Fields(header.Get(HeaderWebhookSignature)) {
+		version, value, ok := strings.Cut(candidate, ",")
+		if !ok || version != "v1" {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(value)
+		if err == nil && hmac.Equal(sig, expected) {
+			return nil
+		}
+	}
+	return export.NewError(export.KindValidation, "webhook signature invalid", nil)
+}
+
+func webhookSecretKey(secret string) ([]byte, error) {
+	if secret == "" {
+		return nil, export.NewError(export.KindValidation, "webhook secret is required", nil)
+	}
+	if encoded, ok := strings.CutPrefix(secret, webhookSecretPrefix); ok {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, export.NewError(export.KindValidation, "webhook secret is invalid", err)
+		}
+		return key, nil
+	}
+	return []byte(secret), nil
+}
+
+func webhookMAC(key []byte, id string, timestamp int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id + "." + strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func newWebhookID() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "msg_" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return "msg_" + hex.EncodeToString(buf)
+}
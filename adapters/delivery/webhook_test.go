@@ -2,10 +2,16 @@ package exportdelivery
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/goliatone/go-export/export"
 )
 
 func TestHTTPWebhookSender_Send(t *testing.T) {
@@ -41,3 +47,341 @@ func TestHTTPWebhookSender_Send(t *testing.T) {
 		t.Fatalf("expected payload")
 	}
 }
+
+func TestHTTPWebhookSender_SignsWithStandardWebhookHeaders(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	secret := "whsec_" + base64.StdEncoding.EncodeToString([]byte("super-secret"))
+	var verifyErr error
+	var gotID string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotID = r.Header.Get(HeaderWebhookID)
+		verifyErr = VerifyWebhook(secret, r.Header, body, 0, now)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sender := &HTTPWebhookSender{Client: srv.Client(), Now: func() time.Time { return now }}
+	if err := sender.Send(context.Background(), WebhookMessage{
+		ID:      "msg_fixed",
+		URL:     srv.URL,
+		Secret:  secret,
+		Payload: map[string]any{"event": "export"},
+	}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if gotID != "msg_fixed" {
+		t.Fatalf("expected webhook-id header, got %q", gotID)
+	}
+	if verifyErr != nil {
+		t.Fatalf("expected valid signature: %v", verifyErr)
+	}
+
+	header := http.Header{}
+	header.Set(HeaderWebhookID, "msg_fixed")
+	header.Set(HeaderWebhookTimestamp, "1700000000")
+	signature, _ := SignWebhook(secret, "msg_fixed", now, []byte(`{"a":1}`))
+	header.Set(HeaderWebhookSignature, signature)
+	if err := VerifyWebhook(secret, header, []byte(`{"a":2}`), 0, now); err == nil {
+		t.Fatalf("expected tampered body to fail")
+	}
+	if err := VerifyWebhook(secret, header, []byte(`{"a":1}`), time.Minute, now.Add(time.Hour)); err == nil {
+		t.Fatalf("expected stale timestamp to fail")
+	}
+}
+
+func TestHTTPWebhookSender_RetriesWithBackoffAndRetryAfter(t *testing.T) {
+	var calls int
+	var ids []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		ids = append(ids, r.Header.Get(HeaderWebhookID))
+		switch calls {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	var sleeps []time.Duration
+	sender := &HTTPWebhookSender{
+		Client: srv.Client(),
+		Retry:  RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second},
+		Sleep: func(ctx context.Context, d time.Duration) error {
+			sleeps = append(sleeps, d)
+			return nil
+		},
+	}
+	if err := sender.Send(context.Background(), WebhookMessage{URL: srv.URL, Secret: "s", Payload: map[string]any{}}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+	if len(sleeps) != 2 || sleeps[0] != time.Second || sleeps[1] != 7*time.Second {
+		t.Fatalf("expected backoff then Retry-After, got %v", sleeps)
+	}
+	if ids[0] == "" || ids[0] != ids[1] || ids[1] != ids[2] {
+		t.Fatalf("expected stable webhook id across retries, got %v", ids)
+	}
+}
+
+func TestHTTPWebhookSender_DeadLettersAndReplay(t *testing.T) {
+	healthy := false
+	var calls int
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		signature = r.Header.Get(HeaderWebhookSignature)
+		if r.URL.Path == "/bad-request" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	dlq := NewMemoryDeadLetterStore()
+	sender := &HTTPWebhookSender{
+		Client:      srv.Client(),
+		Retry:       RetryPolicy{MaxAttempts: 2},
+		DeadLetters: dlq,
+		Secrets:     StaticSecrets{"hooks/exp": []byte("s")},
+		Sleep:       func(ctx context.Context, d time.Duration) error { return nil },
+	}
+	ctx := context.Background()
+
+	if err := sender.Send(ctx, WebhookMessage{URL: srv.URL + "/bad-request", Payload: map[string]any{}}); err == nil {
+		t.Fatalf("expected client error")
+	}
+	if calls != 1 {
+		t.Fatalf("expected 4xx not to be retried, got %d calls", calls)
+	}
+	if err := sender.Send(ctx, WebhookMessage{URL: srv.URL, SecretRef: "hooks/exp", Payload: map[string]any{"export_id": "exp-1"}}); err == nil {
+		t.Fatalf("expected exhausted retries error")
+	}
+
+	letters, _ := dlq.List(ctx, 0)
+	if len(letters) != 2 {
+		t.Fatalf("expected 2 dead letters, got %d", len(letters))
+	}
+	last := letters[1]
+	if last.Attempts != 2 || last.StatusCode != http.StatusServiceUnavailable || string(last.Payload) != `{"export_id":"exp-1"}` {
+		t.Fatalf("unexpected dead letter %+v", last)
+	}
+	if last.SecretRef != "hooks/exp" || !last.Signed {
+		t.Fatalf("expected secret reference kept, got %+v", last)
+	}
+
+	healthy = true
+	cmd := NewReplayDeadLettersCommand(dlq, sender)
+	result, err := cmd.Replay(ctx, []string{last.ID})
+	if err != nil || result.Replayed != 1 {
+		t.Fatalf("expected replay success, got %+v %v", result, err)
+	}
+	if signature == "" {
+		t.Fatalf("expected replay to be re-signed")
+	}
+	if _, err := dlq.Get(ctx, last.ID); err == nil {
+		t.Fatalf("expected replayed dead letter removed")
+	}
+
+	result, err = cmd.Replay(ctx, nil)
+	if err == nil || result.Failed != 1 {
+		t.Fatalf("expected bad request replay to fail, got %+v %v", result, err)
+	}
+	if remaining, _ := dlq.List(ctx, 0); len(remaining) != 0 {
+		t.Fatalf("expected rejected dead letter parked, got %+v", remaining)
+	}
+	rejected, _ := dlq.Get(ctx, letters[0].ID)
+	if !rejected.Parked || rejected.Attempts != 2 || rejected.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected failed replay recorded, got %+v", rejected)
+	}
+
+	inline, _ := dlq.Save(ctx, DeadLetter{WebhookID: "msg_inline", URL: srv.URL, Signed: true})
+	before := calls
+	if err := sender.Replay(ctx, inline.ID); err == nil {
+		t.Fatalf("expected inline-secret dead letter replay to fail")
+	}
+	if parked, _ := dlq.Get(ctx, inline.ID); !parked.Parked || calls != before {
+		t.Fatalf("expected inline-secret dead letter parked without sending, got %+v", parked)
+	}
+}
+
+func TestHTTPWebhookSender_ReplayParksExhaustedDeadLetters(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	dlq := NewMemoryDeadLetterStore()
+	sender := &HTTPWebhookSender{
+		Client:            srv.Client(),
+		DeadLetters:       dlq,
+		Now:               func() time.Time { return now },
+		Sleep:             func(ctx context.Context, d time.Duration) error { return nil },
+		ReplayMaxAttempts: 3,
+		ReplayMaxAge:      24 * time.Hour,
+	}
+	ctx := context.Background()
+
+	retrying, _ := dlq.Save(ctx, DeadLetter{WebhookID: "msg_retry", URL: srv.URL, Attempts: 1, FailedAt: now})
+	stale, _ := dlq.Save(ctx, DeadLetter{WebhookID: "msg_stale", URL: srv.URL, Attempts: 1, FailedAt: now.Add(-25 * time.Hour)})
+	cmd := NewReplayDeadLettersCommand(dlq, sender)
+
+	if _, err := cmd.Replay(ctx, nil); err == nil {
+		t.Fatalf("expected replay failures")
+	}
+	if calls != 1 {
+		t.Fatalf("expected one attempt per replay and none for the stale letter, got %d", calls)
+	}
+	if got, _ := dlq.Get(ctx, stale.ID); !got.Parked {
+		t.Fatalf("expected stale letter parked, got %+v", got)
+	}
+	if got, _ := dlq.Get(ctx, retrying.ID); got.Parked || got.Attempts != 2 {
+		t.Fatalf("expected retryable letter kept pending, got %+v", got)
+	}
+
+	now = now.Add(15 * time.Minute)
+	_, _ = cmd.Replay(ctx, nil)
+	if got, _ := dlq.Get(ctx, retrying.ID); !got.Parked || got.Attempts != 3 {
+		t.Fatalf("expected letter parked at the attempt limit, got %+v", got)
+	}
+	result, err := cmd.Replay(ctx, nil)
+	if err != nil || result.Failed != 0 || calls != 2 {
+		t.Fatalf("expected parked letters skipped, got %+v %v after %d calls", result, err, calls)
+	}
+}
+
+type countingReplayer struct {
+	count int
+}
+
+func (r *countingReplayer) Replay(ctx context.Context, id string) error {
+	r.count++
+	return nil
+}
+
+func TestReplayCommand_CronHandlerRunsOncePerLeaseWindow(t *testing.T) {
+	dlq := NewMemoryDeadLetterStore()
+	if _, err := dlq.Save(context.Background(), DeadLetter{WebhookID: "msg_1"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	replayer := &countingReplayer{}
+	now := time.Date(2024, 5, 1, 8, 0, 1, 0, time.UTC)
+	locker := export.NewMemoryLocker()
+	locker.Now = func() time.Time { return now }
+
+	replicas := make([]*ReplayCommand, 3)
+	for i := range replicas {
+		replicas[i] = NewReplayDeadLettersCommand(dlq, replayer, WithReplayLease(locker, 15*time.Minute))
+		replicas[i].now = func() time.Time { return now }
+	}
+	for _, replica := range replicas {
+		if err := replica.CronHandler()(); err != nil {
+			t.Fatalf("cron: %v", err)
+		}
+	}
+	if replayer.count != 1 {
+		t.Fatalf("expected one replay across replicas, got %d", replayer.count)
+	}
+
+	now = now.Add(15 * time.Minute)
+	if err := replicas[1].CronHandler()(); err != nil {
+		t.Fatalf("cron: %v", err)
+	}
+	if replayer.count != 2 {
+		t.Fatalf("expected the next window to replay, got %d", replayer.count)
+	}
+}
+
+func TestHTTPWebhookSender_DeadLettersOmitCredentialsAndAttachmentData(t *testing.T) {
+	healthy := false
+	var gotAuth string
+	var gotPayload WebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&gotPayload)
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	store := export.NewMemoryStore()
+	ref, err := store.Put(ctx, "exports/exp-1.csv", strings.NewReader("id,ssn\n1,123\n"), export.ArtifactMeta{Filename: "users.csv"})
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	dlq := NewMemoryDeadLetterStore()
+	sender := &HTTPWebhookSender{
+		Client:      srv.Client(),
+		Retry:       RetryPolicy{MaxAttempts: 1},
+		DeadLetters: dlq,
+		Secrets:     StaticSecrets{"hooks/token": []byte("Bearer ref-token")},
+		Store:       store,
+	}
+	payload := WebhookPayload{ExportID: "exp-1", Attachment: &WebhookAttachment{
+		Filename: "users.csv",
+		Data:     base64.StdEncoding.EncodeToString([]byte("id,ssn\n1,123\n")),
+	}}
+
+	result, err := sender.SendWithResult(ctx, WebhookMessage{
+		URL:           srv.URL,
+		Headers:       map[string]string{"X-Tenant": "t1"},
+		HeaderSecrets: map[string]string{"Authorization": "hooks/token"},
+		Payload:       payload,
+		AttachmentKey: ref.Key,
+	})
+	if err == nil {
+		t.Fatalf("expected delivery failure")
+	}
+	if gotAuth != "Bearer ref-token" {
+		t.Fatalf("expected header secret resolved, got %q", gotAuth)
+	}
+	letter, err := dlq.Get(ctx, result.DeadLetterID)
+	if err != nil {
+		t.Fatalf("get dead letter: %v", err)
+	}
+	if strings.Contains(string(letter.Payload), payload.Attachment.Data) || letter.ArtifactKey != ref.Key {
+		t.Fatalf("expected attachment data dropped in favour of the artifact key, got %+v", letter)
+	}
+	if _, ok := letter.Headers["Authorization"]; ok || letter.HeaderSecrets["Authorization"] != "hooks/token" {
+		t.Fatalf("expected header kept as a reference only, got %+v", letter)
+	}
+
+	healthy = true
+	gotPayload = WebhookPayload{}
+	if err := sender.Replay(ctx, letter.ID); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if gotAuth != "Bearer ref-token" || gotPayload.Attachment == nil || gotPayload.Attachment.Data != payload.Attachment.Data {
+		t.Fatalf("expected replay to restore headers and attachment, got auth=%q payload=%+v", gotAuth, gotPayload)
+	}
+
+	healthy = false
+	result, _ = sender.SendWithResult(ctx, WebhookMessage{URL: srv.URL, Headers: map[string]string{"X-Api-Key": "inline"}, Payload: map[string]any{}})
+	letter, _ = dlq.Get(ctx, result.DeadLetterID)
+	if len(letter.Headers) != 0 || len(letter.RedactedHeaders) != 1 {
+		t.Fatalf("expected inline credential header dropped, got %+v", letter)
+	}
+	if err := sender.Replay(ctx, letter.ID); err == nil {
+		t.Fatalf("expected replay without the dropped header to be refused")
+	}
+}
@@ -3,9 +3,12 @@ package exportapi
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
+	"net"
 	"net/http"
 	"path"
 	"path/filepath"
@@ -45,6 +48,8 @@ type Config struct {
 	QueryRequestDecoder RequestDecoder
 	DefinitionResolver  DefinitionResolver
 	MaxBufferBytes      int64
+	// Schedules enables the <base>/schedules endpoints.
+	Schedules export.ScheduleManager
 }
 
 // Controller exposes export API handlers for multiple transports.
@@ -67,6 +72,7 @@ type Controller struct {
 	queryDecoder       RequestDecoder
 	definitionResolver DefinitionResolver
 	maxBufferBytes     int64
+	schedules          export.ScheduleManager
 }
 
 // NewController creates a shared export API controller.
@@ -122,6 +128,7 @@ func NewController(cfg Config) *Controller {
 		queryDecoder:       queryDecoder,
 		definitionResolver: definitionResolver,
 		maxBufferBytes:     maxBuffer,
+		schedules:          cfg.Schedules,
 	}
 }
 
@@ -186,14 +193,25 @@ func (c *Controller) Serve(req Request, res Response) {
 	if pathSuffix != "" {
 		parts = strings.Split(pathSuffix, "/")
 	}
+	if len(parts) > 0 && parts[0] == schedulesSegment {
+		c.serveSchedules(req, res, parts[1:])
+		return
+	}
 
 	switch req.Method() {
 	case http.MethodPost:
-		if len(parts) != 0 {
+		switch {
+		case len(parts) == 0:
+			c.handleRequest(req, res)
+		case len(parts) == 2 && parts[1] == "approve":
+			c.handleDecision(req, res, parts[0], export.ApprovalApproved)
+		case len(parts) == 2 && parts[1] == "reject":
+			c.handleDecision(req, res, parts[0], export.ApprovalRejected)
+		case len(parts) == 2 && parts[1] == "verify":
+			c.handleVerify(req, res, parts[0])
+		default:
 			writeNotFound(res)
-			return
 		}
-		c.handleRequest(req, res)
 	case http.MethodGet:
 		switch len(parts) {
 		case 0:
@@ -206,6 +224,12 @@ func (c *Controller) Serve(req Request, res Response) {
 				c.handleDownload(req, res, parts[0])
 			case "preview":
 				c.handlePreview(req, res, parts[0])
+			case "downloads":
+				c.handleDownloads(req, res, parts[0])
+			case "deliveries":
+				c.handleDeliveries(req, res, parts[0])
+			case "manifest":
+				c.handleManifest(req, res, parts[0])
 			default:
 				writeNotFound(res)
 			}
@@ -241,14 +265,15 @@ func (c *Controller) handleRequest(req Request, res Response) {
 	}
 
 	// Resolve definition/resource before guard checks.
-	resolved, err := c.resolve(req.Context(), decoded)
+	resolved, err := c.resolve(req.Context(), actor, decoded)
 	if err != nil {
 		WriteError(res, err)
 		return
 	}
 	delivery := export.SelectDelivery(resolved.Request, resolved.Definition, c.deliveryPolicyForRequest())
 
-	if delivery == export.DeliveryAsync {
+	// Exports awaiting approval cannot stream; they are recorded and generated later.
+	if delivery == export.DeliveryAsync || len(export.ApprovalReasons(resolved)) > 0 {
 		c.handleAsync(req, res, actor, resolved)
 		return
 	}
@@ -485,6 +510,13 @@ func (c *Controller) handleDownload(req Request, res Response, exportID string)
 		return
 	}
 
+	etag := artifactETag(info.Artifact.Meta)
+	if etagMatchesAny(req.Header("If-None-Match"), etag) {
+		res.SetHeader("ETag", etag)
+		res.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	ttl := c.signedURLTTL
 	if ttl > 0 && !info.Artifact.Meta.ExpiresAt.IsZero() {
 		remaining := time.Until(info.Artifact.Meta.ExpiresAt)
@@ -499,6 +531,7 @@ func (c *Controller) handleDownload(req Request, res Response, exportID string)
 	if ttl > 0 {
 		url, err := c.store.SignedURL(req.Context(), info.Artifact.Key, ttl)
 		if err == nil {
+			c.recordDownload(req, actor, info.ExportID, export.DownloadSignedURL, 0)
 			_ = res.Redirect(url, http.StatusFound)
 			return
 		}
@@ -508,13 +541,55 @@ func (c *Controller) handleDownload(req Request, res Response, exportID string)
 		}
 	}
 
-	reader, meta, err := c.store.Open(req.Context(), info.Artifact.Key)
+	streamOpts := []StreamOption{WithMaxBufferBytes(c.maxBufferBytes)}
+	if etag != "" {
+		streamOpts = append(streamOpts, WithETag(etag))
+	}
+	if digest := export.DigestHeaderValue(info.Artifact.Meta.Checksum); digest != "" {
+		streamOpts = append(streamOpts, WithDigest(digest))
+	}
+	var (
+		rng     byteRange
+		partial bool
+	)
+	rangeOpener, canRange := c.store.(export.RangeOpener)
+	if canRange {
+		streamOpts = append(streamOpts, WithAcceptRanges("bytes"))
+		if header := req.Header("Range"); header != "" && ifRangeAllows(req.Header("If-Range"), etag) {
+			size := info.Artifact.Meta.Size
+			rng, partial, err = parseRange(header, size)
+			if err != nil {
+				res.SetHeader("Content-Range", fmt.Sprintf("bytes */%d", size))
+				res.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+		}
+	}
+
+	var (
+		reader io.ReadCloser
+		meta   export.ArtifactMeta
+	)
+	if partial {
+		reader, meta, err = rangeOpener.OpenRange(req.Context(), info.Artifact.Key, rng.start, rng.length())
+	} else {
+		reader, meta, err = c.store.Open(req.Context(), info.Artifact.Key)
+	}
 	if err != nil {
 		WriteError(res, err)
 		return
 	}
 	defer reader.Close()
 
+	written := meta.Size
+	if partial {
+		written = rng.length()
+		streamOpts = append(streamOpts,
+			WithStatus(http.StatusPartialContent),
+			WithContentRange(rng.contentRange(meta.Size)),
+		)
+	}
+
 	filename := meta.Filename
 	if filename == "" {
 		filename = path.Base(info.Artifact.Key)
@@ -533,12 +608,12 @@ func (c *Controller) handleDownload(req Request, res Response, exportID string)
 	}
 	filename = sanitizeFilename(filename, format)
 	tracked := &trackingReader{reader: reader}
-	streamErr := res.WriteStream(req.Context(), contentType, tracked,
+	streamOpts = append(streamOpts,
 		WithFilename(filename),
 		WithExportID(info.ExportID),
-		WithContentLength(meta.Size),
-		WithMaxBufferBytes(c.maxBufferBytes),
+		WithContentLength(written),
 	)
+	streamErr := res.WriteStream(req.Context(), contentType, tracked, streamOpts...)
 	if streamErr != nil {
 		if !tracked.ReadAny() {
 			WriteError(res, streamErr)
@@ -549,6 +624,100 @@ func (c *Controller) handleDownload(req Request, res Response, exportID string)
 			"export_id", info.ExportID,
 			"artifact_key", info.Artifact.Key,
 		)
+		return
+	}
+	c.recordDownload(req, actor, info.ExportID, export.DownloadStream, written)
+}
+
+func (c *Controller) handleDownloads(req Request, res Response, exportID string) {
+	if c.service == nil {
+		WriteError(res, export.NewError(export.KindNotImpl, "export service not configured", nil))
+		return
+	}
+	actor, err := c.actorFromRequest(req)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+
+	records, err := c.service.Downloads(req.Context(), actor, exportID)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+	writeJSON(res, http.StatusOK, records)
+}
+
+func (c *Controller) handleDeliveries(req Request, res Response, exportID string) {
+	if c.service == nil {
+		WriteError(res, export.NewError(export.KindNotImpl, "export service not configured", nil))
+		return
+	}
+	actor, err := c.actorFromRequest(req)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+
+	records, err := c.service.Deliveries(req.Context(), actor, exportID)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+	writeJSON(res, http.StatusOK, records)
+}
+
+func (c *Controller) handleVerify(req Request, res Response, exportID string) {
+	if c.service == nil {
+		WriteError(res, export.NewError(export.KindNotImpl, "export service not configured", nil))
+		return
+	}
+	actor, err := c.actorFromRequest(req)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+
+	verification, err := c.service.VerifyExport(req.Context(), actor, exportID)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+	writeJSON(res, http.StatusOK, verification)
+}
+
+// handleManifest returns the signed provenance manifest with its verification result.
+func (c *Controller) handleManifest(req Request, res Response, exportID string) {
+	if c.service == nil {
+		WriteError(res, export.NewError(export.KindNotImpl, "export service not configured", nil))
+		return
+	}
+	actor, err := c.actorFromRequest(req)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+
+	verification, err := c.service.VerifyManifest(req.Context(), actor, exportID)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+	writeJSON(res, http.StatusOK, verification)
+}
+
+// recordDownload audits a download; failures are logged and never block the client.
+func (c *Controller) recordDownload(req Request, actor export.Actor, exportID string, method export.DownloadMethod, size int64) {
+	err := c.service.RecordDownload(req.Context(), actor, export.DownloadRecord{
+		ExportID:     exportID,
+		IP:           remoteIP(req),
+		ForwardedFor: req.Header("X-Forwarded-For"),
+		UserAgent:    req.Header("User-Agent"),
+		Method:       method,
+		Bytes:        size,
+	})
+	if err != nil {
+		c.logger.Warn("download audit failed", "error", err, "export_id", exportID)
 	}
 }
 
@@ -650,7 +819,7 @@ func (c *Controller) handleDelete(req Request, res Response, exportID string) {
 	}
 
 	switch record.State {
-	case export.StateQueued, export.StateRunning:
+	case export.StatePendingApproval, export.StateQueued, export.StateRunning:
 		if _, err := c.service.CancelExport(req.Context(), actor, exportID); err != nil {
 			WriteError(res, err)
 			return
@@ -665,6 +834,44 @@ func (c *Controller) handleDelete(req Request, res Response, exportID string) {
 	res.WriteHeader(http.StatusNoContent)
 }
 
+// decisionPayload is the optional JSON body for approve/reject requests.
+type decisionPayload struct {
+	Comment string `json:"comment"`
+}
+
+func (c *Controller) handleDecision(req Request, res Response, exportID string, decision export.ApprovalDecision) {
+	if c.service == nil {
+		WriteError(res, export.NewError(export.KindNotImpl, "export service not configured", nil))
+		return
+	}
+	actor, err := c.actorFromRequest(req)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+
+	payload := decisionPayload{}
+	if body := req.Body(); body != nil {
+		defer body.Close()
+		if err := json.NewDecoder(body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+			WriteError(res, export.NewError(export.KindValidation, "invalid decision payload", err))
+			return
+		}
+	}
+
+	var record export.ExportRecord
+	if decision == export.ApprovalApproved {
+		record, err = c.service.ApproveExport(req.Context(), actor, exportID, payload.Comment)
+	} else {
+		record, err = c.service.RejectExport(req.Context(), actor, exportID, payload.Comment)
+	}
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+	writeJSON(res, http.StatusOK, record)
+}
+
 func (c *Controller) previewMetadata(ctx context.Context, actor export.Actor, record export.ExportRecord) (export.DownloadInfo, error) {
 	if record.State != export.StateCompleted {
 		if err := c.generatePreview(ctx, actor, record); err != nil {
@@ -715,7 +922,7 @@ func (c *Controller) actorFromRequest(req Request) (export.Actor, error) {
 	return actor, nil
 }
 
-func (c *Controller) resolve(ctx context.Context, req export.ExportRequest) (export.ResolvedExport, error) {
+func (c *Controller) resolve(ctx context.Context, actor export.Actor, req export.ExportRequest) (export.ResolvedExport, error) {
 	if c.runner == nil || c.runner.Definitions == nil {
 		return export.ResolvedExport{}, export.NewError(export.KindInternal, "definition registry not configured", nil)
 	}
@@ -737,7 +944,7 @@ func (c *Controller) resolve(ctx context.Context, req export.ExportRequest) (exp
 	if err != nil {
 		return export.ResolvedExport{}, err
 	}
-	return export.ResolveExport(req, def, now)
+	return export.ResolveExportForActor(req, def, actor, now)
 }
 
 func (c *Controller) statusURL(exportID string) string {
@@ -789,6 +996,10 @@ func WriteError(res Response, err error) {
 			Code:    ge.TextCode,
 		},
 	}
+	// Only authz denials explain themselves; other metadata can carry internals.
+	if ge.Category == errorslib.CategoryAuthz {
+		payload.Error.Metadata = ge.Metadata
+	}
 	writeJSON(res, status, payload)
 }
 
@@ -844,13 +1055,25 @@ func parseFilter(req Request) (export.ProgressFilter, error) {
 
 func isReusableState(state export.ExportState) bool {
 	switch state {
-	case export.StateQueued, export.StateRunning, export.StatePublishing, export.StateCompleted:
+	case export.StatePendingApproval, export.StateQueued, export.StateRunning, export.StatePublishing, export.StateCompleted:
 		return true
 	default:
 		return false
 	}
 }
 
+func remoteIP(req Request) string {
+	addrReq, ok := req.(RemoteAddrRequest)
+	if !ok {
+		return ""
+	}
+	addr := addrReq.RemoteAddr()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
 func sanitizeFilename(filename string, format export.Format) string {
 	name := strings.TrimSpace(filename)
 	name = strings.ReplaceAll(name, "\"", "")
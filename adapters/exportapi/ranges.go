@@ -0,0 +1,116 @@
+package exportapi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goliatone/go-export/export"
+)
+
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// byteRange is an inclusive byte range resolved against an artifact size.
+type byteRange struct {
+	start int64
+	end   int64
+}
+
+func (r byteRange) length() int64 {
+	return r.end - r.start + 1
+}
+
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.end, size)
+}
+
+// parseRange resolves a single "bytes=" range against size. It reports ok=false
+// when the header should be ignored (malformed or multi-range) and the full
+// artifact served, and errRangeNotSatisfiable when no byte of the range exists.
+func parseRange(header string, size int64) (byteRange, bool, error) {
+	header = strings.TrimSpace(header)
+	if !strings.HasPrefix(header, "bytes=") {
+		return byteRange{}, false, nil
+	}
+	spec := strings.TrimSpace(strings.TrimPrefix(header, "bytes="))
+	if spec == "" || strings.Contains(spec, ",") {
+		return byteRange{}, false, nil
+	}
+	first, last, found := strings.Cut(spec, "-")
+	if !found {
+		return byteRange{}, false, nil
+	}
+	first = strings.TrimSpace(first)
+	last = strings.TrimSpace(last)
+
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return byteRange{}, false, nil
+		}
+		if suffix == 0 || size == 0 {
+			return byteRange{}, false, errRangeNotSatisfiable
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return byteRange{start: size - suffix, end: size - 1}, true, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return byteRange{}, false, nil
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return byteRange{}, false, nil
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+	if start >= size {
+		return byteRange{}, false, errRangeNotSatisfiable
+	}
+	return byteRange{start: start, end: end}, true, nil
+}
+
+// artifactETag derives a strong ETag from the stored checksum.
+func artifactETag(meta export.ArtifactMeta) string {
+	if meta.Checksum == "" {
+		return ""
+	}
+	return `"` + meta.Checksum + `"`
+}
+
+// etagMatchesAny applies the weak comparison used by If-None-Match.
+func etagMatchesAny(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "" || etag == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifRangeAllows reports whether a Range header may be honored. If-Range only
+// matches strong ETags; dates are not tracked, so they always fall back to a
+// full response.
+func ifRangeAllows(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return true
+	}
+	return etag != "" && header == etag
+}
@@ -0,0 +1,59 @@
+package exportapi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		header  string
+		size    int64
+		want    byteRange
+		ok      bool
+		unsatis bool
+	}{
+		{header: "bytes=0-4", size: 10, want: byteRange{0, 4}, ok: true},
+		{header: "bytes=5-", size: 10, want: byteRange{5, 9}, ok: true},
+		{header: "bytes=-3", size: 10, want: byteRange{7, 9}, ok: true},
+		{header: "bytes=-30", size: 10, want: byteRange{0, 9}, ok: true},
+		{header: "bytes=8-20", size: 10, want: byteRange{8, 9}, ok: true},
+		{header: "bytes=10-", size: 10, unsatis: true},
+		{header: "bytes=-0", size: 10, unsatis: true},
+		{header: "bytes=0-1,4-5", size: 10},
+		{header: "bytes=5-2", size: 10},
+		{header: "items=0-1", size: 10},
+		{header: "bytes=abc", size: 10},
+	}
+	for _, tc := range cases {
+		got, ok, err := parseRange(tc.header, tc.size)
+		if tc.unsatis {
+			if !errors.Is(err, errRangeNotSatisfiable) {
+				t.Fatalf("%s: expected unsatisfiable, got %v", tc.header, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tc.header, err)
+		}
+		if ok != tc.ok || got != tc.want {
+			t.Fatalf("%s: expected %+v/%v, got %+v/%v", tc.header, tc.want, tc.ok, got, ok)
+		}
+	}
+}
+
+func TestETagMatching(t *testing.T) {
+	etag := `"abc"`
+	if !etagMatchesAny(`"x", W/"abc"`, etag) || !etagMatchesAny("*", etag) {
+		t.Fatalf("expected If-None-Match to match")
+	}
+	if etagMatchesAny(`"x"`, etag) || etagMatchesAny(`"abc"`, "") {
+		t.Fatalf("expected If-None-Match to miss")
+	}
+	if !ifRangeAllows("", etag) || !ifRangeAllows(`"abc"`, etag) {
+		t.Fatalf("expected If-Range to allow")
+	}
+	if ifRangeAllows(`W/"abc"`, etag) || ifRangeAllows("Wed, 21 Oct 2015 07:28:00 GMT", etag) {
+		t.Fatalf("expected If-Range to reject weak tags and dates")
+	}
+}
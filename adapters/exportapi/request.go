@@ -21,6 +21,11 @@ type Request interface {
 	Body() io.ReadCloser
 }
 
+// RemoteAddrRequest is implemented by requests that expose the client address.
+type RemoteAddrRequest interface {
+	RemoteAddr() string
+}
+
 // RequestDecoder parses an HTTP request into an export request.
 type RequestDecoder interface {
 	Decode(req Request) (export.ExportRequest, error)
@@ -3,6 +3,7 @@ package exportapi
 import (
 	"context"
 	"io"
+	"net/http"
 )
 
 // DownloadPayload describes a file download response.
@@ -22,6 +23,11 @@ type StreamOptions struct {
 	ExportID       string
 	ContentLength  int64
 	MaxBufferBytes int64
+	Status         int
+	ETag           string
+	ContentRange   string
+	AcceptRanges   string
+	Digest         string
 }
 
 // StreamOption applies stream options.
@@ -55,6 +61,49 @@ func WithMaxBufferBytes(max int64) StreamOption {
 	}
 }
 
+// WithStatus overrides the success status (e.g. 206 for partial content).
+func WithStatus(status int) StreamOption {
+	return func(opts *StreamOptions) {
+		opts.Status = status
+	}
+}
+
+// WithETag sets the ETag response header.
+func WithETag(etag string) StreamOption {
+	return func(opts *StreamOptions) {
+		opts.ETag = etag
+	}
+}
+
+// WithContentRange sets the Content-Range header for partial responses.
+func WithContentRange(contentRange string) StreamOption {
+	return func(opts *StreamOptions) {
+		opts.ContentRange = contentRange
+	}
+}
+
+// WithAcceptRanges advertises range support (usually "bytes").
+func WithAcceptRanges(unit string) StreamOption {
+	return func(opts *StreamOptions) {
+		opts.AcceptRanges = unit
+	}
+}
+
+// WithDigest sets the Digest header (e.g. "SHA-256=<base64>").
+func WithDigest(digest string) StreamOption {
+	return func(opts *StreamOptions) {
+		opts.Digest = digest
+	}
+}
+
+// StatusCode returns the configured success status, defaulting to 200.
+func (o StreamOptions) StatusCode() int {
+	if o.Status == 0 {
+		return http.StatusOK
+	}
+	return o.Status
+}
+
 // ResolveStreamOptions applies stream options.
 func ResolveStreamOptions(opts ...StreamOption) StreamOptions {
 	resolved := StreamOptions{}
@@ -99,6 +148,7 @@ type ErrorResponse struct {
 
 // ErrorBody contains error details.
 type ErrorBody struct {
-	Message string `json:"message"`
-	Code    string `json:"code,omitempty"`
+	Message  string         `json:"message"`
+	Code     string         `json:"code,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
@@ -0,0 +1,196 @@
+package exportapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/goliatone/go-export/export"
+)
+
+// schedulesSegment is the path segment under the base path for schedule
+// endpoints: GET/POST <base>/schedules and GET/POST/PUT/DELETE
+// <base>/schedules/:id. POST on an ID updates, for routers without PUT.
+const schedulesSegment = "schedules"
+
+func (c *Controller) serveSchedules(req Request, res Response, parts []string) {
+	switch {
+	case len(parts) == 0:
+		switch req.Method() {
+		case http.MethodGet:
+			c.handleListSchedules(req, res)
+		case http.MethodPost:
+			c.handleCreateSchedule(req, res)
+		default:
+			res.SetHeader("Allow", "GET,POST")
+			res.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	case len(parts) == 1:
+		switch req.Method() {
+		case http.MethodGet:
+			c.handleGetSchedule(req, res, parts[0])
+		case http.MethodPost, http.MethodPut:
+			c.handleUpdateSchedule(req, res, parts[0])
+		case http.MethodDelete:
+			c.handleDeleteSchedule(req, res, parts[0])
+		default:
+			res.SetHeader("Allow", "GET,POST,PUT,DELETE")
+			res.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	default:
+		writeNotFound(res)
+	}
+}
+
+func (c *Controller) handleListSchedules(req Request, res Response) {
+	if c.schedules == nil {
+		WriteError(res, export.NewError(export.KindNotImpl, "schedule service not configured", nil))
+		return
+	}
+	actor, err := c.actorFromRequest(req)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+
+	filter, err := parseScheduleFilter(req)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+
+	schedules, err := c.schedules.ListSchedules(req.Context(), actor, filter)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+	writeJSON(res, http.StatusOK, schedules)
+}
+
+func (c *Controller) handleCreateSchedule(req Request, res Response) {
+	if c.schedules == nil {
+		WriteError(res, export.NewError(export.KindNotImpl, "schedule service not configured", nil))
+		return
+	}
+	actor, err := c.actorFromRequest(req)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+
+	payload, err := decodeSchedule(req)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+
+	schedule, err := c.schedules.CreateSchedule(req.Context(), actor, payload)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+	writeJSON(res, http.StatusCreated, schedule)
+}
+
+func (c *Controller) handleGetSchedule(req Request, res Response, scheduleID string) {
+	if c.schedules == nil {
+		WriteError(res, export.NewError(export.KindNotImpl, "schedule service not configured", nil))
+		return
+	}
+	actor, err := c.actorFromRequest(req)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+
+	schedule, err := c.schedules.GetSchedule(req.Context(), actor, scheduleID)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+	writeJSON(res, http.StatusOK, schedule)
+}
+
+func (c *Controller) handleUpdateSchedule(req Request, res Response, scheduleID string) {
+	if c.schedules == nil {
+		WriteError(res, export.NewError(export.KindNotImpl, "schedule service not configured", nil))
+		return
+	}
+	actor, err := c.actorFromRequest(req)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+
+	payload, err := decodeSchedule(req)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+	payload.ID = scheduleID
+
+	schedule, err := c.schedules.UpdateSchedule(req.Context(), actor, payload)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+	writeJSON(res, http.StatusOK, schedule)
+}
+
+func (c *Controller) handleDeleteSchedule(req Request, res Response, scheduleID string) {
+	if c.schedules == nil {
+		WriteError(res, export.NewError(export.KindNotImpl, "schedule service not configured", nil))
+		return
+	}
+	actor, err := c.actorFromRequest(req)
+	if err != nil {
+		WriteError(res, err)
+		return
+	}
+
+	if err := c.schedules.DeleteSchedule(req.Context(), actor, scheduleID); err != nil {
+		WriteError(res, err)
+		return
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
+
+func decodeSchedule(req Request) (export.Schedule, error) {
+	var schedule export.Schedule
+	body := req.Body()
+	if body == nil {
+		return schedule, export.NewError(export.KindValidation, "schedule payload is required", nil)
+	}
+	defer body.Close()
+	if err := json.NewDecoder(body).Decode(&schedule); err != nil {
+		if errors.Is(err, io.EOF) {
+			return schedule, export.NewError(export.KindValidation, "schedule payload is required", nil)
+		}
+		return schedule, export.NewError(export.KindValidation, "invalid schedule payload", err)
+	}
+	return schedule, nil
+}
+
+func parseScheduleFilter(req Request) (export.ScheduleFilter, error) {
+	filter := export.ScheduleFilter{
+		Kind:    export.ScheduleKind(req.Query("kind")),
+		OwnerID: req.Query("owner_id"),
+	}
+	if raw := req.Query("enabled"); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return export.ScheduleFilter{}, export.NewError(export.KindValidation, "invalid enabled filter", err)
+		}
+		filter.Enabled = &enabled
+	}
+	if raw := req.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			return export.ScheduleFilter{}, export.NewError(export.KindValidation, "invalid limit", err)
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
@@ -0,0 +1,2 @@
+// Package exportguard provides a declarative, policy-driven export.Guard.
+package exportguard
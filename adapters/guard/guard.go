@@ -0,0 +1,298 @@
+package exportguard
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/goliatone/go-export/export"
+)
+
+// RecordLookup resolves export records for download checks.
+type RecordLookup interface {
+	Status(ctx context.Context, id string) (export.ExportRecord, error)
+}
+
+// ShareStore reports explicit export shares.
+type ShareStore interface {
+	IsShared(ctx context.Context, exportID string, actor export.Actor) (bool, error)
+}
+
+// Config configures the policy guard.
+type Config struct {
+	Policy  Policy
+	Records RecordLookup
+	Shares  ShareStore
+}
+
+// Guard evaluates a declarative Policy.
+type Guard struct {
+	policy  Policy
+	records RecordLookup
+	shares  ShareStore
+}
+
+var (
+	_ export.Guard         = (*Guard)(nil)
+	_ export.AdminGuard    = (*Guard)(nil)
+	_ export.RowLimitGuard = (*Guard)(nil)
+)
+
+// NewGuard creates a policy guard.
+func NewGuard(cfg Config) *Guard {
+	return &Guard{
+		policy:  cfg.Policy,
+		records: cfg.Records,
+		shares:  cfg.Shares,
+	}
+}
+
+// AuthorizeExport allows the request when an allow rule matches and no deny rule does.
+func (g *Guard) AuthorizeExport(ctx context.Context, actor export.Actor, req export.ExportRequest, def export.ResolvedDefinition) error {
+	_ = ctx
+	if g == nil {
+		return export.NewError(export.KindInternal, "guard is nil", nil)
+	}
+	_, err := g.evaluate(actor, req, def)
+	return err
+}
+
+// RowLimit returns the largest MaxRows among the allow rules matching the
+// request, or 0 when any of them is unlimited. The runner enforces it while
+// rows stream, so a missing or low EstimatedRows cannot exceed the rule.
+func (g *Guard) RowLimit(ctx context.Context, actor export.Actor, req export.ExportRequest, def export.ResolvedDefinition) int {
+	_ = ctx
+	if g == nil {
+		return 0
+	}
+	limit, _ := g.evaluate(actor, req, def)
+	return limit
+}
+
+// evaluate matches the policy rules and returns the row limit of the allow
+// rules that matched.
+func (g *Guard) evaluate(actor export.Actor, req export.ExportRequest, def export.ResolvedDefinition) (int, error) {
+	reasons := make([]string, 0)
+	allowedBy := ""
+	limit := 0
+	for idx, rule := range g.policy.Rules {
+		name := ruleName(rule, idx)
+		if reason := matchActor(rule, actor); reason != "" {
+			reasons = append(reasons, fmt.Sprintf("rule %s: %s", name, reason))
+			continue
+		}
+		if reason := matchRequest(rule, req, def); reason != "" {
+			reasons = append(reasons, fmt.Sprintf("rule %s: %s", name, reason))
+			continue
+		}
+		if rule.Effect == EffectDeny {
+			return 0, denial("export denied by policy", []string{fmt.Sprintf("rule %s: denies request", name)}, map[string]any{
+				"rule": ruleLabel(rule, idx),
+			})
+		}
+		if rule.MaxRows > 0 && req.EstimatedRows > rule.MaxRows {
+			reasons = append(reasons, fmt.Sprintf("rule %s: estimated rows %d exceed max rows %d", name, req.EstimatedRows, rule.MaxRows))
+			continue
+		}
+		if allowedBy == "" {
+			allowedBy = name
+			limit = rule.MaxRows
+		} else if limit > 0 && (rule.MaxRows == 0 || rule.MaxRows > limit) {
+			limit = rule.MaxRows
+		}
+	}
+	if allowedBy != "" {
+		return limit, nil
+	}
+	if len(g.policy.Rules) == 0 {
+		reasons = append(reasons, "no rules configured")
+	}
+	return 0, denial("export denied by policy", reasons, map[string]any{
+		"definition": def.Name,
+		"format":     req.Format,
+	})
+}
+
+// AuthorizeDownload allows admins, owners, same-tenant/workspace actors, or explicit shares.
+func (g *Guard) AuthorizeDownload(ctx context.Context, actor export.Actor, exportID string) error {
+	if g == nil {
+		return export.NewError(export.KindInternal, "guard is nil", nil)
+	}
+	download := g.policy.Download
+	if len(download.AdminRoles) > 0 && hasAnyRole(actor.Roles, download.AdminRoles) {
+		return nil
+	}
+	if g.records == nil {
+		return export.NewError(export.KindNotImpl, "record lookup not configured", nil)
+	}
+	record, err := g.records.Status(ctx, exportID)
+	if err != nil {
+		return err
+	}
+
+	reasons := make([]string, 0, 4)
+	if download.AllowOwner {
+		if actor.ID != "" && actor.ID == record.RequestedBy.ID {
+			return nil
+		}
+		reasons = append(reasons, "actor is not the export owner")
+	}
+	if download.AllowTenant {
+		if actor.Scope.TenantID != "" && actor.Scope.TenantID == record.Scope.TenantID {
+			return nil
+		}
+		reasons = append(reasons, "actor tenant does not match export tenant")
+	}
+	if download.AllowWorkspace {
+		if actor.Scope.WorkspaceID != "" && actor.Scope.WorkspaceID == record.Scope.WorkspaceID {
+			return nil
+		}
+		reasons = append(reasons, "actor workspace does not match export workspace")
+	}
+	if download.AllowShared && g.shares != nil {
+		shared, err := g.shares.IsShared(ctx, exportID, actor)
+		if err != nil {
+			return err
+		}
+		if shared {
+			return nil
+		}
+		reasons = append(reasons, "export is not shared with actor")
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "no download rules configured")
+	}
+	return denial("download denied by policy", reasons, map[string]any{
+		"export_id": exportID,
+	})
+}
+
+// AuthorizeAdmin allows actors holding one of the download admin roles to
+// access other actors' download logs and schedules.
+func (g *Guard) AuthorizeAdmin(ctx context.Context, actor export.Actor, resource string) error {
+	_ = ctx
+	if g == nil {
+		return export.NewError(export.KindInternal, "guard is nil", nil)
+	}
+	roles := g.policy.Download.AdminRoles
+	if len(roles) > 0 && hasAnyRole(actor.Roles, roles) {
+		return nil
+	}
+	return denial("admin access denied by policy", []string{
+		fmt.Sprintf("requires one of roles [%s]", strings.Join(roles, ", ")),
+	}, map[string]any{
+		"resource": resource,
+	})
+}
+
+func matchActor(rule Rule, actor export.Actor) string {
+	if len(rule.Roles) > 0 && !slices.Contains(rule.Roles, Wildcard) && !hasAnyRole(actor.Roles, rule.Roles) {
+		return fmt.Sprintf("requires one of roles [%s]", strings.Join(rule.Roles, ", "))
+	}
+	if !matchValue(rule.Tenants, actor.Scope.TenantID) {
+		return fmt.Sprintf("tenant %q not permitted", actor.Scope.TenantID)
+	}
+	for key, expected := range rule.Attributes {
+		value, ok := actor.Details[key]
+		if !ok || fmt.Sprint(value) != expected {
+			return fmt.Sprintf("attribute %q must equal %q", key, expected)
+		}
+	}
+	return ""
+}
+
+func matchRequest(rule Rule, req export.ExportRequest, def export.ResolvedDefinition) string {
+	if !matchValue(rule.Definitions, def.Name) {
+		return fmt.Sprintf("definition %q not permitted", def.Name)
+	}
+	if !matchValue(formatsToStrings(rule.Formats), string(req.Format)) {
+		return fmt.Sprintf("format %q not permitted", req.Format)
+	}
+	if !matchValue(rule.Variants, def.Variant) {
+		return fmt.Sprintf("variant %q not permitted", def.Variant)
+	}
+	if !matchValue(deliveryToStrings(rule.Delivery), string(req.Delivery)) {
+		return fmt.Sprintf("delivery %q not permitted", req.Delivery)
+	}
+	return ""
+}
+
+func matchValue(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	return slices.Contains(allowed, Wildcard) || slices.Contains(allowed, value)
+}
+
+func hasAnyRole(roles []string, required []string) bool {
+	for _, role := range roles {
+		if slices.Contains(required, role) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatsToStrings(formats []export.Format) []string {
+	out := make([]string, 0, len(formats))
+	for _, format := range formats {
+		out = append(out, string(format))
+	}
+	return out
+}
+
+func deliveryToStrings(modes []export.DeliveryMode) []string {
+	out := make([]string, 0, len(modes))
+	for _, mode := range modes {
+		out = append(out, string(mode))
+	}
+	return out
+}
+
+func denial(msg string, reasons []string, meta map[string]any) error {
+	meta["reasons"] = reasons
+	return export.NewError(export.KindAuthz, msg, nil).WithMetadata(meta)
+}
+
+// MemoryShareStore records explicit shares in memory (test/dev only).
+type MemoryShareStore struct {
+	mu     sync.RWMutex
+	shares map[string]map[string]struct{}
+}
+
+// NewMemoryShareStore creates an in-memory share store.
+func NewMemoryShareStore() *MemoryShareStore {
+	return &MemoryShareStore{shares: make(map[string]map[string]struct{})}
+}
+
+// Share grants an actor access to an export.
+func (s *MemoryShareStore) Share(ctx context.Context, exportID, actorID string) error {
+	_ = ctx
+	if s == nil {
+		return export.NewError(export.KindInternal, "share store is nil", nil)
+	}
+	if exportID == "" || actorID == "" {
+		return export.NewError(export.KindValidation, "export ID and actor ID are required", nil)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shares[exportID] == nil {
+		s.shares[exportID] = make(map[string]struct{})
+	}
+	s.shares[exportID][actorID] = struct{}{}
+	return nil
+}
+
+// IsShared reports whether the export is shared with the actor.
+func (s *MemoryShareStore) IsShared(ctx context.Context, exportID string, actor export.Actor) (bool, error) {
+	_ = ctx
+	if s == nil {
+		return false, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.shares[exportID][actor.ID]
+	return ok, nil
+}
@@ -0,0 +1,174 @@
+package exportguard
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/goliatone/go-export/export"
+)
+
+const testPolicyYAML = `
+rules:
+  - name: support-csv
+    roles: [support]
+    definitions: [users]
+    formats: [csv]
+    max_rows: 100
+  - name: analysts
+    roles: [analyst]
+    definitions: ["*"]
+    delivery: [async]
+  - name: no-pdf
+    effect: deny
+    roles: ["*"]
+    formats: [pdf]
+download:
+  allow_owner: true
+  allow_tenant: true
+  allow_shared: true
+  admin_roles: [admin]
+`
+
+func TestParsePolicy_JSONAndYAML(t *testing.T) {
+	fromYAML, err := ParsePolicy([]byte(testPolicyYAML))
+	if err != nil {
+		t.Fatalf("parse yaml: %v", err)
+	}
+	if len(fromYAML.Rules) != 3 || fromYAML.Rules[0].MaxRows != 100 {
+		t.Fatalf("unexpected yaml policy: %+v", fromYAML)
+	}
+	fromJSON, err := ParsePolicy([]byte(`{"rules":[{"name":"all","roles":["*"]}],"download":{"allow_owner":true}}`))
+	if err != nil {
+		t.Fatalf("parse json: %v", err)
+	}
+	if len(fromJSON.Rules) != 1 || !fromJSON.Download.AllowOwner {
+		t.Fatalf("unexpected json policy: %+v", fromJSON)
+	}
+	if _, err := ParsePolicy([]byte(`{"rules":[{"effect":"maybe"}]}`)); err == nil {
+		t.Fatalf("expected invalid effect error")
+	}
+}
+
+func TestGuard_AuthorizeExport(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicyYAML))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	guard := NewGuard(Config{Policy: policy})
+	ctx := context.Background()
+	users := export.ResolvedDefinition{ExportDefinition: export.ExportDefinition{Name: "users"}}
+	support := export.Actor{ID: "s1", Roles: []string{"support"}}
+
+	if err := guard.AuthorizeExport(ctx, support, export.ExportRequest{Format: export.FormatCSV, EstimatedRows: 10}, users); err != nil {
+		t.Fatalf("expected support csv allowed, got %v", err)
+	}
+	if err := guard.AuthorizeExport(ctx, support, export.ExportRequest{Format: export.FormatCSV, EstimatedRows: 500}, users); err == nil {
+		t.Fatalf("expected max rows denial")
+	}
+	if err := guard.AuthorizeExport(ctx, support, export.ExportRequest{Format: export.FormatXLSX}, users); err == nil {
+		t.Fatalf("expected format denial")
+	}
+	if limit := guard.RowLimit(ctx, support, export.ExportRequest{Format: export.FormatCSV}, users); limit != 100 {
+		t.Fatalf("expected support row limit 100, got %d", limit)
+	}
+
+	analyst := export.Actor{ID: "a1", Roles: []string{"analyst"}}
+	if err := guard.AuthorizeExport(ctx, analyst, export.ExportRequest{Format: export.FormatJSON, Delivery: export.DeliveryAsync}, users); err != nil {
+		t.Fatalf("expected analyst async allowed, got %v", err)
+	}
+	if limit := guard.RowLimit(ctx, analyst, export.ExportRequest{Format: export.FormatJSON, Delivery: export.DeliveryAsync}, users); limit != 0 {
+		t.Fatalf("expected analyst unlimited, got %d", limit)
+	}
+	err = guard.AuthorizeExport(ctx, analyst, export.ExportRequest{Format: export.FormatPDF, Delivery: export.DeliveryAsync}, users)
+	var exportErr *export.ExportError
+	if !errors.As(err, &exportErr) || exportErr.Kind != export.KindAuthz {
+		t.Fatalf("expected authz denial, got %v", err)
+	}
+	if exportErr.Metadata["rule"] != "no-pdf" {
+		t.Fatalf("expected deny rule in metadata, got %+v", exportErr.Metadata)
+	}
+}
+
+func TestGuard_UnnamedDenyRuleLabelsMetadata(t *testing.T) {
+	guard := NewGuard(Config{Policy: Policy{Rules: []Rule{
+		{Name: "everyone", Roles: []string{Wildcard}},
+		{Effect: EffectDeny, Formats: []export.Format{export.FormatPDF}},
+	}}})
+	err := guard.AuthorizeExport(context.Background(), export.Actor{ID: "a1"}, export.ExportRequest{Format: export.FormatPDF}, export.ResolvedDefinition{ExportDefinition: export.ExportDefinition{Name: "users"}})
+	var exportErr *export.ExportError
+	if !errors.As(err, &exportErr) || exportErr.Metadata["rule"] != "#1" {
+		t.Fatalf("expected unnamed deny rule labelled by index, got %v", err)
+	}
+}
+
+func TestGuard_DenialReasonsSurfaceInGoError(t *testing.T) {
+	guard := NewGuard(Config{Policy: Policy{Rules: []Rule{{Name: "admins", Roles: []string{"admin"}}}}})
+	err := guard.AuthorizeExport(context.Background(), export.Actor{Roles: []string{"viewer"}}, export.ExportRequest{Format: export.FormatCSV}, export.ResolvedDefinition{ExportDefinition: export.ExportDefinition{Name: "users"}})
+	mapped := export.AsGoError(export.NewError(export.KindAuthz, "export not authorized", err))
+	if mapped.TextCode != "authz" {
+		t.Fatalf("expected authz code, got %s", mapped.TextCode)
+	}
+	reasons, ok := mapped.Metadata["reasons"].([]string)
+	if !ok || len(reasons) != 1 || reasons[0] != `rule "admins": requires one of roles [admin]` {
+		t.Fatalf("unexpected reasons: %#v", mapped.Metadata["reasons"])
+	}
+}
+
+func TestGuard_AuthorizeDownload(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicyYAML))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	ctx := context.Background()
+	tracker := export.NewMemoryTracker()
+	_, _ = tracker.Start(ctx, export.ExportRecord{
+		ID:          "exp-1",
+		RequestedBy: export.Actor{ID: "owner"},
+		Scope:       export.Scope{TenantID: "t1"},
+	})
+	shares := NewMemoryShareStore()
+	guard := NewGuard(Config{Policy: policy, Records: tracker, Shares: shares})
+
+	cases := []struct {
+		name    string
+		actor   export.Actor
+		allowed bool
+	}{
+		{"owner", export.Actor{ID: "owner"}, true},
+		{"tenant", export.Actor{ID: "peer", Scope: export.Scope{TenantID: "t1"}}, true},
+		{"admin", export.Actor{ID: "root", Roles: []string{"admin"}}, true},
+		{"stranger", export.Actor{ID: "guest", Scope: export.Scope{TenantID: "t2"}}, false},
+	}
+	for _, tc := range cases {
+		err := guard.AuthorizeDownload(ctx, tc.actor, "exp-1")
+		if tc.allowed && err != nil {
+			t.Fatalf("%s: expected allowed, got %v", tc.name, err)
+		}
+		if !tc.allowed && err == nil {
+			t.Fatalf("%s: expected denial", tc.name)
+		}
+	}
+
+	if err := shares.Share(ctx, "exp-1", "guest"); err != nil {
+		t.Fatalf("share: %v", err)
+	}
+	if err := guard.AuthorizeDownload(ctx, export.Actor{ID: "guest", Scope: export.Scope{TenantID: "t2"}}, "exp-1"); err != nil {
+		t.Fatalf("expected shared access, got %v", err)
+	}
+}
+
+func TestGuard_AuthorizeAdmin(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicyYAML))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	guard := NewGuard(Config{Policy: policy})
+	ctx := context.Background()
+	if err := guard.AuthorizeAdmin(ctx, export.Actor{ID: "root", Roles: []string{"admin"}}, export.AdminSchedules); err != nil {
+		t.Fatalf("expected admin allowed, got %v", err)
+	}
+	if err := guard.AuthorizeAdmin(ctx, export.Actor{ID: "owner"}, export.AdminSchedules); err == nil {
+		t.Fatalf("expected non-admin denied")
+	}
+}
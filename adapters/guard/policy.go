@@ -0,0 +1,119 @@
+package exportguard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goliatone/go-export/export"
+	"gopkg.in/yaml.v3"
+)
+
+// Effect is the outcome of a matching rule.
+type Effect string
+
+const (
+	EffectAllow Effect = "allow"
+	EffectDeny  Effect = "deny"
+)
+
+// Wildcard matches any value in rule lists.
+const Wildcard = "*"
+
+// Policy declares export and download authorization rules.
+type Policy struct {
+	Rules    []Rule         `json:"rules" yaml:"rules"`
+	Download DownloadPolicy `json:"download" yaml:"download"`
+}
+
+// Rule grants or denies exports for matching actors and requests.
+// Empty lists match everything; deny rules take precedence over allow rules.
+type Rule struct {
+	Name        string                `json:"name" yaml:"name"`
+	Effect      Effect                `json:"effect,omitempty" yaml:"effect,omitempty"`
+	Roles       []string              `json:"roles,omitempty" yaml:"roles,omitempty"`
+	Tenants     []string              `json:"tenants,omitempty" yaml:"tenants,omitempty"`
+	Attributes  map[string]string     `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	Definitions []string              `json:"definitions,omitempty" yaml:"definitions,omitempty"`
+	Formats     []export.Format       `json:"formats,omitempty" yaml:"formats,omitempty"`
+	Variants    []string              `json:"variants,omitempty" yaml:"variants,omitempty"`
+	Delivery    []export.DeliveryMode `json:"delivery,omitempty" yaml:"delivery,omitempty"`
+	MaxRows     int                   `json:"max_rows,omitempty" yaml:"max_rows,omitempty"`
+}
+
+// DownloadPolicy controls who may access an existing export.
+type DownloadPolicy struct {
+	AllowOwner     bool     `json:"allow_owner" yaml:"allow_owner"`
+	AllowTenant    bool     `json:"allow_tenant" yaml:"allow_tenant"`
+	AllowWorkspace bool     `json:"allow_workspace" yaml:"allow_workspace"`
+	AllowShared    bool     `json:"allow_shared" yaml:"allow_shared"`
+	AdminRoles     []string `json:"admin_roles,omitempty" yaml:"admin_roles,omitempty"`
+}
+
+// ParsePolicy decodes a JSON or YAML policy document.
+func ParsePolicy(data []byte) (Policy, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return Policy{}, export.NewError(export.KindValidation, "policy document is empty", nil)
+	}
+	var policy Policy
+	if trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &policy); err != nil {
+			return Policy{}, export.NewError(export.KindValidation, "invalid policy json", err)
+		}
+	} else if err := yaml.Unmarshal(trimmed, &policy); err != nil {
+		return Policy{}, export.NewError(export.KindValidation, "invalid policy yaml", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return Policy{}, err
+	}
+	return policy, nil
+}
+
+// LoadPolicyFile reads a policy from a .json, .yaml, or .yml file.
+func LoadPolicyFile(path string) (Policy, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+	default:
+		return Policy{}, export.NewError(export.KindValidation, fmt.Sprintf("unsupported policy file %q", path), nil)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, export.NewError(export.KindInternal, "read policy file", err)
+	}
+	return ParsePolicy(data)
+}
+
+// Validate checks rule effects and limits.
+func (p Policy) Validate() error {
+	for idx, rule := range p.Rules {
+		switch rule.Effect {
+		case "", EffectAllow, EffectDeny:
+		default:
+			return export.NewError(export.KindValidation, fmt.Sprintf("rule %s has unsupported effect %q", ruleName(rule, idx), rule.Effect), nil)
+		}
+		if rule.MaxRows < 0 {
+			return export.NewError(export.KindValidation, fmt.Sprintf("rule %s has negative max_rows", ruleName(rule, idx)), nil)
+		}
+	}
+	return nil
+}
+
+func ruleName(rule Rule, idx int) string {
+	if rule.Name != "" {
+		return fmt.Sprintf("%q", rule.Name)
+	}
+	return ruleLabel(rule, idx)
+}
+
+// ruleLabel identifies a rule in denial metadata: its name, or "#idx" when
+// the rule is unnamed.
+func ruleLabel(rule Rule, idx int) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+	return fmt.Sprintf("#%d", idx)
+}
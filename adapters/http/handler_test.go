@@ -81,6 +81,21 @@ func (g *captureGuard) AuthorizeDownload(ctx context.Context, actor export.Actor
 	return nil
 }
 
+// adminGuard allows exports, downloads, and admin access.
+type adminGuard struct{}
+
+func (adminGuard) AuthorizeExport(ctx context.Context, actor export.Actor, req export.ExportRequest, def export.ResolvedDefinition) error {
+	return nil
+}
+
+func (adminGuard) AuthorizeDownload(ctx context.Context, actor export.Actor, exportID string) error {
+	return nil
+}
+
+func (adminGuard) AuthorizeAdmin(ctx context.Context, actor export.Actor, resource string) error {
+	return nil
+}
+
 func newTestRunner(t *testing.T) *export.Runner {
 	t.Helper()
 	runner := export.NewRunner()
@@ -303,6 +318,171 @@ func TestHandler_DownloadGuardRejects(t *testing.T) {
 	}
 }
 
+func TestHandler_DownloadAuditTrail(t *testing.T) {
+	runner := newTestRunner(t)
+	emitter := &recordingEmitter{}
+	runner.Emitter = emitter
+	tracker := export.NewMemoryTracker()
+	store := export.NewMemoryStore()
+	svc := export.NewService(export.ServiceConfig{
+		Runner:      runner,
+		Tracker:     tracker,
+		Store:       store,
+		Guard:       adminGuard{},
+		DownloadLog: export.NewMemoryDownloadLog(),
+	})
+
+	ref, err := store.Put(context.Background(), "exports/exp-audit.csv", bytes.NewBufferString("id,name\n1,alice\n"), export.ArtifactMeta{
+		Filename:    "users.csv",
+		ContentType: "text/csv",
+	})
+	if err != nil {
+		t.Fatalf("store put: %v", err)
+	}
+	if _, err := tracker.Start(context.Background(), export.ExportRecord{
+		ID:         "exp-audit",
+		Definition: "users",
+		Format:     export.FormatCSV,
+		State:      export.StateCompleted,
+		Artifact:   ref,
+	}); err != nil {
+		t.Fatalf("tracker start: %v", err)
+	}
+
+	handler := NewHandler(Config{
+		Service:       svc,
+		Store:         store,
+		ActorProvider: StaticActorProvider{Actor: export.Actor{ID: "user-1"}},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/exports/exp-audit/download", nil)
+	req.RemoteAddr = "203.0.113.7:5555"
+	req.Header.Set("User-Agent", "audit-test/1.0")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/exports/exp-audit/downloads", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var downloads []export.DownloadRecord
+	if err := json.Unmarshal(rec.Body.Bytes(), &downloads); err != nil {
+		t.Fatalf("decode downloads: %v", err)
+	}
+	if len(downloads) != 1 {
+		t.Fatalf("expected 1 download, got %d", len(downloads))
+	}
+	got := downloads[0]
+	if got.Actor.ID != "user-1" || got.IP != "203.0.113.7" || got.UserAgent != "audit-test/1.0" || got.Method != export.DownloadStream {
+		t.Fatalf("unexpected download record: %+v", got)
+	}
+
+	var found bool
+	for _, evt := range emitter.events {
+		if evt.Name == "export.downloaded" && evt.ExportID == "exp-audit" && evt.Metadata["ip"] == "203.0.113.7" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected export.downloaded event, got %+v", emitter.events)
+	}
+}
+
+func TestHandler_DownloadRangeAndConditional(t *testing.T) {
+	runner := newTestRunner(t)
+	tracker := export.NewMemoryTracker()
+	store := export.NewMemoryStore()
+	svc := export.NewService(export.ServiceConfig{
+		Runner:  runner,
+		Tracker: tracker,
+		Store:   store,
+	})
+
+	ref, err := store.Put(context.Background(), "exports/exp-range.csv", bytes.NewBufferString("0123456789"), export.ArtifactMeta{
+		Filename:    "users.csv",
+		ContentType: "text/csv",
+	})
+	if err != nil {
+		t.Fatalf("store put: %v", err)
+	}
+	if _, err := tracker.Start(context.Background(), export.ExportRecord{
+		ID:         "exp-range",
+		Definition: "users",
+		Format:     export.FormatCSV,
+		State:      export.StateCompleted,
+		Artifact:   ref,
+	}); err != nil {
+		t.Fatalf("tracker start: %v", err)
+	}
+
+	handler := NewHandler(Config{
+		Service:       svc,
+		Store:         store,
+		ActorProvider: StaticActorProvider{Actor: export.Actor{ID: "user-1"}},
+	})
+	download := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/exports/exp-range/download", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := download(nil)
+	etag := rec.Header().Get("ETag")
+	if digest := rec.Header().Get("Digest"); digest != export.DigestHeaderValue(ref.Meta.Checksum) || digest == "" {
+		t.Fatalf("expected Digest header, got %q", digest)
+	}
+	if rec.Code != http.StatusOK || etag != `"`+ref.Meta.Checksum+`"` || rec.Header().Get("Accept-Ranges") != "bytes" {
+		t.Fatalf("expected full download with etag, got %d %v", rec.Code, rec.Header())
+	}
+
+	rec = download(map[string]string{"Range": "bytes=2-5"})
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("expected 206, got %d", rec.Code)
+	}
+	if rec.Body.String() != "2345" || rec.Header().Get("Content-Range") != "bytes 2-5/10" || rec.Header().Get("Content-Length") != "4" {
+		t.Fatalf("unexpected partial response %q %v", rec.Body.String(), rec.Header())
+	}
+
+	rec = download(map[string]string{"Range": "bytes=7-", "If-Range": etag})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "789" {
+		t.Fatalf("expected If-Range match to resume, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = download(map[string]string{"Range": "bytes=7-", "If-Range": `"stale"`})
+	if rec.Code != http.StatusOK || rec.Body.String() != "0123456789" {
+		t.Fatalf("expected stale If-Range to return full body, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = download(map[string]string{"Range": "bytes=20-"})
+	if rec.Code != http.StatusRequestedRangeNotSatisfiable || rec.Header().Get("Content-Range") != "bytes */10" {
+		t.Fatalf("expected 416, got %d %v", rec.Code, rec.Header())
+	}
+
+	rec = download(map[string]string{"If-None-Match": etag})
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("expected 304, got %d", rec.Code)
+	}
+}
+
+type recordingEmitter struct {
+	events []export.ChangeEvent
+}
+
+func (e *recordingEmitter) Emit(ctx context.Context, evt export.ChangeEvent) error {
+	_ = ctx
+	e.events = append(e.events, evt)
+	return nil
+}
+
 func TestHandler_GetExportUsesQueryDecoder(t *testing.T) {
 	runner := newTestRunner(t)
 	handler := NewHandler(Config{
@@ -444,3 +624,59 @@ func TestHandler_ResourceResolverMissing(t *testing.T) {
 		t.Fatalf("expected 400, got %d", rec.Code)
 	}
 }
+
+func TestHandler_ScheduleCRUD(t *testing.T) {
+	schedules := export.NewScheduleService(export.ScheduleServiceConfig{Store: export.NewMemoryScheduleStore()})
+	handler := NewHandler(Config{
+		ActorProvider: StaticActorProvider{Actor: export.Actor{ID: "user-1", Scope: export.Scope{TenantID: "t1"}}},
+		Schedules:     schedules,
+	})
+
+	body := `{"name":"weekly","kind":"export","cron":"0 6 * * 1","timezone":"Europe/Berlin","enabled":true,"export":{"Definition":"users","Format":"csv"}}`
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/exports/schedules", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created export.Schedule
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if created.ID == "" || created.Owner.ID != "user-1" || created.NextRunAt.IsZero() {
+		t.Fatalf("unexpected schedule: %+v", created)
+	}
+
+	update := `{"name":"weekly","kind":"export","cron":"0 7 * * 1","enabled":false,"export":{"Definition":"users","Format":"csv"}}`
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/exports/schedules/"+created.ID, strings.NewReader(update)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 on update, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/exports/schedules?enabled=false", nil))
+	var listed []export.Schedule
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Cron != "0 7 * * 1" || listed[0].Enabled {
+		t.Fatalf("unexpected list: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/exports/schedules", strings.NewReader(`{"kind":"export","cron":"61 * * * *","export":{"Definition":"users"}}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid cron, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/admin/exports/schedules/"+created.ID, nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/exports/schedules/"+created.ID, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", rec.Code)
+	}
+}
@@ -65,6 +65,13 @@ func (req httpRequest) Body() io.ReadCloser {
 	return req.r.Body
 }
 
+func (req httpRequest) RemoteAddr() string {
+	if req.r == nil {
+		return ""
+	}
+	return req.r.RemoteAddr
+}
+
 type httpResponse struct {
 	w   http.ResponseWriter
 	req *http.Request
@@ -172,7 +179,7 @@ func writeHTTPStream(w http.ResponseWriter, contentType string, r io.Reader, opt
 		n, err := r.Read(buf)
 		if n > 0 {
 			applyDownloadHeaders(w, contentType, opts)
-			w.WriteHeader(http.StatusOK)
+			w.WriteHeader(opts.StatusCode())
 			if _, writeErr := w.Write(buf[:n]); writeErr != nil {
 				return writeErr
 			}
@@ -185,7 +192,7 @@ func writeHTTPStream(w http.ResponseWriter, contentType string, r io.Reader, opt
 		if err != nil {
 			if err == io.EOF {
 				applyDownloadHeaders(w, contentType, opts)
-				w.WriteHeader(http.StatusOK)
+				w.WriteHeader(opts.StatusCode())
 				return nil
 			}
 			return err
@@ -207,6 +214,19 @@ func applyDownloadHeaders(w http.ResponseWriter, contentType string, opts export
 	if opts.ContentLength > 0 {
 		w.Header().Set("Content-Length", fmt.Sprintf("%d", opts.ContentLength))
 	}
+	if opts.ETag != "" {
+		w.Header().Set("ETag", opts.ETag)
+	}
+	if opts.ContentRange != "" {
+		w.Header().Set("Content-Range", opts.ContentRange)
+	}
+	if opts.AcceptRanges != "" {
+		w.Header().Set("Accept-Ranges", opts.AcceptRanges)
+	}
+	if opts.Digest != "" {
+		w.Header().Set("Digest", opts.Digest)
+	}
 }
 
 var _ exportapi.Response = httpResponse{}
+var _ exportapi.RemoteAddrRequest = httpRequest{}
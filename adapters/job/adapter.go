@@ -88,6 +88,10 @@ func (s *Scheduler) RequestExport(ctx context.Context, actor export.Actor, req e
 	if result.Reused {
 		return result.Record, nil
 	}
+	if result.Pending {
+		s.storeIdempotency(ctx, result.Signature, result.Record.ID)
+		return result.Record, nil
+	}
 	if result.Message == nil {
 		return result.Record, export.NewError(export.KindValidation, "execution message is required", nil)
 	}
@@ -104,22 +108,72 @@ func (s *Scheduler) RequestExport(ctx context.Context, actor export.Actor, req e
 		return result.Record, err
 	}
 
-	if result.Signature != "" {
-		if err := s.builder.StoreIdempotency(ctx, result.Signature, result.Record.ID); err != nil {
-			s.logger.Error("idempotency store set failed",
-				"error", err,
-				"export_id", result.Record.ID,
-				"signature", result.Signature,
-			)
+	s.storeIdempotency(ctx, result.Signature, result.Record.ID)
+	return result.Record, nil
+}
+
+// ExportApproved enqueues generation for an approved export; use it as the
+// service ApprovalHook.
+func (s *Scheduler) ExportApproved(ctx context.Context, record export.ExportRecord) error {
+	if s == nil {
+		return export.NewError(export.KindInternal, "scheduler is nil", nil)
+	}
+	if s.enqueuer == nil {
+		return export.NewError(export.KindNotImpl, "job enqueuer not configured", nil)
+	}
+	msg, err := s.builder.BuildApproved(ctx, record)
+	if err != nil {
+		return err
+	}
+	if err := s.enqueuer.Enqueue(ctx, msg); err != nil {
+		if s.tracker != nil {
+			if ferr := s.tracker.Fail(ctx, record.ID, err, map[string]any{"stage": "enqueue"}); ferr != nil {
+				s.logger.Error("enqueue failure tracking failed",
+					"error", ferr,
+					"export_id", record.ID,
+				)
+			}
 		}
+		return err
 	}
+	return nil
+}
 
-	return result.Record, nil
+var _ export.StaleRequeuer = (*Scheduler)(nil)
+
+// RequeueExport re-enqueues generation for a stale export; use it as the
+// export.StaleReaper Requeuer. Enqueue failures are returned so the reaper
+// can fail the export instead.
+func (s *Scheduler) RequeueExport(ctx context.Context, record export.ExportRecord) error {
+	if s == nil {
+		return export.NewError(export.KindInternal, "scheduler is nil", nil)
+	}
+	if s.enqueuer == nil {
+		return export.NewError(export.KindNotImpl, "job enqueuer not configured", nil)
+	}
+	msg, err := s.builder.BuildApproved(ctx, record)
+	if err != nil {
+		return err
+	}
+	return s.enqueuer.Enqueue(ctx, msg)
+}
+
+func (s *Scheduler) storeIdempotency(ctx context.Context, signature, exportID string) {
+	if signature == "" {
+		return
+	}
+	if err := s.builder.StoreIdempotency(ctx, signature, exportID); err != nil {
+		s.logger.Error("idempotency store set failed",
+			"error", err,
+			"export_id", exportID,
+			"signature", signature,
+		)
+	}
 }
 
 func isReusableState(state export.ExportState) bool {
 	switch state {
-	case export.StateQueued, export.StateRunning, export.StatePublishing, export.StateCompleted:
+	case export.StatePendingApproval, export.StateQueued, export.StateRunning, export.StatePublishing, export.StateCompleted:
 		return true
 	default:
 		return false
@@ -236,6 +236,70 @@ func TestScheduler_RequestExport_Idempotency(t *testing.T) {
 	}
 }
 
+func TestScheduler_ApprovalDefersEnqueue(t *testing.T) {
+	runner := setupRunner(t, &stubSource{rows: []export.Row{{"1", "alice"}}})
+	if err := runner.Definitions.Register(export.ExportDefinition{
+		Name:         "sensitive",
+		RowSourceKey: "stub",
+		Schema:       export.Schema{Columns: []export.Column{{Name: "id"}, {Name: "name"}}},
+		Approval:     &export.ApprovalPolicy{Always: true},
+	}); err != nil {
+		t.Fatalf("register definition: %v", err)
+	}
+	tracker := export.NewMemoryTracker()
+	store := export.NewMemoryStore()
+
+	var scheduler *Scheduler
+	svc := export.NewService(export.ServiceConfig{
+		Runner:  runner,
+		Tracker: tracker,
+		Store:   store,
+		ApprovalHook: export.ApprovalHookFunc(func(ctx context.Context, record export.ExportRecord) error {
+			return scheduler.ExportApproved(ctx, record)
+		}),
+	})
+
+	sub := dispatcher.SubscribeCommand(exportcmd.NewGenerateExportHandler(svc))
+	defer sub.Unsubscribe()
+
+	task := NewGenerateTask(TaskConfig{Store: store})
+	cmd := job.NewTaskCommander(task)
+	var enqueueCalls int
+	scheduler = NewScheduler(Config{
+		Service: svc,
+		Enqueuer: EnqueuerFunc(func(ctx context.Context, msg *job.ExecutionMessage) error {
+			enqueueCalls++
+			return cmd.Execute(ctx, msg)
+		}),
+		Tracker: tracker,
+	})
+
+	record, err := scheduler.RequestExport(context.Background(), export.Actor{ID: "actor-1"}, export.ExportRequest{
+		Definition: "sensitive",
+		Format:     export.FormatCSV,
+	})
+	if err != nil {
+		t.Fatalf("request export: %v", err)
+	}
+	if record.State != export.StatePendingApproval || enqueueCalls != 0 {
+		t.Fatalf("expected pending export without enqueue, got %s (%d enqueues)", record.State, enqueueCalls)
+	}
+
+	if _, err := svc.ApproveExport(context.Background(), export.Actor{ID: "approver-1"}, record.ID, ""); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if enqueueCalls != 1 {
+		t.Fatalf("expected enqueue after approval, got %d", enqueueCalls)
+	}
+	status, err := svc.Status(context.Background(), export.Actor{ID: "actor-1"}, record.ID)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.State != export.StateCompleted {
+		t.Fatalf("expected completed state, got %s", status.State)
+	}
+}
+
 func TestScheduler_CancelExportStopsJob(t *testing.T) {
 	started := make(chan struct{})
 	runner := setupRunner(t, &blockingSource{started: started})
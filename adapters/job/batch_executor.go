@@ -21,7 +21,7 @@ func NewBatchExecutor(task *GenerateTask, builder *MessageBuilder) exportcmd.Bat
 		if err != nil {
 			return result.Record, err
 		}
-		if result.Reused {
+		if result.Reused || result.Pending {
 			return result.Record, nil
 		}
 		if result.Message == nil {
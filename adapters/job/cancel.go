@@ -7,7 +7,9 @@ import (
 	"github.com/goliatone/go-export/export"
 )
 
-// CancelRegistry tracks running export jobs for cancellation.
+// CancelRegistry tracks running export jobs for cancellation in this
+// process. Cancels issued on other nodes reach the worker through the
+// runner's tracker polling (export.Runner.CancelPollInterval).
 type CancelRegistry struct {
 	mu      sync.Mutex
 	cancels map[string]context.CancelFunc
@@ -41,6 +41,8 @@ type BuildResult struct {
 	Message   *job.ExecutionMessage
 	Signature string
 	Reused    bool
+	// Pending reports that the export awaits approval and must not be enqueued yet.
+	Pending bool
 }
 
 // NewMessageBuilder creates a new MessageBuilder.
@@ -109,7 +111,35 @@ func (b *MessageBuilder) Build(ctx context.Context, actor export.Actor, req expo
 	if err != nil {
 		return BuildResult{}, err
 	}
+	if record.State == export.StatePendingApproval {
+		return BuildResult{Record: record, Signature: signature, Pending: true}, nil
+	}
 
+	msg, err := b.message(ctx, record, actor, asyncReq, signature)
+	if err != nil {
+		return BuildResult{Record: record, Signature: signature}, err
+	}
+	return BuildResult{Record: record, Message: msg, Signature: signature}, nil
+}
+
+// BuildApproved prepares an execution message for an export that has been approved.
+func (b *MessageBuilder) BuildApproved(ctx context.Context, record export.ExportRecord) (*job.ExecutionMessage, error) {
+	if b == nil {
+		return nil, export.NewError(export.KindInternal, "message builder is nil", nil)
+	}
+	if record.ID == "" {
+		return nil, export.NewError(export.KindValidation, "export ID is required", nil)
+	}
+	if record.Request.Definition == "" {
+		return nil, export.NewError(export.KindValidation, "export request is required", nil)
+	}
+	req := record.Request
+	req.Delivery = export.DeliveryAsync
+	req.Output = nil
+	return b.message(ctx, record, record.RequestedBy, req, "")
+}
+
+func (b *MessageBuilder) message(ctx context.Context, record export.ExportRecord, actor export.Actor, asyncReq export.ExportRequest, signature string) (*job.ExecutionMessage, error) {
 	payload := Payload{
 		ExportID: record.ID,
 		Actor:    actor,
@@ -128,7 +158,7 @@ func (b *MessageBuilder) Build(ctx context.Context, actor export.Actor, req expo
 				)
 			}
 		}
-		return BuildResult{Record: record, Signature: signature}, err
+		return nil, err
 	}
 
 	msg := &job.ExecutionMessage{
@@ -142,8 +172,7 @@ func (b *MessageBuilder) Build(ctx context.Context, actor export.Actor, req expo
 		msg.IdempotencyKey = signature
 		msg.DedupPolicy = job.DedupPolicyMerge
 	}
-
-	return BuildResult{Record: record, Message: msg, Signature: signature}, nil
+	return msg, nil
 }
 
 // BuildMessage returns an execution message or signals a no-op when the request was reused.
@@ -152,7 +181,7 @@ func (b *MessageBuilder) BuildMessage(ctx context.Context, actor export.Actor, r
 	if err != nil {
 		return nil, err
 	}
-	if result.Reused {
+	if result.Reused || result.Pending {
 		return nil, errExecutionSkipped
 	}
 	if result.Message == nil {
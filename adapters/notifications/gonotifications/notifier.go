@@ -38,8 +38,33 @@ func (n *Notifier) Send(ctx context.Context, evt notify.ExportReadyEvent) error
 		Parts:            evt.Parts,
 		ManifestURL:      evt.ManifestURL,
 		Message:          evt.Message,
-		ChannelOverrides: evt.ChannelOverrides,
+		ChannelOverrides: overridesWithChecksum(evt),
 	}
 
 	return n.delegate.Send(ctx, payload)
 }
+
+// overridesWithChecksum exposes the artifact checksum to every channel template,
+// since OnReadyEvent has no dedicated field for it.
+func overridesWithChecksum(evt notify.ExportReadyEvent) map[string]map[string]any {
+	if evt.Checksum == "" {
+		return evt.ChannelOverrides
+	}
+	overrides := make(map[string]map[string]any, len(evt.ChannelOverrides)+len(evt.Channels))
+	for channel, values := range evt.ChannelOverrides {
+		copied := make(map[string]any, len(values)+1)
+		for key, value := range values {
+			copied[key] = value
+		}
+		overrides[channel] = copied
+	}
+	for _, channel := range evt.Channels {
+		if _, ok := overrides[channel]; !ok {
+			overrides[channel] = map[string]any{}
+		}
+	}
+	for _, values := range overrides {
+		values["checksum"] = evt.Checksum
+	}
+	return overrides
+}
@@ -51,3 +51,26 @@ func TestNotifier_SendMapsFields(t *testing.T) {
 		t.Fatalf("expected tenant tenant-1, got %s", capture.event.TenantID)
 	}
 }
+
+func TestNotifier_SendForwardsChecksum(t *testing.T) {
+	capture := &captureNotifier{}
+	notifier := NewNotifier(capture)
+
+	overrides := map[string]map[string]any{"email": {"cta_label": "Download"}}
+	err := notifier.Send(context.Background(), notify.ExportReadyEvent{
+		Channels:         []string{"email", "inbox"},
+		Checksum:         "abc123",
+		ChannelOverrides: overrides,
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	got := capture.event.ChannelOverrides
+	if got["email"]["checksum"] != "abc123" || got["email"]["cta_label"] != "Download" || got["inbox"]["checksum"] != "abc123" {
+		t.Fatalf("expected checksum on every channel, got %v", got)
+	}
+	if _, ok := overrides["email"]["checksum"]; ok {
+		t.Fatalf("expected caller overrides to stay untouched")
+	}
+}
@@ -63,8 +63,13 @@ func (r Renderer) Render(ctx context.Context, schema export.Schema, rows export.
 		return export.RenderStats{}, err
 	}
 
+	html := buffer.Bytes()
+	if opts.Watermark.Active() {
+		html = export.InjectWatermarkHTML(html, opts.Watermark.Stamp, opts.Watermark.PDFVisible)
+	}
+
 	pdf, err := r.Engine.Render(ctx, RenderRequest{
-		HTML:    buffer.Bytes(),
+		HTML:    html,
 		Options: opts,
 	})
 	if err != nil {
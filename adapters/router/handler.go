@@ -43,9 +43,20 @@ func (h *Handler) RegisterRoutes(router any) {
 	if aliasesRequired {
 		r.Get(base+"/", h.Handle)
 	}
+	r.Get(base+"/schedules", h.Handle)
+	r.Post(base+"/schedules", h.Handle)
+	r.Get(base+"/schedules/:id", h.Handle)
+	r.Post(base+"/schedules/:id", h.Handle)
+	r.Delete(base+"/schedules/:id", h.Handle)
 	r.Get(base+"/:id", h.Handle)
 	r.Get(base+"/:id/download", h.Handle)
 	r.Get(base+"/:id/preview", h.Handle)
+	r.Get(base+"/:id/downloads", h.Handle)
+	r.Get(base+"/:id/deliveries", h.Handle)
+	r.Get(base+"/:id/manifest", h.Handle)
+	r.Post(base+"/:id/approve", h.Handle)
+	r.Post(base+"/:id/reject", h.Handle)
+	r.Post(base+"/:id/verify", h.Handle)
 	r.Delete(base+"/:id", h.Handle)
 	if history != "" {
 		r.Get(history, h.Handle)
@@ -10,6 +10,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"slices"
 	"strconv"
 	"strings"
@@ -148,7 +149,7 @@ func assertErrorParity(t *testing.T, rec *httptest.ResponseRecorder, routerRec *
 	if err := json.NewDecoder(bytes.NewReader(routerRec.Body.Bytes())).Decode(&routerPayload); err != nil {
 		t.Fatalf("decode router response: %v", err)
 	}
-	if httpPayload != routerPayload {
+	if !reflect.DeepEqual(httpPayload, routerPayload) {
 		t.Fatalf("payload mismatch: http=%+v router=%+v", httpPayload, routerPayload)
 	}
 }
@@ -249,7 +250,7 @@ func TestTransportParity_AsyncExport(t *testing.T) {
 	if rec.Code != routerCtx.recorder.Code {
 		t.Fatalf("status mismatch: http=%d router=%d", rec.Code, routerCtx.recorder.Code)
 	}
-	if httpPayload != routerPayload {
+	if !reflect.DeepEqual(httpPayload, routerPayload) {
 		t.Fatalf("payload mismatch: http=%+v router=%+v", httpPayload, routerPayload)
 	}
 	if rec.Header().Get("Content-Type") != routerCtx.recorder.Header().Get("Content-Type") {
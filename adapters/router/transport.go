@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 	"strings"
 
@@ -15,6 +14,7 @@ import (
 )
 
 var _ exportapi.Response = routerResponse{}
+var _ exportapi.RemoteAddrRequest = routerRequest{}
 
 type routerRequest struct {
 	ctx router.Context
@@ -82,6 +82,13 @@ func (req routerRequest) Body() io.ReadCloser {
 	return io.NopCloser(bytes.NewReader(req.ctx.Body()))
 }
 
+func (req routerRequest) RemoteAddr() string {
+	if req.ctx == nil {
+		return ""
+	}
+	return req.ctx.IP()
+}
+
 type routerResponse struct {
 	ctx router.Context
 }
@@ -184,7 +191,7 @@ func (res routerResponse) WriteStream(ctx context.Context, contentType string, r
 		return err
 	}
 	res.applyDownloadHeaders(contentType, options)
-	res.ctx.Status(http.StatusOK)
+	res.ctx.Status(options.StatusCode())
 	return res.ctx.Send(data)
 }
 
@@ -197,7 +204,7 @@ func (res routerResponse) writeStreamToWriter(w io.Writer, contentType string, r
 		n, err := r.Read(buf)
 		if n > 0 {
 			res.applyDownloadHeaders(contentType, opts)
-			res.ctx.Status(http.StatusOK)
+			res.ctx.Status(opts.StatusCode())
 			if _, writeErr := w.Write(buf[:n]); writeErr != nil {
 				return writeErr
 			}
@@ -210,7 +217,7 @@ func (res routerResponse) writeStreamToWriter(w io.Writer, contentType string, r
 		if err != nil {
 			if err == io.EOF {
 				res.applyDownloadHeaders(contentType, opts)
-				res.ctx.Status(http.StatusOK)
+				res.ctx.Status(opts.StatusCode())
 				return nil
 			}
 			return err
@@ -232,6 +239,18 @@ func (res routerResponse) applyDownloadHeaders(contentType string, opts exportap
 	if opts.ContentLength > 0 {
 		res.ctx.SetHeader("Content-Length", fmt.Sprintf("%d", opts.ContentLength))
 	}
+	if opts.ETag != "" {
+		res.ctx.SetHeader("ETag", opts.ETag)
+	}
+	if opts.ContentRange != "" {
+		res.ctx.SetHeader("Content-Range", opts.ContentRange)
+	}
+	if opts.AcceptRanges != "" {
+		res.ctx.SetHeader("Accept-Ranges", opts.AcceptRanges)
+	}
+	if opts.Digest != "" {
+		res.ctx.SetHeader("Digest", opts.Digest)
+	}
 }
 
 func readAllWithLimit(r io.Reader, maxSize int64) ([]byte, error) {
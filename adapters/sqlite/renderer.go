@@ -73,6 +73,12 @@ func (r Renderer) Render(ctx context.Context, schema export.Schema, rows export.
 		_ = db.Close()
 		return stats, err
 	}
+	if opts.Watermark.Active() {
+		if err := writeWatermarkTable(ctx, db, opts.Watermark.Stamp); err != nil {
+			_ = db.Close()
+			return stats, err
+		}
+	}
 	if err := db.Close(); err != nil {
 		return stats, export.NewError(export.KindInternal, "sqlite close failed", err)
 	}
@@ -215,6 +221,34 @@ func writeSQLiteRows(ctx context.Context, db *sql.DB, spec tableSpec, rows expor
 	return stats, nil
 }
 
+// watermarkTableName holds leak-tracing metadata as key/value rows.
+const watermarkTableName = "_export_watermark"
+
+func writeWatermarkTable(ctx context.Context, db *sql.DB, watermark export.Watermark) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return export.NewError(export.KindInternal, "sqlite begin transaction failed", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	createSQL := fmt.Sprintf("CREATE TABLE %s (key TEXT PRIMARY KEY, value TEXT)", quoteIdentifier(watermarkTableName))
+	if _, err := tx.ExecContext(ctx, createSQL); err != nil {
+		return export.NewError(export.KindInternal, "sqlite create watermark table failed", err)
+	}
+	insertSQL := fmt.Sprintf("INSERT INTO %s (key, value) VALUES (?, ?)", quoteIdentifier(watermarkTableName))
+	for _, field := range watermark.Fields() {
+		if _, err := tx.ExecContext(ctx, insertSQL, field[0], field[1]); err != nil {
+			return export.NewError(export.KindInternal, "sqlite insert watermark failed", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return export.NewError(export.KindInternal, "sqlite commit failed", err)
+	}
+	return nil
+}
+
 func sqliteColumnType(colType string) string {
 	switch normalizeColumnType(colType) {
 	case "bool", "int":
@@ -0,0 +1,3 @@
+// Package storeencrypted provides an ArtifactStore decorator that envelope-encrypts
+// artifacts at rest with per-tenant keys.
+package storeencrypted
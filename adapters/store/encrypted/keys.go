@@ -0,0 +1,130 @@
+package storeencrypted
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+
+	"github.com/goliatone/go-export/export"
+)
+
+// KeyProvider wraps and unwraps per-artifact data keys with tenant keys.
+// WrapKey must use the tenant's current key; UnwrapKey must accept any key
+// that has not been retired so artifacts can be re-wrapped after rotation.
+type KeyProvider interface {
+	WrapKey(ctx context.Context, tenantID string, dataKey []byte) (keyID string, wrapped []byte, err error)
+	UnwrapKey(ctx context.Context, tenantID, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// LocalKeyProvider holds tenant key-encryption keys in memory and wraps data
+// keys with AES-GCM. It suits tests and deployments that load keys from config;
+// production setups typically implement KeyProvider over a KMS.
+type LocalKeyProvider struct {
+	mu     sync.RWMutex
+	keys   map[string]map[string][]byte
+	active map[string]string
+}
+
+// NewLocalKeyProvider creates an empty LocalKeyProvider.
+func NewLocalKeyProvider() *LocalKeyProvider {
+	return &LocalKeyProvider{
+		keys:   make(map[string]map[string][]byte),
+		active: make(map[string]string),
+	}
+}
+
+// AddKey registers a key-encryption key for a tenant and makes it the current
+// key. Previously added keys remain available for unwrapping.
+func (p *LocalKeyProvider) AddKey(tenantID, keyID string, kek []byte) error {
+	if p == nil {
+		return export.NewError(export.KindInternal, "key provider is nil", nil)
+	}
+	if keyID == "" {
+		return export.NewError(export.KindValidation, "key ID is required", nil)
+	}
+	switch len(kek) {
+	case 16, 24, 32:
+	default:
+		return export.NewError(export.KindValidation, "key must be 16, 24, or 32 bytes", nil)
+	}
+
+	p.mu.Lock()
+	if p.keys[tenantID] == nil {
+		p.keys[tenantID] = make(map[string][]byte)
+	}
+	p.keys[tenantID][keyID] = append([]byte(nil), kek...)
+	p.active[tenantID] = keyID
+	p.mu.Unlock()
+	return nil
+}
+
+// RemoveKey retires a tenant key. Artifacts still wrapped with it become unreadable.
+func (p *LocalKeyProvider) RemoveKey(tenantID, keyID string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	delete(p.keys[tenantID], keyID)
+	if p.active[tenantID] == keyID {
+		delete(p.active, tenantID)
+	}
+	p.mu.Unlock()
+}
+
+// WrapKey encrypts the data key with the tenant's current key.
+func (p *LocalKeyProvider) WrapKey(ctx context.Context, tenantID string, dataKey []byte) (string, []byte, error) {
+	_ = ctx
+	if p == nil {
+		return "", nil, export.NewError(export.KindInternal, "key provider is nil", nil)
+	}
+	p.mu.RLock()
+	keyID := p.active[tenantID]
+	kek := p.keys[tenantID][keyID]
+	p.mu.RUnlock()
+	if keyID == "" || kek == nil {
+		return "", nil, export.NewError(export.KindNotFound, fmt.Sprintf("no encryption key for tenant %q", tenantID), nil)
+	}
+
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return keyID, aead.Seal(nonce, nonce, dataKey, wrapAAD(tenantID, keyID)), nil
+}
+
+// UnwrapKey decrypts a data key wrapped with the given tenant key.
+func (p *LocalKeyProvider) UnwrapKey(ctx context.Context, tenantID, keyID string, wrapped []byte) ([]byte, error) {
+	_ = ctx
+	if p == nil {
+		return nil, export.NewError(export.KindInternal, "key provider is nil", nil)
+	}
+	p.mu.RLock()
+	kek := p.keys[tenantID][keyID]
+	p.mu.RUnlock()
+	if kek == nil {
+		return nil, export.NewError(export.KindNotFound, fmt.Sprintf("encryption key %q not found for tenant %q", keyID, tenantID), nil)
+	}
+
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, export.NewError(export.KindValidation, "wrapped key is invalid", nil)
+	}
+	nonce, sealed := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, wrapAAD(tenantID, keyID))
+	if err != nil {
+		return nil, export.NewError(export.KindValidation, "wrapped key is invalid", err)
+	}
+	return dataKey, nil
+}
+
+func wrapAAD(tenantID, keyID string) []byte {
+	return []byte(tenantID + "\x00" + keyID)
+}
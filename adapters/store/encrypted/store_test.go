@@ -0,0 +1,185 @@
+package storeencrypted
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	storefs "github.com/goliatone/go-export/adapters/store/fs"
+	"github.com/goliatone/go-export/export"
+)
+
+func newKeys(t *testing.T) *LocalKeyProvider {
+	t.Helper()
+	keys := NewLocalKeyProvider()
+	if err := keys.AddKey("t1", "k1", bytes.Repeat([]byte{1}, 32)); err != nil {
+		t.Fatalf("add key: %v", err)
+	}
+	return keys
+}
+
+func readAll(t *testing.T, store export.ArtifactStore, key string) ([]byte, export.ArtifactMeta) {
+	t.Helper()
+	reader, meta, err := store.Open(context.Background(), key)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return data, meta
+}
+
+func TestStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	bases := map[string]export.ArtifactStore{
+		"memory": export.NewMemoryStore(),
+		"fs":     storefs.NewStore(t.TempDir()),
+	}
+	for name, base := range bases {
+		t.Run(name, func(t *testing.T) {
+			store := NewStore(base, newKeys(t))
+			store.ChunkSize = 16
+
+			for _, plain := range []string{"", "short", strings.Repeat("0123456789abcdef", 4), strings.Repeat("x", 100)} {
+				ref, err := store.Put(ctx, "exports/a.csv", strings.NewReader(plain), export.ArtifactMeta{TenantID: "t1", Filename: "a.csv"})
+				if err != nil {
+					t.Fatalf("put: %v", err)
+				}
+				sum := sha256.Sum256([]byte(plain))
+				if ref.Meta.Size != int64(len(plain)) || ref.Meta.Checksum != hex.EncodeToString(sum[:]) {
+					t.Fatalf("expected plaintext meta, got %+v", ref.Meta)
+				}
+				if ref.Meta.Encryption == nil || ref.Meta.Encryption.KeyID != "k1" {
+					t.Fatalf("expected encryption meta, got %+v", ref.Meta.Encryption)
+				}
+
+				raw, _, err := base.Open(ctx, "exports/a.csv")
+				if err != nil {
+					t.Fatalf("open base: %v", err)
+				}
+				ciphertext, _ := io.ReadAll(raw)
+				_ = raw.Close()
+				if len(plain) > 0 && bytes.Contains(ciphertext, []byte(plain)) {
+					t.Fatalf("expected ciphertext at rest")
+				}
+
+				data, meta := readAll(t, store, "exports/a.csv")
+				if string(data) != plain {
+					t.Fatalf("expected %q, got %q", plain, data)
+				}
+				if meta.Size != int64(len(plain)) || meta.Filename != "a.csv" {
+					t.Fatalf("unexpected meta %+v", meta)
+				}
+			}
+		})
+	}
+}
+
+func TestStore_PutRejectsChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	base := export.NewMemoryStore()
+	store := NewStore(base, newKeys(t))
+
+	sum := sha256.Sum256([]byte("plain"))
+	ref, err := store.Put(ctx, "exports/a.csv", strings.NewReader("plain"), export.ArtifactMeta{TenantID: "t1", Checksum: hex.EncodeToString(sum[:])})
+	if err != nil {
+		t.Fatalf("put with matching checksum: %v", err)
+	}
+	if ref.Meta.Checksum != hex.EncodeToString(sum[:]) {
+		t.Fatalf("expected plaintext checksum, got %q", ref.Meta.Checksum)
+	}
+
+	_, err = store.Put(ctx, "exports/b.csv", strings.NewReader("tampered"), export.ArtifactMeta{TenantID: "t1", Checksum: hex.EncodeToString(sum[:])})
+	if export.KindFromError(err) != export.KindValidation {
+		t.Fatalf("expected checksum mismatch validation error, got %v", err)
+	}
+	if _, _, err := base.Open(ctx, "exports/b.csv"); err == nil {
+		t.Fatalf("expected mismatched ciphertext deleted")
+	}
+}
+
+func TestStore_DetectsTamperingAndWrongTenant(t *testing.T) {
+	ctx := context.Background()
+	base := export.NewMemoryStore()
+	keys := newKeys(t)
+	if err := keys.AddKey("t2", "k1", bytes.Repeat([]byte{2}, 32)); err != nil {
+		t.Fatalf("add key: %v", err)
+	}
+	store := NewStore(base, keys)
+	store.ChunkSize = 8
+
+	if _, err := store.Put(ctx, "a", strings.NewReader("sensitive payload"), export.ArtifactMeta{TenantID: "t1"}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	raw, meta, _ := base.Open(ctx, "a")
+	ciphertext, _ := io.ReadAll(raw)
+
+	corrupt := func(data []byte, meta export.ArtifactMeta) error {
+		// Tampering at rest bypasses the base store's checksum check.
+		meta.Checksum = ""
+		if _, err := base.Put(ctx, "b", bytes.NewReader(data), meta); err != nil {
+			t.Fatalf("put base: %v", err)
+		}
+		reader, _, err := store.Open(ctx, "b")
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		_, err = io.ReadAll(reader)
+		return err
+	}
+
+	flipped := append([]byte(nil), ciphertext...)
+	flipped[len(flipped)-1] ^= 0xff
+	if err := corrupt(flipped, meta); err == nil {
+		t.Fatalf("expected modified ciphertext to fail")
+	}
+	truncated := ciphertext[:headerSize+chunkOverhead+8]
+	if err := corrupt(truncated, meta); err == nil {
+		t.Fatalf("expected truncated ciphertext to fail")
+	}
+	otherTenant := meta
+	otherTenant.TenantID = "t2"
+	if err := corrupt(ciphertext, otherTenant); err == nil {
+		t.Fatalf("expected other tenant key to fail")
+	}
+}
+
+func TestStore_RewrapAfterRotation(t *testing.T) {
+	ctx := context.Background()
+	base := storefs.NewStore(t.TempDir())
+	keys := newKeys(t)
+	store := NewStore(base, keys)
+
+	if _, err := store.Put(ctx, "exports/r.csv", strings.NewReader("rotate me"), export.ArtifactMeta{TenantID: "t1"}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	changed, err := store.Rewrap(ctx, "exports/r.csv")
+	if err != nil || changed {
+		t.Fatalf("expected no-op rewrap, got %v %v", changed, err)
+	}
+
+	if err := keys.AddKey("t1", "k2", bytes.Repeat([]byte{3}, 32)); err != nil {
+		t.Fatalf("add key: %v", err)
+	}
+	changed, err = store.Rewrap(ctx, "exports/r.csv")
+	if err != nil || !changed {
+		t.Fatalf("expected rewrap, got %v %v", changed, err)
+	}
+	keys.RemoveKey("t1", "k1")
+
+	data, meta := readAll(t, store, "exports/r.csv")
+	if string(data) != "rotate me" {
+		t.Fatalf("unexpected content %q", data)
+	}
+	if meta.Encryption == nil || meta.Encryption.KeyID != "k2" {
+		t.Fatalf("expected k2, got %+v", meta.Encryption)
+	}
+}
@@ -0,0 +1,224 @@
+package storeencrypted
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/goliatone/go-export/export"
+)
+
+// Stream layout:
+//
+//	header: magic (4) | chunk size uint32 (4) | nonce prefix (7)
+//	chunk:  sealed length uint32 (4) | AES-GCM sealed chunk
+//
+// Chunk nonces are prefix | counter uint32 | final flag, so reordered,
+// dropped, or truncated chunks fail authentication.
+const (
+	streamMagic     = "GXE1"
+	noncePrefixSize = 7
+	headerSize      = len(streamMagic) + 4 + noncePrefixSize
+	lengthSize      = 4
+	tagSize         = 16
+	chunkOverhead   = lengthSize + tagSize
+	maxChunkSize    = 16 << 20
+)
+
+var errStreamCorrupt = export.NewError(export.KindValidation, "encrypted artifact stream is corrupt", nil)
+
+func newAEAD(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(prefix []byte, counter uint32, final bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], counter)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+func encodeHeader(chunkSize int, prefix []byte) []byte {
+	header := make([]byte, headerSize)
+	copy(header, streamMagic)
+	binary.BigEndian.PutUint32(header[len(streamMagic):], uint32(chunkSize))
+	copy(header[len(streamMagic)+4:], prefix)
+	return header
+}
+
+func readHeader(r io.Reader) (int, []byte, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, errStreamCorrupt
+	}
+	if string(header[:len(streamMagic)]) != streamMagic {
+		return 0, nil, errStreamCorrupt
+	}
+	chunkSize := int(binary.BigEndian.Uint32(header[len(streamMagic):]))
+	if chunkSize <= 0 || chunkSize > maxChunkSize {
+		return 0, nil, errStreamCorrupt
+	}
+	return chunkSize, header[len(streamMagic)+4:], nil
+}
+
+// plaintextSize derives the plaintext size from the stored ciphertext size.
+func plaintextSize(cipherSize int64, chunkSize int) int64 {
+	body := cipherSize - int64(headerSize)
+	if body <= 0 || chunkSize <= 0 {
+		return 0
+	}
+	stride := int64(chunkSize + chunkOverhead)
+	chunks := (body + stride - 1) / stride
+	size := body - chunks*chunkOverhead
+	if size < 0 {
+		return 0
+	}
+	return size
+}
+
+// encryptReader encrypts src into the chunked stream format as it is read.
+type encryptReader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	plain   []byte
+	pending []byte
+	counter uint32
+	done    bool
+}
+
+func newEncryptReader(src io.Reader, aead cipher.AEAD, prefix []byte, chunkSize int) *encryptReader {
+	return &encryptReader{
+		src:     bufio.NewReaderSize(src, chunkSize),
+		aead:    aead,
+		prefix:  prefix,
+		plain:   make([]byte, chunkSize),
+		pending: encodeHeader(chunkSize, prefix),
+	}
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.seal(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *encryptReader) seal() error {
+	n, err := io.ReadFull(r.src, r.plain)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	final := err != nil
+	if !final {
+		if _, peekErr := r.src.Peek(1); errors.Is(peekErr, io.EOF) {
+			final = true
+		} else if peekErr != nil {
+			return peekErr
+		}
+	}
+	if r.counter == math.MaxUint32 && !final {
+		return export.NewError(export.KindValidation, "artifact exceeds encrypted chunk limit", nil)
+	}
+
+	out := make([]byte, lengthSize, lengthSize+n+tagSize)
+	out = r.aead.Seal(out, chunkNonce(r.prefix, r.counter, final), r.plain[:n], nil)
+	binary.BigEndian.PutUint32(out, uint32(len(out)-lengthSize))
+	r.pending = out
+	r.counter++
+	r.done = final
+	return nil
+}
+
+// decryptReader authenticates and decrypts the chunked stream format.
+type decryptReader struct {
+	src       io.ReadCloser
+	aead      cipher.AEAD
+	prefix    []byte
+	chunkSize int
+	sealed    []byte
+	pending   []byte
+	counter   uint32
+	done      bool
+}
+
+func newDecryptReader(src io.ReadCloser, aead cipher.AEAD) (*decryptReader, error) {
+	chunkSize, prefix, err := readHeader(src)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		src:       src,
+		aead:      aead,
+		prefix:    prefix,
+		chunkSize: chunkSize,
+		sealed:    make([]byte, chunkSize+tagSize),
+	}, nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *decryptReader) Close() error {
+	return r.src.Close()
+}
+
+func (r *decryptReader) open() error {
+	var length [lengthSize]byte
+	if _, err := io.ReadFull(r.src, length[:]); err != nil {
+		return errStreamCorrupt
+	}
+	size := int(binary.BigEndian.Uint32(length[:]))
+	if size < tagSize || size > len(r.sealed) {
+		return errStreamCorrupt
+	}
+	sealed := r.sealed[:size]
+	if _, err := io.ReadFull(r.src, sealed); err != nil {
+		return errStreamCorrupt
+	}
+
+	plain, err := r.aead.Open(nil, chunkNonce(r.prefix, r.counter, false), sealed, nil)
+	if err != nil {
+		plain, err = r.aead.Open(nil, chunkNonce(r.prefix, r.counter, true), sealed, nil)
+		if err != nil {
+			return errStreamCorrupt
+		}
+		var extra [1]byte
+		if _, err := io.ReadFull(r.src, extra[:]); err == nil {
+			return errStreamCorrupt
+		}
+		r.done = true
+	}
+	r.pending = plain
+	r.counter++
+	return nil
+}
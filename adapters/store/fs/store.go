@@ -2,6 +2,8 @@ package storefs
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -40,7 +42,8 @@ func NewStore(root string) *Store {
 	return &Store{Root: root, Now: time.Now}
 }
 
-// Put stores an artifact on disk.
+// Put stores an artifact on disk. A checksum already set on meta is kept;
+// otherwise the stream is hashed with SHA-256 while it is written.
 func (s *Store) Put(ctx context.Context, key string, r io.Reader, meta export.ArtifactMeta) (export.ArtifactRef, error) {
 	_ = ctx
 	if s == nil {
@@ -72,10 +75,15 @@ func (s *Store) Put(ctx context.Context, key string, r io.Reader, meta export.Ar
 		_ = os.Remove(tmp.Name())
 	}()
 
-	size, err := io.Copy(tmp, r)
+	hasher := sha256.New()
+	size, err := io.Copy(tmp, io.TeeReader(r, hasher))
 	if err != nil {
 		return export.ArtifactRef{}, err
 	}
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+	if meta.Checksum != "" && meta.Checksum != checksum {
+		return export.ArtifactRef{}, export.NewError(export.KindValidation, "artifact checksum mismatch", nil)
+	}
 	if err := tmp.Sync(); err != nil {
 		return export.ArtifactRef{}, err
 	}
@@ -88,6 +96,7 @@ func (s *Store) Put(ctx context.Context, key string, r io.Reader, meta export.Ar
 	}
 
 	meta.Size = size
+	meta.Checksum = checksum
 	if meta.CreatedAt.IsZero() {
 		meta.CreatedAt = s.now()
 	}
@@ -144,6 +153,31 @@ func (s *Store) Open(ctx context.Context, key string) (io.ReadCloser, export.Art
 	return file, meta, nil
 }
 
+// OpenRange reads a byte range of an artifact from disk.
+func (s *Store) OpenRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, export.ArtifactMeta, error) {
+	reader, meta, err := s.Open(ctx, key)
+	if err != nil {
+		return nil, export.ArtifactMeta{}, err
+	}
+	file, ok := reader.(*os.File)
+	if !ok {
+		_ = reader.Close()
+		return nil, export.ArtifactMeta{}, export.NewError(export.KindInternal, "artifact is not seekable", nil)
+	}
+	if offset < 0 || offset > meta.Size {
+		_ = file.Close()
+		return nil, export.ArtifactMeta{}, export.NewError(export.KindValidation, "range offset out of bounds", nil)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, export.ArtifactMeta{}, err
+	}
+	if length < 0 {
+		return file, meta, nil
+	}
+	return rangeReader{Reader: io.LimitReader(file, length), Closer: file}, meta, nil
+}
+
 // Delete removes an artifact from disk.
 func (s *Store) Delete(ctx context.Context, key string) error {
 	_ = ctx
@@ -252,6 +286,11 @@ func (s *Store) now() time.Time {
 	return s.Now()
 }
 
+type rangeReader struct {
+	io.Reader
+	io.Closer
+}
+
 func metaPath(pathOnDisk string) string {
 	return pathOnDisk + ".meta.json"
 }
@@ -94,3 +94,58 @@ func TestStore_SignedURL(t *testing.T) {
 		t.Fatalf("unexpected signer key: %q", signer.input.Key)
 	}
 }
+
+func TestStore_OpenRangeAndChecksum(t *testing.T) {
+	root := t.TempDir()
+	store := NewStore(root)
+
+	ref, err := store.Put(context.Background(), "exports/range.csv", bytes.NewBufferString("0123456789"), export.ArtifactMeta{})
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	// sha256("0123456789")
+	if ref.Meta.Checksum != "84d89877f0d4041efb6bf91a16f0248f2fd573e6af05c19f96bedb9f882f7882" {
+		t.Fatalf("unexpected checksum %q", ref.Meta.Checksum)
+	}
+
+	// A checksum computed upstream must match the data.
+	if _, err := store.Put(context.Background(), "exports/copy.csv", bytes.NewBufferString("0123456789"), export.ArtifactMeta{Checksum: ref.Meta.Checksum}); err != nil {
+		t.Fatalf("put copy: %v", err)
+	}
+	if _, err := store.Put(context.Background(), "exports/tampered.csv", bytes.NewBufferString("9876543210"), export.ArtifactMeta{Checksum: ref.Meta.Checksum}); err == nil {
+		t.Fatalf("expected checksum mismatch error")
+	}
+	if _, _, err := store.Open(context.Background(), "exports/tampered.csv"); err == nil {
+		t.Fatalf("expected mismatched artifact not to be stored")
+	}
+
+	reader, meta, err := store.OpenRange(context.Background(), "exports/range.csv", 2, 3)
+	if err != nil {
+		t.Fatalf("open range: %v", err)
+	}
+	data, err := io.ReadAll(reader)
+	_ = reader.Close()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(data) != "234" {
+		t.Fatalf("expected range 234, got %q", string(data))
+	}
+	if meta.Size != 10 || meta.Checksum != ref.Meta.Checksum {
+		t.Fatalf("expected full artifact meta, got %+v", meta)
+	}
+
+	reader, _, err = store.OpenRange(context.Background(), "exports/range.csv", 7, -1)
+	if err != nil {
+		t.Fatalf("open tail: %v", err)
+	}
+	data, _ = io.ReadAll(reader)
+	_ = reader.Close()
+	if string(data) != "789" {
+		t.Fatalf("expected tail 789, got %q", string(data))
+	}
+
+	if _, _, err := store.OpenRange(context.Background(), "exports/range.csv", 11, 1); err == nil {
+		t.Fatalf("expected out of bounds error")
+	}
+}
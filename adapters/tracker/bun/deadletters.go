@@ -0,0 +1,234 @@
+package trackerbun
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+	"github.com/uptrace/bun"
+)
+
+// DeadLetterStore persists failed webhook deliveries in a Bun-backed database.
+type DeadLetterStore struct {
+	DB          *bun.DB
+	Now         func() time.Time
+	IDGenerator func() string
+}
+
+var _ export.WebhookDeadLetterStore = (*DeadLetterStore)(nil)
+
+// NewDeadLetterStore creates a Bun-backed webhook dead-letter store.
+func NewDeadLetterStore(db *bun.DB) *DeadLetterStore {
+	return &DeadLetterStore{DB: db, Now: time.Now, IDGenerator: defaultDeadLetterIDGenerator()}
+}
+
+// Save inserts or replaces a dead letter.
+func (s *DeadLetterStore) Save(ctx context.Context, letter export.WebhookDeadLetter) (export.WebhookDeadLetter, error) {
+	if s == nil || s.DB == nil {
+		return export.WebhookDeadLetter{}, export.NewError(export.KindNotImpl, "dead-letter database not configured", nil)
+	}
+	if letter.ID == "" {
+		letter.ID = s.nextID()
+	}
+	if letter.FailedAt.IsZero() {
+		letter.FailedAt = s.now()
+	}
+	if letter.CreatedAt.IsZero() {
+		letter.CreatedAt = letter.FailedAt
+	}
+
+	model, err := deadLetterModelFrom(letter)
+	if err != nil {
+		return export.WebhookDeadLetter{}, err
+	}
+	res, err := s.DB.NewUpdate().Model(&model).WherePK().
+		Column("attempts", "status_code", "error", "failed_at", "parked").
+		Exec(ctx)
+	if err != nil {
+		return export.WebhookDeadLetter{}, err
+	}
+	if affected, _ := res.RowsAffected(); affected > 0 {
+		return letter, nil
+	}
+	if _, err := s.DB.NewInsert().Model(&model).Exec(ctx); err != nil {
+		return export.WebhookDeadLetter{}, err
+	}
+	return letter, nil
+}
+
+// Get returns a dead letter by ID.
+func (s *DeadLetterStore) Get(ctx context.Context, id string) (export.WebhookDeadLetter, error) {
+	if s == nil || s.DB == nil {
+		return export.WebhookDeadLetter{}, export.NewError(export.KindNotImpl, "dead-letter database not configured", nil)
+	}
+	var model deadLetterModel
+	err := s.DB.NewSelect().Model(&model).Where("id = ?", id).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return export.WebhookDeadLetter{}, export.NewError(export.KindNotFound, fmt.Sprintf("dead letter %q not found", id), err)
+		}
+		return export.WebhookDeadLetter{}, err
+	}
+	return model.toDeadLetter()
+}
+
+// List returns unparked dead letters, oldest first. limit <= 0 returns all.
+func (s *DeadLetterStore) List(ctx context.Context, limit int) ([]export.WebhookDeadLetter, error) {
+	if s == nil || s.DB == nil {
+		return nil, export.NewError(export.KindNotImpl, "dead-letter database not configured", nil)
+	}
+	var models []deadLetterModel
+	query := s.DB.NewSelect().Model(&models).
+		Where("parked = ?", false).
+		Order("failed_at ASC", "id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	letters := make([]export.WebhookDeadLetter, 0, len(models))
+	for _, model := range models {
+		letter, err := model.toDeadLetter()
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	return letters, nil
+}
+
+// Delete removes a dead letter.
+func (s *DeadLetterStore) Delete(ctx context.Context, id string) error {
+	if s == nil || s.DB == nil {
+		return export.NewError(export.KindNotImpl, "dead-letter database not configured", nil)
+	}
+	_, err := s.DB.NewDelete().Model((*deadLetterModel)(nil)).Where("id = ?", id).Exec(ctx)
+	return err
+}
+
+type deadLetterModel struct {
+	bun.BaseModel `bun:"table:export_webhook_dead_letters,alias:export_webhook_dead_letters"`
+
+	ID              string    `bun:",pk"`
+	WebhookID       string    `bun:"webhook_id,notnull"`
+	URL             string    `bun:"url,notnull"`
+	Method          string    `bun:"method"`
+	Headers         []byte    `bun:"headers"`
+	HeaderSecrets   []byte    `bun:"header_secrets"`
+	RedactedHeaders []byte    `bun:"redacted_headers"`
+	SecretRef       string    `bun:"secret_ref"`
+	Signed          bool      `bun:"signed"`
+	Payload         []byte    `bun:"payload"`
+	ArtifactKey     string    `bun:"artifact_key"`
+	Attempts        int       `bun:"attempts"`
+	StatusCode      int       `bun:"status_code"`
+	Error           string    `bun:"error"`
+	CreatedAt       time.Time `bun:"created_at"`
+	FailedAt        time.Time `bun:"failed_at"`
+	Parked          bool      `bun:"parked,notnull,default:false"`
+}
+
+func deadLetterModelFrom(letter export.WebhookDeadLetter) (deadLetterModel, error) {
+	headers, err := encodeOptionalJSON(len(letter.Headers) > 0, letter.Headers)
+	if err != nil {
+		return deadLetterModel{}, err
+	}
+	headerSecrets, err := encodeOptionalJSON(len(letter.HeaderSecrets) > 0, letter.HeaderSecrets)
+	if err != nil {
+		return deadLetterModel{}, err
+	}
+	redacted, err := encodeOptionalJSON(len(letter.RedactedHeaders) > 0, letter.RedactedHeaders)
+	if err != nil {
+		return deadLetterModel{}, err
+	}
+	return deadLetterModel{
+		ID:              letter.ID,
+		WebhookID:       letter.WebhookID,
+		URL:             letter.URL,
+		Method:          letter.Method,
+		Headers:         headers,
+		HeaderSecrets:   headerSecrets,
+		RedactedHeaders: redacted,
+		SecretRef:       letter.SecretRef,
+		Signed:          letter.Signed,
+		Payload:         letter.Payload,
+		ArtifactKey:     letter.ArtifactKey,
+		Attempts:        letter.Attempts,
+		StatusCode:      letter.StatusCode,
+		Error:           letter.Error,
+		CreatedAt:       letter.CreatedAt,
+		FailedAt:        letter.FailedAt,
+		Parked:          letter.Parked,
+	}, nil
+}
+
+func (m deadLetterModel) toDeadLetter() (export.WebhookDeadLetter, error) {
+	letter := export.WebhookDeadLetter{
+		ID:          m.ID,
+		WebhookID:   m.WebhookID,
+		URL:         m.URL,
+		Method:      m.Method,
+		SecretRef:   m.SecretRef,
+		Signed:      m.Signed,
+		Payload:     m.Payload,
+		ArtifactKey: m.ArtifactKey,
+		Attempts:    m.Attempts,
+		StatusCode:  m.StatusCode,
+		Error:       m.Error,
+		CreatedAt:   m.CreatedAt,
+		FailedAt:    m.FailedAt,
+		Parked:      m.Parked,
+	}
+	if len(m.Headers) > 0 {
+		if err := json.Unmarshal(m.Headers, &letter.Headers); err != nil {
+			return export.WebhookDeadLetter{}, err
+		}
+	}
+	if len(m.HeaderSecrets) > 0 {
+		if err := json.Unmarshal(m.HeaderSecrets, &letter.HeaderSecrets); err != nil {
+			return export.WebhookDeadLetter{}, err
+		}
+	}
+	if len(m.RedactedHeaders) > 0 {
+		if err := json.Unmarshal(m.RedactedHeaders, &letter.RedactedHeaders); err != nil {
+			return export.WebhookDeadLetter{}, err
+		}
+	}
+	return letter, nil
+}
+
+func encodeOptionalJSON(present bool, value any) ([]byte, error) {
+	if !present {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
+
+func (s *DeadLetterStore) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+func (s *DeadLetterStore) nextID() string {
+	if s.IDGenerator != nil {
+		return s.IDGenerator()
+	}
+	return defaultDeadLetterIDGenerator()()
+}
+
+func defaultDeadLetterIDGenerator() func() string {
+	var counter uint64
+	return func() string {
+		id := atomic.AddUint64(&counter, 1)
+		return fmt.Sprintf("dlq-%d-%d", time.Now().UnixNano(), id)
+	}
+}
@@ -0,0 +1,89 @@
+package trackerbun
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+)
+
+func TestDeadLetterStore_SaveListDelete(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	if _, err := db.NewCreateTable().Model((*deadLetterModel)(nil)).IfNotExists().Exec(ctx); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	store := NewDeadLetterStore(db)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	first, err := store.Save(ctx, export.WebhookDeadLetter{
+		WebhookID:       "msg_1",
+		URL:             "https://hooks.test/a",
+		Headers:         map[string]string{"X-Tenant": "t1"},
+		HeaderSecrets:   map[string]string{"Authorization": "vault:hooks/a-token"},
+		RedactedHeaders: []string{"X-Api-Key"},
+		SecretRef:       "vault:hooks/a",
+		Signed:          true,
+		Payload:         json.RawMessage(`{"export_id":"exp-1"}`),
+		ArtifactKey:     "exports/exp-1.csv",
+		Attempts:        5,
+		Error:           "webhook response error: status 503",
+		FailedAt:        base,
+	})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := store.Save(ctx, export.WebhookDeadLetter{WebhookID: "msg_2", URL: "https://hooks.test/b", FailedAt: base.Add(time.Minute)}); err != nil {
+		t.Fatalf("save second: %v", err)
+	}
+
+	first.Attempts = 7
+	if _, err := store.Save(ctx, first); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := store.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Attempts != 7 || got.SecretRef != "vault:hooks/a" || !got.Signed || got.Headers["X-Tenant"] != "t1" || string(got.Payload) != `{"export_id":"exp-1"}` {
+		t.Fatalf("unexpected dead letter: %+v", got)
+	}
+	if got.HeaderSecrets["Authorization"] != "vault:hooks/a-token" || len(got.RedactedHeaders) != 1 || got.ArtifactKey != "exports/exp-1.csv" {
+		t.Fatalf("unexpected secret references: %+v", got)
+	}
+
+	letters, err := store.List(ctx, 0)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(letters) != 2 || letters[0].WebhookID != "msg_1" {
+		t.Fatalf("expected oldest first, got %+v", letters)
+	}
+	if !letters[0].CreatedAt.Equal(base) {
+		t.Fatalf("expected created at defaulted to the first failure, got %+v", letters[0])
+	}
+
+	first.Parked = true
+	if _, err := store.Save(ctx, first); err != nil {
+		t.Fatalf("park: %v", err)
+	}
+	letters, err = store.List(ctx, 0)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(letters) != 1 || letters[0].WebhookID != "msg_2" {
+		t.Fatalf("expected parked letter left out, got %+v", letters)
+	}
+	if got, err := store.Get(ctx, first.ID); err != nil || !got.Parked {
+		t.Fatalf("expected parked letter still readable, got %+v %v", got, err)
+	}
+
+	if err := store.Delete(ctx, first.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Get(ctx, first.ID); err == nil {
+		t.Fatalf("expected deleted dead letter to be missing")
+	}
+}
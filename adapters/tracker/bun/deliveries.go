@@ -0,0 +1,232 @@
+package trackerbun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+	"github.com/uptrace/bun"
+)
+
+// DeliveryTracker stores per-target delivery history in a Bun-backed database.
+type DeliveryTracker struct {
+	DB          *bun.DB
+	Now         func() time.Time
+	IDGenerator func() string
+}
+
+var _ export.DeliveryTracker = (*DeliveryTracker)(nil)
+
+// NewDeliveryTracker creates a Bun-backed delivery tracker.
+func NewDeliveryTracker(db *bun.DB) *DeliveryTracker {
+	return &DeliveryTracker{DB: db, Now: time.Now, IDGenerator: defaultDeliveryIDGenerator()}
+}
+
+// RecordDelivery appends a delivery entry.
+func (t *DeliveryTracker) RecordDelivery(ctx context.Context, record export.DeliveryRecord) error {
+	if t == nil || t.DB == nil {
+		return export.NewError(export.KindNotImpl, "delivery tracker database not configured", nil)
+	}
+	if record.ExportID == "" {
+		return export.NewError(export.KindValidation, "export ID is required", nil)
+	}
+	if record.ID == "" {
+		record.ID = t.nextID()
+	}
+	if record.StartedAt.IsZero() {
+		record.StartedAt = t.now()
+	}
+	if record.CompletedAt.IsZero() {
+		record.CompletedAt = record.StartedAt
+	}
+
+	model, err := deliveryModelFromRecord(record)
+	if err != nil {
+		return err
+	}
+	_, err = t.DB.NewInsert().Model(&model).Exec(ctx)
+	return err
+}
+
+// ListDeliveries returns matching deliveries, newest first. Column filters run
+// in SQL; recipient matching and the limit are applied after decoding.
+func (t *DeliveryTracker) ListDeliveries(ctx context.Context, filter export.DeliveryFilter) ([]export.DeliveryRecord, error) {
+	if t == nil || t.DB == nil {
+		return nil, export.NewError(export.KindNotImpl, "delivery tracker database not configured", nil)
+	}
+
+	var models []deliveryModel
+	query := t.DB.NewSelect().Model(&models)
+	if filter.ExportID != "" {
+		query = query.Where("export_id = ?", filter.ExportID)
+	}
+	if filter.ScheduleID != "" {
+		query = query.Where("schedule_id = ?", filter.ScheduleID)
+	}
+	if filter.Definition != "" {
+		query = query.Where("definition = ?", filter.Definition)
+	}
+	if filter.TargetKind != "" {
+		query = query.Where("target_kind = ?", filter.TargetKind)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", string(filter.Status))
+	}
+	if filter.Scope.TenantID != "" {
+		query = query.Where("tenant_id = ?", filter.Scope.TenantID)
+	}
+	if filter.Scope.WorkspaceID != "" {
+		query = query.Where("workspace_id = ?", filter.Scope.WorkspaceID)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("started_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("started_at <= ?", filter.Until)
+	}
+	if err := query.Order("started_at DESC", "id DESC").Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	records := make([]export.DeliveryRecord, 0, len(models))
+	for _, model := range models {
+		record, err := model.toRecord()
+		if err != nil {
+			return nil, err
+		}
+		if !filter.Matches(record) {
+			continue
+		}
+		records = append(records, record)
+		if filter.Limit > 0 && len(records) >= filter.Limit {
+			break
+		}
+	}
+	return records, nil
+}
+
+type deliveryModel struct {
+	bun.BaseModel `bun:"table:export_deliveries,alias:export_deliveries"`
+
+	ID           string    `bun:",pk"`
+	ExportID     string    `bun:"export_id,notnull"`
+	ScheduleID   string    `bun:"schedule_id"`
+	Definition   string    `bun:"definition"`
+	Format       string    `bun:"format"`
+	Mode         string    `bun:"mode"`
+	ActorID      string    `bun:"actor_id"`
+	TenantID     string    `bun:"tenant_id"`
+	WorkspaceID  string    `bun:"workspace_id"`
+	ActorPayload []byte    `bun:"actor_payload"`
+	TargetKind   string    `bun:"target_kind"`
+	Target       string    `bun:"target"`
+	Recipients   []byte    `bun:"recipients"`
+	Rows         int64     `bun:"rows"`
+	Checksum     string    `bun:"checksum"`
+	Status       string    `bun:"status"`
+	Reason       string    `bun:"reason"`
+	StatusCode   int       `bun:"status_code"`
+	Attempts     int       `bun:"attempts"`
+	Error        string    `bun:"error"`
+	DeadLetterID string    `bun:"dead_letter_id"`
+	StartedAt    time.Time `bun:"started_at"`
+	CompletedAt  time.Time `bun:"completed_at"`
+}
+
+func deliveryModelFromRecord(record export.DeliveryRecord) (deliveryModel, error) {
+	actor, err := json.Marshal(record.Actor)
+	if err != nil {
+		return deliveryModel{}, err
+	}
+	var recipients []byte
+	if len(record.Recipients) > 0 {
+		recipients, err = json.Marshal(record.Recipients)
+		if err != nil {
+			return deliveryModel{}, err
+		}
+	}
+	return deliveryModel{
+		ID:           record.ID,
+		ExportID:     record.ExportID,
+		ScheduleID:   record.ScheduleID,
+		Definition:   record.Definition,
+		Format:       string(record.Format),
+		Mode:         record.Mode,
+		ActorID:      record.Actor.ID,
+		TenantID:     record.Actor.Scope.TenantID,
+		WorkspaceID:  record.Actor.Scope.WorkspaceID,
+		ActorPayload: actor,
+		TargetKind:   record.TargetKind,
+		Target:       record.Target,
+		Recipients:   recipients,
+		Rows:         record.Rows,
+		Checksum:     record.Checksum,
+		Status:       string(record.Status),
+		Reason:       record.Reason,
+		StatusCode:   record.StatusCode,
+		Attempts:     record.Attempts,
+		Error:        record.Error,
+		DeadLetterID: record.DeadLetterID,
+		StartedAt:    record.StartedAt,
+		CompletedAt:  record.CompletedAt,
+	}, nil
+}
+
+func (m deliveryModel) toRecord() (export.DeliveryRecord, error) {
+	record := export.DeliveryRecord{
+		ID:           m.ID,
+		ExportID:     m.ExportID,
+		ScheduleID:   m.ScheduleID,
+		Definition:   m.Definition,
+		Format:       export.Format(m.Format),
+		Mode:         m.Mode,
+		TargetKind:   m.TargetKind,
+		Target:       m.Target,
+		Rows:         m.Rows,
+		Checksum:     m.Checksum,
+		Status:       export.DeliveryStatus(m.Status),
+		Reason:       m.Reason,
+		StatusCode:   m.StatusCode,
+		Attempts:     m.Attempts,
+		Error:        m.Error,
+		DeadLetterID: m.DeadLetterID,
+		StartedAt:    m.StartedAt,
+		CompletedAt:  m.CompletedAt,
+	}
+	if len(m.ActorPayload) > 0 {
+		if err := json.Unmarshal(m.ActorPayload, &record.Actor); err != nil {
+			return export.DeliveryRecord{}, err
+		}
+	}
+	if len(m.Recipients) > 0 {
+		if err := json.Unmarshal(m.Recipients, &record.Recipients); err != nil {
+			return export.DeliveryRecord{}, err
+		}
+	}
+	return record, nil
+}
+
+func (t *DeliveryTracker) now() time.Time {
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
+
+func (t *DeliveryTracker) nextID() string {
+	if t.IDGenerator != nil {
+		return t.IDGenerator()
+	}
+	return defaultDeliveryIDGenerator()()
+}
+
+func defaultDeliveryIDGenerator() func() string {
+	var counter uint64
+	return func() string {
+		id := atomic.AddUint64(&counter, 1)
+		return fmt.Sprintf("dlv-%d-%d", time.Now().UnixNano(), id)
+	}
+}
@@ -0,0 +1,107 @@
+package trackerbun
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+)
+
+func TestDeliveryTracker_RecordList(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	if _, err := db.NewCreateTable().Model((*deliveryModel)(nil)).IfNotExists().Exec(ctx); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	tracker := NewDeliveryTracker(db)
+
+	base := time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)
+	actor := export.Actor{ID: "user-1", Scope: export.Scope{TenantID: "tenant-1"}}
+	records := []export.DeliveryRecord{
+		{
+			ExportID:   "exp-1",
+			ScheduleID: "nightly-orders",
+			Definition: "orders",
+			Actor:      actor,
+			Rows:       42,
+			Checksum:   "sum-1",
+			TargetKind: "email",
+			Target:     "partner@example.com,ops@example.com",
+			Recipients: []string{"partner@example.com", "ops@example.com"},
+			Status:     export.DeliveryStatusSent,
+			Attempts:   1,
+			StartedAt:  base,
+		},
+		{
+			ExportID:     "exp-1",
+			Definition:   "orders",
+			Actor:        actor,
+			TargetKind:   "webhook",
+			Target:       "https://hooks.test/orders",
+			Status:       export.DeliveryStatusFailed,
+			StatusCode:   503,
+			Attempts:     5,
+			Error:        "webhook response error: status 503",
+			DeadLetterID: "dlq-1",
+			StartedAt:    base.Add(time.Minute),
+		},
+		{
+			ExportID:   "exp-2",
+			Definition: "orders",
+			Actor:      export.Actor{ID: "user-2", Scope: export.Scope{TenantID: "tenant-2"}},
+			TargetKind: "email",
+			Target:     "partner@example.com",
+			Recipients: []string{"partner@example.com"},
+			Status:     export.DeliveryStatusSent,
+			StartedAt:  base.Add(2 * time.Minute),
+		},
+	}
+	for _, record := range records {
+		if err := tracker.RecordDelivery(ctx, record); err != nil {
+			t.Fatalf("record delivery: %v", err)
+		}
+	}
+
+	got, err := tracker.ListDeliveries(ctx, export.DeliveryFilter{ExportID: "exp-1"})
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	if len(got) != 2 || got[0].TargetKind != "webhook" || got[1].TargetKind != "email" {
+		t.Fatalf("expected exp-1 deliveries newest first, got %+v", got)
+	}
+	if got[0].StatusCode != 503 || got[0].Attempts != 5 || got[0].DeadLetterID != "dlq-1" {
+		t.Fatalf("unexpected webhook delivery: %+v", got[0])
+	}
+	if len(got[1].Recipients) != 2 || got[1].Actor.ID != "user-1" {
+		t.Fatalf("unexpected email delivery: %+v", got[1])
+	}
+
+	got, err = tracker.ListDeliveries(ctx, export.DeliveryFilter{
+		Target: "partner@example.com",
+		Scope:  export.Scope{TenantID: "tenant-1"},
+		Since:  base.Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("list by recipient: %v", err)
+	}
+	if len(got) != 1 || got[0].ExportID != "exp-1" || got[0].Status != export.DeliveryStatusSent {
+		t.Fatalf("expected tenant-1 delivery to partner, got %+v", got)
+	}
+
+	got, err = tracker.ListDeliveries(ctx, export.DeliveryFilter{Status: export.DeliveryStatusSent, Limit: 1})
+	if err != nil {
+		t.Fatalf("list by status: %v", err)
+	}
+	if len(got) != 1 || got[0].ExportID != "exp-2" {
+		t.Fatalf("expected newest sent delivery, got %+v", got)
+	}
+
+	got, err = tracker.ListDeliveries(ctx, export.DeliveryFilter{ScheduleID: "nightly-orders"})
+	if err != nil {
+		t.Fatalf("list by schedule: %v", err)
+	}
+	if len(got) != 1 || got[0].Checksum != "sum-1" || got[0].Rows != 42 {
+		t.Fatalf("expected scheduled delivery, got %+v", got)
+	}
+}
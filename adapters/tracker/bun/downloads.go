@@ -0,0 +1,151 @@
+package trackerbun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+	"github.com/uptrace/bun"
+)
+
+// DownloadLog stores export download history in a Bun-backed database.
+type DownloadLog struct {
+	DB          *bun.DB
+	Now         func() time.Time
+	IDGenerator func() string
+}
+
+// NewDownloadLog creates a Bun-backed download log.
+func NewDownloadLog(db *bun.DB) *DownloadLog {
+	return &DownloadLog{DB: db, Now: time.Now, IDGenerator: defaultDownloadIDGenerator()}
+}
+
+// RecordDownload appends a download entry.
+func (l *DownloadLog) RecordDownload(ctx context.Context, record export.DownloadRecord) error {
+	if l == nil || l.DB == nil {
+		return export.NewError(export.KindNotImpl, "download log database not configured", nil)
+	}
+	if record.ExportID == "" {
+		return export.NewError(export.KindValidation, "export ID is required", nil)
+	}
+	if record.ID == "" {
+		record.ID = l.nextID()
+	}
+	if record.DownloadedAt.IsZero() {
+		record.DownloadedAt = l.now()
+	}
+
+	model, err := downloadModelFromRecord(record)
+	if err != nil {
+		return err
+	}
+	_, err = l.DB.NewInsert().Model(&model).Exec(ctx)
+	return err
+}
+
+// ListDownloads returns downloads for an export, oldest first.
+func (l *DownloadLog) ListDownloads(ctx context.Context, exportID string) ([]export.DownloadRecord, error) {
+	if l == nil || l.DB == nil {
+		return nil, export.NewError(export.KindNotImpl, "download log database not configured", nil)
+	}
+	if exportID == "" {
+		return nil, export.NewError(export.KindValidation, "export ID is required", nil)
+	}
+
+	var models []downloadModel
+	err := l.DB.NewSelect().Model(&models).
+		Where("export_id = ?", exportID).
+		Order("downloaded_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]export.DownloadRecord, 0, len(models))
+	for _, model := range models {
+		record, err := model.toRecord()
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+type downloadModel struct {
+	bun.BaseModel `bun:"table:export_downloads,alias:export_downloads"`
+
+	ID           string    `bun:",pk"`
+	ExportID     string    `bun:"export_id,notnull"`
+	ActorID      string    `bun:"actor_id"`
+	ActorPayload []byte    `bun:"actor_payload"`
+	IP           string    `bun:"ip"`
+	ForwardedFor string    `bun:"forwarded_for"`
+	UserAgent    string    `bun:"user_agent"`
+	Method       string    `bun:"method"`
+	Bytes        int64     `bun:"bytes"`
+	DownloadedAt time.Time `bun:"downloaded_at"`
+}
+
+func downloadModelFromRecord(record export.DownloadRecord) (downloadModel, error) {
+	actor, err := json.Marshal(record.Actor)
+	if err != nil {
+		return downloadModel{}, err
+	}
+	return downloadModel{
+		ID:           record.ID,
+		ExportID:     record.ExportID,
+		ActorID:      record.Actor.ID,
+		ActorPayload: actor,
+		IP:           record.IP,
+		ForwardedFor: record.ForwardedFor,
+		UserAgent:    record.UserAgent,
+		Method:       string(record.Method),
+		Bytes:        record.Bytes,
+		DownloadedAt: record.DownloadedAt,
+	}, nil
+}
+
+func (m downloadModel) toRecord() (export.DownloadRecord, error) {
+	record := export.DownloadRecord{
+		ID:           m.ID,
+		ExportID:     m.ExportID,
+		IP:           m.IP,
+		ForwardedFor: m.ForwardedFor,
+		UserAgent:    m.UserAgent,
+		Method:       export.DownloadMethod(m.Method),
+		Bytes:        m.Bytes,
+		DownloadedAt: m.DownloadedAt,
+	}
+	if len(m.ActorPayload) > 0 {
+		if err := json.Unmarshal(m.ActorPayload, &record.Actor); err != nil {
+			return export.DownloadRecord{}, err
+		}
+	}
+	return record, nil
+}
+
+func (l *DownloadLog) now() time.Time {
+	if l.Now != nil {
+		return l.Now()
+	}
+	return time.Now()
+}
+
+func (l *DownloadLog) nextID() string {
+	if l.IDGenerator != nil {
+		return l.IDGenerator()
+	}
+	return defaultDownloadIDGenerator()()
+}
+
+func defaultDownloadIDGenerator() func() string {
+	var counter uint64
+	return func() string {
+		id := atomic.AddUint64(&counter, 1)
+		return fmt.Sprintf("dl-%d-%d", time.Now().UnixNano(), id)
+	}
+}
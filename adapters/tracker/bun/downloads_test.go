@@ -0,0 +1,49 @@
+package trackerbun
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+)
+
+func TestDownloadLog_RecordList(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	if _, err := db.NewCreateTable().Model((*downloadModel)(nil)).IfNotExists().Exec(ctx); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	log := NewDownloadLog(db)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+		if err := log.RecordDownload(ctx, export.DownloadRecord{
+			ExportID:     "exp-1",
+			Actor:        export.Actor{ID: "user-1", Roles: []string{"admin"}},
+			IP:           ip,
+			UserAgent:    "curl/8",
+			Method:       export.DownloadStream,
+			DownloadedAt: base.Add(time.Duration(i) * time.Minute),
+		}); err != nil {
+			t.Fatalf("record download: %v", err)
+		}
+	}
+	if err := log.RecordDownload(ctx, export.DownloadRecord{ExportID: "exp-2"}); err != nil {
+		t.Fatalf("record other download: %v", err)
+	}
+
+	records, err := log.ListDownloads(ctx, "exp-1")
+	if err != nil {
+		t.Fatalf("list downloads: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 downloads, got %d", len(records))
+	}
+	if records[0].IP != "10.0.0.1" || records[1].IP != "10.0.0.2" {
+		t.Fatalf("expected downloads in order, got %+v", records)
+	}
+	if records[0].Actor.ID != "user-1" || len(records[0].Actor.Roles) != 1 || records[0].Method != export.DownloadStream {
+		t.Fatalf("unexpected download record: %+v", records[0])
+	}
+}
@@ -0,0 +1,91 @@
+package trackerbun
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+	"github.com/uptrace/bun"
+)
+
+// Locker hands out leases backed by rows in export_leases. The lease name
+// is the primary key, so only one replica can insert it; expired rows are
+// removed before each attempt so a crashed holder frees the lease after its TTL.
+// Inserts use ON CONFLICT DO NOTHING (Postgres, SQLite) or INSERT IGNORE
+// (MySQL, MariaDB); dialects with neither, such as MSSQL, are not supported.
+type Locker struct {
+	DB    *bun.DB
+	Owner string
+	Now   func() time.Time
+}
+
+var _ export.Locker = (*Locker)(nil)
+
+// NewLocker creates a Bun-backed locker owned by this process.
+func NewLocker(db *bun.DB) *Locker {
+	return &Locker{DB: db, Owner: export.DefaultLeaseOwner(), Now: time.Now}
+}
+
+// Acquire inserts the lease row when no unexpired holder exists.
+func (l *Locker) Acquire(ctx context.Context, name string, ttl time.Duration) (export.Lease, bool, error) {
+	if l == nil || l.DB == nil {
+		return export.Lease{}, false, export.NewError(export.KindNotImpl, "lease database not configured", nil)
+	}
+	if strings.TrimSpace(name) == "" {
+		return export.Lease{}, false, export.NewError(export.KindValidation, "lease name is required", nil)
+	}
+	if ttl <= 0 {
+		return export.Lease{}, false, export.NewError(export.KindValidation, "lease ttl must be positive", nil)
+	}
+	now := l.now().UTC()
+
+	if _, err := l.DB.NewDelete().Model((*leaseModel)(nil)).
+		Where("expires_at <= ?", now).
+		Exec(ctx); err != nil {
+		return export.Lease{}, false, err
+	}
+
+	model := leaseModel{
+		Name:       name,
+		Owner:      l.Owner,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	res, err := l.DB.NewInsert().Model(&model).Ignore().Exec(ctx)
+	if err != nil {
+		return export.Lease{}, false, err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return export.Lease{}, false, nil
+	}
+	return export.Lease{Name: name, Owner: l.Owner, ExpiresAt: model.ExpiresAt}, true, nil
+}
+
+// Release deletes the lease row if it is still held by the same owner.
+func (l *Locker) Release(ctx context.Context, lease export.Lease) error {
+	if l == nil || l.DB == nil {
+		return export.NewError(export.KindNotImpl, "lease database not configured", nil)
+	}
+	_, err := l.DB.NewDelete().Model((*leaseModel)(nil)).
+		Where("name = ?", lease.Name).
+		Where("owner = ?", lease.Owner).
+		Exec(ctx)
+	return err
+}
+
+func (l *Locker) now() time.Time {
+	if l.Now != nil {
+		return l.Now()
+	}
+	return time.Now()
+}
+
+type leaseModel struct {
+	bun.BaseModel `bun:"table:export_leases,alias:export_leases"`
+
+	Name       string    `bun:",pk"`
+	Owner      string    `bun:"owner,notnull"`
+	AcquiredAt time.Time `bun:"acquired_at"`
+	ExpiresAt  time.Time `bun:"expires_at,notnull"`
+}
@@ -0,0 +1,65 @@
+package trackerbun
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+)
+
+func TestLocker_SingleHolderAndExpiry(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	if _, err := db.NewCreateTable().Model((*leaseModel)(nil)).IfNotExists().Exec(ctx); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	var acquired int32
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		replica := NewLocker(db)
+		replica.Now = clock
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok, err := replica.Acquire(ctx, "exports-scheduled", time.Minute); err != nil {
+				t.Errorf("acquire: %v", err)
+			} else if ok {
+				atomic.AddInt32(&acquired, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if acquired != 1 {
+		t.Fatalf("expected exactly one replica to acquire, got %d", acquired)
+	}
+
+	other := NewLocker(db)
+	other.Now = clock
+	if err := other.Release(ctx, export.Lease{Name: "exports-scheduled", Owner: other.Owner}); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, ok, _ := other.Acquire(ctx, "exports-scheduled", time.Minute); ok {
+		t.Fatalf("expected release by a non-holder to be ignored")
+	}
+
+	// A crashed holder frees the lease once its TTL passes.
+	now = now.Add(time.Minute)
+	lease, ok, err := other.Acquire(ctx, "exports-scheduled", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected expired lease to be taken over, got %v %v", ok, err)
+	}
+	if err := other.Release(ctx, lease); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	next := NewLocker(db)
+	next.Now = clock
+	if _, ok, _ := next.Acquire(ctx, "exports-scheduled", time.Minute); !ok {
+		t.Fatalf("expected released lease to be acquirable")
+	}
+}
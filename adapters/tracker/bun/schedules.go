@@ -0,0 +1,275 @@
+package trackerbun
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+	"github.com/uptrace/bun"
+)
+
+// ScheduleStore persists export and delivery schedules in a Bun-backed
+// database. Run times are stored in UTC so due checks compare consistently.
+type ScheduleStore struct {
+	DB *bun.DB
+}
+
+var _ export.ScheduleStore = (*ScheduleStore)(nil)
+
+// NewScheduleStore creates a Bun-backed schedule store.
+func NewScheduleStore(db *bun.DB) *ScheduleStore {
+	return &ScheduleStore{DB: db}
+}
+
+// CreateSchedule inserts a schedule.
+func (s *ScheduleStore) CreateSchedule(ctx context.Context, schedule export.Schedule) error {
+	if s == nil || s.DB == nil {
+		return export.NewError(export.KindNotImpl, "schedule database not configured", nil)
+	}
+	if schedule.ID == "" {
+		return export.NewError(export.KindValidation, "schedule ID is required", nil)
+	}
+	model, err := scheduleModelFrom(schedule)
+	if err != nil {
+		return err
+	}
+	_, err = s.DB.NewInsert().Model(&model).Exec(ctx)
+	return err
+}
+
+// UpdateSchedule replaces an existing schedule.
+func (s *ScheduleStore) UpdateSchedule(ctx context.Context, schedule export.Schedule) error {
+	if s == nil || s.DB == nil {
+		return export.NewError(export.KindNotImpl, "schedule database not configured", nil)
+	}
+	model, err := scheduleModelFrom(schedule)
+	if err != nil {
+		return err
+	}
+	res, err := s.DB.NewUpdate().Model(&model).WherePK().Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return export.NewError(export.KindNotFound, fmt.Sprintf("schedule %q not found", schedule.ID), nil)
+	}
+	return nil
+}
+
+// DeleteSchedule removes a schedule.
+func (s *ScheduleStore) DeleteSchedule(ctx context.Context, id string) error {
+	if s == nil || s.DB == nil {
+		return export.NewError(export.KindNotImpl, "schedule database not configured", nil)
+	}
+	res, err := s.DB.NewDelete().Model((*scheduleModel)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return export.NewError(export.KindNotFound, fmt.Sprintf("schedule %q not found", id), nil)
+	}
+	return nil
+}
+
+// GetSchedule returns a schedule by ID.
+func (s *ScheduleStore) GetSchedule(ctx context.Context, id string) (export.Schedule, error) {
+	if s == nil || s.DB == nil {
+		return export.Schedule{}, export.NewError(export.KindNotImpl, "schedule database not configured", nil)
+	}
+	var model scheduleModel
+	err := s.DB.NewSelect().Model(&model).Where("id = ?", id).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return export.Schedule{}, export.NewError(export.KindNotFound, fmt.Sprintf("schedule %q not found", id), err)
+		}
+		return export.Schedule{}, err
+	}
+	return model.toSchedule()
+}
+
+// ListSchedules returns matching schedules ordered by creation time.
+func (s *ScheduleStore) ListSchedules(ctx context.Context, filter export.ScheduleFilter) ([]export.Schedule, error) {
+	if s == nil || s.DB == nil {
+		return nil, export.NewError(export.KindNotImpl, "schedule database not configured", nil)
+	}
+	var models []scheduleModel
+	query := s.DB.NewSelect().Model(&models)
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", string(filter.Kind))
+	}
+	if filter.OwnerID != "" {
+		query = query.Where("owner_id = ?", filter.OwnerID)
+	}
+	if filter.Scope.TenantID != "" {
+		query = query.Where("tenant_id = ?", filter.Scope.TenantID)
+	}
+	if filter.Scope.WorkspaceID != "" {
+		query = query.Where("workspace_id = ?", filter.Scope.WorkspaceID)
+	}
+	if filter.Enabled != nil {
+		query = query.Where("enabled = ?", *filter.Enabled)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if err := query.Order("created_at ASC", "id ASC").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return schedulesFromModels(models)
+}
+
+// DueSchedules returns enabled schedules of kind due at now, oldest first.
+func (s *ScheduleStore) DueSchedules(ctx context.Context, kind export.ScheduleKind, now time.Time) ([]export.Schedule, error) {
+	if s == nil || s.DB == nil {
+		return nil, export.NewError(export.KindNotImpl, "schedule database not configured", nil)
+	}
+	var models []scheduleModel
+	query := s.DB.NewSelect().Model(&models).
+		Where("enabled = ?", true).
+		Where("next_run_at IS NOT NULL").
+		Where("next_run_at <= ?", now.UTC())
+	if kind != "" {
+		query = query.Where("kind = ?", string(kind))
+	}
+	if err := query.Order("next_run_at ASC", "id ASC").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return schedulesFromModels(models)
+}
+
+// MarkScheduleRun advances a schedule if its next_run_at still equals
+// expected; the conditional update makes the claim atomic across processes.
+func (s *ScheduleStore) MarkScheduleRun(ctx context.Context, id string, expected, ranAt, next time.Time) (bool, error) {
+	if s == nil || s.DB == nil {
+		return false, export.NewError(export.KindNotImpl, "schedule database not configured", nil)
+	}
+	var lastRun any
+	if !ranAt.IsZero() {
+		lastRun = ranAt.UTC()
+	}
+	res, err := s.DB.NewUpdate().Model((*scheduleModel)(nil)).
+		Set("last_run_at = ?", lastRun).
+		Set("next_run_at = ?", next.UTC()).
+		Where("id = ?", id).
+		Where("next_run_at = ?", expected.UTC()).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+type scheduleModel struct {
+	bun.BaseModel `bun:"table:export_schedules,alias:export_schedules"`
+
+	ID              string    `bun:",pk"`
+	Name            string    `bun:"name"`
+	Kind            string    `bun:"kind,notnull"`
+	Cron            string    `bun:"cron,notnull"`
+	Timezone        string    `bun:"timezone"`
+	OwnerID         string    `bun:"owner_id"`
+	TenantID        string    `bun:"tenant_id"`
+	WorkspaceID     string    `bun:"workspace_id"`
+	ActorPayload    []byte    `bun:"actor_payload"`
+	Enabled         bool      `bun:"enabled,notnull"`
+	CatchUp         string    `bun:"catch_up"`
+	Calendar        string    `bun:"calendar"`
+	CalendarPolicy  string    `bun:"calendar_policy"`
+	ExportPayload   []byte    `bun:"export_payload"`
+	DeliveryPayload []byte    `bun:"delivery_payload"`
+	LastRunAt       time.Time `bun:"last_run_at,nullzero"`
+	NextRunAt       time.Time `bun:"next_run_at,nullzero"`
+	CreatedAt       time.Time `bun:"created_at"`
+	UpdatedAt       time.Time `bun:"updated_at"`
+}
+
+func scheduleModelFrom(schedule export.Schedule) (scheduleModel, error) {
+	actor, err := json.Marshal(schedule.Owner)
+	if err != nil {
+		return scheduleModel{}, err
+	}
+	var request []byte
+	if schedule.Kind == export.ScheduleKindExport {
+		schedule.Export.Output = nil
+		if request, err = json.Marshal(schedule.Export); err != nil {
+			return scheduleModel{}, err
+		}
+	}
+	return scheduleModel{
+		ID:              schedule.ID,
+		Name:            schedule.Name,
+		Kind:            string(schedule.Kind),
+		Cron:            schedule.Cron,
+		Timezone:        schedule.Timezone,
+		OwnerID:         schedule.Owner.ID,
+		TenantID:        schedule.Owner.Scope.TenantID,
+		WorkspaceID:     schedule.Owner.Scope.WorkspaceID,
+		ActorPayload:    actor,
+		Enabled:         schedule.Enabled,
+		CatchUp:         string(schedule.CatchUp),
+		Calendar:        schedule.Calendar,
+		CalendarPolicy:  string(schedule.CalendarPolicy),
+		ExportPayload:   request,
+		DeliveryPayload: schedule.Delivery,
+		LastRunAt:       utcOrZero(schedule.LastRunAt),
+		NextRunAt:       utcOrZero(schedule.NextRunAt),
+		CreatedAt:       schedule.CreatedAt.UTC(),
+		UpdatedAt:       schedule.UpdatedAt.UTC(),
+	}, nil
+}
+
+func (m scheduleModel) toSchedule() (export.Schedule, error) {
+	schedule := export.Schedule{
+		ID:             m.ID,
+		Name:           m.Name,
+		Kind:           export.ScheduleKind(m.Kind),
+		Cron:           m.Cron,
+		Timezone:       m.Timezone,
+		Enabled:        m.Enabled,
+		CatchUp:        export.CatchUpPolicy(m.CatchUp),
+		Calendar:       m.Calendar,
+		CalendarPolicy: export.CalendarPolicy(m.CalendarPolicy),
+		LastRunAt:      m.LastRunAt,
+		NextRunAt:      m.NextRunAt,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+	if len(m.ActorPayload) > 0 {
+		if err := json.Unmarshal(m.ActorPayload, &schedule.Owner); err != nil {
+			return export.Schedule{}, err
+		}
+	}
+	if len(m.ExportPayload) > 0 {
+		if err := json.Unmarshal(m.ExportPayload, &schedule.Export); err != nil {
+			return export.Schedule{}, err
+		}
+	}
+	if len(m.DeliveryPayload) > 0 {
+		schedule.Delivery = json.RawMessage(m.DeliveryPayload)
+	}
+	return schedule, nil
+}
+
+func schedulesFromModels(models []scheduleModel) ([]export.Schedule, error) {
+	schedules := make([]export.Schedule, 0, len(models))
+	for _, model := range models {
+		schedule, err := model.toSchedule()
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+func utcOrZero(value time.Time) time.Time {
+	if value.IsZero() {
+		return value
+	}
+	return value.UTC()
+}
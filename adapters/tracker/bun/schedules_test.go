@@ -0,0 +1,123 @@
+package trackerbun
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-export/export"
+)
+
+func TestScheduleStore_DueAndClaim(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	if _, err := db.NewCreateTable().Model((*scheduleModel)(nil)).IfNotExists().Exec(ctx); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	store := NewScheduleStore(db)
+
+	created := time.Date(2024, 3, 9, 15, 0, 0, 0, time.UTC)
+	svc := export.NewScheduleService(export.ScheduleServiceConfig{
+		Store: store,
+		Now:   func() time.Time { return created },
+		DeliveryValidator: func(ctx context.Context, actor export.Actor, schedule export.Schedule) error {
+			return nil
+		},
+	})
+	owner := export.Actor{ID: "owner-1", Scope: export.Scope{TenantID: "tenant-bun"}}
+
+	exportSchedule, err := svc.CreateSchedule(ctx, owner, export.Schedule{
+		Name:     "nightly users",
+		Kind:     export.ScheduleKindExport,
+		Cron:     "0 9 * * *",
+		Timezone: "America/New_York",
+		Enabled:  true,
+		Export:   export.ExportRequest{Definition: "users", Format: export.FormatCSV, Columns: []string{"id"}},
+	})
+	if err != nil {
+		t.Fatalf("create export schedule: %v", err)
+	}
+	// 09:00 in New York is 13:00 UTC once daylight saving starts on 2024-03-10.
+	if want := time.Date(2024, 3, 10, 13, 0, 0, 0, time.UTC); !exportSchedule.NextRunAt.Equal(want) {
+		t.Fatalf("expected next run %s, got %s", want, exportSchedule.NextRunAt.UTC())
+	}
+	if _, err := svc.CreateSchedule(ctx, owner, export.Schedule{
+		Kind:     export.ScheduleKindDelivery,
+		Cron:     "@hourly",
+		Enabled:  true,
+		Delivery: json.RawMessage(`{"export":{"Definition":"orders"}}`),
+	}); err != nil {
+		t.Fatalf("create delivery schedule: %v", err)
+	}
+
+	got, err := store.GetSchedule(ctx, exportSchedule.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Owner.ID != "owner-1" || got.Export.Definition != "users" || got.Timezone != "America/New_York" {
+		t.Fatalf("unexpected schedule: %+v", got)
+	}
+
+	due, err := store.DueSchedules(ctx, export.ScheduleKindExport, created.Add(12*time.Hour))
+	if err != nil {
+		t.Fatalf("due: %v", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("expected nothing due yet, got %d", len(due))
+	}
+
+	runAt := time.Date(2024, 3, 10, 13, 5, 0, 0, time.UTC)
+	var claimed atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runs, err := svc.ClaimDue(ctx, export.ScheduleKindExport, runAt)
+			if err != nil {
+				t.Errorf("claim: %v", err)
+				return
+			}
+			claimed.Add(int64(len(runs)))
+		}()
+	}
+	wg.Wait()
+	if claimed.Load() != 1 {
+		t.Fatalf("expected exactly one claim, got %d", claimed.Load())
+	}
+
+	got, err = store.GetSchedule(ctx, exportSchedule.ID)
+	if err != nil {
+		t.Fatalf("get after claim: %v", err)
+	}
+	if !got.LastRunAt.Equal(runAt) || !got.NextRunAt.Equal(time.Date(2024, 3, 11, 13, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected run times: last=%s next=%s", got.LastRunAt, got.NextRunAt)
+	}
+
+	disabled := false
+	got.Enabled = false
+	if _, err := svc.UpdateSchedule(ctx, owner, got); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	list, err := svc.ListSchedules(ctx, owner, export.ScheduleFilter{Enabled: &disabled})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != exportSchedule.ID || !list[0].NextRunAt.IsZero() {
+		t.Fatalf("unexpected disabled list: %+v", list)
+	}
+
+	other := export.Actor{ID: "intruder", Scope: export.Scope{TenantID: "tenant-other"}}
+	if err := svc.DeleteSchedule(ctx, other, exportSchedule.ID); err == nil {
+		t.Fatalf("expected out-of-scope delete to fail")
+	}
+	if err := svc.DeleteSchedule(ctx, owner, exportSchedule.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.GetSchedule(ctx, exportSchedule.ID); err == nil {
+		t.Fatalf("expected schedule to be deleted")
+	}
+}
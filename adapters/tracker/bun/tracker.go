@@ -107,6 +107,79 @@ func (t *Tracker) SetState(ctx context.Context, id string, state export.ExportSt
 	return nil
 }
 
+// TransitionState sets state to to only while the row is in from.
+func (t *Tracker) TransitionState(ctx context.Context, id string, from, to export.ExportState) (bool, error) {
+	return t.transition(ctx, id, from, to, nil)
+}
+
+// TransitionStale sets state to to only while the row is running with the
+// given heartbeat.
+func (t *Tracker) TransitionStale(ctx context.Context, id string, heartbeatAt time.Time, to export.ExportState) (bool, error) {
+	return t.transition(ctx, id, export.StateRunning, to, func(query *bun.UpdateQuery) *bun.UpdateQuery {
+		if heartbeatAt.IsZero() {
+			return query.Where("heartbeat_at IS NULL")
+		}
+		return query.Where("heartbeat_at = ?", heartbeatAt.UTC())
+	})
+}
+
+func (t *Tracker) transition(ctx context.Context, id string, from, to export.ExportState, where func(*bun.UpdateQuery) *bun.UpdateQuery) (bool, error) {
+	if t == nil || t.DB == nil {
+		return false, export.NewError(export.KindNotImpl, "tracker database not configured", nil)
+	}
+	if id == "" {
+		return false, export.NewError(export.KindValidation, "export ID is required", nil)
+	}
+
+	query := t.DB.NewUpdate().Model((*recordModel)(nil)).
+		Set("state = ?", to).
+		Where("id = ?", id).
+		Where("state = ?", from)
+	if where != nil {
+		query = where(query)
+	}
+	if to == export.StateCompleted || to == export.StateFailed {
+		query = query.Set("completed_at = COALESCE(completed_at, ?)", t.now())
+	}
+
+	res, err := query.Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	if affected > 0 {
+		return true, nil
+	}
+	if _, err := t.Status(ctx, id); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// Heartbeat records that a running export is still alive.
+func (t *Tracker) Heartbeat(ctx context.Context, id string, at time.Time) error {
+	if t == nil || t.DB == nil {
+		return export.NewError(export.KindNotImpl, "tracker database not configured", nil)
+	}
+	if id == "" {
+		return export.NewError(export.KindValidation, "export ID is required", nil)
+	}
+
+	res, err := t.DB.NewUpdate().Model((*recordModel)(nil)).
+		Set("heartbeat_at = ?", at.UTC()).
+		Where("id = ?", id).
+		Where("state = ?", export.StateRunning).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return export.NewError(export.KindNotFound, fmt.Sprintf("running export %q not found", id), nil)
+	}
+	return nil
+}
+
 // Fail marks the export as failed.
 func (t *Tracker) Fail(ctx context.Context, id string, err error, meta map[string]any) error {
 	_ = err
@@ -252,6 +325,54 @@ func (t *Tracker) SetArtifact(ctx context.Context, id string, ref export.Artifac
 	return nil
 }
 
+// SetWatermark records the artifact watermark for a record.
+func (t *Tracker) SetWatermark(ctx context.Context, id string, watermark export.Watermark) error {
+	if t == nil || t.DB == nil {
+		return export.NewError(export.KindNotImpl, "tracker database not configured", nil)
+	}
+	if id == "" {
+		return export.NewError(export.KindValidation, "export ID is required", nil)
+	}
+
+	payload, err := json.Marshal(watermark)
+	if err != nil {
+		return err
+	}
+	res, err := t.DB.NewUpdate().Model((*recordModel)(nil)).
+		Set("watermark_token = ?", watermark.Token).
+		Set("watermark_payload = ?", payload).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return export.NewError(export.KindNotFound, fmt.Sprintf("export %q not found", id), nil)
+	}
+	return nil
+}
+
+// FindByWatermark returns the record that issued a watermark token.
+func (t *Tracker) FindByWatermark(ctx context.Context, token string) (export.ExportRecord, error) {
+	if t == nil || t.DB == nil {
+		return export.ExportRecord{}, export.NewError(export.KindNotImpl, "tracker database not configured", nil)
+	}
+	if token == "" {
+		return export.ExportRecord{}, export.NewError(export.KindValidation, "watermark token is required", nil)
+	}
+
+	model := new(recordModel)
+	err := t.DB.NewSelect().Model(model).Where("watermark_token = ?", token).Limit(1).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return export.ExportRecord{}, export.NewError(export.KindNotFound, fmt.Sprintf("watermark %q not found", token), nil)
+		}
+		return export.ExportRecord{}, err
+	}
+	return model.toRecord()
+}
+
 // Update replaces an export record.
 func (t *Tracker) Update(ctx context.Context, record export.ExportRecord) error {
 	if t == nil || t.DB == nil {
@@ -318,8 +439,14 @@ type recordModel struct {
 	ArtifactKey            string    `bun:"artifact_key"`
 	ArtifactMeta           []byte    `bun:"artifact_meta"`
 	RequestPayload         []byte    `bun:"request_payload"`
+	AccessPayload          []byte    `bun:"access_payload"`
+	ApprovalPayload        []byte    `bun:"approval_payload"`
+	WatermarkToken         string    `bun:"watermark_token"`
+	WatermarkPayload       []byte    `bun:"watermark_payload"`
+	ParametersPayload      []byte    `bun:"parameters_payload"`
 	CreatedAt              time.Time `bun:"created_at"`
 	StartedAt              time.Time `bun:"started_at,nullzero"`
+	HeartbeatAt            time.Time `bun:"heartbeat_at,nullzero"`
 	CompletedAt            time.Time `bun:"completed_at,nullzero"`
 	ExpiresAt              time.Time `bun:"expires_at,nullzero"`
 }
@@ -337,6 +464,33 @@ func modelFromRecord(record export.ExportRecord) (recordModel, error) {
 	if err != nil {
 		return recordModel{}, err
 	}
+	access, err := json.Marshal(record.Access)
+	if err != nil {
+		return recordModel{}, err
+	}
+	var approval []byte
+	if record.Approval != nil {
+		approval, err = json.Marshal(record.Approval)
+		if err != nil {
+			return recordModel{}, err
+		}
+	}
+	var watermark []byte
+	watermarkToken := ""
+	if record.Watermark != nil {
+		watermarkToken = record.Watermark.Token
+		watermark, err = json.Marshal(record.Watermark)
+		if err != nil {
+			return recordModel{}, err
+		}
+	}
+	var parameters []byte
+	if len(record.Parameters) > 0 {
+		parameters, err = json.Marshal(record.Parameters)
+		if err != nil {
+			return recordModel{}, err
+		}
+	}
 	var requestPayload []byte
 	if record.Request.Definition != "" {
 		req := record.Request
@@ -367,8 +521,14 @@ func modelFromRecord(record export.ExportRecord) (recordModel, error) {
 		ArtifactKey:            record.Artifact.Key,
 		ArtifactMeta:           meta,
 		RequestPayload:         requestPayload,
+		AccessPayload:          access,
+		ApprovalPayload:        approval,
+		WatermarkToken:         watermarkToken,
+		WatermarkPayload:       watermark,
+		ParametersPayload:      parameters,
 		CreatedAt:              record.CreatedAt,
 		StartedAt:              record.StartedAt,
+		HeartbeatAt:            record.HeartbeatAt,
 		CompletedAt:            record.CompletedAt,
 		ExpiresAt:              record.ExpiresAt,
 	}, nil
@@ -402,6 +562,7 @@ func (m recordModel) toRecord() (export.ExportRecord, error) {
 		},
 		CreatedAt:   m.CreatedAt,
 		StartedAt:   m.StartedAt,
+		HeartbeatAt: m.HeartbeatAt,
 		CompletedAt: m.CompletedAt,
 		ExpiresAt:   m.ExpiresAt,
 	}
@@ -420,12 +581,35 @@ func (m recordModel) toRecord() (export.ExportRecord, error) {
 		if err := json.Unmarshal(m.ArtifactMeta, &record.Artifact.Meta); err != nil {
 			return export.ExportRecord{}, err
 		}
+		record.Checksum = record.Artifact.Meta.Checksum
 	}
 	if len(m.RequestPayload) > 0 {
 		if err := json.Unmarshal(m.RequestPayload, &record.Request); err != nil {
 			return export.ExportRecord{}, err
 		}
 	}
+	if len(m.AccessPayload) > 0 {
+		if err := json.Unmarshal(m.AccessPayload, &record.Access); err != nil {
+			return export.ExportRecord{}, err
+		}
+	}
+	if len(m.ApprovalPayload) > 0 {
+		record.Approval = &export.ApprovalRecord{}
+		if err := json.Unmarshal(m.ApprovalPayload, record.Approval); err != nil {
+			return export.ExportRecord{}, err
+		}
+	}
+	if len(m.WatermarkPayload) > 0 {
+		record.Watermark = &export.Watermark{}
+		if err := json.Unmarshal(m.WatermarkPayload, record.Watermark); err != nil {
+			return export.ExportRecord{}, err
+		}
+	}
+	if len(m.ParametersPayload) > 0 {
+		if err := json.Unmarshal(m.ParametersPayload, &record.Parameters); err != nil {
+			return export.ExportRecord{}, err
+		}
+	}
 
 	return record, nil
 }
@@ -38,6 +38,13 @@ func TestTracker_StartStatusList(t *testing.T) {
 				ContentType: "text/csv",
 			},
 		},
+		Access: export.ColumnAccess{
+			Columns:  []string{"id", "email"},
+			Redacted: []string{"email"},
+		},
+		Approval: &export.ApprovalRecord{
+			Reasons: []string{"definition requires approval"},
+		},
 	})
 	if err != nil {
 		t.Fatalf("start: %v", err)
@@ -56,6 +63,12 @@ func TestTracker_StartStatusList(t *testing.T) {
 	if got.RequestedBy.ID != "user-1" {
 		t.Fatalf("expected actor, got %q", got.RequestedBy.ID)
 	}
+	if len(got.Access.Columns) != 2 || len(got.Access.Redacted) != 1 {
+		t.Fatalf("expected column access to round-trip, got %+v", got.Access)
+	}
+	if got.Approval == nil || len(got.Approval.Reasons) != 1 {
+		t.Fatalf("expected approval to round-trip, got %+v", got.Approval)
+	}
 
 	list, err := tracker.List(ctx, export.ProgressFilter{Definition: "users"})
 	if err != nil {
@@ -101,6 +114,17 @@ func TestTracker_StateTransitions(t *testing.T) {
 	if got.BytesWritten != 100 {
 		t.Fatalf("expected bytes written, got %d", got.BytesWritten)
 	}
+
+	moved, err := tracker.TransitionState(ctx, recordID, export.StatePendingApproval, export.StateQueued)
+	if err != nil || moved {
+		t.Fatalf("expected transition from another state to be skipped, got %v %v", moved, err)
+	}
+	if moved, err := tracker.TransitionState(ctx, recordID, export.StateCompleted, export.StateFailed); err != nil || !moved {
+		t.Fatalf("expected transition from current state, got %v %v", moved, err)
+	}
+	if _, err := tracker.TransitionState(ctx, "missing", export.StateQueued, export.StateRunning); err == nil {
+		t.Fatalf("expected transition on unknown export to fail")
+	}
 }
 
 func TestTracker_ArtifactUpdateDelete(t *testing.T) {
@@ -166,3 +190,95 @@ func newTestDB(t *testing.T) *bun.DB {
 	}
 	return db
 }
+
+func TestTracker_Watermark(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	tracker := NewTracker(db)
+
+	recordID, err := tracker.Start(ctx, export.ExportRecord{
+		ID:         "exp-wm",
+		Definition: "users",
+		Format:     export.FormatCSV,
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := tracker.SetWatermark(ctx, recordID, export.Watermark{
+		Token:    "wm_123",
+		ExportID: recordID,
+		ActorID:  "user-1",
+	}); err != nil {
+		t.Fatalf("set watermark: %v", err)
+	}
+
+	got, err := tracker.FindByWatermark(ctx, "wm_123")
+	if err != nil {
+		t.Fatalf("find by watermark: %v", err)
+	}
+	if got.ID != recordID || got.Watermark == nil || got.Watermark.ActorID != "user-1" {
+		t.Fatalf("unexpected watermark record: %+v", got)
+	}
+	if _, err := tracker.FindByWatermark(ctx, "wm_missing"); err == nil {
+		t.Fatalf("expected missing watermark to fail")
+	}
+}
+
+func TestTracker_HeartbeatAndStaleReap(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	tracker := NewTracker(db)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	for _, id := range []string{"exp-alive", "exp-stale"} {
+		if _, err := tracker.Start(ctx, export.ExportRecord{ID: id, Definition: "orders", Format: export.FormatCSV, State: export.StateRunning}); err != nil {
+			t.Fatalf("start %s: %v", id, err)
+		}
+	}
+	if err := tracker.Heartbeat(ctx, "exp-alive", now.Add(-time.Minute)); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	if err := tracker.Heartbeat(ctx, "exp-stale", now.Add(-time.Hour)); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	if err := tracker.Heartbeat(ctx, "missing", now); err == nil {
+		t.Fatalf("expected heartbeat on unknown export to fail")
+	}
+
+	got, err := tracker.Status(ctx, "exp-alive")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !got.HeartbeatAt.Equal(now.Add(-time.Minute)) {
+		t.Fatalf("expected heartbeat to round-trip, got %s", got.HeartbeatAt)
+	}
+
+	reaper := &export.StaleReaper{Tracker: tracker}
+	result, err := reaper.Reap(ctx, now)
+	if err != nil {
+		t.Fatalf("reap: %v", err)
+	}
+	if result.Failed != 1 {
+		t.Fatalf("expected one stale export, got %+v", result)
+	}
+	if stale, _ := tracker.Status(ctx, "exp-stale"); stale.State != export.StateFailed {
+		t.Fatalf("expected stale export to fail, got %s", stale.State)
+	}
+	if err := tracker.Heartbeat(ctx, "exp-stale", now); export.KindFromError(err) != export.KindNotFound {
+		t.Fatalf("expected heartbeat on reaped export to be rejected, got %v", err)
+	}
+
+	// A heartbeat or completion after the reaper's read makes the transition a no-op.
+	if moved, err := tracker.TransitionStale(ctx, "exp-alive", now.Add(-time.Hour), export.StateFailed); err != nil || moved {
+		t.Fatalf("expected changed heartbeat to block the transition, got %v %v", moved, err)
+	}
+	if err := tracker.Complete(ctx, "exp-alive", nil); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if moved, err := tracker.TransitionStale(ctx, "exp-alive", now.Add(-time.Minute), export.StateFailed); err != nil || moved {
+		t.Fatalf("expected completed export to be skipped, got %v %v", moved, err)
+	}
+	if alive, _ := tracker.Status(ctx, "exp-alive"); alive.State != export.StateCompleted {
+		t.Fatalf("expected completed export kept, got %s", alive.State)
+	}
+}
@@ -71,6 +71,60 @@ func (h *DeleteExportHandler) Execute(ctx context.Context, msg DeleteExport) err
 	return h.Service.DeleteExport(ctx, msg.Actor, msg.ExportID)
 }
 
+// ApproveExportHandler approves pending exports.
+type ApproveExportHandler struct {
+	Service export.Service
+}
+
+func NewApproveExportHandler(svc export.Service) *ApproveExportHandler {
+	return &ApproveExportHandler{Service: svc}
+}
+
+func (h *ApproveExportHandler) Execute(ctx context.Context, msg ApproveExport) error {
+	if h == nil || h.Service == nil {
+		return errors.New("export service is required", errors.CategoryInternal).
+			WithTextCode("SERVICE_REQUIRED")
+	}
+	record, err := h.Service.ApproveExport(ctx, msg.Actor, msg.ExportID, msg.Comment)
+	if err != nil {
+		return err
+	}
+	if msg.Result != nil {
+		*msg.Result = record
+	}
+	if res := gcmd.ResultFromContext[export.ExportRecord](ctx); res != nil {
+		res.Store(record)
+	}
+	return nil
+}
+
+// RejectExportHandler rejects pending exports.
+type RejectExportHandler struct {
+	Service export.Service
+}
+
+func NewRejectExportHandler(svc export.Service) *RejectExportHandler {
+	return &RejectExportHandler{Service: svc}
+}
+
+func (h *RejectExportHandler) Execute(ctx context.Context, msg RejectExport) error {
+	if h == nil || h.Service == nil {
+		return errors.New("export service is required", errors.CategoryInternal).
+			WithTextCode("SERVICE_REQUIRED")
+	}
+	record, err := h.Service.RejectExport(ctx, msg.Actor, msg.ExportID, msg.Comment)
+	if err != nil {
+		return err
+	}
+	if msg.Result != nil {
+		*msg.Result = record
+	}
+	if res := gcmd.ResultFromContext[export.ExportRecord](ctx); res != nil {
+		res.Store(record)
+	}
+	return nil
+}
+
 // GenerateExportHandler runs export generation jobs.
 type GenerateExportHandler struct {
 	Service export.Service
@@ -98,11 +152,14 @@ func (h *GenerateExportHandler) Execute(ctx context.Context, msg GenerateExport)
 	return nil
 }
 
-// CleanupExportsHandler removes expired exports.
+// CleanupExportsHandler removes expired exports. Set Locker so cron runs
+// happen once per LeaseWindow across replicas.
 type CleanupExportsHandler struct {
-	Service export.Service
-	Config  gcmd.HandlerConfig
-	Clock   func() time.Time
+	Service     export.Service
+	Config      gcmd.HandlerConfig
+	Clock       func() time.Time
+	Locker      export.Locker
+	LeaseWindow time.Duration
 }
 
 func NewCleanupExportsHandler(svc export.Service) *CleanupExportsHandler {
@@ -133,10 +190,149 @@ func (h *CleanupExportsHandler) Execute(ctx context.Context, msg CleanupExports)
 
 func (h *CleanupExportsHandler) CronHandler() func() error {
 	return func() error {
-		return h.Execute(context.Background(), CleanupExports{})
+		ctx := context.Background()
+		if h != nil && h.Locker != nil {
+			now := time.Now()
+			if h.Clock != nil {
+				now = h.Clock()
+			}
+			_, acquired, err := export.AcquireRunLease(ctx, h.Locker, "exports-cleanup", h.LeaseWindow, now)
+			if err != nil || !acquired {
+				return err
+			}
+		}
+		return h.Execute(ctx, CleanupExports{})
 	}
 }
 
 func (h *CleanupExportsHandler) CronOptions() gcmd.HandlerConfig {
 	return h.Config
 }
+
+// ReapStaleExportsHandler runs the stale export reaper. Set Locker so cron
+// runs happen once per LeaseWindow across replicas.
+type ReapStaleExportsHandler struct {
+	Reaper      *export.StaleReaper
+	Config      gcmd.HandlerConfig
+	Clock       func() time.Time
+	Locker      export.Locker
+	LeaseWindow time.Duration
+}
+
+func NewReapStaleExportsHandler(reaper *export.StaleReaper) *ReapStaleExportsHandler {
+	return &ReapStaleExportsHandler{Reaper: reaper}
+}
+
+func (h *ReapStaleExportsHandler) Execute(ctx context.Context, msg ReapStaleExports) error {
+	if h == nil || h.Reaper == nil {
+		return errors.New("stale reaper is required", errors.CategoryInternal).
+			WithTextCode("REAPER_REQUIRED")
+	}
+	now := msg.Now
+	if now.IsZero() && h.Clock != nil {
+		now = h.Clock()
+	}
+	result, err := h.Reaper.Reap(ctx, now)
+	if err != nil {
+		return err
+	}
+	if msg.Result != nil {
+		*msg.Result = result
+	}
+	if res := gcmd.ResultFromContext[export.ReapResult](ctx); res != nil {
+		res.Store(result)
+	}
+	return nil
+}
+
+func (h *ReapStaleExportsHandler) CronHandler() func() error {
+	return func() error {
+		ctx := context.Background()
+		if h != nil && h.Locker != nil {
+			now := time.Now()
+			if h.Clock != nil {
+				now = h.Clock()
+			}
+			_, acquired, err := export.AcquireRunLease(ctx, h.Locker, "exports-reap-stale", h.LeaseWindow, now)
+			if err != nil || !acquired {
+				return err
+			}
+		}
+		return h.Execute(ctx, ReapStaleExports{})
+	}
+}
+
+func (h *ReapStaleExportsHandler) CronOptions() gcmd.HandlerConfig {
+	return h.Config
+}
+
+// CreateScheduleHandler creates schedules.
+type CreateScheduleHandler struct {
+	Schedules export.ScheduleManager
+}
+
+func NewCreateScheduleHandler(schedules export.ScheduleManager) *CreateScheduleHandler {
+	return &CreateScheduleHandler{Schedules: schedules}
+}
+
+func (h *CreateScheduleHandler) Execute(ctx context.Context, msg CreateSchedule) error {
+	if h == nil || h.Schedules == nil {
+		return errors.New("schedule service is required", errors.CategoryInternal).
+			WithTextCode("SCHEDULE_SERVICE_REQUIRED")
+	}
+	schedule, err := h.Schedules.CreateSchedule(ctx, msg.Actor, msg.Schedule)
+	if err != nil {
+		return err
+	}
+	if msg.Result != nil {
+		*msg.Result = schedule
+	}
+	if res := gcmd.ResultFromContext[export.Schedule](ctx); res != nil {
+		res.Store(schedule)
+	}
+	return nil
+}
+
+// UpdateScheduleHandler updates schedules.
+type UpdateScheduleHandler struct {
+	Schedules export.ScheduleManager
+}
+
+func NewUpdateScheduleHandler(schedules export.ScheduleManager) *UpdateScheduleHandler {
+	return &UpdateScheduleHandler{Schedules: schedules}
+}
+
+func (h *UpdateScheduleHandler) Execute(ctx context.Context, msg UpdateSchedule) error {
+	if h == nil || h.Schedules == nil {
+		return errors.New("schedule service is required", errors.CategoryInternal).
+			WithTextCode("SCHEDULE_SERVICE_REQUIRED")
+	}
+	schedule, err := h.Schedules.UpdateSchedule(ctx, msg.Actor, msg.Schedule)
+	if err != nil {
+		return err
+	}
+	if msg.Result != nil {
+		*msg.Result = schedule
+	}
+	if res := gcmd.ResultFromContext[export.Schedule](ctx); res != nil {
+		res.Store(schedule)
+	}
+	return nil
+}
+
+// DeleteScheduleHandler deletes schedules.
+type DeleteScheduleHandler struct {
+	Schedules export.ScheduleManager
+}
+
+func NewDeleteScheduleHandler(schedules export.ScheduleManager) *DeleteScheduleHandler {
+	return &DeleteScheduleHandler{Schedules: schedules}
+}
+
+func (h *DeleteScheduleHandler) Execute(ctx context.Context, msg DeleteSchedule) error {
+	if h == nil || h.Schedules == nil {
+		return errors.New("schedule service is required", errors.CategoryInternal).
+			WithTextCode("SCHEDULE_SERVICE_REQUIRED")
+	}
+	return h.Schedules.DeleteSchedule(ctx, msg.Actor, msg.ScheduleID)
+}
@@ -19,6 +19,8 @@ type stubService struct {
 	history  func(ctx context.Context, actor export.Actor, filter export.ProgressFilter) ([]export.ExportRecord, error)
 	download func(ctx context.Context, actor export.Actor, exportID string) (export.DownloadInfo, error)
 	cleanup  func(ctx context.Context, now time.Time) (int, error)
+	approve  func(ctx context.Context, actor export.Actor, exportID, comment string) (export.ExportRecord, error)
+	reject   func(ctx context.Context, actor export.Actor, exportID, comment string) (export.ExportRecord, error)
 }
 
 func (s *stubService) RequestExport(ctx context.Context, actor export.Actor, req export.ExportRequest) (export.ExportRecord, error) {
@@ -77,6 +79,48 @@ func (s *stubService) Cleanup(ctx context.Context, now time.Time) (int, error) {
 	return 0, nil
 }
 
+func (s *stubService) ApproveExport(ctx context.Context, actor export.Actor, exportID, comment string) (export.ExportRecord, error) {
+	if s.approve != nil {
+		return s.approve(ctx, actor, exportID, comment)
+	}
+	return export.ExportRecord{}, nil
+}
+
+func (s *stubService) RejectExport(ctx context.Context, actor export.Actor, exportID, comment string) (export.ExportRecord, error) {
+	if s.reject != nil {
+		return s.reject(ctx, actor, exportID, comment)
+	}
+	return export.ExportRecord{}, nil
+}
+
+func (s *stubService) RecordDownload(ctx context.Context, actor export.Actor, download export.DownloadRecord) error {
+	return nil
+}
+
+func (s *stubService) Downloads(ctx context.Context, actor export.Actor, exportID string) ([]export.DownloadRecord, error) {
+	return nil, nil
+}
+
+func (s *stubService) Deliveries(ctx context.Context, actor export.Actor, exportID string) ([]export.DeliveryRecord, error) {
+	return nil, nil
+}
+
+func (s *stubService) DeliveryHistory(ctx context.Context, actor export.Actor, filter export.DeliveryFilter) ([]export.DeliveryRecord, error) {
+	return nil, nil
+}
+
+func (s *stubService) VerifyExport(ctx context.Context, actor export.Actor, exportID string) (export.ChecksumVerification, error) {
+	return export.ChecksumVerification{}, nil
+}
+
+func (s *stubService) VerifyManifest(ctx context.Context, actor export.Actor, exportID string) (export.ManifestVerification, error) {
+	return export.ManifestVerification{}, nil
+}
+
+func (s *stubService) LookupWatermark(ctx context.Context, actor export.Actor, token string) (export.ExportRecord, error) {
+	return export.ExportRecord{}, nil
+}
+
 type denyGuard struct {
 	exportCalls   int
 	downloadCalls int
@@ -166,3 +210,35 @@ func TestCancelExportHandler_GuardBlocks(t *testing.T) {
 		t.Fatalf("expected download guard to be called")
 	}
 }
+
+func TestApproveExportHandler_StoresResults(t *testing.T) {
+	svc := &stubService{
+		approve: func(ctx context.Context, actor export.Actor, exportID, comment string) (export.ExportRecord, error) {
+			_ = ctx
+			return export.ExportRecord{
+				ID:    exportID,
+				State: export.StateQueued,
+				Approval: &export.ApprovalRecord{
+					Decision:  export.ApprovalApproved,
+					DecidedBy: actor,
+					Comment:   comment,
+				},
+			}, nil
+		},
+	}
+
+	handler := NewApproveExportHandler(svc)
+	var got export.ExportRecord
+	err := handler.Execute(context.Background(), ApproveExport{
+		Actor:    export.Actor{ID: "approver-1"},
+		ExportID: "exp-1",
+		Comment:  "ok",
+		Result:   &got,
+	})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got.Approval == nil || got.Approval.DecidedBy.ID != "approver-1" || got.Approval.Comment != "ok" {
+		t.Fatalf("unexpected approval: %+v", got.Approval)
+	}
+}
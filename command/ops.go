@@ -3,6 +3,7 @@ package command
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"os"
 	"strings"
 	"time"
@@ -13,9 +14,11 @@ import (
 )
 
 // BatchRequest describes a request for backfill/scheduled exports.
+// ScheduleID is set on runs claimed from a stored schedule.
 type BatchRequest struct {
-	Actor   export.Actor         `json:"actor"`
-	Request export.ExportRequest `json:"request"`
+	Actor      export.Actor         `json:"actor"`
+	Request    export.ExportRequest `json:"request"`
+	ScheduleID string               `json:"schedule_id,omitempty"`
 }
 
 // BatchLoader loads batch requests from a source.
@@ -44,19 +47,25 @@ func (f BatchExecutorFunc) ExecuteExport(ctx context.Context, actor export.Actor
 
 // BatchCommand wires CLI/Cron execution for batch exports.
 type BatchCommand struct {
-	requester  BatchRequester
-	executor   BatchExecutor
-	loader     BatchLoader
-	cliConfig  gcmd.CLIConfig
-	cronConfig gcmd.HandlerConfig
-	limits     BatchLimits
-	sleep      func(time.Duration)
+	requester   BatchRequester
+	executor    BatchExecutor
+	loader      BatchLoader
+	cliConfig   gcmd.CLIConfig
+	cronConfig  gcmd.HandlerConfig
+	limits      BatchLimits
+	sleep       func(time.Duration)
+	locker      export.Locker
+	leaseWindow time.Duration
+	now         func() time.Time
+	logger      export.Logger
 }
 
 // BatchOption customizes batch commands.
 type BatchOption func(*BatchCommand)
 
-// BatchLimits bounds batch execution throughput.
+// BatchLimits bounds batch execution throughput. MaxRequests counts
+// successful requests and does not apply to claimed schedule runs, which
+// cannot be retried once claimed.
 type BatchLimits struct {
 	MaxRequests int
 	MinInterval time.Duration
@@ -90,6 +99,24 @@ func WithBatchExecutor(executor BatchExecutor) BatchOption {
 	}
 }
 
+// WithBatchLease makes cron runs acquire a lease per run window, so the
+// command runs once across replicas. Set window to the cron interval.
+func WithBatchLease(locker export.Locker, window time.Duration) BatchOption {
+	return func(cmd *BatchCommand) {
+		cmd.locker = locker
+		cmd.leaseWindow = window
+	}
+}
+
+// WithBatchLogger logs requests that fail during a run.
+func WithBatchLogger(logger export.Logger) BatchOption {
+	return func(cmd *BatchCommand) {
+		if logger != nil {
+			cmd.logger = logger
+		}
+	}
+}
+
 // NewBackfillCommand creates a backfill CLI/Cron command.
 func NewBackfillCommand(requester BatchRequester, loader BatchLoader, opts ...BatchOption) *BatchCommand {
 	cmd := &BatchCommand{
@@ -102,6 +129,8 @@ func NewBackfillCommand(requester BatchRequester, loader BatchLoader, opts ...Ba
 		},
 		cronConfig: gcmd.HandlerConfig{Expression: "0 0 * * *"},
 		sleep:      time.Sleep,
+		now:        time.Now,
+		logger:     export.NopLogger(),
 	}
 	for _, opt := range opts {
 		if opt != nil {
@@ -123,6 +152,8 @@ func NewScheduledExportsCommand(requester BatchRequester, loader BatchLoader, op
 		},
 		cronConfig: gcmd.HandlerConfig{Expression: "0 * * * *"},
 		sleep:      time.Sleep,
+		now:        time.Now,
+		logger:     export.NopLogger(),
 	}
 	for _, opt := range opts {
 		if opt != nil {
@@ -132,14 +163,29 @@ func NewScheduledExportsCommand(requester BatchRequester, loader BatchLoader, op
 	return cmd
 }
 
-// CronHandler executes scheduled batch exports.
+// CronHandler executes scheduled batch exports. With a lease configured,
+// replicas that lose the lease for the current window skip the run.
 func (c *BatchCommand) CronHandler() func() error {
 	return func() error {
-		_, err := c.run(context.Background(), "")
+		ctx := context.Background()
+		if c != nil && c.locker != nil {
+			_, acquired, err := export.AcquireRunLease(ctx, c.locker, c.leaseName(), c.leaseWindow, c.now())
+			if err != nil || !acquired {
+				return err
+			}
+		}
+		_, err := c.run(ctx, "")
 		return err
 	}
 }
 
+func (c *BatchCommand) leaseName() string {
+	if len(c.cliConfig.Path) > 0 {
+		return strings.Join(c.cliConfig.Path, ":")
+	}
+	return "exports-batch"
+}
+
 // CronOptions returns cron configuration.
 func (c *BatchCommand) CronOptions() gcmd.HandlerConfig {
 	if c == nil {
@@ -171,32 +217,81 @@ func (c *BatchCommand) run(ctx context.Context, from string) (int, error) {
 			WithTextCode("REQUESTER_REQUIRED")
 	}
 
-	requests, err := c.loadRequests(ctx, from)
-	if err != nil {
-		return 0, err
+	// Loaders may return the runs they claimed together with an error for
+	// the ones they could not load; the claimed runs still execute.
+	requests, loadErr := c.loadRequests(ctx, from)
+	var claimed []BatchRequest
+	pending := make([]BatchRequest, 0, len(requests))
+	for _, item := range requests {
+		if item.ScheduleID != "" {
+			claimed = append(claimed, item)
+			continue
+		}
+		pending = append(pending, item)
+	}
+	if loadErr != nil && len(claimed) == 0 {
+		return 0, loadErr
 	}
 
-	count := 0
-	for _, item := range requests {
-		if c.limits.MaxRequests > 0 && count >= c.limits.MaxRequests {
+	count, claimedErr := c.runClaimed(ctx, claimed)
+	if loadErr != nil {
+		return count, stderrors.Join(loadErr, claimedErr)
+	}
+	sent := 0
+	for _, item := range pending {
+		if c.limits.MaxRequests > 0 && sent >= c.limits.MaxRequests {
 			break
 		}
-		req := item.Request
-		req.Delivery = export.DeliveryAsync
-		req.Output = nil
-		if c.executor != nil {
-			if _, err := c.executor.ExecuteExport(ctx, item.Actor, req); err != nil {
-				return count, err
-			}
-		} else if _, err := c.requester.RequestExport(ctx, item.Actor, req); err != nil {
-			return count, err
+		if err := c.execute(ctx, item); err != nil {
+			return count, stderrors.Join(claimedErr, err)
 		}
+		sent++
 		count++
 		if c.limits.MinInterval > 0 && c.sleep != nil {
 			c.sleep(c.limits.MinInterval)
 		}
 	}
-	return count, nil
+	return count, claimedErr
+}
+
+// runClaimed executes claimed schedule runs. Claimed runs cannot be retried,
+// so every run is attempted regardless of MaxRequests and failures are
+// logged and joined.
+func (c *BatchCommand) runClaimed(ctx context.Context, claimed []BatchRequest) (int, error) {
+	logger := c.logger
+	if logger == nil {
+		logger = export.NopLogger()
+	}
+	var errs []error
+	count := 0
+	for _, item := range claimed {
+		if err := c.execute(ctx, item); err != nil {
+			logger.Error("scheduled export run failed", "error", err,
+				"definition", item.Request.Definition, "actor_id", item.Actor.ID, "schedule_id", item.ScheduleID)
+			errs = append(errs, err)
+		} else {
+			count++
+		}
+		if c.limits.MinInterval > 0 && c.sleep != nil {
+			c.sleep(c.limits.MinInterval)
+		}
+	}
+	return count, stderrors.Join(errs...)
+}
+
+func (c *BatchCommand) execute(ctx context.Context, item BatchRequest) error {
+	req, err := export.ResolveParams(item.Request, export.ParamContext{})
+	if err != nil {
+		return err
+	}
+	req.Delivery = export.DeliveryAsync
+	req.Output = nil
+	if c.executor != nil {
+		_, err = c.executor.ExecuteExport(ctx, item.Actor, req)
+		return err
+	}
+	_, err = c.requester.RequestExport(ctx, item.Actor, req)
+	return err
 }
 
 func (c *BatchCommand) loadRequests(ctx context.Context, from string) ([]BatchRequest, error) {
@@ -239,6 +334,38 @@ func loadBatchRequestsFromFile(path string) ([]BatchRequest, error) {
 	return requests, nil
 }
 
+// NewScheduleLoader returns a BatchLoader that claims due export schedule
+// runs, so NewScheduledExportsCommand runs stored schedules as their owners
+// with Request.ScheduledAt set to each run's slot. Runs whose parameters do
+// not resolve are skipped and reported in the error returned alongside the
+// other runs. Run the command at least as often as the finest schedule
+// (e.g. every minute).
+func NewScheduleLoader(claimer export.ScheduleClaimer) BatchLoader {
+	return func(ctx context.Context) ([]BatchRequest, error) {
+		if claimer == nil {
+			return nil, errors.New("schedule claimer is required", errors.CategoryInternal).
+				WithTextCode("SCHEDULE_CLAIMER_REQUIRED")
+		}
+		runs, err := claimer.ClaimDue(ctx, export.ScheduleKindExport, time.Time{})
+		errs := []error{err}
+		requests := make([]BatchRequest, 0, len(runs))
+		for _, run := range runs {
+			req := run.Schedule.Export
+			req.ScheduledAt = run.RunAt
+			req, err := export.ResolveParams(req, export.ParamContext{
+				RunTime:  run.RunAt,
+				Timezone: run.Schedule.Timezone,
+			})
+			if err != nil {
+				errs = append(errs, export.AsGoError(err).WithMetadata(map[string]any{"schedule_id": run.Schedule.ID}))
+				continue
+			}
+			requests = append(requests, BatchRequest{Actor: run.Schedule.Owner, Request: req, ScheduleID: run.Schedule.ID})
+		}
+		return requests, stderrors.Join(errs...)
+	}
+}
+
 // DefinitionBatch builds PDF batch requests for a definition list.
 type DefinitionBatch struct {
 	Actor       export.Actor
@@ -278,6 +405,8 @@ func BuildPDFBatchRequests(batch DefinitionBatch) []BatchRequest {
 				EstimatedBytes:    req.EstimatedBytes,
 				EstimatedDuration: req.EstimatedDuration,
 				RenderOptions:     req.RenderOptions,
+				ScheduledAt:       req.ScheduledAt,
+				Parameters:        req.Parameters,
 			},
 		}
 		requests = append(requests, item)
@@ -310,3 +439,29 @@ func (c *cleanupCLI) Run() error {
 	}
 	return c.handler.Execute(context.Background(), CleanupExports{})
 }
+
+// CLIHandler exposes the stale reaper via CLI.
+func (h *ReapStaleExportsHandler) CLIHandler() any {
+	return &reapStaleCLI{handler: h}
+}
+
+// CLIOptions describes stale reaper CLI metadata.
+func (h *ReapStaleExportsHandler) CLIOptions() gcmd.CLIConfig {
+	return gcmd.CLIConfig{
+		Path:        []string{"exports-reap-stale"},
+		Description: "Fail or re-enqueue exports whose worker stopped",
+		Group:       "exports",
+	}
+}
+
+type reapStaleCLI struct {
+	handler *ReapStaleExportsHandler
+}
+
+func (c *reapStaleCLI) Run() error {
+	if c == nil || c.handler == nil {
+		return errors.New("stale reaper handler is required", errors.CategoryInternal).
+			WithTextCode("REAPER_HANDLER_REQUIRED")
+	}
+	return c.handler.Execute(context.Background(), ReapStaleExports{})
+}
@@ -86,3 +86,140 @@ func TestBatchCommand_RunUsesExecutorWhenProvided(t *testing.T) {
 		t.Fatalf("expected executor count 2, got %d", calls)
 	}
 }
+
+func TestScheduleLoader_ResolvesParamsInScheduleTimezone(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2024, 4, 30, 12, 0, 0, 0, time.UTC)
+	now := created
+	svc := export.NewScheduleService(export.ScheduleServiceConfig{
+		Store: export.NewMemoryScheduleStore(),
+		Now:   func() time.Time { return now },
+	})
+	if _, err := svc.CreateSchedule(ctx, export.Actor{ID: "u1"}, export.Schedule{
+		Kind:     export.ScheduleKindExport,
+		Cron:     "0 6 1 * *",
+		Timezone: "Europe/Madrid",
+		Enabled:  true,
+		Export: export.ExportRequest{
+			Definition: "invoices",
+			Query:      map[string]any{"from": `{{ start_of_month -1 | date "2006-01-02" }}`},
+		},
+	}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	now = time.Date(2024, 5, 1, 4, 1, 0, 0, time.UTC)
+	var got export.ExportRequest
+	executor := BatchExecutorFunc(func(ctx context.Context, actor export.Actor, req export.ExportRequest) (export.ExportRecord, error) {
+		got = req
+		return export.ExportRecord{ID: "exp-1"}, nil
+	})
+	cmd := NewScheduledExportsCommand(nil, NewScheduleLoader(svc), WithBatchExecutor(executor))
+	if _, err := cmd.run(ctx, ""); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if from := got.Query.(map[string]any)["from"]; from != "2024-04-01" {
+		t.Fatalf("expected last month's start, got %v", from)
+	}
+	if got.Parameters["query.from"] != "2024-04-01" || got.Parameters["run_time"] != "2024-05-01T06:00:00+02:00" {
+		t.Fatalf("unexpected recorded parameters %v", got.Parameters)
+	}
+}
+
+func TestBatchCommand_CronHandlerRunsOncePerLeaseWindow(t *testing.T) {
+	requester := &captureBatchRequester{}
+	loader := func(ctx context.Context) ([]BatchRequest, error) {
+		return []BatchRequest{{Actor: export.Actor{ID: "actor-1"}, Request: export.ExportRequest{Definition: "users"}}}, nil
+	}
+	now := time.Date(2024, 5, 1, 10, 0, 1, 0, time.UTC)
+	locker := export.NewMemoryLocker()
+	locker.Now = func() time.Time { return now }
+
+	replicas := make([]*BatchCommand, 3)
+	for i := range replicas {
+		replicas[i] = NewScheduledExportsCommand(requester, loader, WithBatchLease(locker, time.Minute))
+		replicas[i].now = func() time.Time { return now }
+	}
+	for _, replica := range replicas {
+		if err := replica.CronHandler()(); err != nil {
+			t.Fatalf("cron: %v", err)
+		}
+	}
+	if requester.count != 1 {
+		t.Fatalf("expected one run across replicas, got %d", requester.count)
+	}
+
+	now = now.Add(time.Minute)
+	if err := replicas[1].CronHandler()(); err != nil {
+		t.Fatalf("cron: %v", err)
+	}
+	if requester.count != 2 {
+		t.Fatalf("expected the next window to run, got %d", requester.count)
+	}
+}
+
+type failingBatchRequester struct {
+	calls int
+	fail  string
+}
+
+func (f *failingBatchRequester) RequestExport(ctx context.Context, actor export.Actor, req export.ExportRequest) (export.ExportRecord, error) {
+	f.calls++
+	if req.Definition == f.fail {
+		return export.ExportRecord{}, export.NewError(export.KindExternal, "queue unavailable", nil)
+	}
+	return export.ExportRecord{ID: "exp-1"}, nil
+}
+
+func TestBatchCommand_RunKeepsClaimedRunsAfterFailure(t *testing.T) {
+	requester := &failingBatchRequester{fail: "teams"}
+	loader := func(ctx context.Context) ([]BatchRequest, error) {
+		return []BatchRequest{
+			{Actor: export.Actor{ID: "actor-1"}, Request: export.ExportRequest{Definition: "users"}, ScheduleID: "sch-1"},
+			{Actor: export.Actor{ID: "actor-1"}, Request: export.ExportRequest{Definition: "teams"}, ScheduleID: "sch-2"},
+			{Actor: export.Actor{ID: "actor-1"}, Request: export.ExportRequest{Definition: "orders"}, ScheduleID: "sch-3"},
+		}, nil
+	}
+
+	cmd := NewScheduledExportsCommand(requester, loader, WithBatchLimits(BatchLimits{MaxRequests: 1}))
+	count, err := cmd.run(context.Background(), "")
+	if err == nil {
+		t.Fatalf("expected the failed run to be reported")
+	}
+	if requester.calls != 3 || count != 2 {
+		t.Fatalf("expected every claimed run attempted, got calls=%d count=%d", requester.calls, count)
+	}
+}
+
+func TestBatchCommand_RunStopsLoaderRequestsOnFirstFailure(t *testing.T) {
+	requester := &failingBatchRequester{fail: "teams"}
+	loader := func(ctx context.Context) ([]BatchRequest, error) {
+		return []BatchRequest{
+			{Actor: export.Actor{ID: "actor-1"}, Request: export.ExportRequest{Definition: "users"}},
+			{Actor: export.Actor{ID: "actor-1"}, Request: export.ExportRequest{Definition: "teams"}},
+			{Actor: export.Actor{ID: "actor-1"}, Request: export.ExportRequest{Definition: "orders"}},
+		}, nil
+	}
+
+	var sleeps int
+	cmd := NewBackfillCommand(requester, loader, WithBatchLimits(BatchLimits{MaxRequests: 2, MinInterval: time.Millisecond}))
+	cmd.sleep = func(time.Duration) { sleeps++ }
+	count, err := cmd.run(context.Background(), "")
+	if err == nil {
+		t.Fatalf("expected the failed request to stop the batch")
+	}
+	if requester.calls != 2 || count != 1 || sleeps != 1 {
+		t.Fatalf("expected the batch to stop at the failure, got calls=%d count=%d sleeps=%d", requester.calls, count, sleeps)
+	}
+
+	requester = &failingBatchRequester{}
+	cmd = NewBackfillCommand(requester, func(ctx context.Context) ([]BatchRequest, error) {
+		items, _ := loader(ctx)
+		return append([]BatchRequest{{Actor: export.Actor{ID: "actor-1"}, Request: export.ExportRequest{Definition: "audit"}, ScheduleID: "sch-1"}}, items...), nil
+	}, WithBatchLimits(BatchLimits{MaxRequests: 2}))
+	count, err = cmd.run(context.Background(), "")
+	if err != nil || requester.calls != 3 || count != 3 {
+		t.Fatalf("expected the claimed run plus 2 loader requests, got calls=%d count=%d err=%v", requester.calls, count, err)
+	}
+}
@@ -68,6 +68,46 @@ func (msg DeleteExport) Validate() error {
 	return nil
 }
 
+// ApproveExport approves an export pending approval.
+type ApproveExport struct {
+	Actor    export.Actor
+	ExportID string
+	Comment  string
+	Result   *export.ExportRecord
+}
+
+func (ApproveExport) Type() string { return "export:approve" }
+
+func (msg ApproveExport) Validate() error {
+	return validateDecision(msg.Actor, msg.ExportID)
+}
+
+// RejectExport rejects an export pending approval.
+type RejectExport struct {
+	Actor    export.Actor
+	ExportID string
+	Comment  string
+	Result   *export.ExportRecord
+}
+
+func (RejectExport) Type() string { return "export:reject" }
+
+func (msg RejectExport) Validate() error {
+	return validateDecision(msg.Actor, msg.ExportID)
+}
+
+func validateDecision(actor export.Actor, exportID string) error {
+	if actor.ID == "" {
+		return errors.New("actor ID is required", errors.CategoryValidation).
+			WithTextCode("ACTOR_REQUIRED")
+	}
+	if exportID == "" {
+		return errors.New("export ID is required", errors.CategoryValidation).
+			WithTextCode("EXPORT_ID_REQUIRED")
+	}
+	return nil
+}
+
 // GenerateExport runs an export generation job.
 type GenerateExport struct {
 	Actor    export.Actor
@@ -103,3 +143,75 @@ type CleanupExports struct {
 func (CleanupExports) Type() string { return "export:cleanup" }
 
 func (CleanupExports) Validate() error { return nil }
+
+// ReapStaleExports fails or re-enqueues running exports without a recent heartbeat.
+type ReapStaleExports struct {
+	Now    time.Time
+	Result *export.ReapResult
+}
+
+func (ReapStaleExports) Type() string { return "export:reap_stale" }
+
+func (ReapStaleExports) Validate() error { return nil }
+
+// CreateSchedule stores a new export or delivery schedule.
+type CreateSchedule struct {
+	Actor    export.Actor
+	Schedule export.Schedule
+	Result   *export.Schedule
+}
+
+func (CreateSchedule) Type() string { return "export:schedule:create" }
+
+func (msg CreateSchedule) Validate() error {
+	if msg.Actor.ID == "" {
+		return errors.New("actor ID is required", errors.CategoryValidation).
+			WithTextCode("ACTOR_REQUIRED")
+	}
+	if msg.Schedule.Cron == "" {
+		return errors.New("schedule cron is required", errors.CategoryValidation).
+			WithTextCode("SCHEDULE_CRON_REQUIRED")
+	}
+	return nil
+}
+
+// UpdateSchedule replaces an existing schedule.
+type UpdateSchedule struct {
+	Actor    export.Actor
+	Schedule export.Schedule
+	Result   *export.Schedule
+}
+
+func (UpdateSchedule) Type() string { return "export:schedule:update" }
+
+func (msg UpdateSchedule) Validate() error {
+	if msg.Actor.ID == "" {
+		return errors.New("actor ID is required", errors.CategoryValidation).
+			WithTextCode("ACTOR_REQUIRED")
+	}
+	if msg.Schedule.ID == "" {
+		return errors.New("schedule ID is required", errors.CategoryValidation).
+			WithTextCode("SCHEDULE_ID_REQUIRED")
+	}
+	return nil
+}
+
+// DeleteSchedule removes a schedule.
+type DeleteSchedule struct {
+	Actor      export.Actor
+	ScheduleID string
+}
+
+func (DeleteSchedule) Type() string { return "export:schedule:delete" }
+
+func (msg DeleteSchedule) Validate() error {
+	if msg.Actor.ID == "" {
+		return errors.New("actor ID is required", errors.CategoryValidation).
+			WithTextCode("ACTOR_REQUIRED")
+	}
+	if msg.ScheduleID == "" {
+		return errors.New("schedule ID is required", errors.CategoryValidation).
+			WithTextCode("SCHEDULE_ID_REQUIRED")
+	}
+	return nil
+}
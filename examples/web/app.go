@@ -108,6 +108,7 @@ func NewApp(ctx context.Context, cfg config.Config) (*App, error) {
 		Guard:          &NoOpGuard{},
 		DeliveryPolicy: deliveryPolicy,
 		CancelHook:     cancelRegistry,
+		DownloadLog:    export.NewMemoryDownloadLog(),
 	})
 	service := baseService
 	baseURL := buildServerBaseURL(cfg.Server)
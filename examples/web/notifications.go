@@ -324,6 +324,42 @@ func (s *notifyingService) Cleanup(ctx context.Context, now time.Time) (int, err
 	return s.base.Cleanup(ctx, now)
 }
 
+func (s *notifyingService) ApproveExport(ctx context.Context, actor export.Actor, exportID, comment string) (export.ExportRecord, error) {
+	return s.base.ApproveExport(ctx, actor, exportID, comment)
+}
+
+func (s *notifyingService) RejectExport(ctx context.Context, actor export.Actor, exportID, comment string) (export.ExportRecord, error) {
+	return s.base.RejectExport(ctx, actor, exportID, comment)
+}
+
+func (s *notifyingService) RecordDownload(ctx context.Context, actor export.Actor, download export.DownloadRecord) error {
+	return s.base.RecordDownload(ctx, actor, download)
+}
+
+func (s *notifyingService) Downloads(ctx context.Context, actor export.Actor, exportID string) ([]export.DownloadRecord, error) {
+	return s.base.Downloads(ctx, actor, exportID)
+}
+
+func (s *notifyingService) VerifyExport(ctx context.Context, actor export.Actor, exportID string) (export.ChecksumVerification, error) {
+	return s.base.VerifyExport(ctx, actor, exportID)
+}
+
+func (s *notifyingService) Deliveries(ctx context.Context, actor export.Actor, exportID string) ([]export.DeliveryRecord, error) {
+	return s.base.Deliveries(ctx, actor, exportID)
+}
+
+func (s *notifyingService) DeliveryHistory(ctx context.Context, actor export.Actor, filter export.DeliveryFilter) ([]export.DeliveryRecord, error) {
+	return s.base.DeliveryHistory(ctx, actor, filter)
+}
+
+func (s *notifyingService) VerifyManifest(ctx context.Context, actor export.Actor, exportID string) (export.ManifestVerification, error) {
+	return s.base.VerifyManifest(ctx, actor, exportID)
+}
+
+func (s *notifyingService) LookupWatermark(ctx context.Context, actor export.Actor, token string) (export.ExportRecord, error) {
+	return s.base.LookupWatermark(ctx, actor, token)
+}
+
 func (s *notifyingService) notifyFromResult(ctx context.Context, actor export.Actor, req export.ExportRequest, result export.ExportResult, exportID string) {
 	if s == nil || s.notifier == nil || s.store == nil {
 		return
@@ -403,6 +439,7 @@ func (s *notifyingService) sendNotification(ctx context.Context, actor export.Ac
 		ActorID:    actor.ID,
 		FileName:   filename,
 		Format:     formatLabel,
+		Checksum:   ref.Meta.Checksum,
 		URL:        link,
 		ExpiresAt:  expiresLabel,
 		Rows:       rowCount,
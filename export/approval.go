@@ -0,0 +1,114 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ApprovalPolicy requires a second person to approve matching exports.
+type ApprovalPolicy struct {
+	// Always requires approval for every export of the definition.
+	Always bool
+	// RowThreshold requires approval when EstimatedRows exceeds it. Runs
+	// without approval are also capped at RowThreshold rows, so omitting or
+	// under-reporting the estimate cannot skip the gate.
+	RowThreshold int
+	// Columns requires approval when any listed column is projected.
+	Columns []string
+	// ApproverRoles limits who may decide. When empty and the service has a
+	// guard, approvers need AdminGuard access to AdminApprovals; without a
+	// guard any other actor in the export's scope may decide.
+	ApproverRoles []string
+	// AllowSelfApproval lets requesters approve their own exports.
+	AllowSelfApproval bool
+}
+
+// ApprovalDecision captures an approver's verdict.
+type ApprovalDecision string
+
+const (
+	ApprovalApproved ApprovalDecision = "approved"
+	ApprovalRejected ApprovalDecision = "rejected"
+)
+
+// ApprovalRecord tracks why approval was required and who decided.
+type ApprovalRecord struct {
+	Reasons     []string         `json:"reasons,omitempty"`
+	RequestedAt time.Time        `json:"requested_at"`
+	Decision    ApprovalDecision `json:"decision,omitempty"`
+	DecidedBy   Actor            `json:"decided_by"`
+	DecidedAt   time.Time        `json:"decided_at"`
+	Comment     string           `json:"comment,omitempty"`
+}
+
+// ApprovalHook is notified after an export is approved, typically to enqueue generation.
+type ApprovalHook interface {
+	ExportApproved(ctx context.Context, record ExportRecord) error
+}
+
+// ApprovalHookFunc adapts a function to an ApprovalHook.
+type ApprovalHookFunc func(ctx context.Context, record ExportRecord) error
+
+func (f ApprovalHookFunc) ExportApproved(ctx context.Context, record ExportRecord) error {
+	if f == nil {
+		return nil
+	}
+	return f(ctx, record)
+}
+
+// ApprovalReasons reports why a resolved export requires approval; an empty
+// result means the export may proceed without one.
+func ApprovalReasons(resolved ResolvedExport) []string {
+	policy := resolved.Definition.Approval
+	if policy == nil {
+		return nil
+	}
+	reasons := make([]string, 0, 2)
+	if policy.Always {
+		reasons = append(reasons, "definition requires approval")
+	}
+	if policy.RowThreshold > 0 && resolved.Request.EstimatedRows > policy.RowThreshold {
+		reasons = append(reasons, fmt.Sprintf("estimated rows %d exceed approval threshold %d", resolved.Request.EstimatedRows, policy.RowThreshold))
+	}
+	for _, name := range resolved.ColumnNames {
+		if slices.Contains(policy.Columns, name) {
+			reasons = append(reasons, fmt.Sprintf("column %q requires approval", name))
+		}
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	return reasons
+}
+
+// approvalRowLimit returns the row cap for a run that was not approved.
+func approvalRowLimit(policy *ApprovalPolicy, maxRows int) int {
+	if policy == nil {
+		return maxRows
+	}
+	return capRows(maxRows, policy.RowThreshold)
+}
+
+func authorizeApprover(policy *ApprovalPolicy, actor Actor, record ExportRecord) error {
+	reasons := make([]string, 0, 2)
+	allowSelf := policy != nil && policy.AllowSelfApproval
+	if actor.ID == "" {
+		reasons = append(reasons, "approver ID is required")
+	} else if !allowSelf && actor.ID == record.RequestedBy.ID {
+		reasons = append(reasons, "requester cannot approve their own export")
+	}
+	if !scopeMatches(actor.Scope, record.Scope) {
+		reasons = append(reasons, "approver is outside the export scope")
+	}
+	if policy != nil && len(policy.ApproverRoles) > 0 && !hasAnyRole(actor.Roles, policy.ApproverRoles) {
+		reasons = append(reasons, fmt.Sprintf("approver requires one of roles %v", policy.ApproverRoles))
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	return NewError(KindAuthz, "approval not authorized", nil).WithMetadata(map[string]any{
+		"reasons": reasons,
+	})
+}
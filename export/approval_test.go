@@ -0,0 +1,280 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	errorslib "github.com/goliatone/go-errors"
+)
+
+func newApprovalService(t *testing.T, emitter ChangeEmitter, hook ApprovalHook) (Service, *MemoryTracker, *MemoryStore) {
+	t.Helper()
+	runner := NewRunner()
+	runner.Emitter = emitter
+	if err := runner.Definitions.Register(ExportDefinition{
+		Name:         "payroll",
+		RowSourceKey: "stub",
+		Schema: Schema{Columns: []Column{
+			{Name: "id"},
+			{Name: "salary"},
+		}},
+		Approval: &ApprovalPolicy{
+			RowThreshold:  100,
+			Columns:       []string{"salary"},
+			ApproverRoles: []string{"approver"},
+		},
+	}); err != nil {
+		t.Fatalf("register definition: %v", err)
+	}
+	if err := runner.RowSources.Register("stub", func(req ExportRequest, def ResolvedDefinition) (RowSource, error) {
+		_ = req
+		_ = def
+		return &stubSource{iter: &stubIterator{rows: []Row{{"1", "100"}}}}, nil
+	}); err != nil {
+		t.Fatalf("register source: %v", err)
+	}
+	tracker := NewMemoryTracker()
+	store := NewMemoryStore()
+	svc := NewService(ServiceConfig{
+		Runner:       runner,
+		Tracker:      tracker,
+		Store:        store,
+		ApprovalHook: hook,
+	})
+	return svc, tracker, store
+}
+
+func TestApprovalReasons(t *testing.T) {
+	def := ResolvedDefinition{ExportDefinition: ExportDefinition{
+		Approval: &ApprovalPolicy{RowThreshold: 10, Columns: []string{"ssn"}},
+	}}
+	reasons := ApprovalReasons(ResolvedExport{
+		Request:     ExportRequest{EstimatedRows: 11},
+		Definition:  def,
+		ColumnNames: []string{"id", "ssn"},
+	})
+	if len(reasons) != 2 {
+		t.Fatalf("expected row and column reasons, got %v", reasons)
+	}
+	if reasons := ApprovalReasons(ResolvedExport{Definition: def, ColumnNames: []string{"id"}}); reasons != nil {
+		t.Fatalf("expected no approval required, got %v", reasons)
+	}
+}
+
+func TestService_ApprovalWorkflow(t *testing.T) {
+	ctx := context.Background()
+	emitter := &recordingEmitter{}
+	var approved []ExportRecord
+	hook := ApprovalHookFunc(func(ctx context.Context, record ExportRecord) error {
+		_ = ctx
+		approved = append(approved, record)
+		return nil
+	})
+	svc, tracker, _ := newApprovalService(t, emitter, hook)
+
+	requester := Actor{ID: "user-1"}
+	record, err := svc.RequestExport(ctx, requester, ExportRequest{
+		Definition: "payroll",
+		Format:     FormatCSV,
+		Delivery:   DeliverySync,
+	})
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if record.State != StatePendingApproval || record.Approval == nil || len(record.Approval.Reasons) == 0 {
+		t.Fatalf("expected pending approval record, got %+v", record)
+	}
+	if record.Request.Definition != "payroll" {
+		t.Fatalf("expected request stored for approval, got %+v", record.Request)
+	}
+
+	if _, err := svc.GenerateExport(ctx, requester, record.ID, record.Request); err == nil {
+		t.Fatalf("expected generation to be blocked while pending")
+	}
+
+	_, err = svc.ApproveExport(ctx, requester, record.ID, "")
+	if mapped := AsGoError(err); mapped == nil || mapped.Category != errorslib.CategoryAuthz || mapped.Metadata["reasons"] == nil {
+		t.Fatalf("expected self-approval to be denied with reasons, got %v", err)
+	}
+	if _, err := svc.ApproveExport(ctx, Actor{ID: "user-2"}, record.ID, ""); err == nil {
+		t.Fatalf("expected approver role to be required")
+	}
+	if _, err := svc.ApproveExport(ctx, Actor{Roles: []string{"approver"}}, record.ID, ""); err == nil {
+		t.Fatalf("expected anonymous approver to be denied")
+	}
+
+	approver := Actor{ID: "boss", Roles: []string{"approver"}}
+	decided, err := svc.ApproveExport(ctx, approver, record.ID, "looks fine")
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if decided.State != StateQueued || decided.Approval.Decision != ApprovalApproved || decided.Approval.DecidedBy.ID != "boss" {
+		t.Fatalf("unexpected approved record: %+v", decided)
+	}
+	if len(approved) != 1 || approved[0].ID != record.ID {
+		t.Fatalf("expected approval hook call, got %+v", approved)
+	}
+
+	if _, err := svc.GenerateExport(ctx, requester, record.ID, decided.Request); err != nil {
+		t.Fatalf("generate after approval: %v", err)
+	}
+	stored, err := tracker.Status(ctx, record.ID)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if stored.State != StateCompleted {
+		t.Fatalf("expected completed export, got %s", stored.State)
+	}
+
+	names := make([]string, 0, len(emitter.events))
+	for _, evt := range emitter.events {
+		names = append(names, evt.Name)
+	}
+	if len(names) < 2 || names[0] != "export.approval_requested" || names[1] != "export.approved" {
+		t.Fatalf("expected approval events first, got %v", names)
+	}
+}
+
+func TestService_RejectExport(t *testing.T) {
+	ctx := context.Background()
+	emitter := &recordingEmitter{}
+	svc, _, _ := newApprovalService(t, emitter, nil)
+
+	record, err := svc.RequestExport(ctx, Actor{ID: "user-1"}, ExportRequest{
+		Definition: "payroll",
+		Format:     FormatCSV,
+	})
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	rejected, err := svc.RejectExport(ctx, Actor{ID: "boss", Roles: []string{"approver"}}, record.ID, "too broad")
+	if err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	if rejected.State != StateRejected || rejected.Approval.Comment != "too broad" {
+		t.Fatalf("unexpected rejected record: %+v", rejected)
+	}
+	if _, err := svc.ApproveExport(ctx, Actor{ID: "boss", Roles: []string{"approver"}}, record.ID, ""); err == nil {
+		t.Fatalf("expected rejected export to stay rejected")
+	}
+	if _, err := svc.GenerateExport(ctx, Actor{ID: "user-1"}, record.ID, rejected.Request); err == nil {
+		t.Fatalf("expected rejected export generation to fail")
+	}
+	if last := emitter.events[len(emitter.events)-1]; last.Name != "export.rejected" {
+		t.Fatalf("expected rejected event, got %s", last.Name)
+	}
+}
+
+func TestRunner_RequiresApproval(t *testing.T) {
+	svc, _, _ := newApprovalService(t, nil, nil)
+	run := svc.(*service).runner
+	_, err := run.Run(context.Background(), ExportRequest{
+		Definition: "payroll",
+		Format:     FormatCSV,
+		Output:     &bytes.Buffer{},
+	})
+	if mapped := AsGoError(err); mapped == nil || mapped.Category != errorslib.CategoryValidation {
+		t.Fatalf("expected approval validation error, got %v", err)
+	}
+}
+
+func TestService_ConcurrentApprovalsEnqueueOnce(t *testing.T) {
+	ctx := context.Background()
+	var calls atomic.Int32
+	hook := ApprovalHookFunc(func(ctx context.Context, record ExportRecord) error {
+		calls.Add(1)
+		return nil
+	})
+	svc, _, _ := newApprovalService(t, nil, hook)
+	record, err := svc.RequestExport(ctx, Actor{ID: "user-1"}, ExportRequest{
+		Definition: "payroll",
+		Format:     FormatCSV,
+	})
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = svc.ApproveExport(ctx, Actor{ID: "boss", Roles: []string{"approver"}}, record.ID, "")
+		}()
+	}
+	wg.Wait()
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected one approval hook call, got %d", got)
+	}
+}
+
+func TestRunner_ApprovalRowThresholdWithoutEstimate(t *testing.T) {
+	svc, _, _ := newApprovalService(t, nil, nil)
+	run := svc.(*service).runner
+	if err := run.Definitions.Register(ExportDefinition{
+		Name:         "ledger",
+		RowSourceKey: "ledger",
+		Schema:       Schema{Columns: []Column{{Name: "id"}}},
+		Approval:     &ApprovalPolicy{RowThreshold: 2},
+	}); err != nil {
+		t.Fatalf("register definition: %v", err)
+	}
+	if err := run.RowSources.Register("ledger", func(req ExportRequest, def ResolvedDefinition) (RowSource, error) {
+		return &stubSource{iter: &stubIterator{rows: []Row{{"1"}, {"2"}, {"3"}}}}, nil
+	}); err != nil {
+		t.Fatalf("register source: %v", err)
+	}
+
+	// No EstimatedRows, so the gate passes up front but the run is capped.
+	_, err := run.Run(context.Background(), ExportRequest{
+		Definition: "ledger",
+		Format:     FormatCSV,
+		Output:     &bytes.Buffer{},
+	})
+	if err == nil {
+		t.Fatalf("expected unapproved run past the row threshold to fail")
+	}
+}
+
+func TestService_ApprovalRequiresScopeAndAdmin(t *testing.T) {
+	ctx := context.Background()
+	runner := NewRunner()
+	if err := runner.Definitions.Register(ExportDefinition{
+		Name:         "payroll",
+		RowSourceKey: "stub",
+		Schema:       Schema{Columns: []Column{{Name: "id"}}},
+		Approval:     &ApprovalPolicy{Always: true},
+	}); err != nil {
+		t.Fatalf("register definition: %v", err)
+	}
+	svc := NewService(ServiceConfig{
+		Runner:  runner,
+		Tracker: NewMemoryTracker(),
+		Store:   NewMemoryStore(),
+		Guard:   roleAdminGuard{},
+	})
+
+	scope := Scope{TenantID: "tenant-a"}
+	record, err := svc.RequestExport(ctx, Actor{ID: "user-1", Scope: scope}, ExportRequest{Definition: "payroll", Format: FormatCSV})
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+
+	outsider := Actor{ID: "admin-b", Roles: []string{"admin"}, Scope: Scope{TenantID: "tenant-b"}}
+	if _, err := svc.ApproveExport(ctx, outsider, record.ID, ""); AsGoError(err).Category != errorslib.CategoryAuthz {
+		t.Fatalf("expected approver from another tenant to be denied, got %v", err)
+	}
+	if _, err := svc.ApproveExport(ctx, Actor{ID: "user-2", Scope: scope}, record.ID, ""); AsGoError(err).Category != errorslib.CategoryAuthz {
+		t.Fatalf("expected non-admin approver to be denied, got %v", err)
+	}
+	decided, err := svc.ApproveExport(ctx, Actor{ID: "admin-a", Roles: []string{"admin"}, Scope: scope}, record.ID, "")
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if decided.State != StateQueued {
+		t.Fatalf("expected queued export, got %s", decided.State)
+	}
+}
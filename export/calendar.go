@@ -0,0 +1,174 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BusinessCalendar marks non-business days. Weekend lists weekday names
+// (default saturday and sunday); Holidays lists dates as YYYY-MM-DD. Dates
+// are compared in the schedule timezone.
+type BusinessCalendar struct {
+	Name     string   `json:"name" yaml:"name"`
+	Weekend  []string `json:"weekend,omitempty" yaml:"weekend,omitempty"`
+	Holidays []string `json:"holidays,omitempty" yaml:"holidays,omitempty"`
+}
+
+// IsBusinessDay reports whether t falls on a business day, using t's own
+// location for the date.
+func (c BusinessCalendar) IsBusinessDay(t time.Time) bool {
+	weekend := c.Weekend
+	if len(weekend) == 0 {
+		weekend = []string{"saturday", "sunday"}
+	}
+	day := strings.ToLower(t.Weekday().String())
+	for _, name := range weekend {
+		if strings.EqualFold(strings.TrimSpace(name), day) {
+			return false
+		}
+	}
+	return !slices.Contains(c.Holidays, t.Format(time.DateOnly))
+}
+
+// Validate checks weekday names and holiday dates.
+func (c BusinessCalendar) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return NewError(KindValidation, "calendar name is required", nil)
+	}
+	for _, name := range c.Weekend {
+		if !isWeekdayName(name) {
+			return NewError(KindValidation, fmt.Sprintf("calendar %s has unknown weekday %q", c.Name, name), nil)
+		}
+	}
+	for _, date := range c.Holidays {
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return NewError(KindValidation, fmt.Sprintf("calendar %s has invalid holiday %q", c.Name, date), err)
+		}
+	}
+	return nil
+}
+
+func isWeekdayName(name string) bool {
+	name = strings.TrimSpace(name)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// CalendarSource resolves business calendars by name.
+type CalendarSource interface {
+	BusinessCalendar(ctx context.Context, name string) (BusinessCalendar, error)
+}
+
+// MemoryCalendarSource serves calendars held in memory.
+type MemoryCalendarSource struct {
+	mu        sync.RWMutex
+	calendars map[string]BusinessCalendar
+}
+
+// NewMemoryCalendarSource creates an in-memory calendar source.
+func NewMemoryCalendarSource(calendars ...BusinessCalendar) *MemoryCalendarSource {
+	source := &MemoryCalendarSource{calendars: make(map[string]BusinessCalendar)}
+	for _, calendar := range calendars {
+		source.calendars[calendar.Name] = calendar
+	}
+	return source
+}
+
+// Set adds or replaces a calendar.
+func (s *MemoryCalendarSource) Set(calendar BusinessCalendar) error {
+	if err := calendar.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.calendars[calendar.Name] = calendar
+	s.mu.Unlock()
+	return nil
+}
+
+// BusinessCalendar returns a calendar by name.
+func (s *MemoryCalendarSource) BusinessCalendar(ctx context.Context, name string) (BusinessCalendar, error) {
+	_ = ctx
+	s.mu.RLock()
+	calendar, ok := s.calendars[name]
+	s.mu.RUnlock()
+	if !ok {
+		return BusinessCalendar{}, NewError(KindNotFound, fmt.Sprintf("calendar %q not found", name), nil)
+	}
+	return calendar, nil
+}
+
+// FileCalendarSource reads calendars from a .json, .yaml, or .yml file on
+// every lookup, so holiday edits apply without a restart.
+type FileCalendarSource struct {
+	Path string
+}
+
+// BusinessCalendar returns a calendar by name from the file.
+func (s FileCalendarSource) BusinessCalendar(ctx context.Context, name string) (BusinessCalendar, error) {
+	_ = ctx
+	calendars, err := LoadCalendarFile(s.Path)
+	if err != nil {
+		return BusinessCalendar{}, err
+	}
+	for _, calendar := range calendars {
+		if calendar.Name == name {
+			return calendar, nil
+		}
+	}
+	return BusinessCalendar{}, NewError(KindNotFound, fmt.Sprintf("calendar %q not found", name), nil)
+}
+
+// calendarDocument is the file layout: {"calendars": [...]}.
+type calendarDocument struct {
+	Calendars []BusinessCalendar `json:"calendars" yaml:"calendars"`
+}
+
+// ParseCalendars decodes a JSON or YAML calendar document.
+func ParseCalendars(data []byte) ([]BusinessCalendar, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, NewError(KindValidation, "calendar document is empty", nil)
+	}
+	var doc calendarDocument
+	if trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &doc); err != nil {
+			return nil, NewError(KindValidation, "invalid calendar json", err)
+		}
+	} else if err := yaml.Unmarshal(trimmed, &doc); err != nil {
+		return nil, NewError(KindValidation, "invalid calendar yaml", err)
+	}
+	for _, calendar := range doc.Calendars {
+		if err := calendar.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return doc.Calendars, nil
+}
+
+// LoadCalendarFile reads calendars from a .json, .yaml, or .yml file.
+func LoadCalendarFile(path string) ([]BusinessCalendar, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+	default:
+		return nil, NewError(KindValidation, fmt.Sprintf("unsupported calendar file %q", path), nil)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, NewError(KindInternal, "read calendar file", err)
+	}
+	return ParseCalendars(data)
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	errorslib "github.com/goliatone/go-errors"
+)
+
+// DefaultCancelPollInterval bounds how long a running export takes to see a
+// cancellation recorded by another process.
+const DefaultCancelPollInterval = 5 * time.Second
+
+// errRunSuperseded stops a run whose record left StateRunning without it,
+// e.g. after the stale reaper requeued or failed it. The record then belongs
+// to another generation, so the run must not write to it.
+var errRunSuperseded = NewError(KindStale, "export run was superseded", nil)
+
+// watchCancel polls the tracker every CancelPollInterval and cancels the
+// returned context once the record leaves StateRunning, so CancelExport on
+// one node stops a generation running on another. Records that were
+// canceled cancel the run normally; records that were requeued, failed,
+// completed or removed elsewhere cancel it with errRunSuperseded. The
+// returned func stops polling and releases the context.
+func (r *Runner) watchCancel(ctx context.Context, exportID string) (context.Context, context.CancelCauseFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	if r.Tracker == nil || r.CancelPollInterval < 0 {
+		return ctx, cancel
+	}
+	interval := r.CancelPollInterval
+	if interval == 0 {
+		interval = DefaultCancelPollInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				record, err := r.Tracker.Status(ctx, exportID)
+				if err != nil {
+					if KindFromError(err) == KindNotFound {
+						r.Logger.Warn("export record removed, stopping run", "export_id", exportID)
+						cancel(errRunSuperseded)
+						return
+					}
+					if ctx.Err() == nil {
+						r.Logger.Warn("export cancel poll failed", "error", err, "export_id", exportID)
+					}
+					continue
+				}
+				switch record.State {
+				case StateRunning:
+				case StateCanceled:
+					r.Logger.Info("export canceled externally", "export_id", exportID)
+					cancel(nil)
+					return
+				default:
+					r.Logger.Warn("export no longer running, stopping run", "export_id", exportID, "state", record.State)
+					cancel(errRunSuperseded)
+					return
+				}
+			}
+		}
+	}()
+	return ctx, cancel
+}
+
+// isCanceledError reports whether err is a cancellation, including errors
+// already mapped by AsGoError.
+func isCanceledError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || KindFromError(err) == KindCanceled {
+		return true
+	}
+	var ge *errorslib.Error
+	return errors.As(err, &ge) && ge.TextCode == string(KindCanceled)
+}
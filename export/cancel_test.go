@@ -0,0 +1,149 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// partialStore keeps whatever was streamed even when the writer fails, like
+// object stores that commit multipart uploads eagerly.
+type partialStore struct {
+	*MemoryStore
+}
+
+func (s partialStore) Put(ctx context.Context, key string, r io.Reader, meta ArtifactMeta) (ArtifactRef, error) {
+	data, err := io.ReadAll(r)
+	ref, putErr := s.MemoryStore.Put(ctx, key, bytes.NewReader(data), meta)
+	if err != nil {
+		return ref, err
+	}
+	return ref, putErr
+}
+
+func TestService_GenerateExportStopsOnExternalCancel(t *testing.T) {
+	ctx := context.Background()
+	runner := NewRunner()
+	runner.CancelPollInterval = 5 * time.Millisecond
+	if err := runner.Definitions.Register(ExportDefinition{
+		Name:         "users",
+		RowSourceKey: "stub",
+		Schema:       Schema{Columns: []Column{{Name: "name"}}},
+	}); err != nil {
+		t.Fatalf("register definition: %v", err)
+	}
+	if err := runner.RowSources.Register("stub", func(req ExportRequest, def ResolvedDefinition) (RowSource, error) {
+		return &stubSource{iter: &blockingIterator{}}, nil
+	}); err != nil {
+		t.Fatalf("register source: %v", err)
+	}
+	tracker := NewMemoryTracker()
+	store := partialStore{MemoryStore: NewMemoryStore()}
+	svc := NewService(ServiceConfig{Runner: runner, Tracker: tracker, Store: store})
+	actor := Actor{ID: "user-1"}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := svc.GenerateExport(ctx, actor, "exp-cancel", ExportRequest{Definition: "users", Format: FormatCSV})
+		done <- err
+	}()
+
+	// Another node records the cancellation directly in the shared tracker.
+	deadline := time.Now().Add(time.Second)
+	for {
+		record, err := tracker.Status(ctx, "exp-cancel")
+		if err == nil && record.State == StateRunning {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("export never started")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := tracker.SetState(ctx, "exp-cancel", StateCanceled, nil); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if !isCanceledError(err) {
+			t.Fatalf("expected cancellation error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("generation did not stop after external cancel")
+	}
+
+	if _, _, err := store.Open(ctx, "exports/exp-cancel.csv"); err == nil {
+		t.Fatalf("expected partial artifact to be removed")
+	}
+	record, _ := tracker.Status(ctx, "exp-cancel")
+	if record.State != StateCanceled {
+		t.Fatalf("expected canceled state, got %s", record.State)
+	}
+
+	// A job picked up after the cancel does not run.
+	if _, err := svc.GenerateExport(ctx, actor, "exp-cancel", ExportRequest{Definition: "users", Format: FormatCSV}); !isCanceledError(err) {
+		t.Fatalf("expected canceled export not to run, got %v", err)
+	}
+}
+
+func TestService_GenerateExportStopsWhenRecordIsRequeued(t *testing.T) {
+	ctx := context.Background()
+	runner := NewRunner()
+	runner.CancelPollInterval = 5 * time.Millisecond
+	if err := runner.Definitions.Register(ExportDefinition{
+		Name:         "users",
+		RowSourceKey: "stub",
+		Schema:       Schema{Columns: []Column{{Name: "name"}}},
+	}); err != nil {
+		t.Fatalf("register definition: %v", err)
+	}
+	if err := runner.RowSources.Register("stub", func(req ExportRequest, def ResolvedDefinition) (RowSource, error) {
+		return &stubSource{iter: &blockingIterator{}}, nil
+	}); err != nil {
+		t.Fatalf("register source: %v", err)
+	}
+	tracker := NewMemoryTracker()
+	svc := NewService(ServiceConfig{Runner: runner, Tracker: tracker, Store: NewMemoryStore()})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := svc.GenerateExport(ctx, Actor{ID: "user-1"}, "exp-requeued", ExportRequest{Definition: "users", Format: FormatCSV})
+		done <- err
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		record, err := tracker.Status(ctx, "exp-requeued")
+		if err == nil && record.State == StateRunning {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("export never started")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// The stale reaper hands the record to another worker.
+	if err := tracker.SetState(ctx, "exp-requeued", StateQueued, nil); err != nil {
+		t.Fatalf("requeue: %v", err)
+	}
+	if err := tracker.Heartbeat(ctx, "exp-requeued", time.Now()); KindFromError(err) != KindNotFound {
+		t.Fatalf("expected heartbeat on requeued export to be rejected, got %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err == nil || isCanceledError(err) {
+			t.Fatalf("expected superseded error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("generation did not stop after requeue")
+	}
+
+	record, _ := tracker.Status(ctx, "exp-requeued")
+	if record.State != StateQueued {
+		t.Fatalf("expected requeued state to be kept, got %s", record.State)
+	}
+}
@@ -0,0 +1,39 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"time"
+)
+
+// ChecksumVerification reports the outcome of re-hashing a stored artifact.
+type ChecksumVerification struct {
+	ExportID   string    `json:"export_id"`
+	Key        string    `json:"key"`
+	Expected   string    `json:"expected"`
+	Actual     string    `json:"actual"`
+	Size       int64     `json:"size"`
+	Valid      bool      `json:"valid"`
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+// DigestHeaderValue formats a hex SHA-256 checksum as a Digest header value
+// ("SHA-256=<base64>"). It returns an empty string for invalid checksums.
+func DigestHeaderValue(checksum string) string {
+	raw, err := hex.DecodeString(checksum)
+	if err != nil || len(raw) != sha256.Size {
+		return ""
+	}
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(raw)
+}
+
+func computeChecksum(r io.Reader) (string, int64, error) {
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, r)
+	if err != nil {
+		return "", size, err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
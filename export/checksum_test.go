@@ -0,0 +1,118 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+func TestService_GenerateExportRecordsChecksum(t *testing.T) {
+	ctx := context.Background()
+	emitter := &recordingEmitter{}
+	runner := NewRunner()
+	runner.Emitter = emitter
+	if err := runner.Definitions.Register(ExportDefinition{
+		Name:         "users",
+		RowSourceKey: "stub",
+		Schema:       Schema{Columns: []Column{{Name: "id"}, {Name: "name"}}},
+	}); err != nil {
+		t.Fatalf("register definition: %v", err)
+	}
+	if err := runner.RowSources.Register("stub", func(req ExportRequest, def ResolvedDefinition) (RowSource, error) {
+		_ = req
+		_ = def
+		return &stubSource{iter: &stubIterator{rows: []Row{{"1", "alice"}}}}, nil
+	}); err != nil {
+		t.Fatalf("register source: %v", err)
+	}
+	tracker := NewMemoryTracker()
+	store := NewMemoryStore()
+	svc := NewService(ServiceConfig{Runner: runner, Tracker: tracker, Store: store})
+
+	actor := Actor{ID: "user-1"}
+	result, err := svc.GenerateExport(ctx, actor, "exp-sum", ExportRequest{Definition: "users", Format: FormatCSV})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	reader, _, err := store.Open(ctx, result.Artifact.Key)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	sum := sha256.Sum256(data)
+	want := hex.EncodeToString(sum[:])
+	if result.Checksum != want || result.Artifact.Meta.Checksum != want {
+		t.Fatalf("expected checksum %s, got %s / %s", want, result.Checksum, result.Artifact.Meta.Checksum)
+	}
+
+	record, err := svc.Status(ctx, actor, "exp-sum")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if record.Checksum != want {
+		t.Fatalf("expected record checksum %s, got %s", want, record.Checksum)
+	}
+
+	verification, err := svc.VerifyExport(ctx, actor, "exp-sum")
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !verification.Valid || verification.Actual != want || verification.Size != int64(len(data)) {
+		t.Fatalf("expected valid verification, got %+v", verification)
+	}
+
+	if _, err := store.Put(ctx, result.Artifact.Key, bytes.NewBufferString("tampered"), ArtifactMeta{}); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	verification, err = svc.VerifyExport(ctx, actor, "exp-sum")
+	if err != nil {
+		t.Fatalf("verify corrupted: %v", err)
+	}
+	if verification.Valid || verification.Expected != want {
+		t.Fatalf("expected corruption to be flagged, got %+v", verification)
+	}
+	last := emitter.events[len(emitter.events)-1]
+	if last.Name != "export.integrity_failed" || last.ExportID != "exp-sum" {
+		t.Fatalf("expected integrity_failed event, got %s", last.Name)
+	}
+}
+
+func TestMemoryStore_PutVerifiesProvidedChecksum(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	// sha256("hello")
+	const sum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	ref, err := store.Put(ctx, "a", bytes.NewBufferString("hello"), ArtifactMeta{})
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if ref.Meta.Checksum != sum {
+		t.Fatalf("expected computed checksum, got %q", ref.Meta.Checksum)
+	}
+	if _, err := store.Put(ctx, "b", bytes.NewBufferString("hello"), ArtifactMeta{Checksum: sum}); err != nil {
+		t.Fatalf("put with matching checksum: %v", err)
+	}
+	_, err = store.Put(ctx, "c", bytes.NewBufferString("goodbye"), ArtifactMeta{Checksum: sum})
+	if KindFromError(err) != KindValidation {
+		t.Fatalf("expected checksum mismatch validation error, got %v", err)
+	}
+	if _, _, err := store.Open(ctx, "c"); err == nil {
+		t.Fatalf("expected mismatched artifact not to be stored")
+	}
+}
+
+func TestDigestHeaderValue(t *testing.T) {
+	// sha256("hello")
+	got := DigestHeaderValue("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824")
+	if got != "SHA-256=LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=" {
+		t.Fatalf("unexpected digest %q", got)
+	}
+	if DigestHeaderValue("not-hex") != "" {
+		t.Fatalf("expected invalid checksum to produce no digest")
+	}
+}
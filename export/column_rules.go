@@ -0,0 +1,147 @@
+package export
+
+import (
+	"slices"
+	"strconv"
+)
+
+// ColumnRule adjusts column access for actors matching its roles and scope.
+// Matching rules apply in order: AllowedColumns replaces the allowlist,
+// RevealColumns lifts earlier redaction and masking, then DenyColumns,
+// RedactColumns, and MaskColumns accumulate.
+type ColumnRule struct {
+	Name           string
+	Roles          []string
+	TenantIDs      []string
+	WorkspaceIDs   []string
+	AllowedColumns []string
+	DenyColumns    []string
+	RedactColumns  []string
+	MaskColumns    []ColumnMask
+	RevealColumns  []string
+}
+
+// ColumnAccess records the effective projection and protection of an export.
+type ColumnAccess struct {
+	Columns  []string                `json:"columns,omitempty"`
+	Redacted []string                `json:"redacted,omitempty"`
+	Masked   map[string]MaskStrategy `json:"masked,omitempty"`
+	Rules    []string                `json:"rules,omitempty"`
+}
+
+// Matches reports whether the rule applies to the actor.
+func (r ColumnRule) Matches(actor Actor) bool {
+	if len(r.Roles) > 0 && !hasAnyRole(actor.Roles, r.Roles) {
+		return false
+	}
+	if len(r.TenantIDs) > 0 && !slices.Contains(r.TenantIDs, actor.Scope.TenantID) {
+		return false
+	}
+	if len(r.WorkspaceIDs) > 0 && !slices.Contains(r.WorkspaceIDs, actor.Scope.WorkspaceID) {
+		return false
+	}
+	return true
+}
+
+// effectivePolicy applies matching column rules to the base policy and
+// returns the names of the rules that matched. It fails with KindAuthz when
+// the rules deny every column.
+func effectivePolicy(schema []Column, policy ExportPolicy, actor Actor) (ExportPolicy, []string, error) {
+	if len(policy.ColumnRules) == 0 {
+		return policy, nil, nil
+	}
+
+	effective := policy
+	effective.ColumnRules = nil
+	effective.AllowedColumns = slices.Clone(policy.AllowedColumns)
+	effective.RedactColumns = slices.Clone(policy.RedactColumns)
+	effective.MaskColumns = slices.Clone(policy.MaskColumns)
+
+	var denied []string
+	var matched []string
+	for idx, rule := range policy.ColumnRules {
+		if !rule.Matches(actor) {
+			continue
+		}
+		name := rule.Name
+		if name == "" {
+			name = "rule-" + strconv.Itoa(idx)
+		}
+		matched = append(matched, name)
+
+		if len(rule.AllowedColumns) > 0 {
+			effective.AllowedColumns = slices.Clone(rule.AllowedColumns)
+		}
+		denied = append(denied, rule.DenyColumns...)
+		for _, column := range rule.RevealColumns {
+			effective.RedactColumns = slices.DeleteFunc(effective.RedactColumns, func(existing string) bool {
+				return existing == column
+			})
+			effective.MaskColumns = slices.DeleteFunc(effective.MaskColumns, func(existing ColumnMask) bool {
+				return existing.Column == column
+			})
+		}
+		for _, column := range rule.RedactColumns {
+			if !slices.Contains(effective.RedactColumns, column) {
+				effective.RedactColumns = append(effective.RedactColumns, column)
+			}
+		}
+		for _, mask := range rule.MaskColumns {
+			effective.MaskColumns = slices.DeleteFunc(effective.MaskColumns, func(existing ColumnMask) bool {
+				return existing.Column == mask.Column
+			})
+			effective.MaskColumns = append(effective.MaskColumns, mask)
+		}
+	}
+
+	if len(denied) > 0 {
+		allowed := effective.AllowedColumns
+		if len(allowed) == 0 {
+			for _, col := range schema {
+				allowed = append(allowed, col.Name)
+			}
+		}
+		effective.AllowedColumns = slices.DeleteFunc(slices.Clone(allowed), func(name string) bool {
+			return slices.Contains(denied, name)
+		})
+		if len(effective.AllowedColumns) == 0 {
+			return ExportPolicy{}, matched, NewError(KindAuthz, "no columns permitted", nil).WithMetadata(map[string]any{
+				"rules": matched,
+			})
+		}
+	}
+
+	return effective, matched, nil
+}
+
+func buildColumnAccess(columns []string, policy ExportPolicy, rules []string) ColumnAccess {
+	access := ColumnAccess{
+		Columns: slices.Clone(columns),
+		Rules:   rules,
+	}
+	for _, name := range columns {
+		if slices.Contains(policy.RedactColumns, name) {
+			access.Redacted = append(access.Redacted, name)
+			continue
+		}
+		for _, mask := range policy.MaskColumns {
+			if mask.Column != name {
+				continue
+			}
+			if access.Masked == nil {
+				access.Masked = make(map[string]MaskStrategy)
+			}
+			access.Masked[name] = mask.Strategy
+		}
+	}
+	return access
+}
+
+func hasAnyRole(roles []string, required []string) bool {
+	for _, role := range roles {
+		if slices.Contains(required, role) {
+			return true
+		}
+	}
+	return false
+}
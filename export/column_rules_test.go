@@ -0,0 +1,125 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func columnRulesDefinition() ResolvedDefinition {
+	return ResolvedDefinition{ExportDefinition: ExportDefinition{
+		Name:           "employees",
+		RowSourceKey:   "stub",
+		AllowedFormats: []Format{FormatCSV},
+		Schema: Schema{Columns: []Column{
+			{Name: "id"},
+			{Name: "email"},
+			{Name: "ssn"},
+			{Name: "salary", Type: "number"},
+		}},
+		Policy: ExportPolicy{
+			RedactColumns: []string{"salary"},
+			ColumnRules: []ColumnRule{
+				{Name: "support", Roles: []string{"support"}, DenyColumns: []string{"ssn", "salary"}},
+				{Name: "managers", Roles: []string{"manager"}, MaskColumns: []ColumnMask{{Column: "salary", Strategy: MaskBand, BandSize: 10000}}, RevealColumns: []string{"salary"}},
+				{Name: "finance", Roles: []string{"finance"}, RevealColumns: []string{"salary"}},
+			},
+		},
+	}}
+}
+
+func TestResolveExportForActor_ColumnRules(t *testing.T) {
+	def := columnRulesDefinition()
+	req := ExportRequest{Definition: "employees", Format: FormatCSV}
+
+	support, err := ResolveExportForActor(req, def, Actor{ID: "s1", Roles: []string{"support"}}, testNow())
+	if err != nil {
+		t.Fatalf("resolve support: %v", err)
+	}
+	if !slices.Equal(support.ColumnNames, []string{"id", "email"}) {
+		t.Fatalf("expected support projection without ssn/salary, got %v", support.ColumnNames)
+	}
+	if !slices.Equal(support.Access.Rules, []string{"support"}) {
+		t.Fatalf("expected support rule recorded, got %v", support.Access.Rules)
+	}
+
+	finance, err := ResolveExportForActor(req, def, Actor{ID: "f1", Roles: []string{"finance"}}, testNow())
+	if err != nil {
+		t.Fatalf("resolve finance: %v", err)
+	}
+	if len(finance.Access.Redacted) != 0 || len(finance.Access.Masked) != 0 {
+		t.Fatalf("expected finance to see full salaries, got %+v", finance.Access)
+	}
+
+	manager, err := ResolveExportForActor(req, def, Actor{ID: "m1", Roles: []string{"manager"}}, testNow())
+	if err != nil {
+		t.Fatalf("resolve manager: %v", err)
+	}
+	if manager.Access.Masked["salary"] != MaskBand || len(manager.Access.Redacted) != 0 {
+		t.Fatalf("expected manager salary bands, got %+v", manager.Access)
+	}
+	if manager.Definition.Policy.ColumnRules != nil {
+		t.Fatalf("expected effective policy without rules")
+	}
+
+	anonymous, err := ResolveExport(req, def, testNow())
+	if err != nil {
+		t.Fatalf("resolve anonymous: %v", err)
+	}
+	if !slices.Equal(anonymous.Access.Redacted, []string{"salary"}) {
+		t.Fatalf("expected base redaction, got %+v", anonymous.Access)
+	}
+
+	_, err = ResolveExportForActor(ExportRequest{Definition: "employees", Format: FormatCSV, Columns: []string{"ssn"}}, def, Actor{Roles: []string{"support"}}, testNow())
+	var exportErr *ExportError
+	if !errors.As(err, &exportErr) || exportErr.Kind != KindValidation {
+		t.Fatalf("expected denied column validation error, got %v", err)
+	}
+}
+
+func TestService_RecordsColumnAccess(t *testing.T) {
+	runner := NewRunner()
+	def := columnRulesDefinition()
+	def.Policy.ColumnRules = def.Policy.ColumnRules[:1]
+	if err := runner.Definitions.Register(def.ExportDefinition); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	tracker := NewMemoryTracker()
+	svc := NewService(ServiceConfig{
+		Runner:  runner,
+		Tracker: tracker,
+		Store:   NewMemoryStore(),
+	})
+
+	record, err := svc.RequestExport(context.Background(), Actor{ID: "s1", Roles: []string{"support"}}, ExportRequest{
+		Definition: "employees",
+		Format:     FormatCSV,
+		Delivery:   DeliveryAsync,
+	})
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	stored, err := tracker.Status(context.Background(), record.ID)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !slices.Equal(stored.Access.Columns, []string{"id", "email"}) {
+		t.Fatalf("expected recorded projection, got %+v", stored.Access)
+	}
+}
+
+func TestResolveExportForActor_ColumnRulesDenyAll(t *testing.T) {
+	def := columnRulesDefinition()
+	def.Policy.ColumnRules = append(def.Policy.ColumnRules, ColumnRule{
+		Name:        "contractors",
+		Roles:       []string{"contractor"},
+		DenyColumns: []string{"id", "email", "ssn", "salary"},
+	})
+
+	_, err := ResolveExportForActor(ExportRequest{Definition: "employees", Format: FormatCSV}, def, Actor{ID: "c1", Roles: []string{"contractor"}}, testNow())
+	var exportErr *ExportError
+	if !errors.As(err, &exportErr) || exportErr.Kind != KindAuthz {
+		t.Fatalf("expected authz error when no columns are permitted, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// WebhookDeadLetter records a webhook delivery that exhausted its retries.
+// Payload holds the body that was sent, minus any attachment data, so replays
+// keep the same webhook ID and content; ArtifactKey names the artifact to
+// re-read for the attachment. Secrets are never stored: SecretRef and
+// HeaderSecrets name secrets to resolve at replay time, Signed marks
+// deliveries that were signed at all, and RedactedHeaders lists
+// credential-like headers that were dropped from Headers. CreatedAt is the
+// first failure and FailedAt the latest. Parked marks letters that will not be
+// replayed again; List leaves them out, Get still returns them.
+type WebhookDeadLetter struct {
+	ID              string            `json:"id"`
+	WebhookID       string            `json:"webhook_id"`
+	URL             string            `json:"url"`
+	Method          string            `json:"method,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	HeaderSecrets   map[string]string `json:"header_secrets,omitempty"`
+	RedactedHeaders []string          `json:"redacted_headers,omitempty"`
+	SecretRef       string            `json:"secret_ref,omitempty"`
+	Signed          bool              `json:"signed,omitempty"`
+	Payload         json.RawMessage   `json:"payload"`
+	ArtifactKey     string            `json:"artifact_key,omitempty"`
+	Attempts        int               `json:"attempts"`
+	StatusCode      int               `json:"status_code,omitempty"`
+	Error           string            `json:"error,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	FailedAt        time.Time         `json:"failed_at"`
+	Parked          bool              `json:"parked,omitempty"`
+}
+
+// WebhookDeadLetterStore persists failed webhook deliveries. List returns
+// unparked letters, oldest failure first.
+type WebhookDeadLetterStore interface {
+	Save(ctx context.Context, letter WebhookDeadLetter) (WebhookDeadLetter, error)
+	Get(ctx context.Context, id string) (WebhookDeadLetter, error)
+	List(ctx context.Context, limit int) ([]WebhookDeadLetter, error)
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,99 @@
+package export
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// DeliveryStatus describes the outcome of a delivery attempt.
+type DeliveryStatus string
+
+const (
+	DeliveryStatusSent   DeliveryStatus = "sent"
+	DeliveryStatusFailed DeliveryStatus = "failed"
+	// DeliveryStatusSkipped marks targets that were not sent; Reason says why.
+	DeliveryStatusSkipped DeliveryStatus = "skipped"
+)
+
+// DeliveryRecord captures the delivery of an export to a single target.
+// Target holds the webhook URL, the comma-joined email recipients, or
+// "destination:path" for store copies; Attempts greater than one means the
+// delivery was retried. ScheduleID and Checksum let scheduled runs compare
+// against the last delivered artifact.
+type DeliveryRecord struct {
+	ID           string         `json:"id"`
+	ExportID     string         `json:"export_id"`
+	ScheduleID   string         `json:"schedule_id,omitempty"`
+	Definition   string         `json:"definition,omitempty"`
+	Format       Format         `json:"format,omitempty"`
+	Mode         string         `json:"mode,omitempty"`
+	Actor        Actor          `json:"actor"`
+	TargetKind   string         `json:"target_kind"`
+	Target       string         `json:"target"`
+	Recipients   []string       `json:"recipients,omitempty"`
+	Rows         int64          `json:"rows"`
+	Checksum     string         `json:"checksum,omitempty"`
+	Status       DeliveryStatus `json:"status"`
+	Reason       string         `json:"reason,omitempty"`
+	StatusCode   int            `json:"status_code,omitempty"`
+	Attempts     int            `json:"attempts,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	DeadLetterID string         `json:"dead_letter_id,omitempty"`
+	StartedAt    time.Time      `json:"started_at"`
+	CompletedAt  time.Time      `json:"completed_at"`
+}
+
+// DeliveryFilter narrows delivery history queries. Target matches the
+// webhook URL or any recipient; Since/Until bound StartedAt.
+type DeliveryFilter struct {
+	ExportID   string         `json:"export_id,omitempty"`
+	ScheduleID string         `json:"schedule_id,omitempty"`
+	Definition string         `json:"definition,omitempty"`
+	TargetKind string         `json:"target_kind,omitempty"`
+	Target     string         `json:"target,omitempty"`
+	Status     DeliveryStatus `json:"status,omitempty"`
+	Scope      Scope          `json:"scope,omitempty"`
+	Since      time.Time      `json:"since,omitempty"`
+	Until      time.Time      `json:"until,omitempty"`
+	Limit      int            `json:"limit,omitempty"`
+}
+
+// Matches reports whether record satisfies the filter. Limit is ignored.
+func (f DeliveryFilter) Matches(record DeliveryRecord) bool {
+	if f.ExportID != "" && f.ExportID != record.ExportID {
+		return false
+	}
+	if f.ScheduleID != "" && f.ScheduleID != record.ScheduleID {
+		return false
+	}
+	if f.Definition != "" && f.Definition != record.Definition {
+		return false
+	}
+	if f.TargetKind != "" && f.TargetKind != record.TargetKind {
+		return false
+	}
+	if f.Target != "" && f.Target != record.Target && !slices.Contains(record.Recipients, f.Target) {
+		return false
+	}
+	if f.Status != "" && f.Status != record.Status {
+		return false
+	}
+	if !scopeMatches(f.Scope, record.Actor.Scope) {
+		return false
+	}
+	if !f.Since.IsZero() && record.StartedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && record.StartedAt.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// DeliveryTracker persists per-target delivery history.
+type DeliveryTracker interface {
+	RecordDelivery(ctx context.Context, record DeliveryRecord) error
+	// ListDeliveries returns matching deliveries, newest first.
+	ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]DeliveryRecord, error)
+}
@@ -0,0 +1,33 @@
+package export
+
+import (
+	"context"
+	"time"
+)
+
+// DownloadMethod describes how an artifact was handed to the client.
+type DownloadMethod string
+
+const (
+	DownloadStream    DownloadMethod = "stream"
+	DownloadSignedURL DownloadMethod = "signed_url"
+)
+
+// DownloadRecord captures a single artifact download.
+type DownloadRecord struct {
+	ID           string         `json:"id"`
+	ExportID     string         `json:"export_id"`
+	Actor        Actor          `json:"actor"`
+	IP           string         `json:"ip,omitempty"`
+	ForwardedFor string         `json:"forwarded_for,omitempty"`
+	UserAgent    string         `json:"user_agent,omitempty"`
+	Method       DownloadMethod `json:"method,omitempty"`
+	Bytes        int64          `json:"bytes,omitempty"`
+	DownloadedAt time.Time      `json:"downloaded_at"`
+}
+
+// DownloadLog persists the download history of exports.
+type DownloadLog interface {
+	RecordDownload(ctx context.Context, record DownloadRecord) error
+	ListDownloads(ctx context.Context, exportID string) ([]DownloadRecord, error)
+}
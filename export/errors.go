@@ -3,6 +3,7 @@ package export
 import (
 	"context"
 	"errors"
+	"maps"
 
 	errorslib "github.com/goliatone/go-errors"
 )
@@ -19,13 +20,16 @@ const (
 	KindExternal   ErrorKind = "external"
 	KindInternal   ErrorKind = "internal"
 	KindNotImpl    ErrorKind = "not_implemented"
+	// KindStale marks running exports whose worker stopped heartbeating.
+	KindStale ErrorKind = "stale"
 )
 
 // ExportError wraps errors with a kind.
 type ExportError struct {
-	Kind ErrorKind
-	Msg  string
-	Err  error
+	Kind     ErrorKind
+	Msg      string
+	Err      error
+	Metadata map[string]any
 }
 
 func (e *ExportError) Error() string {
@@ -44,6 +48,18 @@ func NewError(kind ErrorKind, msg string, err error) *ExportError {
 	return &ExportError{Kind: kind, Msg: msg, Err: err}
 }
 
+// WithMetadata attaches metadata surfaced on the mapped go-errors error.
+func (e *ExportError) WithMetadata(meta map[string]any) *ExportError {
+	if e == nil || len(meta) == 0 {
+		return e
+	}
+	if e.Metadata == nil {
+		e.Metadata = make(map[string]any, len(meta))
+	}
+	maps.Copy(e.Metadata, meta)
+	return e
+}
+
 // AsGoError maps an error into a go-errors error.
 func AsGoError(err error) *errorslib.Error {
 	if err == nil {
@@ -73,24 +89,52 @@ func AsGoError(err error) *errorslib.Error {
 		kind = KindCanceled
 	}
 
+	var mapped *errorslib.Error
 	switch kind {
 	case KindValidation:
-		return errorslib.New(msg, errorslib.CategoryValidation).WithTextCode("validation")
+		mapped = errorslib.New(msg, errorslib.CategoryValidation).WithTextCode("validation")
 	case KindAuthz:
-		return errorslib.New(msg, errorslib.CategoryAuthz).WithTextCode("authz")
+		mapped = errorslib.New(msg, errorslib.CategoryAuthz).WithTextCode("authz")
 	case KindNotFound:
-		return errorslib.New(msg, errorslib.CategoryNotFound).WithTextCode("not_found")
+		mapped = errorslib.New(msg, errorslib.CategoryNotFound).WithTextCode("not_found")
 	case KindTimeout:
-		return errorslib.New(msg, errorslib.CategoryOperation).WithTextCode("timeout")
+		mapped = errorslib.New(msg, errorslib.CategoryOperation).WithTextCode("timeout")
 	case KindCanceled:
-		return errorslib.New(msg, errorslib.CategoryOperation).WithTextCode("canceled")
+		mapped = errorslib.New(msg, errorslib.CategoryOperation).WithTextCode("canceled")
 	case KindExternal:
-		return errorslib.New(msg, errorslib.CategoryExternal).WithTextCode("external")
+		mapped = errorslib.New(msg, errorslib.CategoryExternal).WithTextCode("external")
 	case KindNotImpl:
-		return errorslib.New(msg, errorslib.CategoryOperation).WithTextCode("not_implemented")
+		mapped = errorslib.New(msg, errorslib.CategoryOperation).WithTextCode("not_implemented")
+	case KindStale:
+		mapped = errorslib.New(msg, errorslib.CategoryOperation).WithTextCode("stale")
 	default:
-		return errorslib.New(msg, errorslib.CategoryInternal).WithTextCode("internal")
+		mapped = errorslib.New(msg, errorslib.CategoryInternal).WithTextCode("internal")
+	}
+	if meta := errorMetadata(err); len(meta) > 0 {
+		mapped = mapped.WithMetadata(meta)
+	}
+	return mapped
+}
+
+// errorMetadata merges metadata across wrapped export errors; outer errors
+// take precedence over inner ones.
+func errorMetadata(err error) map[string]any {
+	var meta map[string]any
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		exportErr, ok := current.(*ExportError)
+		if !ok || len(exportErr.Metadata) == 0 {
+			continue
+		}
+		if meta == nil {
+			meta = make(map[string]any, len(exportErr.Metadata))
+		}
+		for key, value := range exportErr.Metadata {
+			if _, exists := meta[key]; !exists {
+				meta[key] = value
+			}
+		}
 	}
+	return meta
 }
 
 // KindFromError maps an error to its export error kind.
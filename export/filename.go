@@ -23,6 +23,12 @@ func renderFilename(def ResolvedDefinition, req ExportRequest, now time.Time) (s
 		name = "{{.Definition}}_{{.Timestamp}}"
 	}
 
+	// Scheduled runs are named after their logical slot, so catch-up runs
+	// get distinct names.
+	if !req.ScheduledAt.IsZero() {
+		now = req.ScheduledAt
+	}
+
 	data := filenameData{
 		Definition: def.Name,
 		Format:     string(req.Format),
@@ -47,3 +47,17 @@ func TestRenderFilename_SQLiteUsesSQLite(t *testing.T) {
 		t.Fatalf("expected .sqlite extension, got %q", name)
 	}
 }
+
+func TestRenderFilename_ScheduledRunUsesLogicalTime(t *testing.T) {
+	def := ResolvedDefinition{ExportDefinition: ExportDefinition{Name: "users", DefaultFilename: "{{.Definition}}_{{.Date}}"}}
+	req := ExportRequest{Definition: "users", Format: FormatCSV, ScheduledAt: time.Date(2024, 5, 4, 10, 0, 0, 0, time.UTC)}
+	now := time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC)
+
+	name, err := renderFilename(def, req, now)
+	if err != nil {
+		t.Fatalf("render filename: %v", err)
+	}
+	if name != "users_20240504.csv" {
+		t.Fatalf("expected logical run date in filename, got %q", name)
+	}
+}
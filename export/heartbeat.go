@@ -0,0 +1,77 @@
+package export
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultHeartbeatInterval is how often running exports touch HeartbeatAt.
+const DefaultHeartbeatInterval = 30 * time.Second
+
+// HeartbeatTracker records liveness for running exports.
+type HeartbeatTracker interface {
+	Heartbeat(ctx context.Context, id string, at time.Time) error
+}
+
+func recordHeartbeat(ctx context.Context, tracker ProgressTracker, id string, at time.Time) error {
+	if tracker == nil {
+		return nil
+	}
+	if ht, ok := tracker.(HeartbeatTracker); ok {
+		return ht.Heartbeat(ctx, id, at)
+	}
+	return NewError(KindNotImpl, "tracker does not support heartbeats", nil)
+}
+
+// startHeartbeat touches the record now and every HeartbeatInterval until
+// the returned stop func is called. Trackers without heartbeat support are
+// skipped. Trackers only accept heartbeats for running records, so a
+// NotFound result means the run was superseded and it is canceled.
+func (r *Runner) startHeartbeat(ctx context.Context, exportID string, cancel context.CancelCauseFunc) func() {
+	if r.Tracker == nil || r.HeartbeatInterval < 0 {
+		return func() {}
+	}
+	// beat reports whether heartbeats should continue.
+	beat := func() bool {
+		err := recordHeartbeat(ctx, r.Tracker, exportID, r.Now())
+		if err == nil || ctx.Err() != nil {
+			return true
+		}
+		switch KindFromError(err) {
+		case KindNotImpl:
+			return false
+		case KindNotFound:
+			r.Logger.Warn("export no longer running, stopping run", "export_id", exportID)
+			cancel(errRunSuperseded)
+			return false
+		}
+		r.Logger.Warn("export heartbeat failed", "error", err, "export_id", exportID)
+		return true
+	}
+	if !beat() {
+		return func() {}
+	}
+
+	interval := r.HeartbeatInterval
+	if interval == 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !beat() {
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAcquireRunLease_OncePerWindow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 10, 0, 5, 0, time.UTC)
+	locker := NewMemoryLocker()
+	locker.Now = func() time.Time { return now }
+
+	lease, ok, err := AcquireRunLease(ctx, locker, "exports-scheduled", time.Minute, now)
+	if err != nil || !ok {
+		t.Fatalf("expected first replica to acquire, got %v %v", ok, err)
+	}
+	if lease.Name != "exports-scheduled@2024-05-01T10:00:00Z" || !lease.ExpiresAt.Equal(time.Date(2024, 5, 1, 10, 1, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected lease %+v", lease)
+	}
+
+	// A replica firing later for the same tick skips.
+	now = now.Add(20 * time.Second)
+	if _, ok, _ := AcquireRunLease(ctx, locker, "exports-scheduled", time.Minute, now); ok {
+		t.Fatalf("expected second acquire in window to fail")
+	}
+	if _, ok, _ := AcquireRunLease(ctx, locker, "exports-cleanup", time.Minute, now); !ok {
+		t.Fatalf("expected other lease names to be independent")
+	}
+
+	now = now.Add(40 * time.Second)
+	if _, ok, _ := AcquireRunLease(ctx, locker, "exports-scheduled", time.Minute, now); !ok {
+		t.Fatalf("expected next window to be acquirable")
+	}
+}
+
+func TestAcquireRunLease_ClockSkewAcrossBoundary(t *testing.T) {
+	ctx := context.Background()
+	locker := NewMemoryLocker()
+	early := time.Date(2024, 5, 1, 7, 59, 59, 999_000_000, time.UTC)
+	late := time.Date(2024, 5, 1, 8, 0, 0, 1_000_000, time.UTC)
+	locker.Now = func() time.Time { return early }
+
+	lease, ok, err := AcquireRunLease(ctx, locker, "exports-scheduled", time.Hour, early)
+	if err != nil || !ok {
+		t.Fatalf("expected first replica to acquire, got %v %v", ok, err)
+	}
+	if lease.Name != "exports-scheduled@2024-05-01T08:00:00Z" {
+		t.Fatalf("expected the 08:00 slot, got %s", lease.Name)
+	}
+	locker.Now = func() time.Time { return late }
+	if _, ok, _ := AcquireRunLease(ctx, locker, "exports-scheduled", time.Hour, late); ok {
+		t.Fatalf("expected replica on the other side of the boundary to skip")
+	}
+}
+
+func TestMemoryLocker_ExpiryAndRelease(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	locker := NewMemoryLocker()
+	locker.Now = func() time.Time { return now }
+
+	lease, ok, err := locker.Acquire(ctx, "job", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("acquire: %v %v", ok, err)
+	}
+	if err := locker.Release(ctx, Lease{Name: "job", Owner: "someone-else"}); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, ok, _ := locker.Acquire(ctx, "job", time.Minute); ok {
+		t.Fatalf("expected foreign release to be ignored")
+	}
+	if err := locker.Release(ctx, lease); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, ok, _ := locker.Acquire(ctx, "job", time.Minute); !ok {
+		t.Fatalf("expected released lease to be acquirable")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok, _ := locker.Acquire(ctx, "job", time.Minute); !ok {
+		t.Fatalf("expected expired lease to be acquirable")
+	}
+	if _, _, err := locker.Acquire(ctx, "job", 0); err == nil {
+		t.Fatalf("expected zero ttl to fail")
+	}
+}
@@ -0,0 +1,325 @@
+package export
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// ManifestVersion is the current provenance manifest schema version.
+const ManifestVersion = 1
+
+const (
+	ManifestAlgHMACSHA256 = "HS256"
+	ManifestAlgEd25519    = "EdDSA"
+)
+
+const modulePath = "github.com/goliatone/go-export"
+
+// ManifestRedacted replaces query parameter values whose names look like
+// credentials (password, secret, token, API key, ...) in manifests.
+const ManifestRedacted = "[redacted]"
+
+var sensitiveParamNames = []string{
+	"password", "passwd", "secret", "token", "apikey", "api_key",
+	"authorization", "credential", "signature", "private_key",
+}
+
+// Manifest records the provenance of a generated artifact.
+type Manifest struct {
+	Version     int                     `json:"version"`
+	ExportID    string                  `json:"export_id"`
+	Definition  string                  `json:"definition"`
+	Variant     string                  `json:"variant,omitempty"`
+	Format      Format                  `json:"format"`
+	Columns     []string                `json:"columns"`
+	Redacted    []string                `json:"redacted,omitempty"`
+	Masked      map[string]MaskStrategy `json:"masked,omitempty"`
+	Query       json.RawMessage         `json:"query,omitempty"`
+	Selection   ManifestSelection       `json:"selection"`
+	Rows        int64                   `json:"rows"`
+	Bytes       int64                   `json:"bytes"`
+	ArtifactKey string                  `json:"artifact_key"`
+	Checksum    string                  `json:"checksum"`
+	Watermark   string                  `json:"watermark,omitempty"`
+	Generator   string                  `json:"generator"`
+	ActorID     string                  `json:"actor_id,omitempty"`
+	TenantID    string                  `json:"tenant_id,omitempty"`
+	WorkspaceID string                  `json:"workspace_id,omitempty"`
+	RequestedAt time.Time               `json:"requested_at"`
+	GeneratedAt time.Time               `json:"generated_at"`
+}
+
+// ManifestSelection is the serialized selection recorded in a manifest.
+type ManifestSelection struct {
+	Mode        SelectionMode `json:"mode,omitempty"`
+	IDs         []string      `json:"ids,omitempty"`
+	QueryName   string        `json:"query_name,omitempty"`
+	QueryParams any           `json:"query_params,omitempty"`
+}
+
+// ManifestSignature describes how a manifest payload was signed.
+type ManifestSignature struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	Value     string `json:"value"`
+}
+
+// SignedManifest is the sidecar document stored next to an artifact.
+// Manifest holds the exact signed bytes so verification never re-encodes.
+type SignedManifest struct {
+	Manifest  json.RawMessage   `json:"manifest"`
+	Signature ManifestSignature `json:"signature"`
+}
+
+// Decode returns the typed manifest.
+func (m SignedManifest) Decode() (Manifest, error) {
+	var manifest Manifest
+	if err := json.Unmarshal(m.Manifest, &manifest); err != nil {
+		return Manifest{}, NewError(KindValidation, "manifest payload invalid", err)
+	}
+	return manifest, nil
+}
+
+// ManifestSigner signs and verifies manifest payloads.
+type ManifestSigner interface {
+	Sign(ctx context.Context, payload []byte) (ManifestSignature, error)
+	Verify(ctx context.Context, payload []byte, sig ManifestSignature) error
+}
+
+// ManifestVerification reports signature and checksum checks for an export manifest.
+type ManifestVerification struct {
+	ExportID       string            `json:"export_id"`
+	Manifest       Manifest          `json:"manifest"`
+	Signature      ManifestSignature `json:"signature"`
+	SignatureValid bool              `json:"signature_valid"`
+	ChecksumValid  bool              `json:"checksum_valid"`
+	Error          string            `json:"error,omitempty"`
+}
+
+// Valid reports whether both the signature and artifact checksum verified.
+func (v ManifestVerification) Valid() bool {
+	return v.SignatureValid && v.ChecksumValid
+}
+
+// ManifestKey returns the sidecar key for an artifact key.
+func ManifestKey(artifactKey string) string {
+	return artifactKey + ".manifest.json"
+}
+
+// SignManifest encodes and signs a manifest.
+func SignManifest(ctx context.Context, signer ManifestSigner, manifest Manifest) (SignedManifest, error) {
+	if signer == nil {
+		return SignedManifest{}, NewError(KindValidation, "manifest signer is required", nil)
+	}
+	payload, err := json.Marshal(manifest)
+	if err != nil {
+		return SignedManifest{}, NewError(KindInternal, "manifest encoding failed", err)
+	}
+	sig, err := signer.Sign(ctx, payload)
+	if err != nil {
+		return SignedManifest{}, err
+	}
+	return SignedManifest{Manifest: payload, Signature: sig}, nil
+}
+
+// VerifyManifest checks the signature of a signed manifest and returns its content.
+func VerifyManifest(ctx context.Context, signer ManifestSigner, signed SignedManifest) (Manifest, error) {
+	if signer == nil {
+		return Manifest{}, NewError(KindValidation, "manifest signer is required", nil)
+	}
+	if len(signed.Manifest) == 0 {
+		return Manifest{}, NewError(KindValidation, "manifest payload is required", nil)
+	}
+	if err := signer.Verify(ctx, signed.Manifest, signed.Signature); err != nil {
+		return Manifest{}, err
+	}
+	return signed.Decode()
+}
+
+// HMACManifestSigner signs manifests with HMAC-SHA256.
+type HMACManifestSigner struct {
+	KeyID string
+	Key   []byte
+}
+
+// Sign computes the HMAC of the payload.
+func (s HMACManifestSigner) Sign(ctx context.Context, payload []byte) (ManifestSignature, error) {
+	_ = ctx
+	if len(s.Key) == 0 {
+		return ManifestSignature{}, NewError(KindValidation, "hmac key is required", nil)
+	}
+	return ManifestSignature{
+		Algorithm: ManifestAlgHMACSHA256,
+		KeyID:     s.KeyID,
+		Value:     base64.StdEncoding.EncodeToString(s.mac(payload)),
+	}, nil
+}
+
+// Verify checks the HMAC of the payload.
+func (s HMACManifestSigner) Verify(ctx context.Context, payload []byte, sig ManifestSignature) error {
+	_ = ctx
+	if len(s.Key) == 0 {
+		return NewError(KindValidation, "hmac key is required", nil)
+	}
+	if sig.Algorithm != ManifestAlgHMACSHA256 || (s.KeyID != "" && sig.KeyID != s.KeyID) {
+		return NewError(KindValidation, "manifest signature algorithm or key mismatch", nil)
+	}
+	value, err := base64.StdEncoding.DecodeString(sig.Value)
+	if err != nil || !hmac.Equal(value, s.mac(payload)) {
+		return NewError(KindValidation, "manifest signature invalid", err)
+	}
+	return nil
+}
+
+func (s HMACManifestSigner) mac(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// Ed25519ManifestSigner signs manifests with Ed25519. A signer holding only
+// PublicKey can verify but not sign.
+type Ed25519ManifestSigner struct {
+	KeyID      string
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+}
+
+// Sign signs the payload with the private key.
+func (s Ed25519ManifestSigner) Sign(ctx context.Context, payload []byte) (ManifestSignature, error) {
+	_ = ctx
+	if len(s.PrivateKey) != ed25519.PrivateKeySize {
+		return ManifestSignature{}, NewError(KindValidation, "ed25519 private key is required", nil)
+	}
+	return ManifestSignature{
+		Algorithm: ManifestAlgEd25519,
+		KeyID:     s.KeyID,
+		Value:     base64.StdEncoding.EncodeToString(ed25519.Sign(s.PrivateKey, payload)),
+	}, nil
+}
+
+// Verify checks the payload signature with the public key.
+func (s Ed25519ManifestSigner) Verify(ctx context.Context, payload []byte, sig ManifestSignature) error {
+	_ = ctx
+	public := s.PublicKey
+	if len(public) == 0 && len(s.PrivateKey) == ed25519.PrivateKeySize {
+		public = s.PrivateKey.Public().(ed25519.PublicKey)
+	}
+	if len(public) != ed25519.PublicKeySize {
+		return NewError(KindValidation, "ed25519 public key is required", nil)
+	}
+	if sig.Algorithm != ManifestAlgEd25519 || (s.KeyID != "" && sig.KeyID != s.KeyID) {
+		return NewError(KindValidation, "manifest signature algorithm or key mismatch", nil)
+	}
+	value, err := base64.StdEncoding.DecodeString(sig.Value)
+	if err != nil || !ed25519.Verify(public, payload, value) {
+		return NewError(KindValidation, "manifest signature invalid", err)
+	}
+	return nil
+}
+
+func buildManifest(exportID string, resolved ResolvedExport, actor Actor, result ExportResult, ref ArtifactRef, requestedAt, generatedAt time.Time) Manifest {
+	manifest := Manifest{
+		Version:     ManifestVersion,
+		ExportID:    exportID,
+		Definition:  resolved.Definition.Name,
+		Variant:     resolved.Definition.Variant,
+		Format:      resolved.Request.Format,
+		Columns:     resolved.ColumnNames,
+		Redacted:    resolved.Access.Redacted,
+		Masked:      resolved.Access.Masked,
+		Rows:        result.Rows,
+		Bytes:       result.Bytes,
+		ArtifactKey: ref.Key,
+		Checksum:    ref.Meta.Checksum,
+		Generator:   generatorVersion(),
+		ActorID:     actor.ID,
+		TenantID:    actor.Scope.TenantID,
+		WorkspaceID: actor.Scope.WorkspaceID,
+		RequestedAt: requestedAt,
+		GeneratedAt: generatedAt,
+		Selection: ManifestSelection{
+			Mode:        resolved.Request.Selection.Mode,
+			IDs:         resolved.Request.Selection.IDs,
+			QueryName:   resolved.Request.Selection.Query.Name,
+			QueryParams: redactParams(resolved.Request.Selection.Query.Params),
+		},
+	}
+	if query := redactParams(resolved.Request.Query); query != nil {
+		if raw, err := json.Marshal(query); err == nil {
+			manifest.Query = raw
+		}
+	}
+	if result.Watermark != nil {
+		manifest.Watermark = result.Watermark.Token
+	}
+	return manifest
+}
+
+// redactParams returns a JSON-shaped copy of params with sensitive values
+// replaced by ManifestRedacted.
+func redactParams(params any) any {
+	if params == nil {
+		return nil
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil
+	}
+	return redactValue(decoded)
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if sensitiveParam(key) {
+				v[key] = ManifestRedacted
+				continue
+			}
+			v[key] = redactValue(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+func sensitiveParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, sensitive := range sensitiveParamNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+func generatorVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return modulePath
+	}
+	if info.Main.Path == modulePath && info.Main.Version != "" {
+		return modulePath + "@" + info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			return modulePath + "@" + dep.Version
+		}
+	}
+	return modulePath
+}
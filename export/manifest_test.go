@@ -0,0 +1,151 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestManifestSigners_SignAndVerify(t *testing.T) {
+	ctx := context.Background()
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	cases := []struct {
+		name     string
+		signer   ManifestSigner
+		verifier ManifestSigner
+	}{
+		{"hmac", HMACManifestSigner{KeyID: "k1", Key: []byte("secret")}, HMACManifestSigner{KeyID: "k1", Key: []byte("secret")}},
+		{"ed25519", Ed25519ManifestSigner{KeyID: "k1", PrivateKey: private}, Ed25519ManifestSigner{KeyID: "k1", PublicKey: public}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			signed, err := SignManifest(ctx, tc.signer, Manifest{Version: ManifestVersion, ExportID: "exp-1", Rows: 2, Checksum: "abc"})
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			manifest, err := VerifyManifest(ctx, tc.verifier, signed)
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if manifest.ExportID != "exp-1" || manifest.Rows != 2 {
+				t.Fatalf("unexpected manifest %+v", manifest)
+			}
+
+			tampered := signed
+			tampered.Manifest = bytes.Replace(signed.Manifest, []byte(`"rows":2`), []byte(`"rows":3`), 1)
+			if _, err := VerifyManifest(ctx, tc.verifier, tampered); err == nil {
+				t.Fatalf("expected tampered manifest to fail verification")
+			}
+		})
+	}
+
+	signed, _ := SignManifest(ctx, HMACManifestSigner{Key: []byte("secret")}, Manifest{ExportID: "exp-1"})
+	if _, err := VerifyManifest(ctx, HMACManifestSigner{Key: []byte("other")}, signed); err == nil {
+		t.Fatalf("expected wrong key to fail verification")
+	}
+}
+
+func TestService_GenerateExportWritesSignedManifest(t *testing.T) {
+	ctx := context.Background()
+	runner := NewRunner()
+	if err := runner.Definitions.Register(ExportDefinition{
+		Name:         "users",
+		RowSourceKey: "stub",
+		Schema:       Schema{Columns: []Column{{Name: "id"}, {Name: "email"}}},
+		Policy:       ExportPolicy{RedactColumns: []string{"email"}},
+	}); err != nil {
+		t.Fatalf("register definition: %v", err)
+	}
+	if err := runner.RowSources.Register("stub", func(req ExportRequest, def ResolvedDefinition) (RowSource, error) {
+		_ = req
+		_ = def
+		return &stubSource{iter: &stubIterator{rows: []Row{{"1", "a@example.com"}, {"2", "b@example.com"}}}}, nil
+	}); err != nil {
+		t.Fatalf("register source: %v", err)
+	}
+	store := NewMemoryStore()
+	svc := NewService(ServiceConfig{
+		Runner:         runner,
+		Tracker:        NewMemoryTracker(),
+		Store:          store,
+		ManifestSigner: HMACManifestSigner{KeyID: "test", Key: []byte("secret")},
+	})
+
+	actor := Actor{ID: "user-1", Scope: Scope{TenantID: "t1"}}
+	result, err := svc.GenerateExport(ctx, actor, "exp-man", ExportRequest{
+		Definition: "users",
+		Format:     FormatCSV,
+		Query:      map[string]any{"status": "active", "filters": map[string]any{"api_token": "tok-123"}},
+		Selection: Selection{Mode: SelectionIDs, IDs: []string{"1", "2"}, Query: SelectionQueryRef{
+			Name:   "by_partner",
+			Params: map[string]string{"partner": "acme", "Password": "hunter2"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if result.ManifestKey != ManifestKey(result.Artifact.Key) {
+		t.Fatalf("expected manifest key, got %q", result.ManifestKey)
+	}
+
+	reader, _, err := store.Open(ctx, result.ManifestKey)
+	if err != nil {
+		t.Fatalf("open manifest: %v", err)
+	}
+	raw, _ := io.ReadAll(reader)
+	var signed SignedManifest
+	if err := json.Unmarshal(raw, &signed); err != nil {
+		t.Fatalf("decode sidecar: %v", err)
+	}
+	manifest, err := signed.Decode()
+	if err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	if manifest.Definition != "users" || manifest.Rows != 2 || manifest.Checksum != result.Checksum {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	if manifest.ActorID != "user-1" || manifest.TenantID != "t1" || manifest.Generator == "" {
+		t.Fatalf("expected provenance fields, got %+v", manifest)
+	}
+	if len(manifest.Redacted) != 1 || manifest.Redacted[0] != "email" {
+		t.Fatalf("expected redactions recorded, got %v", manifest.Redacted)
+	}
+	if manifest.Selection.Mode != SelectionIDs || len(manifest.Selection.IDs) != 2 {
+		t.Fatalf("expected selection recorded, got %+v", manifest.Selection)
+	}
+	if bytes.Contains(raw, []byte("tok-123")) || bytes.Contains(raw, []byte("hunter2")) {
+		t.Fatalf("expected secrets in params to be redacted, got %s", raw)
+	}
+	params, _ := manifest.Selection.QueryParams.(map[string]any)
+	if params["partner"] != "acme" || params["Password"] != ManifestRedacted {
+		t.Fatalf("unexpected selection params %v", manifest.Selection.QueryParams)
+	}
+	if !bytes.Contains(manifest.Query, []byte(`"status":"active"`)) {
+		t.Fatalf("expected plain query params kept, got %s", manifest.Query)
+	}
+
+	verification, err := svc.VerifyManifest(ctx, actor, "exp-man")
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !verification.Valid() {
+		t.Fatalf("expected valid manifest, got %+v", verification)
+	}
+
+	if _, err := store.Put(ctx, result.Artifact.Key, bytes.NewBufferString("tampered"), ArtifactMeta{}); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	verification, err = svc.VerifyManifest(ctx, actor, "exp-man")
+	if err != nil {
+		t.Fatalf("verify tampered: %v", err)
+	}
+	if !verification.SignatureValid || verification.ChecksumValid {
+		t.Fatalf("expected checksum mismatch, got %+v", verification)
+	}
+}
@@ -0,0 +1,225 @@
+package export
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaskStrategy identifies how a column value is masked before rendering.
+type MaskStrategy string
+
+const (
+	// MaskHash replaces values with a keyed HMAC-SHA256 digest (hex).
+	MaskHash MaskStrategy = "hash"
+	// MaskPartial keeps a configurable prefix/suffix and masks the rest.
+	MaskPartial MaskStrategy = "partial"
+	// MaskEmail keeps the first character of the local part and the domain.
+	MaskEmail MaskStrategy = "email"
+	// MaskLast4 keeps only the last four characters.
+	MaskLast4 MaskStrategy = "last4"
+	// MaskTruncate keeps the first Length characters.
+	MaskTruncate MaskStrategy = "truncate"
+	// MaskYear generalizes dates to their year.
+	MaskYear MaskStrategy = "year"
+	// MaskTokenize swaps values for reversible tokens issued by a TokenVault.
+	MaskTokenize MaskStrategy = "tokenize"
+	// MaskBand generalizes numbers into BandSize-wide ranges (e.g. 50000-60000).
+	MaskBand MaskStrategy = "band"
+)
+
+// ColumnMask configures masking for a single column.
+type ColumnMask struct {
+	Column     string
+	Strategy   MaskStrategy
+	KeepPrefix int
+	KeepSuffix int
+	Length     int
+	MaskChar   string
+	BandSize   float64
+}
+
+// TokenVault issues reversible tokens for tokenized columns.
+type TokenVault interface {
+	Tokenize(ctx context.Context, column, value string) (string, error)
+	Detokenize(ctx context.Context, column, token string) (string, error)
+}
+
+const defaultMaskChar = "*"
+
+func validateMasks(policy ExportPolicy) error {
+	for _, mask := range policy.MaskColumns {
+		if strings.TrimSpace(mask.Column) == "" {
+			return NewError(KindValidation, "mask column is required", nil)
+		}
+		switch mask.Strategy {
+		case MaskPartial, MaskEmail, MaskLast4, MaskYear:
+		case MaskHash:
+			if len(policy.MaskKey) == 0 {
+				return NewError(KindValidation, fmt.Sprintf("mask key is required for hashed column %q", mask.Column), nil)
+			}
+		case MaskTruncate:
+			if mask.Length <= 0 {
+				return NewError(KindValidation, fmt.Sprintf("truncate length is required for column %q", mask.Column), nil)
+			}
+		case MaskBand:
+			if mask.BandSize <= 0 {
+				return NewError(KindValidation, fmt.Sprintf("band size is required for column %q", mask.Column), nil)
+			}
+		case MaskTokenize:
+			if policy.TokenVault == nil {
+				return NewError(KindValidation, fmt.Sprintf("token vault is required for tokenized column %q", mask.Column), nil)
+			}
+		default:
+			return NewError(KindValidation, fmt.Sprintf("mask strategy %q not supported", mask.Strategy), nil)
+		}
+	}
+	return nil
+}
+
+// resolveMasks maps column indices to maskers; redacted columns are skipped
+// because redaction always wins over masking.
+func resolveMasks(columns []Column, policy ExportPolicy) map[int]columnMasker {
+	if len(policy.MaskColumns) == 0 {
+		return nil
+	}
+	redacted := make(map[string]struct{}, len(policy.RedactColumns))
+	for _, name := range policy.RedactColumns {
+		redacted[name] = struct{}{}
+	}
+	masks := make(map[int]columnMasker)
+	for idx, col := range columns {
+		if _, ok := redacted[col.Name]; ok {
+			continue
+		}
+		for _, mask := range policy.MaskColumns {
+			if mask.Column == col.Name {
+				masks[idx] = columnMasker{mask: mask, key: policy.MaskKey, vault: policy.TokenVault}
+			}
+		}
+	}
+	if len(masks) == 0 {
+		return nil
+	}
+	return masks
+}
+
+// maskedSchema marks masked columns as strings so typed renderers do not
+// attempt to coerce masked output back into the original type.
+func maskedSchema(schema Schema, masks map[int]columnMasker) Schema {
+	if len(masks) == 0 {
+		return schema
+	}
+	columns := make([]Column, len(schema.Columns))
+	copy(columns, schema.Columns)
+	for idx := range masks {
+		if idx >= 0 && idx < len(columns) {
+			columns[idx].Type = "string"
+			columns[idx].Format = ColumnFormat{}
+		}
+	}
+	schema.Columns = columns
+	return schema
+}
+
+type columnMasker struct {
+	mask  ColumnMask
+	key   []byte
+	vault TokenVault
+}
+
+func (m columnMasker) apply(ctx context.Context, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if m.mask.Strategy == MaskYear {
+		parsed, ok := coerceTime(value)
+		if !ok {
+			return nil, NewError(KindValidation, fmt.Sprintf("invalid date for masked column %q", m.mask.Column), nil)
+		}
+		return fmt.Sprintf("%04d", parsed.Year()), nil
+	}
+	if m.mask.Strategy == MaskBand {
+		number, ok := coerceFloat(value)
+		if !ok {
+			return nil, NewError(KindValidation, fmt.Sprintf("invalid number for masked column %q", m.mask.Column), nil)
+		}
+		lower := math.Floor(number/m.mask.BandSize) * m.mask.BandSize
+		upper := lower + m.mask.BandSize
+		return strconv.FormatFloat(lower, 'f', -1, 64) + "-" + strconv.FormatFloat(upper, 'f', -1, 64), nil
+	}
+
+	raw := stringify(value)
+	if raw == "" {
+		return raw, nil
+	}
+	switch m.mask.Strategy {
+	case MaskHash:
+		mac := hmac.New(sha256.New, m.key)
+		_, _ = mac.Write([]byte(raw))
+		digest := hex.EncodeToString(mac.Sum(nil))
+		if m.mask.Length > 0 && m.mask.Length < len(digest) {
+			digest = digest[:m.mask.Length]
+		}
+		return digest, nil
+	case MaskPartial:
+		return maskRunes(raw, m.mask.KeepPrefix, m.mask.KeepSuffix, m.maskChar()), nil
+	case MaskEmail:
+		at := strings.LastIndex(raw, "@")
+		if at <= 0 {
+			return maskRunes(raw, 0, 0, m.maskChar()), nil
+		}
+		return maskRunes(raw[:at], 1, 0, m.maskChar()) + raw[at:], nil
+	case MaskLast4:
+		return maskRunes(raw, 0, 4, m.maskChar()), nil
+	case MaskTruncate:
+		runes := []rune(raw)
+		if len(runes) > m.mask.Length {
+			runes = runes[:m.mask.Length]
+		}
+		return string(runes), nil
+	case MaskTokenize:
+		if m.vault == nil {
+			return nil, NewError(KindInternal, "token vault is nil", nil)
+		}
+		token, err := m.vault.Tokenize(ctx, m.mask.Column, raw)
+		if err != nil {
+			return nil, NewError(KindExternal, fmt.Sprintf("tokenize column %q", m.mask.Column), err)
+		}
+		return token, nil
+	default:
+		return nil, NewError(KindValidation, fmt.Sprintf("mask strategy %q not supported", m.mask.Strategy), nil)
+	}
+}
+
+func (m columnMasker) maskChar() string {
+	if m.mask.MaskChar != "" {
+		return m.mask.MaskChar
+	}
+	return defaultMaskChar
+}
+
+func maskRunes(value string, keepPrefix, keepSuffix int, maskChar string) string {
+	count := utf8.RuneCountInString(value)
+	if keepPrefix < 0 {
+		keepPrefix = 0
+	}
+	if keepSuffix < 0 {
+		keepSuffix = 0
+	}
+	if keepPrefix+keepSuffix >= count {
+		return strings.Repeat(maskChar, count)
+	}
+	runes := []rune(value)
+	var b strings.Builder
+	b.WriteString(string(runes[:keepPrefix]))
+	b.WriteString(strings.Repeat(maskChar, count-keepPrefix-keepSuffix))
+	b.WriteString(string(runes[count-keepSuffix:]))
+	return b.String()
+}
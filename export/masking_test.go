@@ -0,0 +1,142 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunner_MasksColumns(t *testing.T) {
+	buf := &bytes.Buffer{}
+	birth := time.Date(1985, 6, 7, 0, 0, 0, 0, time.UTC)
+	iter := &stubIterator{rows: []Row{{"alice@example.com", "4111111111111111", "123-45-6789", "Alice Wonderland", birth, "A-100", "secret"}}}
+	vault := NewMemoryTokenVault()
+
+	runner := NewRunner()
+	if err := runner.Definitions.Register(ExportDefinition{
+		Name:         "users",
+		RowSourceKey: "stub",
+		Schema: Schema{Columns: []Column{
+			{Name: "email"},
+			{Name: "card"},
+			{Name: "ssn"},
+			{Name: "name"},
+			{Name: "birth_date", Type: "date"},
+			{Name: "account"},
+			{Name: "token"},
+		}},
+		Policy: ExportPolicy{
+			RedactColumns: []string{"token"},
+			MaskColumns: []ColumnMask{
+				{Column: "email", Strategy: MaskEmail},
+				{Column: "card", Strategy: MaskLast4},
+				{Column: "ssn", Strategy: MaskHash, Length: 16},
+				{Column: "name", Strategy: MaskTruncate, Length: 5},
+				{Column: "birth_date", Strategy: MaskYear},
+				{Column: "account", Strategy: MaskTokenize},
+				{Column: "token", Strategy: MaskPartial, KeepPrefix: 1},
+			},
+			MaskKey:    []byte("test-key"),
+			TokenVault: vault,
+		},
+	}); err != nil {
+		t.Fatalf("register definition: %v", err)
+	}
+	if err := runner.RowSources.Register("stub", func(req ExportRequest, def ResolvedDefinition) (RowSource, error) {
+		_ = req
+		_ = def
+		return &stubSource{iter: iter}, nil
+	}); err != nil {
+		t.Fatalf("register source: %v", err)
+	}
+
+	if _, err := runner.Run(context.Background(), ExportRequest{
+		Definition: "users",
+		Format:     FormatJSON,
+		Output:     buf,
+	}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var payload []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(payload) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(payload))
+	}
+	row := payload[0]
+	if row["email"] != "a****@example.com" {
+		t.Fatalf("unexpected email mask: %v", row["email"])
+	}
+	if row["card"] != "************1111" {
+		t.Fatalf("unexpected last4 mask: %v", row["card"])
+	}
+	ssn, _ := row["ssn"].(string)
+	if len(ssn) != 16 || strings.Contains(ssn, "6789") {
+		t.Fatalf("unexpected hash mask: %v", row["ssn"])
+	}
+	if row["name"] != "Alice" {
+		t.Fatalf("unexpected truncate mask: %v", row["name"])
+	}
+	if row["birth_date"] != "1985" {
+		t.Fatalf("unexpected year mask: %v", row["birth_date"])
+	}
+	if row["token"] != "[redacted]" {
+		t.Fatalf("expected redaction to win over masking, got %v", row["token"])
+	}
+
+	token, _ := row["account"].(string)
+	original, err := vault.Detokenize(context.Background(), "account", token)
+	if err != nil {
+		t.Fatalf("detokenize: %v", err)
+	}
+	if original != "A-100" {
+		t.Fatalf("expected original account, got %q", original)
+	}
+}
+
+func TestColumnMasker_HashIsKeyed(t *testing.T) {
+	first := columnMasker{mask: ColumnMask{Column: "ssn", Strategy: MaskHash}, key: []byte("one")}
+	second := columnMasker{mask: ColumnMask{Column: "ssn", Strategy: MaskHash}, key: []byte("two")}
+
+	a, err := first.apply(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	again, _ := first.apply(context.Background(), "123")
+	b, _ := second.apply(context.Background(), "123")
+	if a != again {
+		t.Fatalf("expected deterministic hash")
+	}
+	if a == b {
+		t.Fatalf("expected different keys to produce different hashes")
+	}
+}
+
+func TestResolveExport_ValidatesMasks(t *testing.T) {
+	def := ResolvedDefinition{ExportDefinition: ExportDefinition{
+		Name:           "users",
+		AllowedFormats: []Format{FormatCSV},
+		Schema:         Schema{Columns: []Column{{Name: "ssn"}}},
+	}}
+
+	cases := []ExportPolicy{
+		{MaskColumns: []ColumnMask{{Column: "ssn", Strategy: MaskHash}}},
+		{MaskColumns: []ColumnMask{{Column: "ssn", Strategy: MaskTokenize}}},
+		{MaskColumns: []ColumnMask{{Column: "ssn", Strategy: MaskTruncate}}},
+		{MaskColumns: []ColumnMask{{Column: "ssn", Strategy: "scramble"}}},
+	}
+	for _, policy := range cases {
+		def.Policy = policy
+		_, err := ResolveExport(ExportRequest{Definition: "users", Format: FormatCSV}, def, testNow())
+		var exportErr *ExportError
+		if !errors.As(err, &exportErr) || exportErr.Kind != KindValidation {
+			t.Fatalf("expected validation error for %+v, got %v", policy.MaskColumns, err)
+		}
+	}
+}
@@ -3,8 +3,11 @@ package export
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -26,7 +29,8 @@ func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{objects: make(map[string]memoryObject)}
 }
 
-// Put stores an artifact.
+// Put stores an artifact and records its SHA-256 checksum. A checksum already
+// set on meta must match the data, otherwise nothing is stored.
 func (s *MemoryStore) Put(ctx context.Context, key string, r io.Reader, meta ArtifactMeta) (ArtifactRef, error) {
 	_ = ctx
 	if key == "" {
@@ -37,6 +41,12 @@ func (s *MemoryStore) Put(ctx context.Context, key string, r io.Reader, meta Art
 		return ArtifactRef{}, err
 	}
 	meta.Size = int64(len(data))
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if meta.Checksum != "" && meta.Checksum != checksum {
+		return ArtifactRef{}, NewError(KindValidation, "artifact checksum mismatch", nil)
+	}
+	meta.Checksum = checksum
 	if meta.CreatedAt.IsZero() {
 		meta.CreatedAt = time.Now()
 	}
@@ -60,6 +70,26 @@ func (s *MemoryStore) Open(ctx context.Context, key string) (io.ReadCloser, Arti
 	return io.NopCloser(bytes.NewReader(obj.data)), obj.meta, nil
 }
 
+// OpenRange reads a byte range of an artifact.
+func (s *MemoryStore) OpenRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, ArtifactMeta, error) {
+	_ = ctx
+	s.mu.RLock()
+	obj, ok := s.objects[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ArtifactMeta{}, NewError(KindNotFound, fmt.Sprintf("artifact %q not found", key), nil)
+	}
+	size := int64(len(obj.data))
+	if offset < 0 || offset > size {
+		return nil, ArtifactMeta{}, NewError(KindValidation, "range offset out of bounds", nil)
+	}
+	end := size
+	if length >= 0 && offset+length < size {
+		end = offset + length
+	}
+	return io.NopCloser(bytes.NewReader(obj.data[offset:end])), obj.meta, nil
+}
+
 // Delete removes an artifact.
 func (s *MemoryStore) Delete(ctx context.Context, key string) error {
 	_ = ctx
@@ -149,6 +179,53 @@ func (t *MemoryTracker) SetState(ctx context.Context, id string, state ExportSta
 	return nil
 }
 
+// TransitionState sets state to to only while the record is in from.
+func (t *MemoryTracker) TransitionState(ctx context.Context, id string, from, to ExportState) (bool, error) {
+	_ = ctx
+	return t.transition(id, from, to, nil)
+}
+
+// TransitionStale sets state to to only while the record is running with
+// the given heartbeat.
+func (t *MemoryTracker) TransitionStale(ctx context.Context, id string, heartbeatAt time.Time, to ExportState) (bool, error) {
+	_ = ctx
+	return t.transition(id, StateRunning, to, func(record ExportRecord) bool {
+		return record.HeartbeatAt.Equal(heartbeatAt)
+	})
+}
+
+func (t *MemoryTracker) transition(id string, from, to ExportState, match func(ExportRecord) bool) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	record, ok := t.records[id]
+	if !ok {
+		return false, NewError(KindNotFound, fmt.Sprintf("export %q not found", id), nil)
+	}
+	if record.State != from || (match != nil && !match(record)) {
+		return false, nil
+	}
+	record.State = to
+	if (to == StateCompleted || to == StateFailed) && record.CompletedAt.IsZero() {
+		record.CompletedAt = time.Now()
+	}
+	t.records[id] = record
+	return true, nil
+}
+
+// Heartbeat records that a running export is still alive.
+func (t *MemoryTracker) Heartbeat(ctx context.Context, id string, at time.Time) error {
+	_ = ctx
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	record, ok := t.records[id]
+	if !ok || record.State != StateRunning {
+		return NewError(KindNotFound, fmt.Sprintf("running export %q not found", id), nil)
+	}
+	record.HeartbeatAt = at
+	t.records[id] = record
+	return nil
+}
+
 // Fail records failure state.
 func (t *MemoryTracker) Fail(ctx context.Context, id string, err error, meta map[string]any) error {
 	_ = ctx
@@ -208,11 +285,43 @@ func (t *MemoryTracker) SetArtifact(ctx context.Context, id string, ref Artifact
 		return NewError(KindNotFound, fmt.Sprintf("export %q not found", id), nil)
 	}
 	record.Artifact = ref
+	record.Checksum = ref.Meta.Checksum
+	t.records[id] = record
+	t.mu.Unlock()
+	return nil
+}
+
+// SetWatermark records the artifact watermark for a record.
+func (t *MemoryTracker) SetWatermark(ctx context.Context, id string, watermark Watermark) error {
+	_ = ctx
+	t.mu.Lock()
+	record, ok := t.records[id]
+	if !ok {
+		t.mu.Unlock()
+		return NewError(KindNotFound, fmt.Sprintf("export %q not found", id), nil)
+	}
+	record.Watermark = &watermark
 	t.records[id] = record
 	t.mu.Unlock()
 	return nil
 }
 
+// FindByWatermark returns the record that issued a watermark token.
+func (t *MemoryTracker) FindByWatermark(ctx context.Context, token string) (ExportRecord, error) {
+	_ = ctx
+	if token == "" {
+		return ExportRecord{}, NewError(KindValidation, "watermark token is required", nil)
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, record := range t.records {
+		if record.Watermark != nil && record.Watermark.Token == token {
+			return record, nil
+		}
+	}
+	return ExportRecord{}, NewError(KindNotFound, fmt.Sprintf("watermark %q not found", token), nil)
+}
+
 // Update replaces a record by ID.
 func (t *MemoryTracker) Update(ctx context.Context, record ExportRecord) error {
 	_ = ctx
@@ -271,3 +380,283 @@ func (t *MemoryTracker) nextID() string {
 	id := atomic.AddUint64(&t.counter, 1)
 	return fmt.Sprintf("exp-%d", id)
 }
+
+// MemoryTokenVault issues reversible tokens in memory (test/dev only).
+type MemoryTokenVault struct {
+	mu      sync.RWMutex
+	tokens  map[string]string
+	values  map[string]string
+	counter uint64
+}
+
+// NewMemoryTokenVault creates an in-memory token vault.
+func NewMemoryTokenVault() *MemoryTokenVault {
+	return &MemoryTokenVault{
+		tokens: make(map[string]string),
+		values: make(map[string]string),
+	}
+}
+
+// Tokenize returns a stable token for a column value.
+func (v *MemoryTokenVault) Tokenize(ctx context.Context, column, value string) (string, error) {
+	_ = ctx
+	if v == nil {
+		return "", NewError(KindInternal, "token vault is nil", nil)
+	}
+	key := column + "\x00" + value
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if token, ok := v.tokens[key]; ok {
+		return token, nil
+	}
+	v.counter++
+	token := fmt.Sprintf("tok-%d", v.counter)
+	v.tokens[key] = token
+	v.values[column+"\x00"+token] = value
+	return token, nil
+}
+
+// Detokenize resolves a token back to its original value.
+func (v *MemoryTokenVault) Detokenize(ctx context.Context, column, token string) (string, error) {
+	_ = ctx
+	if v == nil {
+		return "", NewError(KindInternal, "token vault is nil", nil)
+	}
+	v.mu.RLock()
+	value, ok := v.values[column+"\x00"+token]
+	v.mu.RUnlock()
+	if !ok {
+		return "", NewError(KindNotFound, "token not found", nil)
+	}
+	return value, nil
+}
+
+// MemoryDownloadLog stores download history in memory (test/dev only).
+type MemoryDownloadLog struct {
+	mu      sync.RWMutex
+	records map[string][]DownloadRecord
+	counter uint64
+}
+
+// NewMemoryDownloadLog creates an in-memory download log.
+func NewMemoryDownloadLog() *MemoryDownloadLog {
+	return &MemoryDownloadLog{records: make(map[string][]DownloadRecord)}
+}
+
+// RecordDownload appends a download entry.
+func (l *MemoryDownloadLog) RecordDownload(ctx context.Context, record DownloadRecord) error {
+	_ = ctx
+	if record.ExportID == "" {
+		return NewError(KindValidation, "export ID is required", nil)
+	}
+	if record.ID == "" {
+		record.ID = fmt.Sprintf("dl-%d", atomic.AddUint64(&l.counter, 1))
+	}
+	if record.DownloadedAt.IsZero() {
+		record.DownloadedAt = time.Now()
+	}
+
+	l.mu.Lock()
+	l.records[record.ExportID] = append(l.records[record.ExportID], record)
+	l.mu.Unlock()
+	return nil
+}
+
+// ListDownloads returns downloads for an export in the order they occurred.
+func (l *MemoryDownloadLog) ListDownloads(ctx context.Context, exportID string) ([]DownloadRecord, error) {
+	_ = ctx
+	l.mu.RLock()
+	result := append([]DownloadRecord{}, l.records[exportID]...)
+	l.mu.RUnlock()
+	return result, nil
+}
+
+// MemoryDeliveryTracker stores delivery history in memory (test/dev only).
+type MemoryDeliveryTracker struct {
+	mu      sync.RWMutex
+	records []DeliveryRecord
+	counter uint64
+}
+
+// NewMemoryDeliveryTracker creates an in-memory delivery tracker.
+func NewMemoryDeliveryTracker() *MemoryDeliveryTracker {
+	return &MemoryDeliveryTracker{}
+}
+
+// RecordDelivery appends a delivery entry.
+func (t *MemoryDeliveryTracker) RecordDelivery(ctx context.Context, record DeliveryRecord) error {
+	_ = ctx
+	if record.ExportID == "" {
+		return NewError(KindValidation, "export ID is required", nil)
+	}
+	if record.ID == "" {
+		record.ID = fmt.Sprintf("dlv-%d", atomic.AddUint64(&t.counter, 1))
+	}
+	if record.StartedAt.IsZero() {
+		record.StartedAt = time.Now()
+	}
+	if record.CompletedAt.IsZero() {
+		record.CompletedAt = record.StartedAt
+	}
+	record.Recipients = append([]string(nil), record.Recipients...)
+
+	t.mu.Lock()
+	t.records = append(t.records, record)
+	t.mu.Unlock()
+	return nil
+}
+
+// ListDeliveries returns matching deliveries, newest first.
+func (t *MemoryDeliveryTracker) ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]DeliveryRecord, error) {
+	_ = ctx
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := make([]DeliveryRecord, 0)
+	for i := len(t.records) - 1; i >= 0; i-- {
+		if !filter.Matches(t.records[i]) {
+			continue
+		}
+		result = append(result, t.records[i])
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// MemoryScheduleStore stores schedules in memory (test/dev only).
+type MemoryScheduleStore struct {
+	mu        sync.RWMutex
+	schedules map[string]Schedule
+}
+
+// NewMemoryScheduleStore creates an in-memory schedule store.
+func NewMemoryScheduleStore() *MemoryScheduleStore {
+	return &MemoryScheduleStore{schedules: make(map[string]Schedule)}
+}
+
+// CreateSchedule stores a new schedule.
+func (s *MemoryScheduleStore) CreateSchedule(ctx context.Context, schedule Schedule) error {
+	_ = ctx
+	if schedule.ID == "" {
+		return NewError(KindValidation, "schedule ID is required", nil)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.schedules[schedule.ID]; ok {
+		return NewError(KindValidation, "schedule already exists", nil)
+	}
+	s.schedules[schedule.ID] = cloneSchedule(schedule)
+	return nil
+}
+
+// UpdateSchedule replaces an existing schedule.
+func (s *MemoryScheduleStore) UpdateSchedule(ctx context.Context, schedule Schedule) error {
+	_ = ctx
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.schedules[schedule.ID]; !ok {
+		return NewError(KindNotFound, "schedule not found", nil)
+	}
+	s.schedules[schedule.ID] = cloneSchedule(schedule)
+	return nil
+}
+
+// DeleteSchedule removes a schedule.
+func (s *MemoryScheduleStore) DeleteSchedule(ctx context.Context, id string) error {
+	_ = ctx
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.schedules[id]; !ok {
+		return NewError(KindNotFound, "schedule not found", nil)
+	}
+	delete(s.schedules, id)
+	return nil
+}
+
+// GetSchedule returns a schedule by ID.
+func (s *MemoryScheduleStore) GetSchedule(ctx context.Context, id string) (Schedule, error) {
+	_ = ctx
+	s.mu.RLock()
+	schedule, ok := s.schedules[id]
+	s.mu.RUnlock()
+	if !ok {
+		return Schedule{}, NewError(KindNotFound, "schedule not found", nil)
+	}
+	return cloneSchedule(schedule), nil
+}
+
+// ListSchedules returns matching schedules ordered by creation time.
+func (s *MemoryScheduleStore) ListSchedules(ctx context.Context, filter ScheduleFilter) ([]Schedule, error) {
+	_ = ctx
+	s.mu.RLock()
+	result := make([]Schedule, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		if filter.Matches(schedule) {
+			result = append(result, cloneSchedule(schedule))
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+// DueSchedules returns enabled schedules of kind due at now, oldest first.
+func (s *MemoryScheduleStore) DueSchedules(ctx context.Context, kind ScheduleKind, now time.Time) ([]Schedule, error) {
+	_ = ctx
+	s.mu.RLock()
+	result := make([]Schedule, 0)
+	for _, schedule := range s.schedules {
+		if !schedule.Enabled || schedule.NextRunAt.IsZero() || schedule.NextRunAt.After(now) {
+			continue
+		}
+		if kind != "" && schedule.Kind != kind {
+			continue
+		}
+		result = append(result, cloneSchedule(schedule))
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].NextRunAt.Equal(result[j].NextRunAt) {
+			return result[i].NextRunAt.Before(result[j].NextRunAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// MarkScheduleRun advances a schedule if its NextRunAt still equals expected.
+func (s *MemoryScheduleStore) MarkScheduleRun(ctx context.Context, id string, expected, ranAt, next time.Time) (bool, error) {
+	_ = ctx
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedule, ok := s.schedules[id]
+	if !ok {
+		return false, NewError(KindNotFound, "schedule not found", nil)
+	}
+	if !schedule.NextRunAt.Equal(expected) {
+		return false, nil
+	}
+	schedule.LastRunAt = ranAt
+	schedule.NextRunAt = next
+	s.schedules[id] = schedule
+	return true, nil
+}
+
+func cloneSchedule(schedule Schedule) Schedule {
+	schedule.Owner.Roles = append([]string(nil), schedule.Owner.Roles...)
+	schedule.Delivery = append([]byte(nil), schedule.Delivery...)
+	return schedule
+}
@@ -16,6 +16,7 @@ type ExportReadyEvent struct {
 	ActorID          string
 	FileName         string
 	Format           string
+	Checksum         string
 	URL              string
 	ExpiresAt        string
 	Rows             int
@@ -32,4 +33,5 @@ type NotificationAttachment struct {
 	ContentType string
 	Data        []byte
 	Size        int64
+	Checksum    string
 }
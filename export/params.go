@@ -0,0 +1,226 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ParamContext controls how query parameter templates are evaluated.
+type ParamContext struct {
+	// RunTime anchors the relative helpers; defaults to the request
+	// ScheduledAt, then Now.
+	RunTime time.Time
+	// Now is the evaluation clock; defaults to time.Now.
+	Now time.Time
+	// Timezone is the IANA zone templates are evaluated in; defaults to the
+	// request Timezone, then UTC.
+	Timezone string
+}
+
+// paramRunTimeKey records the run time used for a resolution.
+const paramRunTimeKey = "run_time"
+
+// ResolveParams evaluates {{ ... }} templates in string values of
+// req.Query and req.Selection.Query.Params. Maps and slices are walked
+// recursively; other values are left untouched. Resolved values are recorded
+// on req.Parameters keyed by path (e.g. "query.from", "selection.params.to"),
+// so the export record shows what actually ran.
+//
+// Templates support run_time, now, start_of_day/week/month/year and
+// end_of_day/week/month/year (each taking an optional offset, e.g.
+// {{ start_of_month -1 }}), add_days, add_months, and date:
+// {{ now | date "2006-01-02" }}. Times render as RFC3339 unless formatted.
+func ResolveParams(req ExportRequest, pc ParamContext) (ExportRequest, error) {
+	tz := strings.TrimSpace(pc.Timezone)
+	if tz == "" {
+		tz = strings.TrimSpace(req.Timezone)
+	}
+	loc := time.UTC
+	if tz != "" {
+		parsed, err := time.LoadLocation(tz)
+		if err != nil {
+			return req, NewError(KindValidation, fmt.Sprintf("parameter timezone %q is invalid", tz), err)
+		}
+		loc = parsed
+	}
+	now := pc.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	runTime := pc.RunTime
+	if runTime.IsZero() {
+		runTime = req.ScheduledAt
+	}
+	if runTime.IsZero() {
+		runTime = now
+	}
+
+	r := &paramResolver{
+		funcs:    paramFuncs(runTime.In(loc), now.In(loc)),
+		resolved: make(map[string]string),
+	}
+	query, err := r.walk(req.Query, "query")
+	if err != nil {
+		return req, err
+	}
+	params, err := r.walk(req.Selection.Query.Params, "selection.params")
+	if err != nil {
+		return req, err
+	}
+	if len(r.resolved) == 0 {
+		return req, nil
+	}
+
+	req.Query = query
+	req.Selection.Query.Params = params
+	merged := make(map[string]string, len(req.Parameters)+len(r.resolved)+1)
+	for key, value := range req.Parameters {
+		merged[key] = value
+	}
+	for key, value := range r.resolved {
+		merged[key] = value
+	}
+	merged[paramRunTimeKey] = runTime.In(loc).Format(time.RFC3339)
+	req.Parameters = merged
+	return req, nil
+}
+
+type paramResolver struct {
+	funcs    template.FuncMap
+	resolved map[string]string
+}
+
+func (r *paramResolver) walk(value any, path string) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return r.eval(v, path)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			resolved, err := r.walk(item, path+"."+key)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for key, item := range v {
+			resolved, err := r.eval(item, path+"."+key)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			resolved, err := r.walk(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	case []string:
+		out := make([]string, len(v))
+		for i, item := range v {
+			resolved, err := r.eval(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return value, nil
+	}
+}
+
+func (r *paramResolver) eval(value, path string) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+	tmpl, err := template.New(path).Funcs(r.funcs).Parse(value)
+	if err != nil {
+		return "", NewError(KindValidation, fmt.Sprintf("invalid parameter template at %s", path), err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, nil); err != nil {
+		return "", NewError(KindValidation, fmt.Sprintf("parameter template failed at %s", path), err)
+	}
+	r.resolved[path] = out.String()
+	return out.String(), nil
+}
+
+// paramTime renders as RFC3339 when printed without a date layout.
+type paramTime time.Time
+
+func (t paramTime) String() string {
+	return time.Time(t).Format(time.RFC3339)
+}
+
+func paramFuncs(runTime, now time.Time) template.FuncMap {
+	day := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	week := func(t time.Time) time.Time {
+		// Weeks start on Monday.
+		offset := (int(t.Weekday()) + 6) % 7
+		return day(t).AddDate(0, 0, -offset)
+	}
+	month := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+	year := func(t time.Time) time.Time {
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+	// start returns the period start shifted by offset periods; end returns
+	// the last instant before the following period.
+	start := func(floor func(time.Time) time.Time, years, months, days int) func(...int) paramTime {
+		return func(offset ...int) paramTime {
+			n := sumOffsets(offset)
+			return paramTime(floor(runTime).AddDate(years*n, months*n, days*n))
+		}
+	}
+	end := func(floor func(time.Time) time.Time, years, months, days int) func(...int) paramTime {
+		return func(offset ...int) paramTime {
+			n := sumOffsets(offset) + 1
+			return paramTime(floor(runTime).AddDate(years*n, months*n, days*n).Add(-time.Nanosecond))
+		}
+	}
+
+	return template.FuncMap{
+		"run_time":       func() paramTime { return paramTime(runTime) },
+		"now":            func() paramTime { return paramTime(now) },
+		"start_of_day":   start(day, 0, 0, 1),
+		"end_of_day":     end(day, 0, 0, 1),
+		"start_of_week":  start(week, 0, 0, 7),
+		"end_of_week":    end(week, 0, 0, 7),
+		"start_of_month": start(month, 0, 1, 0),
+		"end_of_month":   end(month, 0, 1, 0),
+		"start_of_year":  start(year, 1, 0, 0),
+		"end_of_year":    end(year, 1, 0, 0),
+		"add_days": func(days int, t paramTime) paramTime {
+			return paramTime(time.Time(t).AddDate(0, 0, days))
+		},
+		"add_months": func(months int, t paramTime) paramTime {
+			return paramTime(time.Time(t).AddDate(0, months, 0))
+		},
+		"date": func(layout string, t paramTime) string {
+			return time.Time(t).Format(layout)
+		},
+	}
+}
+
+func sumOffsets(offsets []int) int {
+	total := 0
+	for _, offset := range offsets {
+		total += offset
+	}
+	return total
+}
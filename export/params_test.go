@@ -0,0 +1,91 @@
+package export
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveParams_RelativeDates(t *testing.T) {
+	req := ExportRequest{
+		Definition: "invoices",
+		Query: map[string]any{
+			"from":   "{{ start_of_month -1 }}",
+			"until":  "{{ end_of_month -1 }}",
+			"status": "paid",
+			"limit":  50,
+			"tags":   []any{"{{ run_time | date \"2006-01\" }}"},
+		},
+		Selection: Selection{Query: SelectionQueryRef{
+			Name:   "by_period",
+			Params: map[string]string{"generated": "{{ now | date \"2006-01-02\" }}"},
+		}},
+		// 03:30 UTC on March 1st is still February 29th in New York.
+		ScheduledAt: time.Date(2024, 3, 1, 3, 30, 0, 0, time.UTC),
+	}
+
+	resolved, err := ResolveParams(req, ParamContext{
+		Now:      time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC),
+		Timezone: "America/New_York",
+	})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	query := resolved.Query.(map[string]any)
+	if got := query["from"]; got != "2024-01-01T00:00:00-05:00" {
+		t.Fatalf("unexpected from %v", got)
+	}
+	if got := query["until"]; got != "2024-01-31T23:59:59-05:00" {
+		t.Fatalf("unexpected until %v", got)
+	}
+	if query["status"] != "paid" || query["limit"] != 50 {
+		t.Fatalf("expected literals untouched, got %v", query)
+	}
+	if got := query["tags"].([]any)[0]; got != "2024-02" {
+		t.Fatalf("unexpected tag %v", got)
+	}
+	if got := resolved.Selection.Query.Params.(map[string]string)["generated"]; got != "2024-03-05" {
+		t.Fatalf("unexpected generated %v", got)
+	}
+
+	want := map[string]string{
+		"query.from":                 "2024-01-01T00:00:00-05:00",
+		"query.until":                "2024-01-31T23:59:59-05:00",
+		"query.tags[0]":              "2024-02",
+		"selection.params.generated": "2024-03-05",
+		"run_time":                   "2024-02-29T22:30:00-05:00",
+	}
+	if len(resolved.Parameters) != len(want) {
+		t.Fatalf("expected %d parameters, got %v", len(want), resolved.Parameters)
+	}
+	for key, value := range want {
+		if resolved.Parameters[key] != value {
+			t.Fatalf("parameter %s: expected %q, got %q", key, value, resolved.Parameters[key])
+		}
+	}
+
+	// The source request is not mutated, so stored schedules keep their templates.
+	if req.Query.(map[string]any)["from"] != "{{ start_of_month -1 }}" {
+		t.Fatalf("expected source query to keep its template")
+	}
+}
+
+func TestResolveParams_NoTemplatesOrInvalid(t *testing.T) {
+	req := ExportRequest{Definition: "users", Query: map[string]any{"status": "active"}}
+	resolved, err := ResolveParams(req, ParamContext{})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if resolved.Parameters != nil {
+		t.Fatalf("expected no parameters, got %v", resolved.Parameters)
+	}
+
+	req.Query = map[string]any{"from": "{{ start_of_decade }}"}
+	if _, err := ResolveParams(req, ParamContext{}); err == nil {
+		t.Fatalf("expected unknown function to fail")
+	}
+	req.Query = map[string]any{"from": "{{ run_time }}"}
+	if _, err := ResolveParams(req, ParamContext{Timezone: "Mars/Olympus"}); err == nil {
+		t.Fatalf("expected invalid timezone to fail")
+	}
+}
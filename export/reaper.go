@@ -0,0 +1,163 @@
+package export
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultStaleThreshold is how long a running export may go without a
+// heartbeat before the reaper treats its worker as gone.
+const DefaultStaleThreshold = 5 * time.Minute
+
+// StaleRequeuer re-enqueues generation for an export whose worker died.
+type StaleRequeuer interface {
+	RequeueExport(ctx context.Context, record ExportRecord) error
+}
+
+// StaleTransitioner is optionally implemented by trackers that can move an
+// export out of running only while its heartbeat still matches the one the
+// reaper saw (a zero heartbeatAt matches records without one). It reports
+// false when the export completed or heartbeated in between.
+type StaleTransitioner interface {
+	TransitionStale(ctx context.Context, id string, heartbeatAt time.Time, to ExportState) (bool, error)
+}
+
+// ReapResult counts what a reaper pass did.
+type ReapResult struct {
+	Failed   int `json:"failed"`
+	Requeued int `json:"requeued"`
+}
+
+// StaleReaper finds running exports without a recent heartbeat. With a
+// Requeuer, exports that kept their request are moved back to queued and
+// re-enqueued; everything else is failed with KindStale and emits
+// export.failed. Exports that change state or heartbeat while the reaper
+// runs are left alone.
+type StaleReaper struct {
+	Tracker   ProgressTracker
+	Emitter   ChangeEmitter
+	Requeuer  StaleRequeuer
+	Threshold time.Duration
+	Logger    Logger
+}
+
+// Reap processes running exports whose last heartbeat is older than
+// Threshold at now. Records without a heartbeat fall back to StartedAt,
+// then CreatedAt.
+func (r *StaleReaper) Reap(ctx context.Context, now time.Time) (ReapResult, error) {
+	if r == nil || r.Tracker == nil {
+		return ReapResult{}, NewError(KindNotImpl, "progress tracker not configured", nil)
+	}
+	if now.IsZero() {
+		now = time.Now()
+	}
+	threshold := r.Threshold
+	if threshold <= 0 {
+		threshold = DefaultStaleThreshold
+	}
+	logger := r.Logger
+	if logger == nil {
+		logger = NopLogger()
+	}
+
+	records, err := r.Tracker.List(ctx, ProgressFilter{State: StateRunning})
+	if err != nil {
+		return ReapResult{}, err
+	}
+
+	result := ReapResult{}
+	for _, record := range records {
+		if record.State != StateRunning {
+			continue
+		}
+		last := lastSeen(record)
+		if now.Sub(last) <= threshold {
+			continue
+		}
+
+		requeue := r.Requeuer != nil && record.Request.Definition != ""
+		to := StateFailed
+		if requeue {
+			to = StateQueued
+		}
+		moved, err := transitionStale(ctx, r.Tracker, record, to)
+		if err != nil {
+			return result, err
+		}
+		if !moved {
+			continue
+		}
+
+		if requeue {
+			err := r.Requeuer.RequeueExport(ctx, record)
+			if err == nil {
+				result.Requeued++
+				r.emit(ctx, record, "export.requeued", now, map[string]any{
+					"heartbeat_at": last,
+				})
+				continue
+			}
+			logger.Warn("stale export requeue failed", "error", err, "export_id", record.ID)
+			// The record is queued but nothing was enqueued, so it is still ours.
+			if err := r.Tracker.Fail(ctx, record.ID, staleError(), map[string]any{"stage": "reaper"}); err != nil {
+				return result, err
+			}
+		}
+
+		result.Failed++
+		r.emit(ctx, record, "export.failed", now, map[string]any{
+			"error":        staleError().Error(),
+			"error_kind":   KindStale,
+			"heartbeat_at": last,
+		})
+	}
+	return result, nil
+}
+
+func staleError() error {
+	return NewError(KindStale, "export heartbeat is stale", nil)
+}
+
+// transitionStale moves a running export to to only if its state and
+// heartbeat still match record. Trackers without StaleTransitioner are
+// re-read first, which narrows but does not close the race.
+func transitionStale(ctx context.Context, tracker ProgressTracker, record ExportRecord, to ExportState) (bool, error) {
+	if transitioner, ok := tracker.(StaleTransitioner); ok {
+		return transitioner.TransitionStale(ctx, record.ID, record.HeartbeatAt, to)
+	}
+	current, err := tracker.Status(ctx, record.ID)
+	if err != nil {
+		return false, err
+	}
+	if !current.HeartbeatAt.Equal(record.HeartbeatAt) {
+		return false, nil
+	}
+	return transitionState(ctx, tracker, record.ID, StateRunning, to)
+}
+
+func (r *StaleReaper) emit(ctx context.Context, record ExportRecord, name string, now time.Time, meta map[string]any) {
+	if r.Emitter == nil {
+		return
+	}
+	_ = r.Emitter.Emit(ctx, ChangeEvent{
+		Name:       name,
+		ExportID:   record.ID,
+		Definition: record.Definition,
+		Format:     record.Format,
+		Delivery:   DeliveryAsync,
+		Actor:      record.RequestedBy,
+		Timestamp:  now,
+		Metadata:   meta,
+	})
+}
+
+func lastSeen(record ExportRecord) time.Time {
+	switch {
+	case !record.HeartbeatAt.IsZero():
+		return record.HeartbeatAt
+	case !record.StartedAt.IsZero():
+		return record.StartedAt
+	default:
+		return record.CreatedAt
+	}
+}
@@ -0,0 +1,163 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubRequeuer struct {
+	requeued []string
+	fail     string
+}
+
+func (s *stubRequeuer) RequeueExport(_ context.Context, record ExportRecord) error {
+	if record.ID == s.fail {
+		return errors.New("queue down")
+	}
+	s.requeued = append(s.requeued, record.ID)
+	return nil
+}
+
+func TestRunner_RecordsHeartbeat(t *testing.T) {
+	tracker := NewMemoryTracker()
+	runner := NewRunner()
+	runner.Tracker = tracker
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	runner.Now = func() time.Time { return now }
+	runner.IDGenerator = func() string { return "exp-hb" }
+	if err := runner.Definitions.Register(ExportDefinition{
+		Name:         "users",
+		RowSourceKey: "stub",
+		Schema:       Schema{Columns: []Column{{Name: "id"}}},
+	}); err != nil {
+		t.Fatalf("register definition: %v", err)
+	}
+	if err := runner.RowSources.Register("stub", func(req ExportRequest, def ResolvedDefinition) (RowSource, error) {
+		return &stubSource{iter: &stubIterator{rows: []Row{{"1"}}}}, nil
+	}); err != nil {
+		t.Fatalf("register source: %v", err)
+	}
+
+	if _, err := runner.Run(context.Background(), ExportRequest{Definition: "users", Format: FormatCSV, Output: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	record, err := tracker.Status(context.Background(), "exp-hb")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !record.HeartbeatAt.Equal(now) {
+		t.Fatalf("expected heartbeat at %s, got %s", now, record.HeartbeatAt)
+	}
+}
+
+func TestStaleReaper_FailsAndRequeues(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tracker := NewMemoryTracker()
+	start := func(record ExportRecord) {
+		t.Helper()
+		record.State = StateRunning
+		record.Definition = "users"
+		record.Format = FormatCSV
+		if _, err := tracker.Start(ctx, record); err != nil {
+			t.Fatalf("start %s: %v", record.ID, err)
+		}
+	}
+	start(ExportRecord{ID: "alive", HeartbeatAt: now.Add(-time.Minute)})
+	start(ExportRecord{ID: "crashed", HeartbeatAt: now.Add(-10 * time.Minute)})
+	start(ExportRecord{ID: "never-beat", StartedAt: now.Add(-time.Hour)})
+	start(ExportRecord{ID: "retry", HeartbeatAt: now.Add(-10 * time.Minute), Request: ExportRequest{Definition: "users"}})
+	start(ExportRecord{ID: "retry-broken", HeartbeatAt: now.Add(-10 * time.Minute), Request: ExportRequest{Definition: "users"}})
+
+	emitter := &recordingEmitter{}
+	requeuer := &stubRequeuer{fail: "retry-broken"}
+	reaper := &StaleReaper{Tracker: tracker, Emitter: emitter, Requeuer: requeuer}
+
+	result, err := reaper.Reap(ctx, now)
+	if err != nil {
+		t.Fatalf("reap: %v", err)
+	}
+	if result.Failed != 3 || result.Requeued != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if len(requeuer.requeued) != 1 || requeuer.requeued[0] != "retry" {
+		t.Fatalf("unexpected requeues %v", requeuer.requeued)
+	}
+
+	want := map[string]ExportState{
+		"alive":        StateRunning,
+		"crashed":      StateFailed,
+		"never-beat":   StateFailed,
+		"retry":        StateQueued,
+		"retry-broken": StateFailed,
+	}
+	for id, state := range want {
+		record, _ := tracker.Status(ctx, id)
+		if record.State != state {
+			t.Fatalf("%s: expected %s, got %s", id, state, record.State)
+		}
+	}
+
+	failed := 0
+	for _, evt := range emitter.events {
+		if evt.Name != "export.failed" {
+			continue
+		}
+		failed++
+		if evt.Metadata["error_kind"] != KindStale {
+			t.Fatalf("expected stale error kind, got %v", evt.Metadata["error_kind"])
+		}
+	}
+	if failed != 3 {
+		t.Fatalf("expected 3 export.failed events, got %d", failed)
+	}
+}
+
+// staleListTracker returns a List snapshot taken before the records moved on.
+type staleListTracker struct {
+	*MemoryTracker
+	snapshot []ExportRecord
+}
+
+func (t staleListTracker) List(ctx context.Context, filter ProgressFilter) ([]ExportRecord, error) {
+	return t.snapshot, nil
+}
+
+func TestStaleReaper_SkipsRecordsThatMovedOn(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tracker := NewMemoryTracker()
+	old := now.Add(-10 * time.Minute)
+	for _, id := range []string{"finished", "revived"} {
+		if _, err := tracker.Start(ctx, ExportRecord{ID: id, State: StateRunning, HeartbeatAt: old}); err != nil {
+			t.Fatalf("start %s: %v", id, err)
+		}
+	}
+	snapshot, _ := tracker.List(ctx, ProgressFilter{State: StateRunning})
+
+	// Between the list and the transition one export completes and the other heartbeats.
+	if err := tracker.Complete(ctx, "finished", nil); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if err := tracker.Heartbeat(ctx, "revived", now); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+
+	reaper := &StaleReaper{Tracker: staleListTracker{MemoryTracker: tracker, snapshot: snapshot}}
+	result, err := reaper.Reap(ctx, now)
+	if err != nil {
+		t.Fatalf("reap: %v", err)
+	}
+	if result.Failed != 0 || result.Requeued != 0 {
+		t.Fatalf("expected nothing reaped, got %+v", result)
+	}
+	if record, _ := tracker.Status(ctx, "finished"); record.State != StateCompleted {
+		t.Fatalf("expected completed export kept, got %s", record.State)
+	}
+	if record, _ := tracker.Status(ctx, "revived"); record.State != StateRunning {
+		t.Fatalf("expected revived export kept running, got %s", record.State)
+	}
+}
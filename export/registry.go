@@ -71,6 +71,9 @@ func (r *DefinitionRegistry) Resolve(req ExportRequest) (ResolvedDefinition, err
 		if variant.Policy != nil {
 			resolved.Policy = mergePolicy(def.Policy, *variant.Policy)
 		}
+		if variant.Approval != nil {
+			resolved.Approval = variant.Approval
+		}
 		if variant.Template != nil {
 			resolved.Template = mergeTemplateOptions(resolved.Template, *variant.Template)
 		}
@@ -67,6 +67,17 @@ func (r CSVRenderer) Render(ctx context.Context, schema Schema, rows RowIterator
 		stats.Rows++
 	}
 
+	if opts.Watermark.Active() && opts.Watermark.CSVCanaryRows && len(schema.Columns) > 0 {
+		canary := make([]string, len(schema.Columns))
+		canary[0] = opts.Watermark.Stamp.Token
+		if len(canary) > 1 {
+			canary[1] = opts.Watermark.Stamp.String()
+		}
+		if err := writer.Write(canary); err != nil {
+			return stats, err
+		}
+	}
+
 	writer.Flush()
 	if err := writer.Error(); err != nil {
 		return stats, err
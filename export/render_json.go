@@ -26,6 +26,11 @@ func (r JSONRenderer) Render(ctx context.Context, schema Schema, rows RowIterato
 
 	if mode == JSONModeLines {
 		encoder := json.NewEncoder(cw)
+		if opts.Watermark.Active() {
+			if err := encoder.Encode(map[string]any{"_watermark": opts.Watermark.Stamp}); err != nil {
+				return stats, err
+			}
+		}
 		for {
 			if err := ctx.Err(); err != nil {
 				return stats, err
@@ -59,6 +64,23 @@ func (r JSONRenderer) Render(ctx context.Context, schema Schema, rows RowIterato
 		return stats, nil
 	}
 
+	if opts.Watermark.Active() {
+		// Watermarked arrays are wrapped in an envelope: {"watermark":{...},"data":[...]}.
+		header, err := json.Marshal(opts.Watermark.Stamp)
+		if err != nil {
+			return stats, err
+		}
+		if _, err := cw.Write([]byte(`{"watermark":`)); err != nil {
+			return stats, err
+		}
+		if _, err := cw.Write(header); err != nil {
+			return stats, err
+		}
+		if _, err := cw.Write([]byte(`,"data":`)); err != nil {
+			return stats, err
+		}
+	}
+
 	if _, err := cw.Write([]byte("[")); err != nil {
 		return stats, err
 	}
@@ -106,6 +128,11 @@ func (r JSONRenderer) Render(ctx context.Context, schema Schema, rows RowIterato
 	if _, err := cw.Write([]byte("]")); err != nil {
 		return stats, err
 	}
+	if opts.Watermark.Active() {
+		if _, err := cw.Write([]byte("}")); err != nil {
+			return stats, err
+		}
+	}
 
 	stats.Bytes = cw.count
 	return stats, nil
@@ -123,6 +123,11 @@ func (r XLSXRenderer) Render(ctx context.Context, schema Schema, rows RowIterato
 	if err := stream.Flush(); err != nil {
 		return stats, err
 	}
+	if opts.Watermark.Active() {
+		if err := applyXLSXWatermark(file, opts.Watermark.Stamp); err != nil {
+			return stats, err
+		}
+	}
 
 	lw := newLimitedWriter(w, opts.XLSX.MaxBytes)
 	if _, err := file.WriteTo(lw); err != nil {
@@ -132,6 +137,33 @@ func (r XLSXRenderer) Render(ctx context.Context, schema Schema, rows RowIterato
 	return stats, nil
 }
 
+const watermarkSheetName = "_watermark"
+
+// applyXLSXWatermark stamps document properties and a very hidden sheet.
+func applyXLSXWatermark(file *excelize.File, watermark Watermark) error {
+	if err := file.SetDocProps(&excelize.DocProperties{
+		Creator:     watermark.ActorID,
+		Identifier:  watermark.Token,
+		Description: watermark.String(),
+		Keywords:    "export:" + watermark.ExportID,
+	}); err != nil {
+		return err
+	}
+	if _, err := file.NewSheet(watermarkSheetName); err != nil {
+		return err
+	}
+	for idx, field := range watermark.Fields() {
+		row := idx + 1
+		if err := file.SetCellValue(watermarkSheetName, fmt.Sprintf("A%d", row), field[0]); err != nil {
+			return err
+		}
+		if err := file.SetCellValue(watermarkSheetName, fmt.Sprintf("B%d", row), field[1]); err != nil {
+			return err
+		}
+	}
+	return file.SetSheetVisible(watermarkSheetName, false, true)
+}
+
 type xlsxStyles struct {
 	headerID  int
 	dateID    int
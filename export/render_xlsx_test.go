@@ -62,6 +62,25 @@ func TestXLSXRenderer_WritesRows(t *testing.T) {
 	}
 }
 
+// Change-aware delivery compares artifact checksums, so unwatermarked
+// workbooks must not embed render-time timestamps.
+func TestXLSXRenderer_IsDeterministic(t *testing.T) {
+	render := func() []byte {
+		buf := &bytes.Buffer{}
+		iter := &stubIterator{rows: []Row{{int64(1), "alice"}}}
+		schema := Schema{Columns: []Column{{Name: "id", Type: "int"}, {Name: "name", Type: "string"}}}
+		if _, err := (XLSXRenderer{}).Render(context.Background(), schema, iter, buf, RenderOptions{}); err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		return buf.Bytes()
+	}
+	first := render()
+	time.Sleep(1100 * time.Millisecond)
+	if !bytes.Equal(first, render()) {
+		t.Fatalf("expected identical workbooks for identical rows")
+	}
+}
+
 func TestXLSXRenderer_MaxRows(t *testing.T) {
 	buf := &bytes.Buffer{}
 	iter := &stubIterator{rows: []Row{{"a"}, {"b"}}}
@@ -27,6 +27,16 @@ type Runner struct {
 	DeliveryPolicy DeliveryPolicy
 	Now            func() time.Time
 	IDGenerator    func() string
+	// HeartbeatInterval sets how often running exports record a heartbeat;
+	// zero uses DefaultHeartbeatInterval and a negative value disables it.
+	HeartbeatInterval time.Duration
+	// CancelPollInterval sets how often running exports check the tracker
+	// for a cancellation; zero uses DefaultCancelPollInterval and a negative
+	// value disables polling.
+	CancelPollInterval time.Duration
+
+	// approvalGranted is set by the service once an approval gate has passed.
+	approvalGranted bool
 }
 
 // NewRunner creates a runner with default registries.
@@ -71,7 +81,15 @@ func (r *Runner) Run(ctx context.Context, req ExportRequest) (ExportResult, erro
 		return ExportResult{}, AsGoError(err)
 	}
 
-	resolved, err := ResolveExport(req, def, r.Now())
+	actor := Actor{}
+	if r.ActorProvider != nil {
+		actor, err = r.ActorProvider.FromContext(ctx)
+		if err != nil {
+			return ExportResult{}, AsGoError(NewError(KindAuthz, "failed to resolve actor", err))
+		}
+	}
+
+	resolved, err := ResolveExportForActor(req, def, actor, r.Now())
 	if err != nil {
 		return ExportResult{}, AsGoError(err)
 	}
@@ -85,18 +103,23 @@ func (r *Runner) Run(ctx context.Context, req ExportRequest) (ExportResult, erro
 		return ExportResult{}, AsGoError(NewError(KindNotImpl, "async delivery not supported", nil))
 	}
 
-	actor := Actor{}
-	if r.ActorProvider != nil {
-		actor, err = r.ActorProvider.FromContext(ctx)
-		if err != nil {
-			return ExportResult{}, AsGoError(NewError(KindAuthz, "failed to resolve actor", err))
-		}
-	}
-
 	if r.Guard != nil {
 		if err := r.Guard.AuthorizeExport(ctx, actor, resolved.Request, resolved.Definition); err != nil {
 			return ExportResult{}, AsGoError(NewError(KindAuthz, "export not authorized", err))
 		}
+		if limiter, ok := r.Guard.(RowLimitGuard); ok {
+			limit := limiter.RowLimit(ctx, actor, resolved.Request, resolved.Definition)
+			resolved.Definition.Policy.MaxRows = capRows(resolved.Definition.Policy.MaxRows, limit)
+		}
+	}
+
+	if reasons := ApprovalReasons(resolved); len(reasons) > 0 && !r.approvalGranted {
+		return ExportResult{}, AsGoError(NewError(KindValidation, "export requires approval", nil).WithMetadata(map[string]any{
+			"reasons": reasons,
+		}))
+	}
+	if !r.approvalGranted {
+		resolved.Definition.Policy.MaxRows = approvalRowLimit(resolved.Definition.Approval, resolved.Definition.Policy.MaxRows)
 	}
 
 	runReq := resolved.Request
@@ -130,6 +153,8 @@ func (r *Runner) Run(ctx context.Context, req ExportRequest) (ExportResult, erro
 			State:       StateQueued,
 			RequestedBy: actor,
 			Scope:       actor.Scope,
+			Access:      resolved.Access,
+			Parameters:  runReq.Parameters,
 			CreatedAt:   r.Now(),
 		}
 		if isTemplateFormat(runReq.Format) {
@@ -143,6 +168,11 @@ func (r *Runner) Run(ctx context.Context, req ExportRequest) (ExportResult, erro
 			exportID = id
 		}
 		_ = r.Tracker.SetState(ctx, exportID, StateRunning, nil)
+		var stopWatch context.CancelCauseFunc
+		ctx, stopWatch = r.watchCancel(ctx, exportID)
+		defer stopWatch(nil)
+		stopHeartbeat := r.startHeartbeat(ctx, exportID, stopWatch)
+		defer stopHeartbeat()
 	}
 
 	runInfo := buildRunInfo(exportID, resolved, actor, delivery, r.Now)
@@ -150,17 +180,32 @@ func (r *Runner) Run(ctx context.Context, req ExportRequest) (ExportResult, erro
 	r.emitMetrics(ctx, runInfo, "export.requested", RenderStats{}, nil)
 	r.emit(ctx, runInfo, "export.started", nil)
 
+	if runReq.RenderOptions.Watermark.Enabled || resolved.Definition.Policy.Watermark {
+		watermark, err := newWatermark(exportID, actor, resolved.Definition.Name, r.Now())
+		if err != nil {
+			return ExportResult{}, AsGoError(r.fail(ctx, runInfo, err))
+		}
+		runReq.RenderOptions.Watermark.Enabled = true
+		runReq.RenderOptions.Watermark.Stamp = watermark
+		// A watermark that cannot be looked up later defeats leak tracing,
+		// so only trackers without watermark support may skip recording it.
+		if err := recordWatermark(ctx, r.Tracker, exportID, watermark); err != nil {
+			if KindFromError(err) != KindNotImpl {
+				return ExportResult{}, AsGoError(r.fail(ctx, runInfo, NewError(KindExternal, "watermark tracking failed", err)))
+			}
+			r.Logger.Warn("watermark tracking not supported", "error", err, "export_id", exportID)
+		}
+	}
+
 	factory, ok := r.RowSources.Resolve(resolved.Definition.RowSourceKey)
 	if !ok {
 		err := NewError(KindNotFound, fmt.Sprintf("row source %q not registered", resolved.Definition.RowSourceKey), nil)
-		r.fail(ctx, runInfo, err)
-		return ExportResult{}, AsGoError(err)
+		return ExportResult{}, AsGoError(r.fail(ctx, runInfo, err))
 	}
 
 	source, err := factory(runReq, resolved.Definition)
 	if err != nil {
-		r.fail(ctx, runInfo, err)
-		return ExportResult{}, AsGoError(err)
+		return ExportResult{}, AsGoError(r.fail(ctx, runInfo, err))
 	}
 
 	iterator, err := source.Open(ctx, RowSourceSpec{
@@ -170,8 +215,7 @@ func (r *Runner) Run(ctx context.Context, req ExportRequest) (ExportResult, erro
 		Actor:      actor,
 	})
 	if err != nil {
-		r.fail(ctx, runInfo, err)
-		return ExportResult{}, AsGoError(err)
+		return ExportResult{}, AsGoError(r.fail(ctx, runInfo, err))
 	}
 
 	rows := iterator
@@ -179,31 +223,29 @@ func (r *Runner) Run(ctx context.Context, req ExportRequest) (ExportResult, erro
 	transformers, err := r.resolveTransformers(resolved.Definition)
 	if err != nil {
 		_ = iterator.Close()
-		r.fail(ctx, runInfo, err)
-		return ExportResult{}, AsGoError(err)
+		return ExportResult{}, AsGoError(r.fail(ctx, runInfo, err))
 	}
 	rows, schema, err = applyTransformers(ctx, rows, schema, transformers)
 	if err != nil {
 		_ = iterator.Close()
-		r.fail(ctx, runInfo, err)
-		return ExportResult{}, AsGoError(err)
+		return ExportResult{}, AsGoError(r.fail(ctx, runInfo, err))
 	}
 	defer rows.Close()
 
 	redactions := resolveRedactions(schema.Columns, resolved.Definition.Policy)
-	tracked := newTrackingIterator(rows, r.Tracker, exportID, redactions, resolved.Definition.Policy.MaxRows)
+	masks := resolveMasks(schema.Columns, resolved.Definition.Policy)
+	schema = maskedSchema(schema, masks)
+	tracked := newTrackingIterator(rows, r.Tracker, exportID, redactions, masks, resolved.Definition.Policy.MaxRows)
 
 	renderer, ok := r.Renderers.Resolve(runReq.Format)
 	if !ok {
 		err := NewError(KindNotFound, fmt.Sprintf("renderer %q not registered", runReq.Format), nil)
-		r.fail(ctx, runInfo, err)
-		return ExportResult{}, AsGoError(err)
+		return ExportResult{}, AsGoError(r.fail(ctx, runInfo, err))
 	}
 
 	stats, err := renderer.Render(ctx, schema, tracked, runReq.Output, runReq.RenderOptions)
 	if err != nil {
-		r.fail(ctx, runInfo, err)
-		return ExportResult{}, AsGoError(err)
+		return ExportResult{}, AsGoError(r.fail(ctx, runInfo, err))
 	}
 
 	result := ExportResult{
@@ -216,12 +258,20 @@ func (r *Runner) Run(ctx context.Context, req ExportRequest) (ExportResult, erro
 	}
 
 	if r.Tracker != nil {
+		if cause := context.Cause(ctx); errors.Is(cause, errRunSuperseded) {
+			return ExportResult{}, AsGoError(r.fail(ctx, runInfo, cause))
+		}
 		_ = r.Tracker.Complete(ctx, exportID, map[string]any{
 			"rows":  stats.Rows,
 			"bytes": stats.Bytes,
 		})
 	}
 
+	if runReq.RenderOptions.Watermark.Active() {
+		watermark := runReq.RenderOptions.Watermark.Stamp
+		result.Watermark = &watermark
+	}
+
 	r.emit(ctx, runInfo, "export.completed", map[string]any{
 		"rows":     stats.Rows,
 		"bytes":    stats.Bytes,
@@ -232,9 +282,16 @@ func (r *Runner) Run(ctx context.Context, req ExportRequest) (ExportResult, erro
 	return result, nil
 }
 
-func (r *Runner) fail(ctx context.Context, runInfo runInfo, err error) {
+// fail records err on the export and returns the error to report. A run
+// superseded by another generation leaves the record untouched and reports
+// errRunSuperseded instead.
+func (r *Runner) fail(ctx context.Context, runInfo runInfo, err error) error {
 	if runInfo.exportID == "" {
-		return
+		return err
+	}
+	if cause := context.Cause(ctx); errors.Is(cause, errRunSuperseded) {
+		r.Logger.Warn("export run superseded, leaving record untouched", "error", err, "export_id", runInfo.exportID)
+		return cause
 	}
 
 	if errors.Is(err, context.Canceled) {
@@ -245,7 +302,7 @@ func (r *Runner) fail(ctx context.Context, runInfo runInfo, err error) {
 			"duration": r.Now().Sub(runInfo.startedAt),
 		})
 		r.emitMetrics(ctx, runInfo, "export.canceled", RenderStats{}, err)
-		return
+		return err
 	}
 
 	if r.Tracker != nil {
@@ -257,6 +314,7 @@ func (r *Runner) fail(ctx context.Context, runInfo runInfo, err error) {
 		"duration":   r.Now().Sub(runInfo.startedAt),
 	})
 	r.emitMetrics(ctx, runInfo, "export.failed", RenderStats{}, err)
+	return err
 }
 
 func (r *Runner) emit(ctx context.Context, runInfo runInfo, name string, meta map[string]any) {
@@ -379,16 +437,26 @@ type trackingIterator struct {
 	tracker     ProgressTracker
 	exportID    string
 	redactions  map[int]any
+	masks       map[int]columnMasker
 	maxRows     int
 	currentRows int64
 }
 
-func newTrackingIterator(base RowIterator, tracker ProgressTracker, exportID string, redactions map[int]any, maxRows int) *trackingIterator {
+// capRows returns the stricter of two row limits, where 0 means no limit.
+func capRows(maxRows, limit int) int {
+	if limit > 0 && (maxRows <= 0 || limit < maxRows) {
+		return limit
+	}
+	return maxRows
+}
+
+func newTrackingIterator(base RowIterator, tracker ProgressTracker, exportID string, redactions map[int]any, masks map[int]columnMasker, maxRows int) *trackingIterator {
 	return &trackingIterator{
 		base:       base,
 		tracker:    tracker,
 		exportID:   exportID,
 		redactions: redactions,
+		masks:      masks,
 		maxRows:    maxRows,
 	}
 }
@@ -407,7 +475,7 @@ func (it *trackingIterator) Next(ctx context.Context) (Row, error) {
 		return nil, NewError(KindValidation, "max rows exceeded", nil)
 	}
 
-	if len(it.redactions) > 0 {
+	if len(it.redactions) > 0 || len(it.masks) > 0 {
 		copyRow := make(Row, len(row))
 		copy(copyRow, row)
 		row = copyRow
@@ -416,6 +484,16 @@ func (it *trackingIterator) Next(ctx context.Context) (Row, error) {
 				row[idx] = value
 			}
 		}
+		for idx, masker := range it.masks {
+			if idx < 0 || idx >= len(row) {
+				continue
+			}
+			masked, err := masker.apply(ctx, row[idx])
+			if err != nil {
+				return nil, err
+			}
+			row[idx] = masked
+		}
 	}
 
 	if it.tracker != nil {
@@ -124,6 +124,42 @@ func TestRunner_GuardFirst(t *testing.T) {
 	}
 }
 
+type rowLimitGuard struct {
+	stubGuard
+	limit int
+}
+
+func (g *rowLimitGuard) RowLimit(ctx context.Context, actor Actor, req ExportRequest, def ResolvedDefinition) int {
+	return g.limit
+}
+
+func TestRunner_GuardRowLimit(t *testing.T) {
+	runner := NewRunner()
+	runner.Guard = &rowLimitGuard{limit: 2}
+	if err := runner.Definitions.Register(ExportDefinition{
+		Name:         "users",
+		RowSourceKey: "stub",
+		Schema:       Schema{Columns: []Column{{Name: "id"}}},
+	}); err != nil {
+		t.Fatalf("register definition: %v", err)
+	}
+	if err := runner.RowSources.Register("stub", func(req ExportRequest, def ResolvedDefinition) (RowSource, error) {
+		return &stubSource{iter: &stubIterator{rows: []Row{{"1"}, {"2"}, {"3"}}}}, nil
+	}); err != nil {
+		t.Fatalf("register source: %v", err)
+	}
+
+	// The request carries no estimate, so only the runtime cap can stop it.
+	_, err := runner.Run(context.Background(), ExportRequest{
+		Definition: "users",
+		Format:     FormatCSV,
+		Output:     &bytes.Buffer{},
+	})
+	if err == nil {
+		t.Fatalf("expected guard row limit to stop the export")
+	}
+}
+
 func TestRunner_GuardBlocksOpen(t *testing.T) {
 	order := []string{}
 	source := &stubSource{order: &order, iter: &stubIterator{rows: []Row{{"1"}}}}
@@ -0,0 +1,599 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ScheduleKind identifies what a schedule runs.
+type ScheduleKind string
+
+const (
+	ScheduleKindExport   ScheduleKind = "export"
+	ScheduleKindDelivery ScheduleKind = "delivery"
+)
+
+// CatchUpPolicy decides what happens to slots that passed while no evaluator
+// was running.
+type CatchUpPolicy string
+
+const (
+	// CatchUpOnce runs the most recent missed slot once (default).
+	CatchUpOnce CatchUpPolicy = "once"
+	// CatchUpAll runs every missed slot, oldest first.
+	CatchUpAll CatchUpPolicy = "all"
+	// CatchUpSkip drops slots older than the service misfire threshold.
+	CatchUpSkip CatchUpPolicy = "skip"
+)
+
+// CalendarPolicy decides what happens to slots that fall on non-business
+// days of the schedule's calendar.
+type CalendarPolicy string
+
+const (
+	// CalendarSkip drops the slot (default).
+	CalendarSkip CalendarPolicy = "skip"
+	// CalendarNextBusinessDay moves the slot to the same time on the next
+	// business day; slots that land on the same time run once.
+	CalendarNextBusinessDay CalendarPolicy = "next_business_day"
+)
+
+// maxCalendarLookahead bounds the slots examined when a calendar rejects
+// every candidate.
+const maxCalendarLookahead = 1000
+
+// Schedule runs an export or delivery request on a cron expression. Cron
+// accepts the standard five fields or descriptors such as "@daily" and is
+// evaluated in Timezone (default UTC). Export schedules use Export as the
+// request template; delivery schedules carry an encoded delivery request
+// (see exportdelivery.Request) in Delivery. Runs execute as Owner.
+// Calendar names a business calendar from the service CalendarSource.
+type Schedule struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name,omitempty"`
+	Kind           ScheduleKind    `json:"kind"`
+	Cron           string          `json:"cron"`
+	Timezone       string          `json:"timezone,omitempty"`
+	Owner          Actor           `json:"owner"`
+	Enabled        bool            `json:"enabled"`
+	CatchUp        CatchUpPolicy   `json:"catch_up,omitempty"`
+	Calendar       string          `json:"calendar,omitempty"`
+	CalendarPolicy CalendarPolicy  `json:"calendar_policy,omitempty"`
+	Export         ExportRequest   `json:"export,omitempty"`
+	Delivery       json.RawMessage `json:"delivery,omitempty"`
+	LastRunAt      time.Time       `json:"last_run_at,omitempty"`
+	NextRunAt      time.Time       `json:"next_run_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// ScheduleRun is one claimed run. RunAt is the logical slot time, which can
+// be in the past when missed slots are caught up.
+type ScheduleRun struct {
+	Schedule Schedule
+	RunAt    time.Time
+}
+
+// NextRun returns the first run time strictly after after, in the
+// schedule's timezone, ignoring business calendars.
+func (s Schedule) NextRun(after time.Time) (time.Time, error) {
+	return s.NextBusinessRun(after, nil)
+}
+
+// NextBusinessRun returns the first run time strictly after after, applying
+// cal with the schedule's CalendarPolicy. A nil cal allows every day.
+func (s Schedule) NextBusinessRun(after time.Time, cal *BusinessCalendar) (time.Time, error) {
+	clock, err := newScheduleClock(s, cal)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return clock.next(after)
+}
+
+// scheduleClock evaluates a parsed schedule so catch-up can walk many slots
+// without reparsing the cron expression.
+type scheduleClock struct {
+	spec   cron.Schedule
+	loc    *time.Location
+	cal    *BusinessCalendar
+	policy CalendarPolicy
+}
+
+func newScheduleClock(s Schedule, cal *BusinessCalendar) (scheduleClock, error) {
+	spec, loc, err := parseScheduleSpec(s.Cron, s.Timezone)
+	if err != nil {
+		return scheduleClock{}, err
+	}
+	return scheduleClock{spec: spec, loc: loc, cal: cal, policy: s.CalendarPolicy}, nil
+}
+
+func (c scheduleClock) next(after time.Time) (time.Time, error) {
+	slot := after.In(c.loc)
+	for range maxCalendarLookahead {
+		slot = c.spec.Next(slot)
+		if slot.IsZero() {
+			return time.Time{}, NewError(KindValidation, "schedule cron never fires", nil)
+		}
+		if c.cal == nil || c.cal.IsBusinessDay(slot) {
+			return slot, nil
+		}
+		if c.policy == CalendarNextBusinessDay {
+			if shifted, ok := nextBusinessDay(slot, *c.cal); ok {
+				return shifted, nil
+			}
+		}
+	}
+	return time.Time{}, NewError(KindValidation, "schedule has no run on a business day", nil)
+}
+
+// nextBusinessDay keeps the wall-clock time of slot and moves it forward one
+// day at a time until the calendar allows it.
+func nextBusinessDay(slot time.Time, cal BusinessCalendar) (time.Time, bool) {
+	for day := 1; day <= 366; day++ {
+		candidate := time.Date(slot.Year(), slot.Month(), slot.Day()+day, slot.Hour(), slot.Minute(), slot.Second(), 0, slot.Location())
+		if cal.IsBusinessDay(candidate) {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ValidateSchedule checks a schedule before it is stored.
+func ValidateSchedule(s Schedule) error {
+	switch s.Kind {
+	case ScheduleKindExport:
+		if s.Export.Definition == "" {
+			return NewError(KindValidation, "export schedule definition is required", nil)
+		}
+	case ScheduleKindDelivery:
+		if len(s.Delivery) == 0 || !json.Valid(s.Delivery) {
+			return NewError(KindValidation, "delivery schedule request is required", nil)
+		}
+	default:
+		return NewError(KindValidation, fmt.Sprintf("schedule kind %q is not supported", s.Kind), nil)
+	}
+	if s.Owner.ID == "" {
+		return NewError(KindValidation, "schedule owner is required", nil)
+	}
+	switch s.CatchUp {
+	case "", CatchUpOnce, CatchUpAll, CatchUpSkip:
+	default:
+		return NewError(KindValidation, fmt.Sprintf("schedule catch-up policy %q is not supported", s.CatchUp), nil)
+	}
+	switch s.CalendarPolicy {
+	case "", CalendarSkip, CalendarNextBusinessDay:
+	default:
+		return NewError(KindValidation, fmt.Sprintf("schedule calendar policy %q is not supported", s.CalendarPolicy), nil)
+	}
+	_, _, err := parseScheduleSpec(s.Cron, s.Timezone)
+	return err
+}
+
+func parseScheduleSpec(expr, timezone string) (cron.Schedule, *time.Location, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, nil, NewError(KindValidation, "schedule cron is required", nil)
+	}
+	loc := time.UTC
+	if timezone = strings.TrimSpace(timezone); timezone != "" {
+		parsed, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, nil, NewError(KindValidation, fmt.Sprintf("schedule timezone %q is invalid", timezone), err)
+		}
+		loc = parsed
+	}
+	spec, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, nil, NewError(KindValidation, fmt.Sprintf("schedule cron %q is invalid", expr), err)
+	}
+	return spec, loc, nil
+}
+
+// ScheduleFilter narrows schedule listings.
+type ScheduleFilter struct {
+	Kind    ScheduleKind `json:"kind,omitempty"`
+	OwnerID string       `json:"owner_id,omitempty"`
+	Scope   Scope        `json:"scope,omitempty"`
+	Enabled *bool        `json:"enabled,omitempty"`
+	Limit   int          `json:"limit,omitempty"`
+}
+
+// Matches reports whether s satisfies the filter. Limit is ignored.
+func (f ScheduleFilter) Matches(s Schedule) bool {
+	if f.Kind != "" && f.Kind != s.Kind {
+		return false
+	}
+	if f.OwnerID != "" && f.OwnerID != s.Owner.ID {
+		return false
+	}
+	if f.Scope.TenantID != "" && f.Scope.TenantID != s.Owner.Scope.TenantID {
+		return false
+	}
+	if f.Scope.WorkspaceID != "" && f.Scope.WorkspaceID != s.Owner.Scope.WorkspaceID {
+		return false
+	}
+	if f.Enabled != nil && *f.Enabled != s.Enabled {
+		return false
+	}
+	return true
+}
+
+// ScheduleStore persists schedules.
+type ScheduleStore interface {
+	CreateSchedule(ctx context.Context, schedule Schedule) error
+	UpdateSchedule(ctx context.Context, schedule Schedule) error
+	DeleteSchedule(ctx context.Context, id string) error
+	GetSchedule(ctx context.Context, id string) (Schedule, error)
+	// ListSchedules returns matching schedules ordered by creation time.
+	ListSchedules(ctx context.Context, filter ScheduleFilter) ([]Schedule, error)
+	// DueSchedules returns enabled schedules of kind with NextRunAt at or
+	// before now, oldest first.
+	DueSchedules(ctx context.Context, kind ScheduleKind, now time.Time) ([]Schedule, error)
+	// MarkScheduleRun records a run and moves NextRunAt to next, but only if
+	// NextRunAt still equals expected. It reports whether the caller won the
+	// run, so concurrent evaluators never fire a schedule twice.
+	MarkScheduleRun(ctx context.Context, id string, expected, ranAt, next time.Time) (bool, error)
+}
+
+// ScheduleManager manages schedules on behalf of actors; ScheduleService
+// implements it.
+type ScheduleManager interface {
+	CreateSchedule(ctx context.Context, actor Actor, schedule Schedule) (Schedule, error)
+	UpdateSchedule(ctx context.Context, actor Actor, schedule Schedule) (Schedule, error)
+	DeleteSchedule(ctx context.Context, actor Actor, id string) error
+	GetSchedule(ctx context.Context, actor Actor, id string) (Schedule, error)
+	ListSchedules(ctx context.Context, actor Actor, filter ScheduleFilter) ([]Schedule, error)
+}
+
+// ScheduleValidator checks the request a schedule carries before it is
+// stored on behalf of actor. Delivery schedules use one to decode and
+// validate their encoded request (see exportdelivery.Service.ValidateSchedule).
+type ScheduleValidator func(ctx context.Context, actor Actor, schedule Schedule) error
+
+// ScheduleClaimer claims due schedule runs; ScheduleService implements it.
+type ScheduleClaimer interface {
+	ClaimDue(ctx context.Context, kind ScheduleKind, now time.Time) ([]ScheduleRun, error)
+}
+
+// Catch-up defaults.
+const (
+	DefaultMisfireThreshold = 5 * time.Minute
+	DefaultMaxCatchUp       = 100
+)
+
+// ScheduleServiceConfig configures a ScheduleService. MisfireThreshold is how
+// late a slot may run under CatchUpSkip; MaxCatchUp caps the runs one
+// schedule yields per claim under CatchUpAll, keeping the most recent.
+// Delivery schedules are rejected unless DeliveryValidator is set.
+type ScheduleServiceConfig struct {
+	Store             ScheduleStore
+	Guard             Guard
+	Calendars         CalendarSource
+	DeliveryValidator ScheduleValidator
+	MisfireThreshold  time.Duration
+	MaxCatchUp        int
+	Now               func() time.Time
+	IDGenerator       func() string
+}
+
+// ScheduleService manages schedules on behalf of actors and claims due runs.
+// Actors only see schedules they own; a Guard implementing AdminGuard may
+// grant access to every schedule within the actor's scope.
+type ScheduleService struct {
+	store             ScheduleStore
+	guard             Guard
+	calendars         CalendarSource
+	deliveryValidator ScheduleValidator
+	misfireThreshold  time.Duration
+	maxCatchUp        int
+	now               func() time.Time
+	idGenerator       func() string
+}
+
+// NewScheduleService creates a schedule service.
+func NewScheduleService(cfg ScheduleServiceConfig) *ScheduleService {
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+	idGenerator := cfg.IDGenerator
+	if idGenerator == nil {
+		idGenerator = defaultScheduleIDGenerator()
+	}
+	misfireThreshold := cfg.MisfireThreshold
+	if misfireThreshold <= 0 {
+		misfireThreshold = DefaultMisfireThreshold
+	}
+	maxCatchUp := cfg.MaxCatchUp
+	if maxCatchUp <= 0 {
+		maxCatchUp = DefaultMaxCatchUp
+	}
+	return &ScheduleService{
+		store:             cfg.Store,
+		guard:             cfg.Guard,
+		calendars:         cfg.Calendars,
+		deliveryValidator: cfg.DeliveryValidator,
+		misfireThreshold:  misfireThreshold,
+		maxCatchUp:        maxCatchUp,
+		now:               now,
+		idGenerator:       idGenerator,
+	}
+}
+
+// CreateSchedule stores a new schedule owned by actor.
+func (s *ScheduleService) CreateSchedule(ctx context.Context, actor Actor, schedule Schedule) (Schedule, error) {
+	if err := s.ready(); err != nil {
+		return Schedule{}, AsGoError(err)
+	}
+	now := s.now()
+	schedule.ID = s.idGenerator()
+	schedule.Owner = actor
+	schedule.LastRunAt = time.Time{}
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+	if err := s.prepare(ctx, actor, &schedule, now); err != nil {
+		return Schedule{}, AsGoError(err)
+	}
+	if err := s.store.CreateSchedule(ctx, schedule); err != nil {
+		return Schedule{}, AsGoError(err)
+	}
+	return schedule, nil
+}
+
+// UpdateSchedule replaces the editable fields of an existing schedule. Owner,
+// creation time and run history are kept; the next run is recomputed.
+func (s *ScheduleService) UpdateSchedule(ctx context.Context, actor Actor, schedule Schedule) (Schedule, error) {
+	current, err := s.GetSchedule(ctx, actor, schedule.ID)
+	if err != nil {
+		return Schedule{}, err
+	}
+	now := s.now()
+	schedule.Owner = current.Owner
+	schedule.LastRunAt = current.LastRunAt
+	schedule.CreatedAt = current.CreatedAt
+	schedule.UpdatedAt = now
+	if err := s.prepare(ctx, actor, &schedule, now); err != nil {
+		return Schedule{}, AsGoError(err)
+	}
+	if err := s.store.UpdateSchedule(ctx, schedule); err != nil {
+		return Schedule{}, AsGoError(err)
+	}
+	return schedule, nil
+}
+
+// DeleteSchedule removes a schedule.
+func (s *ScheduleService) DeleteSchedule(ctx context.Context, actor Actor, id string) error {
+	if _, err := s.GetSchedule(ctx, actor, id); err != nil {
+		return err
+	}
+	if err := s.store.DeleteSchedule(ctx, id); err != nil {
+		return AsGoError(err)
+	}
+	return nil
+}
+
+// GetSchedule returns a schedule visible to actor.
+func (s *ScheduleService) GetSchedule(ctx context.Context, actor Actor, id string) (Schedule, error) {
+	if err := s.ready(); err != nil {
+		return Schedule{}, AsGoError(err)
+	}
+	if id == "" {
+		return Schedule{}, AsGoError(NewError(KindValidation, "schedule ID is required", nil))
+	}
+	schedule, err := s.store.GetSchedule(ctx, id)
+	if err != nil {
+		return Schedule{}, AsGoError(err)
+	}
+	if !s.canAccess(ctx, actor, schedule.Owner) {
+		return Schedule{}, AsGoError(NewError(KindNotFound, "schedule not found", nil))
+	}
+	return schedule, nil
+}
+
+// ListSchedules returns schedules visible to actor.
+func (s *ScheduleService) ListSchedules(ctx context.Context, actor Actor, filter ScheduleFilter) ([]Schedule, error) {
+	if err := s.ready(); err != nil {
+		return nil, AsGoError(err)
+	}
+	filter.Scope = actor.Scope
+	if !s.isAdmin(ctx, actor) {
+		if actor.ID == "" {
+			return nil, AsGoError(NewError(KindAuthz, "actor ID is required", nil))
+		}
+		filter.OwnerID = actor.ID
+	}
+	schedules, err := s.store.ListSchedules(ctx, filter)
+	if err != nil {
+		return nil, AsGoError(err)
+	}
+	return schedules, nil
+}
+
+// canAccess reports whether actor may read or change a schedule owned by owner.
+func (s *ScheduleService) canAccess(ctx context.Context, actor Actor, owner Actor) bool {
+	if !scopeMatches(actor.Scope, owner.Scope) {
+		return false
+	}
+	if actor.ID != "" && actor.ID == owner.ID {
+		return true
+	}
+	return s.isAdmin(ctx, actor)
+}
+
+func (s *ScheduleService) isAdmin(ctx context.Context, actor Actor) bool {
+	if s.guard == nil {
+		return false
+	}
+	return authorizeAdmin(ctx, s.guard, actor, AdminSchedules) == nil
+}
+
+// ClaimDue returns the runs of kind due at now and advances each schedule
+// past now before the runs execute, so runs are at-most-once. Schedules
+// claimed by a concurrent evaluator are skipped. Slots missed while no
+// evaluator ran are handled by the schedule's CatchUp policy; each run
+// carries its slot time. A zero now uses the service clock. A schedule that
+// cannot be evaluated is left unclaimed and reported in the error, which is
+// returned alongside the runs claimed from the other schedules.
+func (s *ScheduleService) ClaimDue(ctx context.Context, kind ScheduleKind, now time.Time) ([]ScheduleRun, error) {
+	if err := s.ready(); err != nil {
+		return nil, AsGoError(err)
+	}
+	if now.IsZero() {
+		now = s.now()
+	}
+	due, err := s.store.DueSchedules(ctx, kind, now)
+	if err != nil {
+		return nil, AsGoError(err)
+	}
+	runs := make([]ScheduleRun, 0, len(due))
+	var errs []error
+	for _, schedule := range due {
+		claimed, err := s.claimSchedule(ctx, schedule, now)
+		if err != nil {
+			errs = append(errs, AsGoError(err).WithMetadata(map[string]any{"schedule_id": schedule.ID}))
+			continue
+		}
+		runs = append(runs, claimed...)
+	}
+	return runs, errors.Join(errs...)
+}
+
+func (s *ScheduleService) claimSchedule(ctx context.Context, schedule Schedule, now time.Time) ([]ScheduleRun, error) {
+	calendar, err := s.calendar(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	slots, next, err := s.dueSlots(schedule, calendar, now)
+	if err != nil {
+		return nil, err
+	}
+	lastRun := schedule.LastRunAt
+	if len(slots) > 0 {
+		lastRun = now
+	}
+	ok, err := s.store.MarkScheduleRun(ctx, schedule.ID, schedule.NextRunAt, lastRun, next)
+	if err != nil || !ok {
+		return nil, err
+	}
+	schedule.LastRunAt = lastRun
+	schedule.NextRunAt = next
+	runs := make([]ScheduleRun, 0, len(slots))
+	for _, slot := range slots {
+		runs = append(runs, ScheduleRun{Schedule: schedule, RunAt: slot})
+	}
+	return runs, nil
+}
+
+// dueSlots walks the slots from NextRunAt through now, filters them by the
+// catch-up policy, and returns the first slot after now.
+func (s *ScheduleService) dueSlots(schedule Schedule, cal *BusinessCalendar, now time.Time) ([]time.Time, time.Time, error) {
+	clock, err := newScheduleClock(schedule, cal)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var missed []time.Time
+	slot := schedule.NextRunAt
+	for !slot.After(now) {
+		missed = append(missed, slot)
+		if len(missed) > s.maxCatchUp {
+			missed = missed[1:]
+		}
+		if slot, err = clock.next(slot); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+	if len(missed) == 0 {
+		return nil, slot, nil
+	}
+	latest := missed[len(missed)-1]
+	switch schedule.CatchUp {
+	case CatchUpAll:
+		return missed, slot, nil
+	case CatchUpSkip:
+		if now.Sub(latest) > s.misfireThreshold {
+			return nil, slot, nil
+		}
+		return []time.Time{latest}, slot, nil
+	default:
+		return []time.Time{latest}, slot, nil
+	}
+}
+
+// calendar resolves the schedule's business calendar; nil means none.
+func (s *ScheduleService) calendar(ctx context.Context, schedule Schedule) (*BusinessCalendar, error) {
+	if schedule.Calendar == "" {
+		return nil, nil
+	}
+	if s.calendars == nil {
+		return nil, NewError(KindNotImpl, "calendar source not configured", nil)
+	}
+	calendar, err := s.calendars.BusinessCalendar(ctx, schedule.Calendar)
+	if err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+var (
+	_ ScheduleManager = (*ScheduleService)(nil)
+	_ ScheduleClaimer = (*ScheduleService)(nil)
+)
+
+func (s *ScheduleService) ready() error {
+	if s == nil {
+		return NewError(KindInternal, "schedule service is nil", nil)
+	}
+	if s.store == nil {
+		return NewError(KindNotImpl, "schedule store not configured", nil)
+	}
+	return nil
+}
+
+func (s *ScheduleService) prepare(ctx context.Context, actor Actor, schedule *Schedule, now time.Time) error {
+	schedule.Cron = strings.TrimSpace(schedule.Cron)
+	schedule.Timezone = strings.TrimSpace(schedule.Timezone)
+	schedule.Calendar = strings.TrimSpace(schedule.Calendar)
+	if schedule.Kind == ScheduleKindExport {
+		schedule.Export = sanitizeRequestForRecord(schedule.Export)
+	}
+	if err := ValidateSchedule(*schedule); err != nil {
+		return err
+	}
+	if schedule.Kind == ScheduleKindDelivery {
+		if s.deliveryValidator == nil {
+			return NewError(KindNotImpl, "delivery schedule validator not configured", nil)
+		}
+		if err := s.deliveryValidator(ctx, actor, *schedule); err != nil {
+			return err
+		}
+	}
+	calendar, err := s.calendar(ctx, *schedule)
+	if err != nil {
+		return err
+	}
+	schedule.NextRunAt = time.Time{}
+	if !schedule.Enabled {
+		return nil
+	}
+	next, err := schedule.NextBusinessRun(now, calendar)
+	if err != nil {
+		return err
+	}
+	schedule.NextRunAt = next
+	return nil
+}
+
+func defaultScheduleIDGenerator() func() string {
+	var counter uint64
+	return func() string {
+		id := atomic.AddUint64(&counter, 1)
+		return fmt.Sprintf("sch-%d-%d", time.Now().UnixNano(), id)
+	}
+}
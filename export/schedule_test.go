@@ -0,0 +1,251 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduleService_MemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	svc := NewScheduleService(ScheduleServiceConfig{
+		Store: NewMemoryScheduleStore(),
+		Now:   func() time.Time { return now },
+	})
+	owner := Actor{ID: "u1", Scope: Scope{TenantID: "t1"}}
+
+	if _, err := svc.CreateSchedule(ctx, owner, Schedule{Kind: ScheduleKindExport, Cron: "not a cron", Export: ExportRequest{Definition: "users"}}); err == nil {
+		t.Fatalf("expected invalid cron to fail")
+	}
+	if _, err := svc.CreateSchedule(ctx, owner, Schedule{Kind: ScheduleKindExport, Cron: "@daily", Timezone: "Mars/Olympus", Export: ExportRequest{Definition: "users"}}); err == nil {
+		t.Fatalf("expected invalid timezone to fail")
+	}
+
+	schedule, err := svc.CreateSchedule(ctx, owner, Schedule{
+		Kind:     ScheduleKindExport,
+		Cron:     "0 8 * * 1-5",
+		Timezone: "Europe/Madrid",
+		Enabled:  true,
+		Export:   ExportRequest{Definition: "users", Format: FormatCSV},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// 08:00 CEST is 06:00 UTC; the next weekday after Wednesday 10:00 UTC is Thursday.
+	if want := time.Date(2024, 5, 2, 6, 0, 0, 0, time.UTC); !schedule.NextRunAt.Equal(want) {
+		t.Fatalf("expected next run %s, got %s", want, schedule.NextRunAt.UTC())
+	}
+
+	other := Actor{ID: "u2", Scope: Scope{TenantID: "t2"}}
+	if _, err := svc.GetSchedule(ctx, other, schedule.ID); err == nil {
+		t.Fatalf("expected out-of-scope get to fail")
+	}
+	if list, _ := svc.ListSchedules(ctx, other, ScheduleFilter{}); len(list) != 0 {
+		t.Fatalf("expected no schedules for other tenant, got %d", len(list))
+	}
+
+	// Three missed runs collapse into one claim.
+	late := time.Date(2024, 5, 7, 7, 0, 0, 0, time.UTC)
+	claimed, err := svc.ClaimDue(ctx, ScheduleKindExport, late)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if len(claimed) != 1 || !claimed[0].RunAt.Equal(time.Date(2024, 5, 7, 6, 0, 0, 0, time.UTC)) || !claimed[0].Schedule.NextRunAt.Equal(time.Date(2024, 5, 8, 6, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected claim: %+v", claimed)
+	}
+	if again, _ := svc.ClaimDue(ctx, ScheduleKindExport, late); len(again) != 0 {
+		t.Fatalf("expected schedule to be claimed once, got %d", len(again))
+	}
+	if deliveries, _ := svc.ClaimDue(ctx, ScheduleKindDelivery, late.Add(48*time.Hour)); len(deliveries) != 0 {
+		t.Fatalf("expected no delivery schedules, got %d", len(deliveries))
+	}
+}
+
+// roleAdminGuard grants admin access to actors with the "admin" role.
+type roleAdminGuard struct{}
+
+func (roleAdminGuard) AuthorizeExport(context.Context, Actor, ExportRequest, ResolvedDefinition) error {
+	return nil
+}
+
+func (roleAdminGuard) AuthorizeDownload(context.Context, Actor, string) error { return nil }
+
+func (roleAdminGuard) AuthorizeAdmin(_ context.Context, actor Actor, _ string) error {
+	if hasAnyRole(actor.Roles, []string{"admin"}) {
+		return nil
+	}
+	return errors.New("not an admin")
+}
+
+func TestScheduleService_OwnerAccess(t *testing.T) {
+	ctx := context.Background()
+	svc := NewScheduleService(ScheduleServiceConfig{
+		Store: NewMemoryScheduleStore(),
+		Guard: roleAdminGuard{},
+	})
+	owner := Actor{ID: "u1", Scope: Scope{TenantID: "t1"}}
+	schedule, err := svc.CreateSchedule(ctx, owner, Schedule{
+		Kind:   ScheduleKindExport,
+		Cron:   "@daily",
+		Export: ExportRequest{Definition: "users"},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Same tenant or an empty scope is not enough without owning the schedule.
+	for _, actor := range []Actor{
+		{ID: "u2", Scope: Scope{TenantID: "t1"}},
+		{ID: "u3"},
+		{},
+	} {
+		if _, err := svc.GetSchedule(ctx, actor, schedule.ID); err == nil {
+			t.Fatalf("expected get by %q to fail", actor.ID)
+		}
+		if _, err := svc.UpdateSchedule(ctx, actor, schedule); err == nil {
+			t.Fatalf("expected update by %q to fail", actor.ID)
+		}
+		if err := svc.DeleteSchedule(ctx, actor, schedule.ID); err == nil {
+			t.Fatalf("expected delete by %q to fail", actor.ID)
+		}
+		if list, _ := svc.ListSchedules(ctx, actor, ScheduleFilter{}); len(list) != 0 {
+			t.Fatalf("expected no schedules for %q, got %d", actor.ID, len(list))
+		}
+	}
+
+	admin := Actor{ID: "a1", Scope: Scope{TenantID: "t1"}, Roles: []string{"admin"}}
+	if list, _ := svc.ListSchedules(ctx, admin, ScheduleFilter{}); len(list) != 1 {
+		t.Fatalf("expected admin to list the schedule, got %d", len(list))
+	}
+	updated, err := svc.UpdateSchedule(ctx, admin, schedule)
+	if err != nil {
+		t.Fatalf("admin update: %v", err)
+	}
+	if updated.Owner.ID != owner.ID {
+		t.Fatalf("expected owner to be kept, got %q", updated.Owner.ID)
+	}
+	if _, err := svc.GetSchedule(ctx, Actor{ID: "a2", Scope: Scope{TenantID: "t2"}, Roles: []string{"admin"}}, schedule.ID); err == nil {
+		t.Fatalf("expected admin of another tenant to be denied")
+	}
+	if err := svc.DeleteSchedule(ctx, owner, schedule.ID); err != nil {
+		t.Fatalf("owner delete: %v", err)
+	}
+}
+
+func TestScheduleService_CatchUpPolicies(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2024, 5, 4, 9, 30, 0, 0, time.UTC)
+	back := time.Date(2024, 5, 4, 12, 20, 0, 0, time.UTC)
+
+	cases := []struct {
+		policy CatchUpPolicy
+		now    time.Time
+		runs   []time.Time
+	}{
+		{policy: CatchUpAll, now: back, runs: []time.Time{
+			time.Date(2024, 5, 4, 10, 0, 0, 0, time.UTC),
+			time.Date(2024, 5, 4, 11, 0, 0, 0, time.UTC),
+			time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC),
+		}},
+		{policy: CatchUpOnce, now: back, runs: []time.Time{time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)}},
+		{policy: CatchUpSkip, now: back, runs: nil},
+		{policy: CatchUpSkip, now: time.Date(2024, 5, 4, 12, 3, 0, 0, time.UTC), runs: []time.Time{time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)}},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			svc := NewScheduleService(ScheduleServiceConfig{
+				Store: NewMemoryScheduleStore(),
+				Now:   func() time.Time { return created },
+			})
+			if _, err := svc.CreateSchedule(ctx, Actor{ID: "u1"}, Schedule{
+				Kind:    ScheduleKindExport,
+				Cron:    "@hourly",
+				Enabled: true,
+				CatchUp: tc.policy,
+				Export:  ExportRequest{Definition: "users"},
+			}); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+
+			runs, err := svc.ClaimDue(ctx, ScheduleKindExport, tc.now)
+			if err != nil {
+				t.Fatalf("claim: %v", err)
+			}
+			if len(runs) != len(tc.runs) {
+				t.Fatalf("expected %d runs, got %+v", len(tc.runs), runs)
+			}
+			for i, run := range runs {
+				if !run.RunAt.Equal(tc.runs[i]) {
+					t.Fatalf("run %d: expected %s, got %s", i, tc.runs[i], run.RunAt)
+				}
+			}
+			if again, _ := svc.ClaimDue(ctx, ScheduleKindExport, tc.now); len(again) != 0 {
+				t.Fatalf("expected slots to be consumed, got %d", len(again))
+			}
+		})
+	}
+}
+
+func TestScheduleService_BusinessCalendar(t *testing.T) {
+	ctx := context.Background()
+	calendars, err := ParseCalendars([]byte(`
+calendars:
+  - name: uk
+    holidays: ["2024-05-27"]
+`))
+	if err != nil {
+		t.Fatalf("parse calendars: %v", err)
+	}
+	// Friday 2024-05-24 after the 09:00 slot.
+	now := time.Date(2024, 5, 24, 10, 0, 0, 0, time.UTC)
+	svc := NewScheduleService(ScheduleServiceConfig{
+		Store:     NewMemoryScheduleStore(),
+		Calendars: NewMemoryCalendarSource(calendars...),
+		Now:       func() time.Time { return now },
+	})
+	owner := Actor{ID: "u1"}
+
+	daily, err := svc.CreateSchedule(ctx, owner, Schedule{
+		Kind:     ScheduleKindExport,
+		Cron:     "0 9 * * *",
+		Timezone: "Europe/London",
+		Calendar: "uk",
+		Enabled:  true,
+		Export:   ExportRequest{Definition: "users"},
+	})
+	if err != nil {
+		t.Fatalf("create daily: %v", err)
+	}
+	// Weekend and the bank holiday Monday are skipped: Tuesday 09:00 BST.
+	if want := time.Date(2024, 5, 28, 8, 0, 0, 0, time.UTC); !daily.NextRunAt.Equal(want) {
+		t.Fatalf("expected %s, got %s", want, daily.NextRunAt.UTC())
+	}
+
+	monthly, err := svc.CreateSchedule(ctx, owner, Schedule{
+		Kind:           ScheduleKindExport,
+		Cron:           "0 9 1 * *",
+		Timezone:       "Europe/London",
+		Calendar:       "uk",
+		CalendarPolicy: CalendarNextBusinessDay,
+		Enabled:        true,
+		Export:         ExportRequest{Definition: "users"},
+	})
+	if err != nil {
+		t.Fatalf("create monthly: %v", err)
+	}
+	// 2024-06-01 is a Saturday, so the run shifts to Monday 2024-06-03.
+	if want := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC); !monthly.NextRunAt.Equal(want) {
+		t.Fatalf("expected %s, got %s", want, monthly.NextRunAt.UTC())
+	}
+
+	if _, err := svc.CreateSchedule(ctx, owner, Schedule{
+		Kind:     ScheduleKindExport,
+		Cron:     "@daily",
+		Calendar: "missing",
+		Export:   ExportRequest{Definition: "users"},
+	}); err == nil {
+		t.Fatalf("expected unknown calendar to fail")
+	}
+}
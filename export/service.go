@@ -1,10 +1,15 @@
 package export
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"path"
 	"time"
 )
 
@@ -24,6 +29,15 @@ type Service interface {
 	History(ctx context.Context, actor Actor, filter ProgressFilter) ([]ExportRecord, error)
 	DownloadMetadata(ctx context.Context, actor Actor, exportID string) (DownloadInfo, error)
 	Cleanup(ctx context.Context, now time.Time) (int, error)
+	ApproveExport(ctx context.Context, actor Actor, exportID, comment string) (ExportRecord, error)
+	RejectExport(ctx context.Context, actor Actor, exportID, comment string) (ExportRecord, error)
+	RecordDownload(ctx context.Context, actor Actor, download DownloadRecord) error
+	Downloads(ctx context.Context, actor Actor, exportID string) ([]DownloadRecord, error)
+	Deliveries(ctx context.Context, actor Actor, exportID string) ([]DeliveryRecord, error)
+	DeliveryHistory(ctx context.Context, actor Actor, filter DeliveryFilter) ([]DeliveryRecord, error)
+	LookupWatermark(ctx context.Context, actor Actor, token string) (ExportRecord, error)
+	VerifyExport(ctx context.Context, actor Actor, exportID string) (ChecksumVerification, error)
+	VerifyManifest(ctx context.Context, actor Actor, exportID string) (ManifestVerification, error)
 }
 
 // DeleteStrategy defines how delete requests are handled.
@@ -41,27 +55,35 @@ type DeleteParams struct {
 
 // ServiceConfig supplies dependencies for Service.
 type ServiceConfig struct {
-	Runner         *Runner
-	Tracker        ProgressTracker
-	Store          ArtifactStore
-	Guard          Guard
-	DeliveryPolicy DeliveryPolicy
-	DeleteStrategy DeleteStrategy
-	CancelHook     CancelHook
-	Now            func() time.Time
-	IDGenerator    func() string
+	Runner          *Runner
+	Tracker         ProgressTracker
+	Store           ArtifactStore
+	Guard           Guard
+	DeliveryPolicy  DeliveryPolicy
+	DeleteStrategy  DeleteStrategy
+	CancelHook      CancelHook
+	ApprovalHook    ApprovalHook
+	DownloadLog     DownloadLog
+	DeliveryTracker DeliveryTracker
+	ManifestSigner  ManifestSigner
+	Now             func() time.Time
+	IDGenerator     func() string
 }
 
 type service struct {
-	runner         *Runner
-	tracker        ProgressTracker
-	store          ArtifactStore
-	guard          Guard
-	deliveryPolicy DeliveryPolicy
-	deleteStrategy DeleteStrategy
-	cancelHook     CancelHook
-	now            func() time.Time
-	idGenerator    func() string
+	runner          *Runner
+	tracker         ProgressTracker
+	store           ArtifactStore
+	guard           Guard
+	deliveryPolicy  DeliveryPolicy
+	deleteStrategy  DeleteStrategy
+	cancelHook      CancelHook
+	approvalHook    ApprovalHook
+	downloadLog     DownloadLog
+	deliveryTracker DeliveryTracker
+	manifestSigner  ManifestSigner
+	now             func() time.Time
+	idGenerator     func() string
 }
 
 // NewService creates a Service with the provided configuration.
@@ -118,15 +140,19 @@ func NewService(cfg ServiceConfig) Service {
 	}
 
 	return &service{
-		runner:         runner,
-		tracker:        tracker,
-		store:          store,
-		guard:          guard,
-		deliveryPolicy: policy,
-		deleteStrategy: deleteStrategy,
-		cancelHook:     cfg.CancelHook,
-		now:            nowFn,
-		idGenerator:    idGen,
+		runner:          runner,
+		tracker:         tracker,
+		store:           store,
+		guard:           guard,
+		deliveryPolicy:  policy,
+		deleteStrategy:  deleteStrategy,
+		cancelHook:      cfg.CancelHook,
+		approvalHook:    cfg.ApprovalHook,
+		downloadLog:     cfg.DownloadLog,
+		deliveryTracker: cfg.DeliveryTracker,
+		manifestSigner:  cfg.ManifestSigner,
+		now:             nowFn,
+		idGenerator:     idGen,
 	}
 }
 
@@ -136,11 +162,15 @@ func (s *service) RequestExport(ctx context.Context, actor Actor, req ExportRequ
 		return ExportRecord{}, AsGoError(NewError(KindInternal, "service is nil", nil))
 	}
 
-	resolved, err := s.resolveRequest(req)
+	resolved, err := s.resolveRequest(actor, req)
 	if err != nil {
 		return ExportRecord{}, AsGoError(err)
 	}
 
+	if reasons := ApprovalReasons(resolved); len(reasons) > 0 {
+		return s.requestApproval(ctx, actor, resolved, reasons)
+	}
+
 	delivery := SelectDelivery(resolved.Request, resolved.Definition, s.deliveryPolicy)
 	if delivery == DeliveryAsync {
 		return s.requestAsync(ctx, actor, resolved)
@@ -164,12 +194,26 @@ func (s *service) GenerateExport(ctx context.Context, actor Actor, exportID stri
 		return ExportResult{}, AsGoError(NewError(KindNotImpl, "progress tracker not configured", nil))
 	}
 
-	resolved, err := s.resolveRequest(req)
+	resolved, err := s.resolveRequest(actor, req)
 	if err != nil {
 		return ExportResult{}, AsGoError(err)
 	}
 
-	if _, err := s.tracker.Status(ctx, exportID); err != nil {
+	existing, statusErr := s.tracker.Status(ctx, exportID)
+	approvalRequired := len(ApprovalReasons(resolved)) > 0
+	requestedAt := existing.CreatedAt
+	if statusErr == nil {
+		if existing.State == StateCanceled {
+			return ExportResult{}, AsGoError(NewError(KindCanceled, "export was canceled", nil))
+		}
+		if err := checkApprovalGate(existing, approvalRequired); err != nil {
+			return ExportResult{}, AsGoError(err)
+		}
+	} else if approvalRequired {
+		return ExportResult{}, AsGoError(NewError(KindValidation, "export requires approval", nil))
+	}
+
+	if statusErr != nil {
 		record := ExportRecord{
 			ID:          exportID,
 			Definition:  resolved.Definition.Name,
@@ -177,12 +221,15 @@ func (s *service) GenerateExport(ctx context.Context, actor Actor, exportID stri
 			State:       StateQueued,
 			RequestedBy: actor,
 			Scope:       actor.Scope,
+			Access:      resolved.Access,
+			Parameters:  resolved.Request.Parameters,
 			CreatedAt:   s.now(),
 			Artifact: ArtifactRef{
 				Key: s.artifactKey(exportID, resolved.Request.Format),
 				Meta: ArtifactMeta{
 					ContentType: contentTypeForFormat(resolved.Request.Format),
 					Filename:    resolved.Filename,
+					TenantID:    actor.Scope.TenantID,
 					CreatedAt:   s.now(),
 				},
 			},
@@ -200,6 +247,7 @@ func (s *service) GenerateExport(ctx context.Context, actor Actor, exportID stri
 	meta := ArtifactMeta{
 		ContentType: contentTypeForFormat(resolved.Request.Format),
 		Filename:    resolved.Filename,
+		TenantID:    actor.Scope.TenantID,
 		CreatedAt:   s.now(),
 	}
 
@@ -219,15 +267,23 @@ func (s *service) GenerateExport(ctx context.Context, actor Actor, exportID stri
 	}
 	run.IDGenerator = func() string { return exportID }
 	run.Tracker = runnerTracker{base: s.tracker, exportID: exportID}
+	run.approvalGranted = approvalRequired
 
+	hasher := sha256.New()
 	runReq := resolved.Request
 	runReq.Delivery = DeliverySync
-	runReq.Output = pw
+	runReq.Output = io.MultiWriter(pw, hasher)
 
 	result, err := run.Run(ctx, runReq)
 	if err != nil {
 		_ = pw.CloseWithError(err)
 		<-putCh
+		if isCanceledError(err) {
+			// Stores may keep what was streamed before the cancel.
+			if derr := s.store.Delete(context.WithoutCancel(ctx), key); derr != nil && KindFromError(derr) != KindNotFound {
+				run.Logger.Warn("canceled artifact cleanup failed", "error", derr, "export_id", exportID)
+			}
+		}
 		return ExportResult{}, err
 	}
 
@@ -238,8 +294,22 @@ func (s *service) GenerateExport(ctx context.Context, actor Actor, exportID stri
 		return result, AsGoError(putResult.err)
 	}
 
+	putResult.ref.Meta.Checksum = hex.EncodeToString(hasher.Sum(nil))
+	if s.manifestSigner != nil {
+		if requestedAt.IsZero() {
+			requestedAt = meta.CreatedAt
+		}
+		manifest := buildManifest(exportID, resolved, actor, result, putResult.ref, requestedAt, s.now())
+		manifestKey, err := s.putManifest(ctx, manifest)
+		if err != nil {
+			_ = s.tracker.Fail(ctx, exportID, err, nil)
+			return result, AsGoError(err)
+		}
+		result.ManifestKey = manifestKey
+	}
 	s.updateArtifact(ctx, exportID, putResult.ref)
 	result.Artifact = &putResult.ref
+	result.Checksum = putResult.ref.Meta.Checksum
 	return result, nil
 }
 
@@ -287,6 +357,16 @@ func (s *service) CancelExport(ctx context.Context, actor Actor, exportID string
 	return record, nil
 }
 
+// ApproveExport approves a pending export and hands it to the ApprovalHook.
+func (s *service) ApproveExport(ctx context.Context, actor Actor, exportID, comment string) (ExportRecord, error) {
+	return s.decideApproval(ctx, actor, exportID, comment, ApprovalApproved)
+}
+
+// RejectExport rejects a pending export.
+func (s *service) RejectExport(ctx context.Context, actor Actor, exportID, comment string) (ExportRecord, error) {
+	return s.decideApproval(ctx, actor, exportID, comment, ApprovalRejected)
+}
+
 // DeleteExport removes artifacts for an export.
 func (s *service) DeleteExport(ctx context.Context, actor Actor, exportID string) error {
 	if s == nil {
@@ -406,6 +486,9 @@ func (s *service) DownloadMetadata(ctx context.Context, actor Actor, exportID st
 		return DownloadInfo{}, AsGoError(err)
 	}
 	_ = reader.Close()
+	if meta.Checksum == "" {
+		meta.Checksum = record.Artifact.Meta.Checksum
+	}
 
 	return DownloadInfo{
 		ExportID: exportID,
@@ -413,6 +496,285 @@ func (s *service) DownloadMetadata(ctx context.Context, actor Actor, exportID st
 	}, nil
 }
 
+// RecordDownload logs a completed download and emits export.downloaded.
+func (s *service) RecordDownload(ctx context.Context, actor Actor, download DownloadRecord) error {
+	if s == nil {
+		return AsGoError(NewError(KindInternal, "service is nil", nil))
+	}
+	if download.ExportID == "" {
+		return AsGoError(NewError(KindValidation, "export ID is required", nil))
+	}
+	download.Actor = actor
+	if download.DownloadedAt.IsZero() {
+		download.DownloadedAt = s.now()
+	}
+	if download.ID == "" {
+		download.ID = s.nextID()
+	}
+
+	if s.downloadLog != nil {
+		if err := s.downloadLog.RecordDownload(ctx, download); err != nil {
+			return AsGoError(err)
+		}
+	}
+
+	record := ExportRecord{ID: download.ExportID}
+	if s.tracker != nil {
+		if stored, err := s.tracker.Status(ctx, download.ExportID); err == nil {
+			record = stored
+		}
+	}
+	s.emitRecordEvent(ctx, record, actor, "export.downloaded", map[string]any{
+		"download_id": download.ID,
+		"ip":          download.IP,
+		"user_agent":  download.UserAgent,
+		"method":      string(download.Method),
+		"bytes":       download.Bytes,
+	})
+	return nil
+}
+
+// Downloads returns the download history for an export. It requires
+// AdminGuard access to AdminDownloads and an actor scope covering the export.
+func (s *service) Downloads(ctx context.Context, actor Actor, exportID string) ([]DownloadRecord, error) {
+	if s == nil {
+		return nil, AsGoError(NewError(KindInternal, "service is nil", nil))
+	}
+	if exportID == "" {
+		return nil, AsGoError(NewError(KindValidation, "export ID is required", nil))
+	}
+	if s.downloadLog == nil {
+		return nil, AsGoError(NewError(KindNotImpl, "download log not configured", nil))
+	}
+	if s.tracker == nil {
+		return nil, AsGoError(NewError(KindNotImpl, "progress tracker not configured", nil))
+	}
+	// The log exposes other downloaders' IPs and user agents, so it needs
+	// admin access rather than download access.
+	if err := authorizeAdmin(ctx, s.guard, actor, AdminDownloads); err != nil {
+		return nil, AsGoError(err)
+	}
+	record, err := s.tracker.Status(ctx, exportID)
+	if err != nil {
+		return nil, AsGoError(err)
+	}
+	if !scopeMatches(actor.Scope, record.Scope) {
+		return nil, AsGoError(NewError(KindNotFound, fmt.Sprintf("export %q not found", exportID), nil))
+	}
+	records, err := s.downloadLog.ListDownloads(ctx, exportID)
+	if err != nil {
+		return nil, AsGoError(err)
+	}
+	return records, nil
+}
+
+// LookupWatermark returns the export that issued a watermark token. It
+// requires AdminGuard access to AdminWatermarks and an actor scope covering
+// the export.
+func (s *service) LookupWatermark(ctx context.Context, actor Actor, token string) (ExportRecord, error) {
+	if s == nil {
+		return ExportRecord{}, AsGoError(NewError(KindInternal, "service is nil", nil))
+	}
+	if token == "" {
+		return ExportRecord{}, AsGoError(NewError(KindValidation, "watermark token is required", nil))
+	}
+	watermarks, ok := s.tracker.(WatermarkTracker)
+	if !ok {
+		return ExportRecord{}, AsGoError(NewError(KindNotImpl, "tracker does not support watermarks", nil))
+	}
+	if err := authorizeAdmin(ctx, s.guard, actor, AdminWatermarks); err != nil {
+		return ExportRecord{}, AsGoError(err)
+	}
+	record, err := watermarks.FindByWatermark(ctx, token)
+	if err != nil {
+		return ExportRecord{}, AsGoError(err)
+	}
+	if !scopeMatches(actor.Scope, record.Scope) {
+		return ExportRecord{}, AsGoError(NewError(KindNotFound, "watermark not found", nil))
+	}
+	return record, nil
+}
+
+// Deliveries returns the per-target delivery history of an export, newest first.
+func (s *service) Deliveries(ctx context.Context, actor Actor, exportID string) ([]DeliveryRecord, error) {
+	if s == nil {
+		return nil, AsGoError(NewError(KindInternal, "service is nil", nil))
+	}
+	if exportID == "" {
+		return nil, AsGoError(NewError(KindValidation, "export ID is required", nil))
+	}
+	if s.deliveryTracker == nil {
+		return nil, AsGoError(NewError(KindNotImpl, "delivery tracker not configured", nil))
+	}
+	if err := s.authorizeDownload(ctx, actor, exportID); err != nil {
+		return nil, err
+	}
+	records, err := s.deliveryTracker.ListDeliveries(ctx, DeliveryFilter{ExportID: exportID})
+	if err != nil {
+		return nil, AsGoError(err)
+	}
+	return records, nil
+}
+
+// DeliveryHistory searches delivery history across exports. It requires
+// AdminGuard access to AdminDeliveries, and results are always limited to the
+// actor's scope.
+func (s *service) DeliveryHistory(ctx context.Context, actor Actor, filter DeliveryFilter) ([]DeliveryRecord, error) {
+	if s == nil {
+		return nil, AsGoError(NewError(KindInternal, "service is nil", nil))
+	}
+	if s.deliveryTracker == nil {
+		return nil, AsGoError(NewError(KindNotImpl, "delivery tracker not configured", nil))
+	}
+	if err := authorizeAdmin(ctx, s.guard, actor, AdminDeliveries); err != nil {
+		return nil, AsGoError(err)
+	}
+	filter.Scope = actor.Scope
+	records, err := s.deliveryTracker.ListDeliveries(ctx, filter)
+	if err != nil {
+		return nil, AsGoError(err)
+	}
+	return records, nil
+}
+
+// VerifyExport re-hashes the stored artifact and compares it with the checksum
+// recorded at generation time. Mismatches are reported, not returned as errors,
+// and emit export.integrity_failed.
+func (s *service) VerifyExport(ctx context.Context, actor Actor, exportID string) (ChecksumVerification, error) {
+	if s == nil {
+		return ChecksumVerification{}, AsGoError(NewError(KindInternal, "service is nil", nil))
+	}
+	if exportID == "" {
+		return ChecksumVerification{}, AsGoError(NewError(KindValidation, "export ID is required", nil))
+	}
+	if s.tracker == nil {
+		return ChecksumVerification{}, AsGoError(NewError(KindNotImpl, "progress tracker not configured", nil))
+	}
+	if s.store == nil {
+		return ChecksumVerification{}, AsGoError(NewError(KindNotImpl, "artifact store not configured", nil))
+	}
+	if err := s.authorizeDownload(ctx, actor, exportID); err != nil {
+		return ChecksumVerification{}, err
+	}
+
+	record, err := s.tracker.Status(ctx, exportID)
+	if err != nil {
+		return ChecksumVerification{}, AsGoError(err)
+	}
+	expected := record.Artifact.Meta.Checksum
+	if expected == "" {
+		expected = record.Checksum
+	}
+	if expected == "" {
+		return ChecksumVerification{}, AsGoError(NewError(KindValidation, "export has no recorded checksum", nil))
+	}
+	key := record.Artifact.Key
+	if key == "" {
+		key = s.artifactKey(exportID, record.Format)
+	}
+
+	reader, _, err := s.store.Open(ctx, key)
+	if err != nil {
+		return ChecksumVerification{}, AsGoError(err)
+	}
+	actual, size, err := computeChecksum(reader)
+	_ = reader.Close()
+	if err != nil {
+		return ChecksumVerification{}, AsGoError(NewError(KindInternal, "artifact read failed", err))
+	}
+
+	verification := ChecksumVerification{
+		ExportID:   exportID,
+		Key:        key,
+		Expected:   expected,
+		Actual:     actual,
+		Size:       size,
+		Valid:      actual == expected,
+		VerifiedAt: s.now(),
+	}
+	if !verification.Valid {
+		s.emitRecordEvent(ctx, record, actor, "export.integrity_failed", map[string]any{
+			"expected": expected,
+			"actual":   actual,
+			"key":      key,
+		})
+	}
+	return verification, nil
+}
+
+// VerifyManifest loads the signed provenance manifest stored next to the
+// artifact, checks its signature, and re-hashes the artifact against the
+// manifest checksum. Failed checks are reported, not returned as errors.
+func (s *service) VerifyManifest(ctx context.Context, actor Actor, exportID string) (ManifestVerification, error) {
+	if s == nil {
+		return ManifestVerification{}, AsGoError(NewError(KindInternal, "service is nil", nil))
+	}
+	if exportID == "" {
+		return ManifestVerification{}, AsGoError(NewError(KindValidation, "export ID is required", nil))
+	}
+	if s.tracker == nil {
+		return ManifestVerification{}, AsGoError(NewError(KindNotImpl, "progress tracker not configured", nil))
+	}
+	if s.store == nil {
+		return ManifestVerification{}, AsGoError(NewError(KindNotImpl, "artifact store not configured", nil))
+	}
+	if s.manifestSigner == nil {
+		return ManifestVerification{}, AsGoError(NewError(KindNotImpl, "manifest signer not configured", nil))
+	}
+	if err := s.authorizeDownload(ctx, actor, exportID); err != nil {
+		return ManifestVerification{}, err
+	}
+
+	record, err := s.tracker.Status(ctx, exportID)
+	if err != nil {
+		return ManifestVerification{}, AsGoError(err)
+	}
+	key := record.Artifact.Key
+	if key == "" {
+		key = s.artifactKey(exportID, record.Format)
+	}
+
+	reader, _, err := s.store.Open(ctx, ManifestKey(key))
+	if err != nil {
+		return ManifestVerification{}, AsGoError(err)
+	}
+	var signed SignedManifest
+	err = json.NewDecoder(reader).Decode(&signed)
+	_ = reader.Close()
+	if err != nil {
+		return ManifestVerification{}, AsGoError(NewError(KindValidation, "manifest sidecar invalid", err))
+	}
+
+	verification := ManifestVerification{ExportID: exportID, Signature: signed.Signature}
+	manifest, err := VerifyManifest(ctx, s.manifestSigner, signed)
+	if err != nil {
+		verification.Manifest, _ = signed.Decode()
+		verification.Error = err.Error()
+		return verification, nil
+	}
+	verification.Manifest = manifest
+	verification.SignatureValid = true
+
+	artifactKey := manifest.ArtifactKey
+	if artifactKey == "" {
+		artifactKey = key
+	}
+	artifact, _, err := s.store.Open(ctx, artifactKey)
+	if err != nil {
+		return ManifestVerification{}, AsGoError(err)
+	}
+	actual, _, err := computeChecksum(artifact)
+	_ = artifact.Close()
+	if err != nil {
+		return ManifestVerification{}, AsGoError(NewError(KindInternal, "artifact read failed", err))
+	}
+	verification.ChecksumValid = manifest.Checksum != "" && actual == manifest.Checksum
+	if !verification.ChecksumValid {
+		verification.Error = "artifact checksum does not match manifest"
+	}
+	return verification, nil
+}
+
 // Cleanup deletes expired artifacts and returns the count removed.
 func (s *service) Cleanup(ctx context.Context, now time.Time) (int, error) {
 	if s == nil {
@@ -446,6 +808,7 @@ func (s *service) Cleanup(ctx context.Context, now time.Time) (int, error) {
 			if err := s.store.Delete(ctx, key); err != nil {
 				return deleted, AsGoError(err)
 			}
+			_ = s.store.Delete(ctx, ManifestKey(key))
 		}
 		if deleter, ok := s.tracker.(RecordDeleter); ok {
 			if err := deleter.Delete(ctx, record.ID); err != nil {
@@ -492,6 +855,23 @@ func (s *service) requestSync(ctx context.Context, actor Actor, resolved Resolve
 }
 
 func (s *service) requestAsync(ctx context.Context, actor Actor, resolved ResolvedExport) (ExportRecord, error) {
+	return s.startAsync(ctx, actor, resolved, nil)
+}
+
+// requestApproval records a pending export; generation starts once an
+// approver accepts it.
+func (s *service) requestApproval(ctx context.Context, actor Actor, resolved ResolvedExport, reasons []string) (ExportRecord, error) {
+	record, err := s.startAsync(ctx, actor, resolved, reasons)
+	if err != nil {
+		return ExportRecord{}, err
+	}
+	s.emitRecordEvent(ctx, record, actor, "export.approval_requested", map[string]any{
+		"reasons": reasons,
+	})
+	return record, nil
+}
+
+func (s *service) startAsync(ctx context.Context, actor Actor, resolved ResolvedExport, approvalReasons []string) (ExportRecord, error) {
 	if s.store == nil {
 		return ExportRecord{}, AsGoError(NewError(KindNotImpl, "artifact store not configured", nil))
 	}
@@ -513,18 +893,30 @@ func (s *service) requestAsync(ctx context.Context, actor Actor, resolved Resolv
 		State:       StateQueued,
 		RequestedBy: actor,
 		Scope:       actor.Scope,
+		Access:      resolved.Access,
+		Parameters:  resolved.Request.Parameters,
 		CreatedAt:   s.now(),
 		Artifact: ArtifactRef{
 			Key: s.artifactKey(exportID, resolved.Request.Format),
 			Meta: ArtifactMeta{
 				ContentType: contentTypeForFormat(resolved.Request.Format),
 				Filename:    resolved.Filename,
+				TenantID:    actor.Scope.TenantID,
 				CreatedAt:   s.now(),
 			},
 		},
 	}
-	if isTemplateFormat(resolved.Request.Format) {
-		record.Request = sanitizeRequestForRecord(resolved.Request)
+	// Approvers, the generation job, and the stale reaper need the original
+	// request to rebuild the job.
+	pending := resolved.Request
+	pending.Delivery = DeliveryAsync
+	record.Request = sanitizeRequestForRecord(pending)
+	if len(approvalReasons) > 0 {
+		record.State = StatePendingApproval
+		record.Approval = &ApprovalRecord{
+			Reasons:     approvalReasons,
+			RequestedAt: s.now(),
+		}
 	}
 
 	if s.runner != nil && s.runner.Retention != nil {
@@ -549,7 +941,100 @@ func (s *service) requestAsync(ctx context.Context, actor Actor, resolved Resolv
 	return record, nil
 }
 
-func (s *service) resolveRequest(req ExportRequest) (ResolvedExport, error) {
+func (s *service) decideApproval(ctx context.Context, actor Actor, exportID, comment string, decision ApprovalDecision) (ExportRecord, error) {
+	if s == nil {
+		return ExportRecord{}, AsGoError(NewError(KindInternal, "service is nil", nil))
+	}
+	if exportID == "" {
+		return ExportRecord{}, AsGoError(NewError(KindValidation, "export ID is required", nil))
+	}
+	if s.tracker == nil {
+		return ExportRecord{}, AsGoError(NewError(KindNotImpl, "progress tracker not configured", nil))
+	}
+
+	record, err := s.tracker.Status(ctx, exportID)
+	if err != nil {
+		return ExportRecord{}, AsGoError(err)
+	}
+	if record.State != StatePendingApproval {
+		return ExportRecord{}, AsGoError(NewError(KindValidation, fmt.Sprintf("export is not pending approval (state %q)", record.State), nil))
+	}
+	if s.runner == nil || s.runner.Definitions == nil {
+		return ExportRecord{}, AsGoError(NewError(KindInternal, "definition registry not configured", nil))
+	}
+	def, err := s.runner.Definitions.Resolve(record.Request)
+	if err != nil {
+		return ExportRecord{}, AsGoError(err)
+	}
+	if err := authorizeApprover(def.Approval, actor, record); err != nil {
+		return ExportRecord{}, AsGoError(err)
+	}
+	if s.guard != nil && (def.Approval == nil || len(def.Approval.ApproverRoles) == 0) {
+		if err := authorizeAdmin(ctx, s.guard, actor, AdminApprovals); err != nil {
+			return ExportRecord{}, AsGoError(err)
+		}
+	}
+
+	approval := ApprovalRecord{}
+	if record.Approval != nil {
+		approval = *record.Approval
+	}
+	approval.Decision = decision
+	approval.DecidedBy = actor
+	approval.DecidedAt = s.now()
+	approval.Comment = comment
+	record.Approval = &approval
+
+	record.State = StateQueued
+	event := "export.approved"
+	if decision == ApprovalRejected {
+		record.State = StateRejected
+		event = "export.rejected"
+	}
+	// Claim the decision first so concurrent approvers cannot both enqueue.
+	decided, err := transitionState(ctx, s.tracker, exportID, StatePendingApproval, record.State)
+	if err != nil {
+		return ExportRecord{}, AsGoError(err)
+	}
+	if !decided {
+		return ExportRecord{}, AsGoError(NewError(KindValidation, "export is no longer pending approval", nil))
+	}
+	if updater, ok := s.tracker.(RecordUpdater); ok {
+		if err := updater.Update(ctx, record); err != nil {
+			return ExportRecord{}, AsGoError(err)
+		}
+	}
+
+	s.emitRecordEvent(ctx, record, actor, event, map[string]any{
+		"requested_by": record.RequestedBy.ID,
+		"comment":      comment,
+	})
+
+	if decision == ApprovalApproved && s.approvalHook != nil {
+		if err := s.approvalHook.ExportApproved(ctx, record); err != nil {
+			return record, AsGoError(err)
+		}
+	}
+	return record, nil
+}
+
+func (s *service) emitRecordEvent(ctx context.Context, record ExportRecord, actor Actor, name string, meta map[string]any) {
+	if s.runner == nil || s.runner.Emitter == nil {
+		return
+	}
+	_ = s.runner.Emitter.Emit(ctx, ChangeEvent{
+		Name:       name,
+		ExportID:   record.ID,
+		Definition: record.Definition,
+		Format:     record.Format,
+		Delivery:   DeliveryAsync,
+		Actor:      actor,
+		Timestamp:  s.now(),
+		Metadata:   meta,
+	})
+}
+
+func (s *service) resolveRequest(actor Actor, req ExportRequest) (ResolvedExport, error) {
 	if s.runner == nil || s.runner.Definitions == nil {
 		return ResolvedExport{}, NewError(KindInternal, "definition registry not configured", nil)
 	}
@@ -560,7 +1045,7 @@ func (s *service) resolveRequest(req ExportRequest) (ResolvedExport, error) {
 	if err != nil {
 		return ResolvedExport{}, err
 	}
-	resolved, err := ResolveExport(req, def, s.now())
+	resolved, err := ResolveExportForActor(req, def, actor, s.now())
 	if err != nil {
 		return ResolvedExport{}, err
 	}
@@ -589,6 +1074,19 @@ func (s *service) authorizeDownload(ctx context.Context, actor Actor, exportID s
 	return nil
 }
 
+// authorizeAdmin grants access to resource owned by other actors. A guard
+// without AdminGuard support grants nothing.
+func authorizeAdmin(ctx context.Context, guard Guard, actor Actor, resource string) error {
+	admin, ok := guard.(AdminGuard)
+	if !ok {
+		return NewError(KindAuthz, resource+" require admin access", nil)
+	}
+	if err := admin.AuthorizeAdmin(ctx, actor, resource); err != nil {
+		return NewError(KindAuthz, resource+" require admin access", err)
+	}
+	return nil
+}
+
 func (s *service) artifactKey(exportID string, format Format) string {
 	if exportID == "" {
 		return ""
@@ -599,6 +1097,28 @@ func (s *service) artifactKey(exportID string, format Format) string {
 	return fmt.Sprintf("exports/%s.%s", exportID, format)
 }
 
+func (s *service) putManifest(ctx context.Context, manifest Manifest) (string, error) {
+	signed, err := SignManifest(ctx, s.manifestSigner, manifest)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(signed)
+	if err != nil {
+		return "", NewError(KindInternal, "manifest encoding failed", err)
+	}
+	key := ManifestKey(manifest.ArtifactKey)
+	_, err = s.store.Put(ctx, key, bytes.NewReader(payload), ArtifactMeta{
+		ContentType: "application/json",
+		Filename:    path.Base(key),
+		TenantID:    manifest.TenantID,
+		CreatedAt:   manifest.GeneratedAt,
+	})
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
 func (s *service) nextID() string {
 	if s.idGenerator == nil {
 		s.idGenerator = defaultIDGenerator()
@@ -620,6 +1140,7 @@ func (s *service) updateArtifact(ctx context.Context, exportID string, ref Artif
 			return
 		}
 		record.Artifact = ref
+		record.Checksum = ref.Meta.Checksum
 		_ = updater.Update(ctx, record)
 	}
 }
@@ -681,6 +1202,24 @@ func (t runnerTracker) List(ctx context.Context, filter ProgressFilter) ([]Expor
 	return t.base.List(ctx, filter)
 }
 
+func (t runnerTracker) Heartbeat(ctx context.Context, id string, at time.Time) error {
+	return recordHeartbeat(ctx, t.base, id, at)
+}
+
+func (t runnerTracker) SetWatermark(ctx context.Context, id string, watermark Watermark) error {
+	if t.base == nil {
+		return nil
+	}
+	return recordWatermark(ctx, t.base, id, watermark)
+}
+
+func (t runnerTracker) FindByWatermark(ctx context.Context, token string) (ExportRecord, error) {
+	if wt, ok := t.base.(WatermarkTracker); ok {
+		return wt.FindByWatermark(ctx, token)
+	}
+	return ExportRecord{}, NewError(KindNotImpl, "tracker does not support watermarks", nil)
+}
+
 type staticActorProvider struct {
 	actor Actor
 }
@@ -707,6 +1246,8 @@ func recordFromResult(actor Actor, resolved ResolvedExport, result ExportResult,
 			Processed: result.Rows,
 		},
 		BytesWritten: result.Bytes,
+		Access:       resolved.Access,
+		Parameters:   resolved.Request.Parameters,
 		CreatedAt:    now,
 		StartedAt:    now,
 		CompletedAt:  now,
@@ -743,6 +1284,7 @@ func (SoftDeleteStrategy) Delete(ctx context.Context, params DeleteParams) error
 			if err := params.Store.Delete(ctx, artifactKey); err != nil {
 				return err
 			}
+			_ = params.Store.Delete(ctx, ManifestKey(artifactKey))
 		}
 	}
 	updated := params.Record
@@ -771,6 +1313,7 @@ func (s TombstoneDeleteStrategy) Delete(ctx context.Context, params DeleteParams
 			if err := params.Store.Delete(ctx, artifactKey); err != nil {
 				return err
 			}
+			_ = params.Store.Delete(ctx, ManifestKey(artifactKey))
 		}
 	}
 	updated := params.Record
@@ -794,6 +1337,38 @@ func updateRecord(ctx context.Context, tracker ProgressTracker, record ExportRec
 	return tracker.SetState(ctx, record.ID, record.State, nil)
 }
 
+// transitionState moves a record between states when the tracker supports
+// it atomically; otherwise it re-reads the state before setting it.
+func transitionState(ctx context.Context, tracker ProgressTracker, id string, from, to ExportState) (bool, error) {
+	if transitioner, ok := tracker.(StateTransitioner); ok {
+		return transitioner.TransitionState(ctx, id, from, to)
+	}
+	record, err := tracker.Status(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if record.State != from {
+		return false, nil
+	}
+	return true, tracker.SetState(ctx, id, to, nil)
+}
+
+func checkApprovalGate(record ExportRecord, required bool) error {
+	switch record.State {
+	case StatePendingApproval:
+		return NewError(KindValidation, "export is pending approval", nil)
+	case StateRejected:
+		return NewError(KindValidation, "export was rejected", nil)
+	}
+	if !required {
+		return nil
+	}
+	if record.Approval == nil || record.Approval.Decision != ApprovalApproved {
+		return NewError(KindValidation, "export requires approval", nil)
+	}
+	return nil
+}
+
 func isZeroDeliveryPolicy(policy DeliveryPolicy) bool {
 	return policy.Default == "" &&
 		policy.Thresholds.MaxRows == 0 &&
@@ -803,7 +1378,7 @@ func isZeroDeliveryPolicy(policy DeliveryPolicy) bool {
 
 func isCancelableState(state ExportState) bool {
 	switch state {
-	case StateQueued, StateRunning, StatePublishing:
+	case StatePendingApproval, StateQueued, StateRunning, StatePublishing:
 		return true
 	default:
 		return false
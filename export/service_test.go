@@ -6,6 +6,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	errorslib "github.com/goliatone/go-errors"
 )
 
 func TestService_DeleteExport_TombstoneStrategy(t *testing.T) {
@@ -76,3 +78,61 @@ func TestService_DeleteExport_TombstoneStrategy(t *testing.T) {
 		t.Fatalf("expected not found error, got %v", err)
 	}
 }
+
+func TestService_DownloadsRequireAdmin(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewMemoryTracker()
+	if _, err := tracker.Start(ctx, ExportRecord{ID: "exp-1", Scope: Scope{TenantID: "acme"}}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	downloads := NewMemoryDownloadLog()
+	svc := NewService(ServiceConfig{
+		Runner:      NewRunner(),
+		Tracker:     tracker,
+		Store:       NewMemoryStore(),
+		Guard:       roleAdminGuard{},
+		DownloadLog: downloads,
+	})
+	owner := Actor{ID: "user-1", Scope: Scope{TenantID: "acme"}}
+	if err := svc.RecordDownload(ctx, owner, DownloadRecord{ExportID: "exp-1", IP: "203.0.113.7"}); err != nil {
+		t.Fatalf("record download: %v", err)
+	}
+
+	// Download access is not enough to see who else downloaded the export.
+	if _, err := svc.Downloads(ctx, owner, "exp-1"); err == nil {
+		t.Fatalf("expected non-admin to be denied")
+	}
+	records, err := svc.Downloads(ctx, Actor{ID: "auditor", Roles: []string{"admin"}, Scope: Scope{TenantID: "acme"}}, "exp-1")
+	if err != nil {
+		t.Fatalf("admin downloads: %v", err)
+	}
+	if len(records) != 1 || records[0].IP != "203.0.113.7" {
+		t.Fatalf("unexpected downloads %+v", records)
+	}
+
+	_, err = svc.Downloads(ctx, Actor{ID: "auditor", Roles: []string{"admin"}, Scope: Scope{TenantID: "other"}}, "exp-1")
+	var goErr *errorslib.Error
+	if !errors.As(err, &goErr) || goErr.Category != errorslib.CategoryNotFound {
+		t.Fatalf("expected cross-tenant admin to get not found, got %v", err)
+	}
+}
+
+func TestService_DownloadsWithoutAdminGuardDenied(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewMemoryTracker()
+	if _, err := tracker.Start(ctx, ExportRecord{ID: "exp-1"}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	svc := NewService(ServiceConfig{
+		Runner:      NewRunner(),
+		Tracker:     tracker,
+		Store:       NewMemoryStore(),
+		DownloadLog: NewMemoryDownloadLog(),
+	})
+	if err := svc.RecordDownload(ctx, Actor{ID: "user-1"}, DownloadRecord{ExportID: "exp-1", IP: "203.0.113.7"}); err != nil {
+		t.Fatalf("record download: %v", err)
+	}
+	if _, err := svc.Downloads(ctx, Actor{ID: "user-1", Roles: []string{"admin"}}, "exp-1"); err == nil {
+		t.Fatalf("expected download log to be denied without an admin guard")
+	}
+}
@@ -83,6 +83,12 @@ type ExportRequest struct {
 	EstimatedDuration time.Duration
 	Output            io.Writer
 	RenderOptions     RenderOptions
+	// ScheduledAt is the logical run time of a scheduled run; zero for
+	// ad-hoc requests. Catch-up runs carry their missed slot time.
+	ScheduledAt time.Time
+	// Parameters records query parameter templates resolved by
+	// ResolveParams, keyed by path.
+	Parameters map[string]string
 }
 
 // ExportDefinition declares an exportable dataset.
@@ -100,6 +106,7 @@ type ExportDefinition struct {
 	Policy           ExportPolicy
 	DeliveryPolicy   *DeliveryPolicy
 	Template         TemplateOptions
+	Approval         *ApprovalPolicy
 }
 
 // SourceVariant allows alternate sources and policy overrides.
@@ -110,16 +117,23 @@ type SourceVariant struct {
 	Transformers    []TransformerConfig
 	Policy          *ExportPolicy
 	Template        *TemplateOptions
+	Approval        *ApprovalPolicy
 }
 
-// ExportPolicy enforces export limits and redaction.
+// ExportPolicy enforces export limits, redaction, and masking.
 type ExportPolicy struct {
 	AllowedColumns []string
 	RedactColumns  []string
 	RedactionValue any
+	MaskColumns    []ColumnMask
+	MaskKey        []byte
+	TokenVault     TokenVault
+	ColumnRules    []ColumnRule
 	MaxRows        int
 	MaxBytes       int64
 	MaxDuration    time.Duration
+	// Watermark forces leak-tracing watermarks for every export of the definition.
+	Watermark bool
 }
 
 // DeliveryPolicy configures delivery selection thresholds.
@@ -166,31 +180,41 @@ type ExportCounts struct {
 type ExportState string
 
 const (
-	StateQueued     ExportState = "queued"
-	StateRunning    ExportState = "running"
-	StatePublishing ExportState = "publishing"
-	StateCompleted  ExportState = "completed"
-	StateFailed     ExportState = "failed"
-	StateCanceled   ExportState = "canceled"
-	StateDeleted    ExportState = "deleted"
+	StatePendingApproval ExportState = "pending_approval"
+	StateQueued          ExportState = "queued"
+	StateRunning         ExportState = "running"
+	StatePublishing      ExportState = "publishing"
+	StateCompleted       ExportState = "completed"
+	StateFailed          ExportState = "failed"
+	StateCanceled        ExportState = "canceled"
+	StateRejected        ExportState = "rejected"
+	StateDeleted         ExportState = "deleted"
 )
 
 // ExportRecord captures tracker state for an export.
 type ExportRecord struct {
-	ID           string        `json:"id"`
-	Definition   string        `json:"definition"`
-	Format       Format        `json:"format"`
-	State        ExportState   `json:"state"`
-	RequestedBy  Actor         `json:"requested_by"`
-	Scope        Scope         `json:"scope"`
-	Request      ExportRequest `json:"-"`
-	Counts       ExportCounts  `json:"counts"`
-	BytesWritten int64         `json:"bytes_written,omitempty"`
-	Artifact     ArtifactRef   `json:"artifact"`
-	CreatedAt    time.Time     `json:"created_at"`
-	StartedAt    time.Time     `json:"started_at"`
-	CompletedAt  time.Time     `json:"completed_at"`
-	ExpiresAt    time.Time     `json:"expires_at"`
+	ID           string          `json:"id"`
+	Definition   string          `json:"definition"`
+	Format       Format          `json:"format"`
+	State        ExportState     `json:"state"`
+	RequestedBy  Actor           `json:"requested_by"`
+	Scope        Scope           `json:"scope"`
+	Request      ExportRequest   `json:"-"`
+	Counts       ExportCounts    `json:"counts"`
+	BytesWritten int64           `json:"bytes_written,omitempty"`
+	Artifact     ArtifactRef     `json:"artifact"`
+	Access       ColumnAccess    `json:"access"`
+	Approval     *ApprovalRecord `json:"approval,omitempty"`
+	Watermark    *Watermark      `json:"watermark,omitempty"`
+	Checksum     string          `json:"checksum,omitempty"`
+	// Parameters holds the resolved query parameter templates for the run.
+	Parameters map[string]string `json:"parameters,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	StartedAt  time.Time         `json:"started_at"`
+	// HeartbeatAt is touched periodically while the export is running.
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
 }
 
 // Actor identifies the requesting principal.
@@ -209,13 +233,17 @@ type Scope struct {
 
 // ExportResult captures a completed export.
 type ExportResult struct {
-	ID       string       `json:"id"`
-	Delivery DeliveryMode `json:"delivery"`
-	Format   Format       `json:"format"`
-	Rows     int64        `json:"rows"`
-	Bytes    int64        `json:"bytes"`
-	Filename string       `json:"filename"`
-	Artifact *ArtifactRef `json:"artifact,omitempty"`
+	ID        string       `json:"id"`
+	Delivery  DeliveryMode `json:"delivery"`
+	Format    Format       `json:"format"`
+	Rows      int64        `json:"rows"`
+	Bytes     int64        `json:"bytes"`
+	Filename  string       `json:"filename"`
+	Artifact  *ArtifactRef `json:"artifact,omitempty"`
+	Watermark *Watermark   `json:"watermark,omitempty"`
+	Checksum  string       `json:"checksum,omitempty"`
+	// ManifestKey is the store key of the signed provenance manifest, when one was written.
+	ManifestKey string `json:"manifest_key,omitempty"`
 }
 
 // Row is a column-aligned record.
@@ -352,22 +380,37 @@ type FormatOptions struct {
 
 // RenderOptions configures renderer behavior.
 type RenderOptions struct {
-	CSV      CSVOptions
-	JSON     JSONOptions
-	Template TemplateOptions
-	XLSX     XLSXOptions
-	SQLite   SQLiteOptions
-	PDF      PDFOptions
-	Format   FormatOptions
+	CSV       CSVOptions
+	JSON      JSONOptions
+	Template  TemplateOptions
+	XLSX      XLSXOptions
+	SQLite    SQLiteOptions
+	PDF       PDFOptions
+	Format    FormatOptions
+	Watermark WatermarkOptions
 }
 
 // ArtifactMeta captures stored artifact metadata.
+// Checksum is the hex-encoded SHA-256 of the artifact content. TenantID scopes
+// per-tenant encryption keys; Encryption is set by encrypting stores.
 type ArtifactMeta struct {
-	ContentType string    `json:"content_type,omitempty"`
-	Size        int64     `json:"size,omitempty"`
-	Filename    string    `json:"filename,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	ExpiresAt   time.Time `json:"expires_at"`
+	ContentType string              `json:"content_type,omitempty"`
+	Size        int64               `json:"size,omitempty"`
+	Filename    string              `json:"filename,omitempty"`
+	Checksum    string              `json:"checksum,omitempty"`
+	TenantID    string              `json:"tenant_id,omitempty"`
+	Encryption  *ArtifactEncryption `json:"encryption,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	ExpiresAt   time.Time           `json:"expires_at"`
+}
+
+// ArtifactEncryption describes envelope encryption applied to a stored artifact.
+// WrappedKey is the data key encrypted by the tenant key identified by KeyID.
+type ArtifactEncryption struct {
+	Algorithm  string `json:"alg"`
+	KeyID      string `json:"kid"`
+	WrappedKey []byte `json:"wrapped_key"`
+	ChunkSize  int    `json:"chunk_size"`
 }
 
 // ArtifactRef references a stored artifact.
@@ -384,6 +427,12 @@ type ArtifactStore interface {
 	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
 }
 
+// RangeOpener is implemented by stores that can read a byte range of an artifact.
+// A negative length reads to the end of the artifact.
+type RangeOpener interface {
+	OpenRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, ArtifactMeta, error)
+}
+
 // ProgressDelta indicates progress changes.
 type ProgressDelta struct {
 	Rows  int64
@@ -416,6 +465,13 @@ type RecordUpdater interface {
 	Update(ctx context.Context, record ExportRecord) error
 }
 
+// StateTransitioner is optionally implemented by trackers that can change a
+// record's state only while it is still in the expected state. It reports
+// false, without error, when the record has moved on.
+type StateTransitioner interface {
+	TransitionState(ctx context.Context, id string, from, to ExportState) (bool, error)
+}
+
 // RecordDeleter removes records from the tracker.
 type RecordDeleter interface {
 	Delete(ctx context.Context, id string) error
@@ -435,6 +491,28 @@ type Guard interface {
 	AuthorizeDownload(ctx context.Context, actor Actor, exportID string) error
 }
 
+// RowLimitGuard is optionally implemented by a Guard to cap the rows an
+// authorized export may produce. The runner enforces the limit while rows
+// stream; 0 means no limit.
+type RowLimitGuard interface {
+	RowLimit(ctx context.Context, actor Actor, req ExportRequest, def ResolvedDefinition) int
+}
+
+// Admin resources checked through AdminGuard.
+const (
+	AdminApprovals  = "approvals"
+	AdminDeliveries = "deliveries"
+	AdminDownloads  = "downloads"
+	AdminSchedules  = "schedules"
+	AdminWatermarks = "watermarks"
+)
+
+// AdminGuard is optionally implemented by a Guard to grant access to data
+// owned by other actors, such as download logs and schedules.
+type AdminGuard interface {
+	AuthorizeAdmin(ctx context.Context, actor Actor, resource string) error
+}
+
 // ActorProvider extracts the actor from context.
 type ActorProvider interface {
 	FromContext(ctx context.Context) (Actor, error)
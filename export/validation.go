@@ -14,11 +14,21 @@ type ResolvedExport struct {
 	Columns       []Column
 	ColumnNames   []string
 	RedactIndices map[int]any
+	Access        ColumnAccess
 	Filename      string
 }
 
 // ResolveExport validates and resolves a request against a definition.
+// Column rules are evaluated for an anonymous actor; use ResolveExportForActor
+// when the requesting actor is known.
 func ResolveExport(req ExportRequest, def ResolvedDefinition, now time.Time) (ResolvedExport, error) {
+	return ResolveExportForActor(req, def, Actor{}, now)
+}
+
+// ResolveExportForActor validates and resolves a request, applying the
+// definition's column rules for the actor. The effective policy replaces
+// def.Policy on the resolved definition.
+func ResolveExportForActor(req ExportRequest, def ResolvedDefinition, actor Actor, now time.Time) (ResolvedExport, error) {
 	req = normalizeRequest(req)
 
 	if !formatAllowed(req.Format, def.AllowedFormats) {
@@ -33,6 +43,15 @@ func ResolveExport(req ExportRequest, def ResolvedDefinition, now time.Time) (Re
 		return ResolvedExport{}, err
 	}
 
+	policy, rules, err := effectivePolicy(def.Schema.Columns, def.Policy, actor)
+	if err != nil {
+		return ResolvedExport{}, err
+	}
+	def.Policy = policy
+	if err := validateMasks(def.Policy); err != nil {
+		return ResolvedExport{}, err
+	}
+
 	columns, columnNames, redactions, err := resolveColumns(def.Schema.Columns, req.Columns, def.Policy)
 	if err != nil {
 		return ResolvedExport{}, err
@@ -76,6 +95,7 @@ func ResolveExport(req ExportRequest, def ResolvedDefinition, now time.Time) (Re
 		Columns:       columns,
 		ColumnNames:   columnNames,
 		RedactIndices: redactions,
+		Access:        buildColumnAccess(columnNames, def.Policy, rules),
 		Filename:      filename,
 	}, nil
 }
@@ -251,6 +271,18 @@ func mergePolicy(base ExportPolicy, override ExportPolicy) ExportPolicy {
 	if override.RedactionValue != nil {
 		merged.RedactionValue = override.RedactionValue
 	}
+	if len(override.MaskColumns) > 0 {
+		merged.MaskColumns = override.MaskColumns
+	}
+	if len(override.MaskKey) > 0 {
+		merged.MaskKey = override.MaskKey
+	}
+	if override.TokenVault != nil {
+		merged.TokenVault = override.TokenVault
+	}
+	if len(override.ColumnRules) > 0 {
+		merged.ColumnRules = override.ColumnRules
+	}
 	if override.MaxRows > 0 {
 		merged.MaxRows = override.MaxRows
 	}
@@ -260,5 +292,8 @@ func mergePolicy(base ExportPolicy, override ExportPolicy) ExportPolicy {
 	if override.MaxDuration > 0 {
 		merged.MaxDuration = override.MaxDuration
 	}
+	if override.Watermark {
+		merged.Watermark = true
+	}
 	return merged
 }
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// Watermark identifies who generated an artifact and when.
+type Watermark struct {
+	Token       string    `json:"token"`
+	ExportID    string    `json:"export_id"`
+	ActorID     string    `json:"actor_id,omitempty"`
+	TenantID    string    `json:"tenant_id,omitempty"`
+	Definition  string    `json:"definition,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// WatermarkOptions configures leak-tracing watermarks embedded in artifacts.
+type WatermarkOptions struct {
+	Enabled bool
+	// CSVCanaryRows appends a trailing row carrying the watermark token to CSV output.
+	CSVCanaryRows bool
+	// PDFVisible renders the watermark as a visible footer instead of invisible text.
+	PDFVisible bool
+	// Stamp is set by the runner; renderers embed it when non-zero.
+	Stamp Watermark
+}
+
+// WatermarkTracker records and looks up artifact watermarks.
+type WatermarkTracker interface {
+	SetWatermark(ctx context.Context, id string, watermark Watermark) error
+	FindByWatermark(ctx context.Context, token string) (ExportRecord, error)
+}
+
+// IsZero reports whether the watermark is unset.
+func (w Watermark) IsZero() bool {
+	return w.Token == "" && w.ExportID == ""
+}
+
+// String renders the watermark as a single line of key=value pairs.
+func (w Watermark) String() string {
+	parts := []string{"export=" + w.ExportID}
+	if w.ActorID != "" {
+		parts = append(parts, "actor="+w.ActorID)
+	}
+	if w.TenantID != "" {
+		parts = append(parts, "tenant="+w.TenantID)
+	}
+	if !w.GeneratedAt.IsZero() {
+		parts = append(parts, "at="+w.GeneratedAt.UTC().Format(time.RFC3339))
+	}
+	parts = append(parts, "token="+w.Token)
+	return strings.Join(parts, " ")
+}
+
+// Fields returns the watermark as ordered key/value pairs for metadata tables.
+func (w Watermark) Fields() [][2]string {
+	generatedAt := ""
+	if !w.GeneratedAt.IsZero() {
+		generatedAt = w.GeneratedAt.UTC().Format(time.RFC3339)
+	}
+	return [][2]string{
+		{"token", w.Token},
+		{"export_id", w.ExportID},
+		{"actor_id", w.ActorID},
+		{"tenant_id", w.TenantID},
+		{"definition", w.Definition},
+		{"generated_at", generatedAt},
+	}
+}
+
+// WatermarkHTML returns markup that embeds the watermark into HTML documents
+// before PDF conversion. Invisible marks stay extractable as PDF text.
+func WatermarkHTML(w Watermark, visible bool) string {
+	text := html.EscapeString(w.String())
+	if visible {
+		return fmt.Sprintf(`<div class="export-watermark" style="position:fixed;bottom:4px;right:8px;font-size:8px;color:#999;">%s</div>`, text)
+	}
+	return fmt.Sprintf(`<div class="export-watermark" style="position:fixed;bottom:0;left:0;font-size:1px;color:rgba(255,255,255,0.01);">%s</div>`, text)
+}
+
+// InjectWatermarkHTML inserts the watermark markup before </body>, or appends it.
+func InjectWatermarkHTML(doc []byte, w Watermark, visible bool) []byte {
+	mark := WatermarkHTML(w, visible)
+	lower := strings.ToLower(string(doc))
+	idx := strings.LastIndex(lower, "</body>")
+	if idx < 0 {
+		return append(doc, mark...)
+	}
+	out := make([]byte, 0, len(doc)+len(mark))
+	out = append(out, doc[:idx]...)
+	out = append(out, mark...)
+	out = append(out, doc[idx:]...)
+	return out
+}
+
+func newWatermark(exportID string, actor Actor, definition string, now time.Time) (Watermark, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return Watermark{}, NewError(KindInternal, "watermark token generation failed", err)
+	}
+	return Watermark{
+		Token:       "wm_" + hex.EncodeToString(buf),
+		ExportID:    exportID,
+		ActorID:     actor.ID,
+		TenantID:    actor.Scope.TenantID,
+		Definition:  definition,
+		GeneratedAt: now,
+	}, nil
+}
+
+func recordWatermark(ctx context.Context, tracker ProgressTracker, id string, watermark Watermark) error {
+	if tracker == nil {
+		return nil
+	}
+	if wt, ok := tracker.(WatermarkTracker); ok {
+		return wt.SetWatermark(ctx, id, watermark)
+	}
+	if updater, ok := tracker.(RecordUpdater); ok {
+		record, err := tracker.Status(ctx, id)
+		if err != nil {
+			return err
+		}
+		record.Watermark = &watermark
+		return updater.Update(ctx, record)
+	}
+	return NewError(KindNotImpl, "tracker does not support watermarks", nil)
+}
+
+// Active reports whether a stamped watermark should be embedded.
+func (o WatermarkOptions) Active() bool {
+	return o.Enabled && !o.Stamp.IsZero()
+}
@@ -0,0 +1,251 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func newWatermarkRunner(t *testing.T, policy ExportPolicy) (*Runner, *MemoryTracker) {
+	t.Helper()
+	runner := NewRunner()
+	tracker := NewMemoryTracker()
+	runner.Tracker = tracker
+	runner.Now = func() time.Time { return time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC) }
+	runner.ActorProvider = stubActorProvider{actor: Actor{ID: "user-7", Scope: Scope{TenantID: "acme"}}}
+	if err := runner.Definitions.Register(ExportDefinition{
+		Name:         "users",
+		RowSourceKey: "stub",
+		Schema: Schema{Columns: []Column{
+			{Name: "id"},
+			{Name: "email"},
+		}},
+		Policy: policy,
+	}); err != nil {
+		t.Fatalf("register definition: %v", err)
+	}
+	if err := runner.RowSources.Register("stub", func(req ExportRequest, def ResolvedDefinition) (RowSource, error) {
+		_ = req
+		_ = def
+		return &stubSource{iter: &stubIterator{rows: []Row{{"1", "a@example.com"}}}}, nil
+	}); err != nil {
+		t.Fatalf("register source: %v", err)
+	}
+	return runner, tracker
+}
+
+func TestRunner_WatermarksCSVAndTracksLookup(t *testing.T) {
+	runner, tracker := newWatermarkRunner(t, ExportPolicy{Watermark: true})
+	buf := &bytes.Buffer{}
+
+	result, err := runner.Run(context.Background(), ExportRequest{
+		Definition: "users",
+		Format:     FormatCSV,
+		Output:     buf,
+		RenderOptions: RenderOptions{
+			Watermark: WatermarkOptions{CSVCanaryRows: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if result.Watermark == nil || !strings.HasPrefix(result.Watermark.Token, "wm_") {
+		t.Fatalf("expected watermark on result, got %+v", result.Watermark)
+	}
+	if result.Watermark.ActorID != "user-7" || result.Watermark.TenantID != "acme" || result.Watermark.ExportID != result.ID {
+		t.Fatalf("unexpected watermark: %+v", result.Watermark)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	last := records[len(records)-1]
+	if last[0] != result.Watermark.Token || !strings.Contains(last[1], "actor=user-7") {
+		t.Fatalf("expected canary row, got %v", last)
+	}
+
+	record, err := tracker.FindByWatermark(context.Background(), result.Watermark.Token)
+	if err != nil {
+		t.Fatalf("find by watermark: %v", err)
+	}
+	if record.ID != result.ID {
+		t.Fatalf("expected record %s, got %s", result.ID, record.ID)
+	}
+	if _, err := tracker.FindByWatermark(context.Background(), "wm_missing"); err == nil {
+		t.Fatalf("expected unknown watermark to fail")
+	}
+}
+
+type failingWatermarkTracker struct {
+	*MemoryTracker
+}
+
+func (t failingWatermarkTracker) SetWatermark(ctx context.Context, id string, watermark Watermark) error {
+	return errors.New("watermark index unavailable")
+}
+
+func TestRunner_FailsWhenWatermarkCannotBeRecorded(t *testing.T) {
+	runner, tracker := newWatermarkRunner(t, ExportPolicy{Watermark: true})
+	runner.Tracker = failingWatermarkTracker{MemoryTracker: tracker}
+	buf := &bytes.Buffer{}
+
+	if _, err := runner.Run(context.Background(), ExportRequest{
+		Definition: "users",
+		Format:     FormatCSV,
+		Output:     buf,
+	}); err == nil {
+		t.Fatalf("expected run to fail when the watermark is not recorded")
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected nothing rendered, got %q", buf.String())
+	}
+	records, err := tracker.List(context.Background(), ProgressFilter{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(records) != 1 || records[0].State != StateFailed {
+		t.Fatalf("expected failed export record, got %+v", records)
+	}
+}
+
+func TestService_LookupWatermarkRequiresAdminInScope(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewMemoryTracker()
+	if _, err := tracker.Start(ctx, ExportRecord{
+		ID:          "exp-1",
+		RequestedBy: Actor{ID: "user-7"},
+		Scope:       Scope{TenantID: "acme"},
+	}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := tracker.SetWatermark(ctx, "exp-1", Watermark{Token: "wm_1", ExportID: "exp-1"}); err != nil {
+		t.Fatalf("set watermark: %v", err)
+	}
+
+	if _, err := NewService(ServiceConfig{Tracker: tracker}).LookupWatermark(ctx, Actor{ID: "user-7"}, "wm_1"); err == nil {
+		t.Fatalf("expected lookup without an admin guard to be denied")
+	}
+	svc := NewService(ServiceConfig{Tracker: tracker, Guard: roleAdminGuard{}})
+	if _, err := svc.LookupWatermark(ctx, Actor{ID: "user-7", Scope: Scope{TenantID: "acme"}}, "wm_1"); err == nil {
+		t.Fatalf("expected non-admin lookup to be denied")
+	}
+	admin := Actor{ID: "root", Roles: []string{"admin"}, Scope: Scope{TenantID: "other"}}
+	if _, err := svc.LookupWatermark(ctx, admin, "wm_1"); err == nil {
+		t.Fatalf("expected lookup outside the admin scope to fail")
+	}
+	admin.Scope = Scope{TenantID: "acme"}
+	record, err := svc.LookupWatermark(ctx, admin, "wm_1")
+	if err != nil || record.ID != "exp-1" {
+		t.Fatalf("expected admin lookup to find exp-1, got %+v %v", record, err)
+	}
+}
+
+func TestRunner_WatermarksJSON(t *testing.T) {
+	runner, _ := newWatermarkRunner(t, ExportPolicy{})
+
+	buf := &bytes.Buffer{}
+	result, err := runner.Run(context.Background(), ExportRequest{
+		Definition:    "users",
+		Format:        FormatJSON,
+		Output:        buf,
+		RenderOptions: RenderOptions{Watermark: WatermarkOptions{Enabled: true}},
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	var envelope struct {
+		Watermark Watermark        `json:"watermark"`
+		Data      []map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &envelope); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	if envelope.Watermark.Token != result.Watermark.Token || len(envelope.Data) != 1 {
+		t.Fatalf("unexpected envelope: %+v", envelope)
+	}
+
+	buf.Reset()
+	if _, err := runner.Run(context.Background(), ExportRequest{
+		Definition:    "users",
+		Format:        FormatNDJSON,
+		Output:        buf,
+		RenderOptions: RenderOptions{Watermark: WatermarkOptions{Enabled: true}},
+	}); err != nil {
+		t.Fatalf("run ndjson: %v", err)
+	}
+	first := strings.SplitN(buf.String(), "\n", 2)[0]
+	if !strings.Contains(first, `"_watermark"`) {
+		t.Fatalf("expected leading watermark line, got %s", first)
+	}
+
+	buf.Reset()
+	plain, err := runner.Run(context.Background(), ExportRequest{
+		Definition: "users",
+		Format:     FormatJSON,
+		Output:     buf,
+	})
+	if err != nil {
+		t.Fatalf("run plain: %v", err)
+	}
+	if plain.Watermark != nil || !strings.HasPrefix(strings.TrimSpace(buf.String()), "[") {
+		t.Fatalf("expected unwatermarked array, got %s", buf.String())
+	}
+}
+
+func TestXLSXRenderer_Watermark(t *testing.T) {
+	stamp := Watermark{Token: "wm_abc", ExportID: "exp-1", ActorID: "user-7", GeneratedAt: time.Now()}
+	buf := &bytes.Buffer{}
+	_, err := XLSXRenderer{}.Render(context.Background(), Schema{Columns: []Column{{Name: "id"}}},
+		&stubIterator{rows: []Row{{"1"}}}, buf, RenderOptions{
+			Watermark: WatermarkOptions{Enabled: true, Stamp: stamp},
+		})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	file, err := excelize.OpenReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("open xlsx: %v", err)
+	}
+	props, err := file.GetDocProps()
+	if err != nil {
+		t.Fatalf("doc props: %v", err)
+	}
+	if props.Identifier != "wm_abc" || props.Creator != "user-7" {
+		t.Fatalf("unexpected doc props: %+v", props)
+	}
+	visible, err := file.GetSheetVisible(watermarkSheetName)
+	if err != nil {
+		t.Fatalf("sheet visible: %v", err)
+	}
+	if visible {
+		t.Fatalf("expected watermark sheet to be hidden")
+	}
+	token, err := file.GetCellValue(watermarkSheetName, "B1")
+	if err != nil {
+		t.Fatalf("cell value: %v", err)
+	}
+	if token != "wm_abc" {
+		t.Fatalf("expected token in hidden sheet, got %q", token)
+	}
+}
+
+func TestInjectWatermarkHTML(t *testing.T) {
+	stamp := Watermark{Token: "wm_abc", ExportID: "exp-1"}
+	out := string(InjectWatermarkHTML([]byte("<html><body><p>hi</p></BODY></html>"), stamp, true))
+	if !strings.Contains(out, "token=wm_abc</div></BODY>") {
+		t.Fatalf("expected watermark before body close, got %s", out)
+	}
+	out = string(InjectWatermarkHTML([]byte("<p>hi</p>"), stamp, false))
+	if !strings.HasSuffix(out, "</div>") {
+		t.Fatalf("expected watermark appended, got %s", out)
+	}
+}
@@ -57,3 +57,106 @@ func (h *DownloadMetadataHandler) Query(ctx context.Context, msg DownloadMetadat
 	}
 	return h.Service.DownloadMetadata(ctx, msg.Actor, msg.ExportID)
 }
+
+// ExportDownloadsHandler returns the download log of an export.
+type ExportDownloadsHandler struct {
+	Service export.Service
+}
+
+func NewExportDownloadsHandler(svc export.Service) *ExportDownloadsHandler {
+	return &ExportDownloadsHandler{Service: svc}
+}
+
+func (h *ExportDownloadsHandler) Query(ctx context.Context, msg ExportDownloads) ([]export.DownloadRecord, error) {
+	if h == nil || h.Service == nil {
+		return nil, errors.New("export service is required", errors.CategoryInternal).
+			WithTextCode("SERVICE_REQUIRED")
+	}
+	return h.Service.Downloads(ctx, msg.Actor, msg.ExportID)
+}
+
+// ExportDeliveriesHandler returns the delivery history of an export.
+type ExportDeliveriesHandler struct {
+	Service export.Service
+}
+
+func NewExportDeliveriesHandler(svc export.Service) *ExportDeliveriesHandler {
+	return &ExportDeliveriesHandler{Service: svc}
+}
+
+func (h *ExportDeliveriesHandler) Query(ctx context.Context, msg ExportDeliveries) ([]export.DeliveryRecord, error) {
+	if h == nil || h.Service == nil {
+		return nil, errors.New("export service is required", errors.CategoryInternal).
+			WithTextCode("SERVICE_REQUIRED")
+	}
+	return h.Service.Deliveries(ctx, msg.Actor, msg.ExportID)
+}
+
+// DeliveryHistoryHandler answers "did the partner receive the file?" across
+// exports.
+type DeliveryHistoryHandler struct {
+	Service export.Service
+}
+
+func NewDeliveryHistoryHandler(svc export.Service) *DeliveryHistoryHandler {
+	return &DeliveryHistoryHandler{Service: svc}
+}
+
+func (h *DeliveryHistoryHandler) Query(ctx context.Context, msg DeliveryHistory) ([]export.DeliveryRecord, error) {
+	if h == nil || h.Service == nil {
+		return nil, errors.New("export service is required", errors.CategoryInternal).
+			WithTextCode("SERVICE_REQUIRED")
+	}
+	return h.Service.DeliveryHistory(ctx, msg.Actor, msg.Filter)
+}
+
+// WatermarkLookupHandler answers "who exported this file" from tracked watermarks.
+type WatermarkLookupHandler struct {
+	Service export.Service
+}
+
+func NewWatermarkLookupHandler(svc export.Service) *WatermarkLookupHandler {
+	return &WatermarkLookupHandler{Service: svc}
+}
+
+func (h *WatermarkLookupHandler) Query(ctx context.Context, msg WatermarkLookup) (export.ExportRecord, error) {
+	if h == nil || h.Service == nil {
+		return export.ExportRecord{}, errors.New("export service is required", errors.CategoryInternal).
+			WithTextCode("SERVICE_REQUIRED")
+	}
+	return h.Service.LookupWatermark(ctx, msg.Actor, msg.Token)
+}
+
+// GetScheduleHandler returns a single schedule.
+type GetScheduleHandler struct {
+	Schedules export.ScheduleManager
+}
+
+func NewGetScheduleHandler(schedules export.ScheduleManager) *GetScheduleHandler {
+	return &GetScheduleHandler{Schedules: schedules}
+}
+
+func (h *GetScheduleHandler) Query(ctx context.Context, msg GetSchedule) (export.Schedule, error) {
+	if h == nil || h.Schedules == nil {
+		return export.Schedule{}, errors.New("schedule service is required", errors.CategoryInternal).
+			WithTextCode("SCHEDULE_SERVICE_REQUIRED")
+	}
+	return h.Schedules.GetSchedule(ctx, msg.Actor, msg.ScheduleID)
+}
+
+// ListSchedulesHandler lists schedules.
+type ListSchedulesHandler struct {
+	Schedules export.ScheduleManager
+}
+
+func NewListSchedulesHandler(schedules export.ScheduleManager) *ListSchedulesHandler {
+	return &ListSchedulesHandler{Schedules: schedules}
+}
+
+func (h *ListSchedulesHandler) Query(ctx context.Context, msg ListSchedules) ([]export.Schedule, error) {
+	if h == nil || h.Schedules == nil {
+		return nil, errors.New("schedule service is required", errors.CategoryInternal).
+			WithTextCode("SCHEDULE_SERVICE_REQUIRED")
+	}
+	return h.Schedules.ListSchedules(ctx, msg.Actor, msg.Filter)
+}
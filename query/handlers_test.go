@@ -58,3 +58,60 @@ func TestExportStatusHandler_GuardBlocks(t *testing.T) {
 		t.Fatalf("expected download guard to be called")
 	}
 }
+
+// adminGuard grants admin access to actors with the "admin" role.
+type adminGuard struct{}
+
+func (adminGuard) AuthorizeExport(ctx context.Context, actor export.Actor, req export.ExportRequest, def export.ResolvedDefinition) error {
+	return nil
+}
+
+func (adminGuard) AuthorizeDownload(ctx context.Context, actor export.Actor, exportID string) error {
+	return nil
+}
+
+func (adminGuard) AuthorizeAdmin(ctx context.Context, actor export.Actor, resource string) error {
+	for _, role := range actor.Roles {
+		if role == "admin" {
+			return nil
+		}
+	}
+	return errors.New("deny")
+}
+
+func TestDeliveryHistoryHandler_RequiresAdminAndScopesToActor(t *testing.T) {
+	tracker := export.NewMemoryDeliveryTracker()
+	for _, tenant := range []string{"tenant-1", "tenant-2"} {
+		if err := tracker.RecordDelivery(context.Background(), export.DeliveryRecord{
+			ExportID:   "exp-" + tenant,
+			Actor:      export.Actor{ID: "actor-1", Scope: export.Scope{TenantID: tenant}},
+			TargetKind: "email",
+			Target:     "partner@example.com",
+			Recipients: []string{"partner@example.com"},
+			Status:     export.DeliveryStatusSent,
+		}); err != nil {
+			t.Fatalf("record delivery: %v", err)
+		}
+	}
+
+	handler := NewDeliveryHistoryHandler(export.NewService(export.ServiceConfig{
+		Guard:           adminGuard{},
+		DeliveryTracker: tracker,
+	}))
+	if _, err := handler.Query(context.Background(), DeliveryHistory{
+		Actor: export.Actor{ID: "actor-1"},
+	}); err == nil {
+		t.Fatalf("expected non-admin delivery history to be denied")
+	}
+
+	records, err := handler.Query(context.Background(), DeliveryHistory{
+		Actor:  export.Actor{ID: "auditor", Roles: []string{"admin"}, Scope: export.Scope{TenantID: "tenant-1"}},
+		Filter: export.DeliveryFilter{Target: "partner@example.com", Scope: export.Scope{TenantID: "tenant-2"}},
+	})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(records) != 1 || records[0].ExportID != "exp-tenant-1" {
+		t.Fatalf("expected only tenant-1 delivery, got %+v", records)
+	}
+}
@@ -60,3 +60,116 @@ func (msg DownloadMetadata) Validate() error {
 	}
 	return nil
 }
+
+// ExportDownloads requests the download log of an export.
+type ExportDownloads struct {
+	Actor    export.Actor
+	ExportID string
+}
+
+func (ExportDownloads) Type() string { return "export:downloads" }
+
+func (msg ExportDownloads) Validate() error {
+	if msg.Actor.ID == "" {
+		return errors.New("actor ID is required", errors.CategoryValidation).
+			WithTextCode("ACTOR_REQUIRED")
+	}
+	if msg.ExportID == "" {
+		return errors.New("export ID is required", errors.CategoryValidation).
+			WithTextCode("EXPORT_ID_REQUIRED")
+	}
+	return nil
+}
+
+// ExportDeliveries requests the per-target delivery history of an export.
+type ExportDeliveries struct {
+	Actor    export.Actor
+	ExportID string
+}
+
+func (ExportDeliveries) Type() string { return "export:deliveries" }
+
+func (msg ExportDeliveries) Validate() error {
+	if msg.Actor.ID == "" {
+		return errors.New("actor ID is required", errors.CategoryValidation).
+			WithTextCode("ACTOR_REQUIRED")
+	}
+	if msg.ExportID == "" {
+		return errors.New("export ID is required", errors.CategoryValidation).
+			WithTextCode("EXPORT_ID_REQUIRED")
+	}
+	return nil
+}
+
+// DeliveryHistory searches delivery history across exports, e.g. by target
+// and time window.
+type DeliveryHistory struct {
+	Actor  export.Actor
+	Filter export.DeliveryFilter
+}
+
+func (DeliveryHistory) Type() string { return "export:delivery_history" }
+
+func (msg DeliveryHistory) Validate() error {
+	if msg.Actor.ID == "" {
+		return errors.New("actor ID is required", errors.CategoryValidation).
+			WithTextCode("ACTOR_REQUIRED")
+	}
+	return nil
+}
+
+// WatermarkLookup resolves a leaked artifact's watermark token to its export.
+type WatermarkLookup struct {
+	Actor export.Actor
+	Token string
+}
+
+func (WatermarkLookup) Type() string { return "export:watermark" }
+
+func (msg WatermarkLookup) Validate() error {
+	if msg.Actor.ID == "" {
+		return errors.New("actor ID is required", errors.CategoryValidation).
+			WithTextCode("ACTOR_REQUIRED")
+	}
+	if msg.Token == "" {
+		return errors.New("watermark token is required", errors.CategoryValidation).
+			WithTextCode("WATERMARK_TOKEN_REQUIRED")
+	}
+	return nil
+}
+
+// GetSchedule requests a single schedule.
+type GetSchedule struct {
+	Actor      export.Actor
+	ScheduleID string
+}
+
+func (GetSchedule) Type() string { return "export:schedule:get" }
+
+func (msg GetSchedule) Validate() error {
+	if msg.Actor.ID == "" {
+		return errors.New("actor ID is required", errors.CategoryValidation).
+			WithTextCode("ACTOR_REQUIRED")
+	}
+	if msg.ScheduleID == "" {
+		return errors.New("schedule ID is required", errors.CategoryValidation).
+			WithTextCode("SCHEDULE_ID_REQUIRED")
+	}
+	return nil
+}
+
+// ListSchedules requests schedules visible to the actor.
+type ListSchedules struct {
+	Actor  export.Actor
+	Filter export.ScheduleFilter
+}
+
+func (ListSchedules) Type() string { return "export:schedule:list" }
+
+func (msg ListSchedules) Validate() error {
+	if msg.Actor.ID == "" {
+		return errors.New("actor ID is required", errors.CategoryValidation).
+			WithTextCode("ACTOR_REQUIRED")
+	}
+	return nil
+}